@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// GetVStreamCursors makes a GetVStreamCursors gRPC call to a vtctld.
+	GetVStreamCursors = &cobra.Command{
+		Use:                   "GetVStreamCursors",
+		Short:                 "Displays the positions of the VStream cursors.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		RunE:                  commandGetVStreamCursors,
+	}
+	// ResetVStreamCursor makes a ResetVStreamCursor gRPC call to a vtctld.
+	ResetVStreamCursor = &cobra.Command{
+		Use:   "ResetVStreamCursor [--vgtid VGTID] <name>",
+		Short: "Sets the position of a VStream cursor, or deletes the cursor.",
+		Long: `Sets the position of a VStream cursor to the given VGtid, specified as JSON.
+
+Without --vgtid, the cursor is deleted, and the next VStream using it starts from its requested position.`,
+		Example: `ResetVStreamCursor --vgtid '{"shard_gtids":[{"keyspace":"commerce","shard":"0","gtid":"current"}]}' orders
+ResetVStreamCursor orders`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandResetVStreamCursor,
+	}
+)
+
+func commandGetVStreamCursors(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetVStreamCursors(commandCtx, &vtctldatapb.GetVStreamCursorsRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var resetVStreamCursorOptions = struct {
+	VGtid string
+}{}
+
+func commandResetVStreamCursor(cmd *cobra.Command, args []string) error {
+	var vgtid *binlogdatapb.VGtid
+	if resetVStreamCursorOptions.VGtid != "" {
+		vgtid = &binlogdatapb.VGtid{}
+		if err := json2.Unmarshal([]byte(resetVStreamCursorOptions.VGtid), vgtid); err != nil {
+			return fmt.Errorf("invalid --vgtid: %w", err)
+		}
+	}
+
+	cli.FinishedParsing(cmd)
+
+	name := cmd.Flags().Arg(0)
+	_, err := client.ResetVStreamCursor(commandCtx, &vtctldatapb.ResetVStreamCursorRequest{
+		Name:  name,
+		Vgtid: vgtid,
+	})
+	if err != nil {
+		return err
+	}
+
+	if vgtid == nil {
+		fmt.Printf("Successfully deleted VStream cursor %s.\n", name)
+	} else {
+		fmt.Printf("Successfully reset VStream cursor %s.\n", name)
+	}
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(GetVStreamCursors)
+
+	ResetVStreamCursor.Flags().StringVar(&resetVStreamCursorOptions.VGtid, "vgtid", "", "Position to set the cursor to, specified as a JSON VGtid. The cursor is deleted if it is not specified.")
+	Root.AddCommand(ResetVStreamCursor)
+}
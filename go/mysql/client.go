@@ -174,7 +174,7 @@ func Connect(ctx context.Context, params *ConnParams) (*Conn, error) {
 // Ping implements mysql ping command.
 func (c *Conn) Ping() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComPing
 
@@ -195,6 +195,68 @@ func (c *Conn) Ping() error {
 	return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected packet type: %d", data[0])
 }
 
+// ChangeUser implements the mysql COM_CHANGE_USER command. It
+// re-authenticates the connection with the user, password and database
+// from the given params. The server resets the session state.
+// Returns a SQLError.
+func (c *Conn) ChangeUser(params *ConnParams) error {
+	// This is a new command, need to reset the sequence.
+	c.resetSequence()
+
+	charset, err := collations.Local().ParseConnectionCharset(params.Charset)
+	if err != nil {
+		return err
+	}
+
+	// The password is scrambled with the salt of the initial handshake, even
+	// if an auth switch sent another one since, or sent in clear text if the
+	// server switched to mysql_clear_password.
+	var scrambledPassword []byte
+	switch c.authPluginName {
+	case MysqlClearPassword:
+		scrambledPassword = append([]byte(params.Pass), 0)
+	case CachingSha2Password:
+		scrambledPassword = ScrambleCachingSha2Password(c.handshakeSalt, []byte(params.Pass))
+	default:
+		scrambledPassword = ScrambleMysqlNativePassword(c.handshakeSalt, []byte(params.Pass))
+	}
+	if len(scrambledPassword) > 255 {
+		return sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "ChangeUser: password is too long")
+	}
+
+	length := 1 + // ComChangeUser
+		lenNullString(params.Uname) +
+		1 + len(scrambledPassword) +
+		lenNullString(params.DbName) +
+		2 + // Character set.
+		lenNullString(string(c.authPluginName))
+
+	data, pos := c.startEphemeralPacketWithHeader(length)
+	pos = writeByte(data, pos, ComChangeUser)
+	pos = writeNullString(data, pos, params.Uname)
+	pos = writeByte(data, pos, byte(len(scrambledPassword)))
+	pos += copy(data[pos:], scrambledPassword)
+	pos = writeNullString(data, pos, params.DbName)
+	pos = writeUint16(data, pos, uint16(charset))
+	pos = writeNullString(data, pos, string(c.authPluginName))
+
+	// Sanity-check the length.
+	if pos != len(data) {
+		return sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "ChangeUser: only packed %v bytes, out of %v allocated", pos, len(data))
+	}
+
+	if err := c.writeEphemeralPacket(); err != nil {
+		return sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+
+	// Read the server response, this may involve an auth switch.
+	if err := c.handleAuthResponse(params); err != nil {
+		return err
+	}
+	c.schemaName = params.DbName
+	return nil
+}
+
 // clientHandshake handles the client side of the handshake.
 // Note the connection can be closed while this is running.
 // Returns a SQLError.
@@ -216,6 +278,7 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 	}
 	c.fillFlavor(params)
 	c.salt = salt
+	c.handshakeSalt = salt
 
 	// Sanity check.
 	if capabilities&CapabilityClientProtocol41 == 0 {
@@ -289,6 +352,17 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 		scrambledPassword = ScrambleMysqlNativePassword(salt, []byte(params.Pass))
 	}
 
+	// Compression, only if the server supports it.
+	switch params.Compression {
+	case "":
+	case CompressionZlib:
+		c.Capabilities |= capabilities & CapabilityClientCompress
+	case CompressionZstd:
+		c.Capabilities |= capabilities & CapabilityClientZstdCompressionAlgorithm
+	default:
+		return sqlerror.NewSQLError(sqlerror.CRUnknownError, sqlerror.SSUnknownSQLState, "unsupported compression algorithm: %q", params.Compression)
+	}
+
 	// Client Session Tracking Capability.
 	if capabilities&CapabilityClientSessionTrack == CapabilityClientSessionTrack {
 		// If the server also supports it, we will have enabled
@@ -311,6 +385,13 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 		return err
 	}
 
+	// Everything after the OK packet is compressed, if negotiated.
+	if algorithm := c.negotiatedCompression(); algorithm != "" {
+		if err := c.enableCompression(algorithm, params.ZstdCompressionLevel); err != nil {
+			return err
+		}
+	}
+
 	// If the server didn't support DbName in its handshake, set
 	// it now. This is what the 'mysql' client does.
 	if capabilities&CapabilityClientConnectWithDB == 0 && params.DbName != "" {
@@ -542,7 +623,9 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 		CapabilityClientFoundRows&uint32(params.Flags) |
 		// If the server supported
 		// CapabilityClientSessionTrack, we also support it.
-		c.Capabilities&CapabilityClientSessionTrack
+		c.Capabilities&CapabilityClientSessionTrack |
+		// Compression, if requested and supported by the server.
+		c.Capabilities&(CapabilityClientCompress|CapabilityClientZstdCompressionAlgorithm)
 
 	// FIXME(alainjobart) add multi statement.
 
@@ -557,6 +640,11 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 			len(c.authPluginName) +
 			1 // terminating zero.
 
+	// The zstd compression level comes last.
+	if capabilityFlags&CapabilityClientZstdCompressionAlgorithm != 0 {
+		length++
+	}
+
 	// Add the DB name if the server supports it.
 	if params.DbName != "" && (capabilities&CapabilityClientConnectWithDB != 0) {
 		capabilityFlags |= CapabilityClientConnectWithDB
@@ -605,6 +693,14 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 	// Assume native client during response
 	pos = writeNullString(data, pos, string(c.authPluginName))
 
+	if capabilityFlags&CapabilityClientZstdCompressionAlgorithm != 0 {
+		level := params.ZstdCompressionLevel
+		if level == 0 {
+			level = DefaultZstdCompressionLevel
+		}
+		pos = writeByte(data, pos, byte(level))
+	}
+
 	// Sanity-check the length.
 	if pos != len(data) {
 		return sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "writeHandshakeResponse41: only packed %v bytes, out of %v allocated", pos, len(data))
@@ -88,6 +88,11 @@ type Conn struct {
 	// salt is sent by the server during initial handshake to be used for authentication
 	salt []byte
 
+	// handshakeSalt is the salt of the initial handshake, on the client side.
+	// Unlike salt, it is not replaced by an auth switch, as COM_CHANGE_USER
+	// is always scrambled with it.
+	handshakeSalt []byte
+
 	// authPluginName is the name of server's authentication plugin.
 	// It is set during the initial handshake.
 	authPluginName AuthMethodDescription
@@ -130,6 +135,14 @@ type Conn struct {
 	flushTimer     *time.Timer
 	header         [packetHeaderSize]byte
 
+	// compression is set once the compressed protocol was negotiated
+	// during the handshake. It sits between the packet layer and conn.
+	compression *compressedConn
+
+	// zstdCompressionLevel is the zstd level requested by the client
+	// in the handshake, when using CapabilityClientZstdCompressionAlgorithm.
+	zstdCompressionLevel int
+
 	// Keep track of how and of the buffer we allocated for an
 	// ephemeral packet on the read and write sides.
 	// These fields are used by:
@@ -156,8 +169,8 @@ type Conn struct {
 	// the client and the server, and currently in use.
 	// It is set during the initial handshake.
 	//
-	// It is only used for CapabilityClientDeprecateEOF
-	// and CapabilityClientFoundRows.
+	// It is only used for CapabilityClientDeprecateEOF,
+	// CapabilityClientFoundRows and CapabilityClientQueryAttributes.
 	Capabilities uint32
 
 	// closed is set to true when Close() is called on the connection.
@@ -172,6 +185,12 @@ type Conn struct {
 	// StatementID is the prepared statement ID.
 	StatementID uint32
 
+	// QueryAttributes are the query attributes sent by the client along
+	// with the COM_QUERY or COM_STMT_EXECUTE being handled, by name.
+	// NULL attributes are left out. It is only used by the server, and is
+	// reset for every command.
+	QueryAttributes map[string]string
+
 	// StatusFlags are the status flags we will base our returned flags on.
 	// This is a bit field, with values documented in constants.go.
 	// An interesting value here would be ServerStatusAutocommit.
@@ -225,6 +244,9 @@ type PrepareData struct {
 	BindVars    map[string]*querypb.BindVariable
 	StatementID uint32
 	ParamsCount uint16
+
+	// cursor is the open cursor of the statement, if any.
+	cursor *cursor
 }
 
 // execResult is an enum signifying the result of executing a query
@@ -314,7 +336,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.netReadWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -358,7 +380,7 @@ func (c *Conn) getWriter() (w io.Writer, unget func()) {
 		}
 	}
 	c.bufMu.Unlock()
-	return c.conn, func() {}
+	return c.netReadWriter(), func() {}
 }
 
 // startFlushTimer must be called while holding lock on bufMu.
@@ -390,9 +412,55 @@ func (c *Conn) getReader() io.Reader {
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
+	return c.netReadWriter()
+}
+
+// netReadWriter returns what the packet layer reads from and writes to:
+// the network connection, or the compressed protocol framing on top of
+// it once compression was negotiated.
+func (c *Conn) netReadWriter() io.ReadWriter {
+	if c.compression != nil {
+		return c.compression
+	}
 	return c.conn
 }
 
+// enableCompression switches the connection to the compressed protocol.
+// Both sides do so right after the OK packet that ends the handshake.
+func (c *Conn) enableCompression(algorithm string, zstdLevel int) error {
+	cc, err := newCompressedConn(c.conn, algorithm, zstdLevel)
+	if err != nil {
+		return err
+	}
+	c.compression = cc
+	if c.bufferedReader != nil {
+		c.bufferedReader.Reset(cc)
+	}
+	return nil
+}
+
+// negotiatedCompression returns the compression algorithm that was
+// negotiated in the handshake, if any.
+func (c *Conn) negotiatedCompression() string {
+	switch {
+	case c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		return CompressionZstd
+	case c.Capabilities&CapabilityClientCompress != 0:
+		return CompressionZlib
+	default:
+		return ""
+	}
+}
+
+// resetSequence resets the packet sequence numbers. This needs to be
+// done at the start of every command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	if c.compression != nil {
+		c.compression.sequence = 0
+	}
+}
+
 func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	// Note io.ReadFull will return two different types of errors:
 	// 1. if the socket is already closed, and the go runtime knows it,
@@ -414,7 +482,11 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	}
 
 	sequence := uint8(c.header[3])
-	if sequence != c.sequence {
+	if c.compression != nil {
+		// MySQL doesn't check the sequence of the packets carried by
+		// compressed packets, and peers don't always keep it in sync.
+		c.sequence = sequence
+	} else if sequence != c.sequence {
 		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
 	}
 
@@ -718,7 +790,7 @@ func (c *Conn) recycleWritePacket() {
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -924,7 +996,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -936,11 +1008,26 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 	if len(data) == 0 {
 		return false
 	}
+	c.QueryAttributes = nil
 	// before continue to process the packet, check if the connection should be closed or not.
 	if c.IsMarkedForClose() {
 		return false
 	}
 
+	switch data[0] {
+	case ComResetConnection, ComChangeUser:
+		c.closeCursors()
+	case ComInitDB, ComQuery, ComPrepare, ComBinlogDump, ComBinlogDumpGTID, ComRegisterReplica:
+		// The statements of the open cursors must be done executing
+		// before the handler runs anything else for this connection.
+		// The other commands don't need the handler, or deal with the
+		// open cursors themselves.
+		if err := c.materializeCursors(); err != nil {
+			c.recycleReadPacket()
+			return c.writeErrorPacketFromErrorAndLog(err)
+		}
+	}
+
 	switch data[0] {
 	case ComQuit:
 		c.recycleReadPacket()
@@ -966,13 +1053,20 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		stmtID, ok := c.parseComStmtClose(data)
 		c.recycleReadPacket()
 		if ok {
+			if prepare, ok := c.PrepareData[stmtID]; ok {
+				prepare.closeCursor()
+			}
 			delete(c.PrepareData, stmtID)
 		}
 	case ComStmtReset:
 		return c.handleComStmtReset(data)
+	case ComStmtFetch:
+		return c.handleComStmtFetch(handler, data)
 	case ComResetConnection:
 		c.handleComResetConnection(handler)
 		return true
+	case ComChangeUser:
+		return c.handleComChangeUser(handler, data)
 	case ComFieldList:
 		c.recycleReadPacket()
 		if !c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "command handling not implemented yet: %v", data[0]) {
@@ -1073,6 +1167,58 @@ func (c *Conn) handleComResetConnection(handler Handler) {
 	}
 }
 
+func (c *Conn) handleComChangeUser(handler Handler, data []byte) bool {
+	user, clientAuthMethod, clientAuthResponse, schemaName, err := c.parseComChangeUser(data)
+	c.recycleReadPacket()
+	if err != nil {
+		log.Errorf("Cannot parse COM_CHANGE_USER from %s: %v", c, err)
+		c.writeErrorAndLog(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "cannot parse COM_CHANGE_USER: %v", err)
+		return false
+	}
+	if c.listener == nil {
+		c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "command handling not implemented yet: %v", ComChangeUser)
+		return false
+	}
+
+	// Like MySQL, a failed authentication terminates the connection,
+	// we never fall back to the previous user.
+	userData, ok := c.listener.authenticateUser(c, user, clientAuthMethod, c.salt, clientAuthResponse)
+	if !ok {
+		return false
+	}
+
+	if c.User != "" {
+		connCountPerUser.Add(c.User, -1)
+	}
+	c.User = user
+	c.UserData = userData
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+
+	// The session is reset exactly like COM_RESET_CONNECTION does,
+	// including all prepared statements.
+	handler.ComChangeUser(c)
+	c.PrepareData = make(map[uint32]*PrepareData)
+
+	c.schemaName = schemaName
+	if c.schemaName != "" {
+		err = handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			c.writeErrorPacketFromError(err)
+			return false
+		}
+	}
+
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Cannot write OK packet to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
 func (c *Conn) handleComStmtReset(data []byte) bool {
 	stmtID, ok := c.parseComStmtReset(data)
 	c.recycleReadPacket()
@@ -1091,6 +1237,7 @@ func (c *Conn) handleComStmtReset(data []byte) bool {
 		}
 	}
 
+	prepare.closeCursor()
 	if prepare.BindVars != nil {
 		for k := range prepare.BindVars {
 			prepare.BindVars[k] = nil
@@ -1143,9 +1290,14 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		}
 	}()
 	queryStart := time.Now()
-	stmtID, _, err := c.parseComStmtExecute(c.PrepareData, data)
+	stmtID, cursorType, err := c.parseComStmtExecute(c.PrepareData, data)
 	c.recycleReadPacket()
 
+	// Executing a statement closes its open cursor, if any.
+	if prepare, ok := c.PrepareData[stmtID]; ok {
+		prepare.closeCursor()
+	}
+
 	if stmtID != uint32(0) {
 		defer func() {
 			// Allocate a new bindvar map every time since VTGate.Execute() mutates it.
@@ -1158,10 +1310,22 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		return c.writeErrorPacketFromErrorAndLog(err)
 	}
 
+	if err := c.materializeCursors(); err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	prepare := c.PrepareData[stmtID]
+	if cursorType&CursorTypeReadOnly != 0 {
+		if !c.handleComStmtExecuteCursor(handler, prepare) {
+			return false
+		}
+		timings.Record(queryTimingKey, queryStart)
+		return true
+	}
+
 	fieldSent := false
 	// sendFinished is set if the response should just be an OK packet.
 	sendFinished := false
-	prepare := c.PrepareData[stmtID]
 	err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
 		if sendFinished {
 			// Failsafe: Unreachable if server is well-behaved.
@@ -1361,11 +1525,13 @@ func (c *Conn) handleComQuery(handler Handler, data []byte) (kontinue bool) {
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
 
 	var queries []string
-	var err error
 	if c.Capabilities&CapabilityClientMultiStatements != 0 {
 		queries, err = splitStatementFunction(query)
 		if err != nil {
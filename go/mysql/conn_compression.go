@@ -0,0 +1,309 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Compression algorithms that can be negotiated for the MySQL protocol.
+// See https://dev.mysql.com/doc/refman/8.0/en/connection-compression-control.html
+const (
+	CompressionZlib = "zlib"
+	CompressionZstd = "zstd"
+)
+
+const (
+	// compressedPacketHeaderSize is the size of the header of a
+	// compressed packet: 3 bytes for the length of the compressed
+	// payload, 1 byte for the sequence and 3 bytes for the length
+	// of the payload once uncompressed.
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the payload size under which we don't
+	// bother compressing. This is the same value as MySQL uses.
+	minCompressLength = 50
+
+	// DefaultZstdCompressionLevel is the zstd level used when none
+	// is specified, this is the MySQL default.
+	DefaultZstdCompressionLevel = 3
+)
+
+// zlibWriters pools zlib writers, as they are expensive to allocate
+// and we don't want to keep one around for every idle connection.
+var zlibWriters = sync.Pool{New: func() any {
+	return zlib.NewWriter(nil)
+}}
+
+// zlibReaders pools zlib readers. The pool is empty to begin with,
+// as a zlib reader can only be created from a valid stream.
+var zlibReaders sync.Pool
+
+// zstdPacketDecoder decodes the zstd payloads of compressed packets.
+// DecodeAll never decodes more than the capacity of its destination,
+// whatever the frames sent by the peer claim, so a payload can't make
+// us allocate more than the uncompressed length of its header.
+var zstdPacketDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(MaxPacketSize), zstd.WithDecodeAllCapLimit(true))
+
+// zstdEncoders caches one zstd encoder per encoder level. EncodeAll
+// is safe for concurrent use, so they are shared by all connections.
+var zstdEncoders struct {
+	mu       sync.Mutex
+	encoders map[zstd.EncoderLevel]*zstd.Encoder
+}
+
+func zstdEncoderForLevel(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncoders.mu.Lock()
+	defer zstdEncoders.mu.Unlock()
+
+	if enc, ok := zstdEncoders.encoders[encoderLevel]; ok {
+		return enc, nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	if zstdEncoders.encoders == nil {
+		zstdEncoders.encoders = make(map[zstd.EncoderLevel]*zstd.Encoder)
+	}
+	zstdEncoders.encoders[encoderLevel] = enc
+	return enc, nil
+}
+
+// compressedConn implements the framing of the compressed protocol on
+// top of the network connection. Every Write is sent as one or more
+// compressed packets, and Read returns the uncompressed payloads.
+// The regular packets are carried inside of these payloads, so the
+// rest of the packet layer is unaware of compression.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_compression.html
+type compressedConn struct {
+	conn      io.ReadWriter
+	algorithm string
+	zstdLevel int
+
+	// sequence is the sequence number of the compressed packets. It is
+	// distinct from the sequence number of the packets it carries,
+	// and is reset at the start of every command.
+	sequence uint8
+
+	header [compressedPacketHeaderSize]byte
+
+	// pending is the uncompressed data that was read but not yet
+	// returned by Read. It points into either readBuf or uncompressBuf.
+	pending       []byte
+	readBuf       []byte
+	uncompressBuf []byte
+
+	// writeBuf holds the compressed packet being written.
+	writeBuf []byte
+}
+
+func newCompressedConn(conn io.ReadWriter, algorithm string, zstdLevel int) (*compressedConn, error) {
+	switch algorithm {
+	case CompressionZlib:
+	case CompressionZstd:
+		if zstdLevel == 0 {
+			zstdLevel = DefaultZstdCompressionLevel
+		}
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported compression algorithm: %q", algorithm)
+	}
+	return &compressedConn{
+		conn:      conn,
+		algorithm: algorithm,
+		zstdLevel: zstdLevel,
+	}, nil
+}
+
+// Read is part of the io.Reader interface.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	for len(cc.pending) == 0 {
+		if err := cc.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.pending)
+	cc.pending = cc.pending[n:]
+	return n, nil
+}
+
+func (cc *compressedConn) readCompressedPacket() error {
+	if _, err := io.ReadFull(cc.conn, cc.header[:]); err != nil {
+		// io.EOF is returned as is, see readHeaderFrom.
+		return err
+	}
+	compressedLength := int(uint32(cc.header[0]) | uint32(cc.header[1])<<8 | uint32(cc.header[2])<<16)
+	uncompressedLength := int(uint32(cc.header[4]) | uint32(cc.header[5])<<8 | uint32(cc.header[6])<<16)
+
+	// Like MySQL, we follow the sequence of the peer instead of
+	// enforcing it: the next packet we write is the one after this.
+	cc.sequence = cc.header[3] + 1
+
+	if cap(cc.readBuf) < compressedLength {
+		cc.readBuf = make([]byte, compressedLength)
+	}
+	cc.readBuf = cc.readBuf[:compressedLength]
+	if _, err := io.ReadFull(cc.conn, cc.readBuf); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", compressedLength)
+	}
+
+	// An uncompressed length of 0 means the payload was sent as is.
+	if uncompressedLength == 0 {
+		cc.pending = cc.readBuf
+		return nil
+	}
+
+	if cap(cc.uncompressBuf) < uncompressedLength {
+		cc.uncompressBuf = make([]byte, uncompressedLength)
+	}
+	cc.uncompressBuf = cc.uncompressBuf[:uncompressedLength]
+
+	var err error
+	switch cc.algorithm {
+	case CompressionZlib:
+		err = zlibUncompress(cc.readBuf, cc.uncompressBuf)
+	case CompressionZstd:
+		var out []byte
+		out, err = zstdPacketDecoder.DecodeAll(cc.readBuf, cc.uncompressBuf[:0:uncompressedLength])
+		if err == nil && len(out) != uncompressedLength {
+			err = vterrors.Errorf(vtrpcpb.Code_INTERNAL, "zstd payload has length %v, expected %v", len(out), uncompressedLength)
+		}
+	}
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot uncompress %v packet", cc.algorithm)
+	}
+	cc.pending = cc.uncompressBuf
+	return nil
+}
+
+func zlibUncompress(in, out []byte) error {
+	var r io.ReadCloser
+	if pooled := zlibReaders.Get(); pooled != nil {
+		r = pooled.(io.ReadCloser)
+		if err := r.(zlib.Resetter).Reset(bytes.NewReader(in), nil); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		r, err = zlib.NewReader(bytes.NewReader(in))
+		if err != nil {
+			return err
+		}
+	}
+	defer zlibReaders.Put(r)
+
+	if _, err := io.ReadFull(r, out); err != nil {
+		return err
+	}
+	return r.Close()
+}
+
+// Write is part of the io.Writer interface. The data is sent right
+// away, buffering is left to the bufio.Writer of the Conn.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		// The uncompressed length of a compressed packet is limited
+		// to 3 bytes, like the one of a regular packet.
+		n := min(len(p), MaxPacketSize)
+		if err := cc.writeCompressedPacket(p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writeCompressedPacket(data []byte) error {
+	cc.writeBuf = append(cc.writeBuf[:0], make([]byte, compressedPacketHeaderSize)...)
+
+	uncompressedLength := 0
+	if len(data) >= minCompressLength {
+		var err error
+		switch cc.algorithm {
+		case CompressionZlib:
+			err = cc.zlibCompress(data)
+		case CompressionZstd:
+			err = cc.zstdCompress(data)
+		}
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot compress %v packet", cc.algorithm)
+		}
+		uncompressedLength = len(data)
+	}
+
+	// Send small or incompressible payloads as is.
+	if compressedLength := len(cc.writeBuf) - compressedPacketHeaderSize; uncompressedLength == 0 || compressedLength >= len(data) {
+		cc.writeBuf = append(cc.writeBuf[:compressedPacketHeaderSize], data...)
+		uncompressedLength = 0
+	}
+
+	compressedLength := len(cc.writeBuf) - compressedPacketHeaderSize
+	cc.writeBuf[0] = byte(compressedLength)
+	cc.writeBuf[1] = byte(compressedLength >> 8)
+	cc.writeBuf[2] = byte(compressedLength >> 16)
+	cc.writeBuf[3] = cc.sequence
+	cc.writeBuf[4] = byte(uncompressedLength)
+	cc.writeBuf[5] = byte(uncompressedLength >> 8)
+	cc.writeBuf[6] = byte(uncompressedLength >> 16)
+
+	if n, err := cc.conn.Write(cc.writeBuf); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	} else if n != len(cc.writeBuf) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Write(compressed packet) returned a short write: %v < %v", n, len(cc.writeBuf))
+	}
+	cc.sequence++
+	return nil
+}
+
+// zlibCompress appends the compressed data to writeBuf.
+func (cc *compressedConn) zlibCompress(data []byte) error {
+	buf := bytes.NewBuffer(cc.writeBuf)
+	w := zlibWriters.Get().(*zlib.Writer)
+	defer zlibWriters.Put(w)
+
+	w.Reset(buf)
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	cc.writeBuf = buf.Bytes()
+	return nil
+}
+
+// zstdCompress appends the compressed data to writeBuf.
+func (cc *compressedConn) zstdCompress(data []byte) error {
+	enc, err := zstdEncoderForLevel(cc.zstdLevel)
+	if err != nil {
+		return err
+	}
+	cc.writeBuf = enc.EncodeAll(data, cc.writeBuf)
+	return nil
+}
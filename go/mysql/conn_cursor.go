@@ -0,0 +1,325 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"errors"
+	"io"
+	"math"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// cursor is a read-only server-side cursor, opened by a COM_STMT_EXECUTE
+// with the CURSOR_TYPE_READ_ONLY flag.
+//
+// The statement is executed by the handler in its own goroutine, which
+// only runs while the connection waits for more rows: the callback given
+// to the handler blocks after every result, until COM_STMT_FETCH asks
+// for more rows. The handler and the connection thus never run at the
+// same time, and the result set is never buffered as a whole.
+type cursor struct {
+	// results receives the results of the handler.
+	results chan *sqltypes.Result
+	// resume is used to let the handler go on after a result.
+	resume chan struct{}
+	// stop is closed to make the handler give up.
+	stop chan struct{}
+	// done is closed once the handler returned, and err is set then.
+	done chan struct{}
+	err  error
+
+	// waiting is set while the handler waits to be resumed.
+	waiting bool
+	// exhausted is set once the handler returned.
+	exhausted bool
+
+	fields []*querypb.Field
+	// rows are the rows received from the handler, but not fetched yet.
+	rows [][]sqltypes.Value
+	// size is the size in bytes of the values of rows.
+	size int64
+}
+
+// newCursor starts the execution of the statement by the handler.
+func newCursor(handler Handler, c *Conn, prepare *PrepareData) *cursor {
+	cur := &cursor{
+		results: make(chan *sqltypes.Result),
+		resume:  make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	// The handler gets a copy of the statement, as the bind variables
+	// of the statement are reset once COM_STMT_EXECUTE is handled.
+	stmt := *prepare
+	stmt.cursor = cur
+	go func() {
+		defer close(cur.done)
+		cur.err = handler.ComStmtExecute(c, &stmt, cur.send)
+	}()
+	return cur
+}
+
+// send is the callback given to the handler.
+func (cur *cursor) send(qr *sqltypes.Result) error {
+	select {
+	case cur.results <- qr:
+	case <-cur.stop:
+		return io.EOF
+	}
+	select {
+	case <-cur.resume:
+		return nil
+	case <-cur.stop:
+		return io.EOF
+	}
+}
+
+// receive returns the next result of the handler. It returns nil once
+// the handler returned, and cur.err is then set.
+func (cur *cursor) receive() *sqltypes.Result {
+	if cur.exhausted {
+		return nil
+	}
+	if cur.waiting {
+		cur.waiting = false
+		cur.resume <- struct{}{}
+	}
+	select {
+	case qr := <-cur.results:
+		cur.waiting = true
+		return qr
+	case <-cur.done:
+		cur.exhausted = true
+		return nil
+	}
+}
+
+// receiveRows receives the next result of the handler, and adds its rows to
+// the pending rows. It returns false once the handler returned.
+func (cur *cursor) receiveRows() bool {
+	qr := cur.receive()
+	if qr == nil {
+		return false
+	}
+	cur.rows = append(cur.rows, qr.Rows...)
+	cur.size += rowsSize(qr.Rows)
+	return true
+}
+
+// fill receives results until n rows are pending, or the handler returned.
+func (cur *cursor) fill(n int) {
+	for len(cur.rows) < n && cur.receiveRows() {
+	}
+}
+
+// materialize receives all the remaining rows, so the handler is done
+// executing the statement. It fails once more than maxRows rows or maxBytes
+// bytes are pending, a limit of zero meaning no limit: the cursor then stays
+// open with the rows received so far.
+func (cur *cursor) materialize(maxRows int, maxBytes int64) bool {
+	for {
+		if (maxRows > 0 && len(cur.rows) > maxRows) || (maxBytes > 0 && cur.size > maxBytes) {
+			return false
+		}
+		if !cur.receiveRows() {
+			return true
+		}
+	}
+}
+
+// rowsSize returns the size in bytes of the values of rows.
+func rowsSize(rows [][]sqltypes.Value) int64 {
+	var size int64
+	for _, row := range rows {
+		for _, v := range row {
+			size += int64(v.Len())
+		}
+	}
+	return size
+}
+
+// close stops the execution of the statement, and waits for the handler.
+func (cur *cursor) close() {
+	if !cur.exhausted {
+		close(cur.stop)
+		<-cur.done
+		cur.exhausted = true
+	}
+	cur.rows = nil
+	cur.size = 0
+}
+
+// HasCursor returns true if the statement is executed to back a
+// server-side cursor. The handler should then stream the results rather
+// than buffer them, as they are only sent as the client fetches them.
+func (prepare *PrepareData) HasCursor() bool {
+	return prepare.cursor != nil
+}
+
+func (prepare *PrepareData) closeCursor() {
+	if prepare.cursor != nil {
+		prepare.cursor.close()
+		prepare.cursor = nil
+	}
+}
+
+// closeCursors closes all the cursors of the connection.
+func (c *Conn) closeCursors() {
+	for _, prepare := range c.PrepareData {
+		prepare.closeCursor()
+	}
+}
+
+// materializeCursors makes sure that no handler is executing a statement
+// for a cursor, before the connection runs a command that needs the handler:
+// the open cursors receive all their remaining rows. It fails if a cursor has
+// more rows left than the limits of the listener allow to read into memory.
+func (c *Conn) materializeCursors() error {
+	var maxRows int
+	var maxBytes int64
+	if c.listener != nil {
+		maxRows, maxBytes = c.listener.CursorMaxMaterializedRows, c.listener.CursorMaxMaterializedBytes
+	}
+	for _, prepare := range c.PrepareData {
+		if prepare.cursor != nil && !prepare.cursor.materialize(maxRows, maxBytes) {
+			return sqlerror.NewSQLError(sqlerror.EROutOfResources, sqlerror.SSUnknownSQLState,
+				"the open cursor of statement %d has too many rows left to read them into memory: fetch them or close the cursor before running other commands", prepare.StatementID)
+		}
+	}
+	return nil
+}
+
+// handleComStmtExecuteCursor opens a cursor for the statement. Only the
+// fields are sent back, the rows are sent in response to COM_STMT_FETCH.
+// If the statement does not return rows, no cursor is opened, and the
+// response is the same as without a cursor.
+func (c *Conn) handleComStmtExecuteCursor(handler Handler, prepare *PrepareData) bool {
+	cur := newCursor(handler, c, prepare)
+
+	qr := cur.receive()
+	if qr == nil {
+		err := cur.err
+		if err == nil || err == io.EOF {
+			// This is just a failsafe. Should never happen.
+			err = sqlerror.NewSQLErrorFromError(errors.New("unexpected: query ended without no results and no error"))
+		}
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	if len(qr.Fields) == 0 {
+		cur.fill(math.MaxInt)
+		if cur.err != nil {
+			return c.writeErrorPacketFromErrorAndLog(cur.err)
+		}
+		ok := PacketOK{
+			affectedRows:     qr.RowsAffected,
+			lastInsertID:     qr.InsertID,
+			statusFlags:      c.StatusFlags,
+			sessionStateData: qr.SessionStateChanges,
+		}
+		if err := c.writeOKPacket(&ok); err != nil {
+			log.Errorf("Error writing result to %s: %v", c, err)
+			return false
+		}
+		return true
+	}
+
+	cur.fields = qr.Fields
+	cur.rows = qr.Rows
+	cur.size = rowsSize(qr.Rows)
+	prepare.cursor = cur
+
+	if err := c.sendColumnCount(uint64(len(qr.Fields))); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	for _, field := range qr.Fields {
+		if err := c.writeColumnDefinition(field); err != nil {
+			log.Errorf("Error writing result to %s: %v", c, err)
+			return false
+		}
+	}
+	// The fields are always followed by an EOF packet, even with
+	// CapabilityClientDeprecateEOF, to let the client know about the cursor.
+	if err := c.writeEOFPacket(c.StatusFlags|ServerStatusCursorExists, 0); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
+func (c *Conn) handleComStmtFetch(handler Handler, data []byte) (kontinue bool) {
+	c.startWriterBuffering()
+	defer func() {
+		if err := c.endWriterBuffering(); err != nil {
+			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
+			kontinue = false
+		}
+	}()
+
+	stmtID, numRows, ok := c.parseComStmtFetch(data)
+	c.recycleReadPacket()
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "error parsing statement fetch packet: %v", data)
+	}
+
+	prepare, ok := c.PrepareData[stmtID]
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.ERUnknownStmtHandler, sqlerror.SSUnknownSQLState, "Unknown prepared statement handler (%v) given to mysqld_stmt_fetch", stmtID)
+	}
+	cur := prepare.cursor
+	if cur == nil {
+		return c.writeErrorAndLog(sqlerror.ERStmtHasNoOpenCursor, sqlerror.SSUnknownSQLState, "The statement (%v) has no open cursor.", stmtID)
+	}
+
+	cur.fill(int(numRows))
+	if cur.exhausted && cur.err != nil {
+		err := cur.err
+		prepare.closeCursor()
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	n := min(int(numRows), len(cur.rows))
+	if err := c.writeBinaryRows(&sqltypes.Result{Fields: cur.fields, Rows: cur.rows[:n]}); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	cur.size -= rowsSize(cur.rows[:n])
+	cur.rows = cur.rows[n:]
+
+	flags := c.StatusFlags | ServerStatusCursorExists
+	if cur.exhausted && len(cur.rows) == 0 {
+		flags |= ServerStatusLastRowSent
+		prepare.closeCursor()
+	}
+	warnings := handler.WarningCount(c)
+	var err error
+	if c.Capabilities&CapabilityClientDeprecateEOF == 0 {
+		err = c.writeEOFPacket(flags, warnings)
+	} else {
+		err = c.writeOKPacketWithEOFHeader(&PacketOK{statusFlags: flags, warnings: warnings})
+	}
+	if err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	return true
+}
@@ -23,11 +23,13 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"math/rand"
 	"net"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -935,6 +937,33 @@ func TestInitDbAgainstWrongDbDoesNotDropConnection(t *testing.T) {
 	require.EqualValues(t, data[0], ErrPacket) // we should see the error here
 }
 
+func TestMalformedComChangeUser(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	// The packet ends before the null terminated user name.
+	data, pos := cConn.startEphemeralPacketWithHeader(5)
+	copy(data[pos:], []byte{ComChangeUser, 'u', 's', 'e', 'r'})
+	err := cConn.writeEphemeralPacket()
+	require.NoError(t, err)
+
+	handler := &testRun{t: t, err: fmt.Errorf("not used")}
+	res := sConn.handleNextCommand(handler)
+	require.False(t, res, "we should break the connection on a malformed COM_CHANGE_USER")
+
+	data, err = cConn.ReadPacket()
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+	require.EqualValues(t, ErrPacket, data[0])
+	sqlErr, ok := ParseErrorPacket(data).(*sqlerror.SQLError)
+	require.True(t, ok)
+	require.Equal(t, sqlerror.CRMalformedPacket, sqlErr.Number())
+}
+
 func TestConnectionErrorWhileWritingComQuery(t *testing.T) {
 	// Set the conn for the server connection to the simulated connection which always returns an error on writing
 	sConn := newConn(testConn{
@@ -1151,3 +1180,154 @@ func (t testRun) WarningCount(c *Conn) uint16 {
 }
 
 var _ Handler = (*testRun)(nil)
+
+// cursorTestRun streams its results one row at a time, and counts
+// how many results it sent.
+type cursorTestRun struct {
+	testRun
+	rows int
+	sent *atomic.Int32
+}
+
+func (t cursorTestRun) ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error {
+	assert.True(t.t, prepare.HasCursor())
+	t.sent.Add(1)
+	if err := callback(&sqltypes.Result{Fields: selectRowsResult.Fields}); err != nil {
+		return err
+	}
+	for i := 0; i < t.rows; i++ {
+		t.sent.Add(1)
+		err := callback(&sqltypes.Result{Rows: [][]sqltypes.Value{{
+			sqltypes.NewInt32(int32(i)),
+			sqltypes.NewVarChar(fmt.Sprintf("row %d", i)),
+		}}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestStmtFetch(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	sent := &atomic.Int32{}
+	handler := cursorTestRun{testRun: testRun{t: t}, rows: 5, sent: sent}
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "select * from t"}
+
+	// readEOF reads the EOF packet that ends a response, and returns its status flags.
+	readEOF := func() uint16 {
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		require.True(t, cConn.isEOFPacket(data), "expected EOF packet, got %v", data)
+		_, flags, err := parseEOFPacket(data)
+		require.NoError(t, err)
+		return flags
+	}
+	fetch := func(numRows uint32) {
+		packet := []byte{0, 0, 0, 0, ComStmtFetch, 1, 0, 0, 0, 0, 0, 0, 0}
+		binary.LittleEndian.PutUint32(packet[9:], numRows)
+		cConn.sequence = 0
+		require.NoError(t, cConn.writePacket(packet))
+		require.True(t, sConn.handleNextCommand(handler))
+	}
+	readRows := func(first, count int) {
+		for i := first; i < first+count; i++ {
+			data, err := cConn.ReadPacket()
+			require.NoError(t, err)
+			require.EqualValues(t, OKPacket, data[0])
+			assert.True(t, bytes.HasSuffix(data, []byte(fmt.Sprintf("row %d", i))), "unexpected row %d: %v", i, data)
+		}
+	}
+
+	// execute executes the statement with a read-only cursor: only
+	// the fields are sent, followed by an EOF packet.
+	execute := func() {
+		cConn.sequence = 0
+		err := cConn.writePacket([]byte{0, 0, 0, 0, ComStmtExecute, 1, 0, 0, 0, CursorTypeReadOnly, 1, 0, 0, 0})
+		require.NoError(t, err)
+		require.True(t, sConn.handleNextCommand(handler))
+
+		data, err := cConn.ReadPacket()
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{byte(len(selectRowsResult.Fields))}, data)
+		for range selectRowsResult.Fields {
+			_, err = cConn.ReadPacket()
+			require.NoError(t, err)
+		}
+		flags := readEOF()
+		assert.NotZero(t, flags&ServerStatusCursorExists)
+	}
+
+	execute()
+	assert.EqualValues(t, 1, sent.Load())
+
+	// The rows are only produced as they are fetched.
+	fetch(3)
+	readRows(0, 3)
+	flags := readEOF()
+	assert.NotZero(t, flags&ServerStatusCursorExists)
+	assert.Zero(t, flags&ServerStatusLastRowSent)
+	assert.EqualValues(t, 4, sent.Load())
+
+	fetch(3)
+	readRows(3, 2)
+	flags = readEOF()
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+	assert.EqualValues(t, 6, sent.Load())
+
+	// The cursor is closed once all rows were sent.
+	fetch(3)
+	data, err := cConn.ReadPacket()
+	require.NoError(t, err)
+	err = ParseErrorPacket(data)
+	assert.EqualValues(t, sqlerror.ERStmtHasNoOpenCursor, err.(*sqlerror.SQLError).Number())
+
+	// Closing the statement stops the execution of an open cursor.
+	execute()
+	cur := sConn.PrepareData[1].cursor
+	require.NotNil(t, cur)
+	cConn.sequence = 0
+	require.NoError(t, cConn.writePacket([]byte{0, 0, 0, 0, ComStmtClose, 1, 0, 0, 0}))
+	require.True(t, sConn.handleNextCommand(handler))
+	<-cur.done
+	assert.Equal(t, io.EOF, cur.err)
+	assert.Empty(t, sConn.PrepareData)
+
+	// A command that doesn't need the handler leaves an open cursor as is.
+	sConn.listener = &Listener{CursorMaxMaterializedRows: 3}
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "select * from t"}
+	execute()
+	sentBefore := sent.Load()
+	cConn.sequence = 0
+	require.NoError(t, cConn.writePacket([]byte{0, 0, 0, 0, ComPing}))
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err = cConn.ReadPacket()
+	require.NoError(t, err)
+	assert.EqualValues(t, OKPacket, data[0])
+	assert.Equal(t, sentBefore, sent.Load())
+
+	// A query can't run while the cursor has more rows left than can be read into memory.
+	cConn.sequence = 0
+	require.NoError(t, cConn.writePacket(append([]byte{0, 0, 0, 0, ComQuery}, "select 1"...)))
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err = cConn.ReadPacket()
+	require.NoError(t, err)
+	err = ParseErrorPacket(data)
+	assert.EqualValues(t, sqlerror.EROutOfResources, err.(*sqlerror.SQLError).Number())
+	assert.Equal(t, sentBefore+4, sent.Load())
+
+	// The cursor is still open, with the rows read so far.
+	fetch(5)
+	readRows(0, 5)
+	flags = readEOF()
+	assert.Zero(t, flags&ServerStatusLastRowSent)
+	fetch(5)
+	flags = readEOF()
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+}
@@ -57,6 +57,16 @@ type ConnParams struct {
 	// for informative purposes. It has no programmatic value. Returning this field is
 	// disabled by default.
 	EnableQueryInfo bool
+
+	// Compression is the compression algorithm to request for the
+	// MySQL protocol, one of "zlib" or "zstd". The connection stays
+	// uncompressed if the server doesn't support it. Empty means
+	// no compression.
+	Compression string `json:"compression,omitempty"`
+
+	// ZstdCompressionLevel is the zstd level to request when
+	// Compression is "zstd". 0 means the default level.
+	ZstdCompressionLevel int `json:"zstd_compression_level,omitempty"`
 }
 
 // EnableSSL will set the right flag on the parameters.
@@ -76,8 +76,10 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Use the zlib compressed protocol. CPU is usually our bottleneck,
+	// so this is only advertised by the server if enabled.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
@@ -148,6 +150,18 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CLIENT_OPTIONAL_RESULTSET_METADATA 1 << 25
+	// Not yet supported.
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Use the zstd compressed protocol. Like CapabilityClientCompress,
+	// only advertised by the server if enabled.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+
+	// CapabilityClientQueryAttributes is CLIENT_QUERY_ATTRIBUTES.
+	// Query attributes can be sent with COM_QUERY and COM_STMT_EXECUTE.
+	CapabilityClientQueryAttributes = 1 << 27
 )
 
 // Status flags. They are returned by the server in a few cases.
@@ -209,6 +223,9 @@ const (
 	// ComPing is COM_PING.
 	ComPing = 0x0e
 
+	// ComChangeUser is COM_CHANGE_USER.
+	ComChangeUser = 0x11
+
 	// ComBinlogDump is COM_BINLOG_DUMP.
 	ComBinlogDump = 0x12
 
@@ -230,7 +247,7 @@ const (
 	// ComStmtReset is COM_STMT_RESET
 	ComStmtReset = 0x1a
 
-	//ComStmtFetch is COM_STMT_FETCH
+	// ComStmtFetch is COM_STMT_FETCH
 	ComStmtFetch = 0x1c
 
 	// ComSetOption is COM_SET_OPTION
@@ -259,6 +276,27 @@ const (
 	NullValue = 0xfb
 )
 
+// Cursor type flags of COM_STMT_EXECUTE.
+const (
+	// CursorTypeNoCursor executes the statement without a cursor.
+	CursorTypeNoCursor = 0x00
+
+	// CursorTypeReadOnly opens a read-only cursor, the rows are then
+	// sent in response to COM_STMT_FETCH.
+	CursorTypeReadOnly = 0x01
+
+	// CursorTypeForUpdate is CURSOR_TYPE_FOR_UPDATE, it is not supported.
+	CursorTypeForUpdate = 0x02
+
+	// CursorTypeScrollable is CURSOR_TYPE_SCROLLABLE, it is not supported.
+	CursorTypeScrollable = 0x04
+
+	// ParameterCountAvailable is PARAMETER_COUNT_AVAILABLE. With
+	// CapabilityClientQueryAttributes, it is set when the parameter count
+	// is sent, as it then includes the query attributes.
+	ParameterCountAvailable = 0x08
+)
+
 // Auth packet types
 const (
 	// AuthMoreDataPacket is sent when server requires more data to authenticate
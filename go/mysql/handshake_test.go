@@ -17,6 +17,7 @@ limitations under the License.
 package mysql
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"net"
@@ -24,6 +25,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -87,6 +89,227 @@ func TestClearTextClientAuth(t *testing.T) {
 	conn.writeComQuit()
 }
 
+// TestChangeUser makes sure COM_CHANGE_USER re-authenticates the
+// connection and updates the user data returned by the AuthServer.
+func TestChangeUser(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{
+		{Password: "password1", UserData: "userData1"},
+	}
+	authServer.entries["user2"] = []*AuthServerStaticEntry{
+		{Password: "password2", UserData: "userData2"},
+	}
+	defer authServer.close()
+
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0)
+	require.NoError(t, err, "NewListener failed: %v", err)
+	defer l.Close()
+	host := l.Addr().(*net.TCPAddr).IP.String()
+	port := l.Addr().(*net.TCPAddr).Port
+	go func() {
+		l.Accept()
+	}()
+
+	params := &ConnParams{
+		Host:    host,
+		Port:    port,
+		Uname:   "user1",
+		Pass:    "password1",
+		SslMode: vttls.Disabled,
+	}
+
+	ctx := context.Background()
+	conn, err := Connect(ctx, params)
+	require.NoError(t, err, "unexpected connection error: %v", err)
+	defer conn.Close()
+
+	result, err := conn.ExecuteFetch("userData echo", 10000, true)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", result.Rows[0][0].ToString())
+	assert.Equal(t, "userData1", result.Rows[0][1].ToString())
+
+	// Switch to the second user, with a new default database.
+	err = conn.ChangeUser(&ConnParams{
+		Uname:  "user2",
+		Pass:   "password2",
+		DbName: "db2",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user2", conn.User)
+
+	result, err = conn.ExecuteFetch("userData echo", 10000, true)
+	require.NoError(t, err)
+	assert.Equal(t, "user2", result.Rows[0][0].ToString())
+	assert.Equal(t, "userData2", result.Rows[0][1].ToString())
+
+	result, err = conn.ExecuteFetch("schema echo", 10000, true)
+	require.NoError(t, err)
+	assert.Equal(t, "db2", result.Rows[0][0].ToString())
+
+	// A wrong password fails, and the server closes the connection.
+	err = conn.ChangeUser(&ConnParams{
+		Uname: "user1",
+		Pass:  "bad",
+	})
+	require.ErrorContains(t, err, "Access denied for user 'user1'")
+	_, err = conn.ExecuteFetch("userData echo", 10000, true)
+	require.Error(t, err)
+}
+
+// TestChangeUserAfterAuthSwitch makes sure COM_CHANGE_USER works after the
+// server switched the connection to mysql_clear_password, which replaced the
+// salt of the initial handshake.
+func TestChangeUserAfterAuthSwitch(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStaticWithAuthMethodDescription("", "", 0, MysqlClearPassword)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{
+		{Password: "password1", UserData: "userData1"},
+	}
+	authServer.entries["user2"] = []*AuthServerStaticEntry{
+		{Password: "password2", UserData: "userData2"},
+	}
+	defer authServer.close()
+
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0)
+	require.NoError(t, err, "NewListener failed: %v", err)
+	defer l.Close()
+	l.AllowClearTextWithoutTLS.Store(true)
+	host := l.Addr().(*net.TCPAddr).IP.String()
+	port := l.Addr().(*net.TCPAddr).Port
+	go func() {
+		l.Accept()
+	}()
+
+	params := &ConnParams{
+		Host:    host,
+		Port:    port,
+		Uname:   "user1",
+		Pass:    "password1",
+		SslMode: vttls.Disabled,
+	}
+
+	ctx := context.Background()
+	conn, err := Connect(ctx, params)
+	require.NoError(t, err, "unexpected connection error: %v", err)
+	defer conn.Close()
+	assert.Equal(t, MysqlClearPassword, conn.authPluginName)
+
+	err = conn.ChangeUser(&ConnParams{
+		Uname: "user2",
+		Pass:  "password2",
+	})
+	require.NoError(t, err)
+
+	result, err := conn.ExecuteFetch("userData echo", 10000, true)
+	require.NoError(t, err)
+	assert.Equal(t, "user2", result.Rows[0][0].ToString())
+	assert.Equal(t, "userData2", result.Rows[0][1].ToString())
+}
+
+// TestCompressedConnection creates a server with compression enabled,
+// and checks that clients can negotiate both algorithms.
+func TestCompressedConnection(t *testing.T) {
+	th := &testHandler{}
+
+	authServer := NewAuthServerStatic("", "", 0)
+	authServer.entries["user1"] = []*AuthServerStaticEntry{
+		{Password: "password1"},
+	}
+	defer authServer.close()
+
+	l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0)
+	require.NoError(t, err, "NewListener failed: %v", err)
+	defer l.Close()
+	l.EnableCompression = true
+	host := l.Addr().(*net.TCPAddr).IP.String()
+	port := l.Addr().(*net.TCPAddr).Port
+	go func() {
+		l.Accept()
+	}()
+
+	for _, algorithm := range []string{"", CompressionZlib, CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			params := &ConnParams{
+				Host:        host,
+				Port:        port,
+				Uname:       "user1",
+				Pass:        "password1",
+				SslMode:     vttls.Disabled,
+				Compression: algorithm,
+			}
+
+			ctx := context.Background()
+			conn, err := Connect(ctx, params)
+			require.NoError(t, err, "Connect failed: %v", err)
+			defer conn.Close()
+			assert.Equal(t, algorithm, conn.negotiatedCompression())
+
+			result, err := conn.ExecuteFetch("select rows", 10000, true)
+			require.NoError(t, err)
+			utils.MustMatch(t, result, selectRowsResult)
+
+			// The query is echoed back, so a large one is compressed
+			// both ways and split across several packets.
+			for _, size := range []int{10, 1000, MaxPacketSize + 10} {
+				query := benchmarkQueryPrefix + strings.Repeat("x", size)
+				result, err = conn.ExecuteFetch(query, 10000, true)
+				require.NoError(t, err)
+				require.Len(t, result.Rows, 1)
+				assert.Equal(t, query, result.Rows[0][0].ToString())
+			}
+
+			require.NoError(t, conn.Ping())
+		})
+	}
+
+	// The server does not compress when it does not advertise it.
+	l2, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0)
+	require.NoError(t, err, "NewListener failed: %v", err)
+	defer l2.Close()
+	go func() {
+		l2.Accept()
+	}()
+
+	conn, err := Connect(context.Background(), &ConnParams{
+		Host:        host,
+		Port:        l2.Addr().(*net.TCPAddr).Port,
+		Uname:       "user1",
+		Pass:        "password1",
+		SslMode:     vttls.Disabled,
+		Compression: CompressionZstd,
+	})
+	require.NoError(t, err, "Connect failed: %v", err)
+	defer conn.Close()
+	assert.Equal(t, "", conn.negotiatedCompression())
+
+	result, err := conn.ExecuteFetch("select rows", 10000, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, result, selectRowsResult)
+}
+
+// TestCompressedPacketTooLarge makes sure a zstd payload is never
+// uncompressed past the length given in its packet header.
+func TestCompressedPacketTooLarge(t *testing.T) {
+	enc, err := zstdEncoderForLevel(DefaultZstdCompressionLevel)
+	require.NoError(t, err)
+	payload := enc.EncodeAll(make([]byte, 1<<20), nil)
+
+	uncompressedLength := 100
+	packet := []byte{
+		byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16), 0,
+		byte(uncompressedLength), 0, 0,
+	}
+	packet = append(packet, payload...)
+
+	cc, err := newCompressedConn(bytes.NewBuffer(packet), CompressionZstd, 0)
+	require.NoError(t, err)
+	err = cc.readCompressedPacket()
+	require.ErrorContains(t, err, zstd.ErrDecoderSizeExceeded.Error())
+}
+
 // TestSSLConnection creates a server with TLS support, a client that
 // also has SSL support, and connects them.
 func TestSSLConnection(t *testing.T) {
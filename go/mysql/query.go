@@ -42,7 +42,7 @@ import (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -531,8 +531,109 @@ func (c *Conn) readComQueryResponse() (int, *PacketOK, error) {
 // Server side methods.
 //
 
-func (c *Conn) parseComQuery(data []byte) string {
-	return string(data[1:])
+func (c *Conn) parseComQuery(data []byte) (string, error) {
+	if c.Capabilities&CapabilityClientQueryAttributes == 0 {
+		return string(data[1:]), nil
+	}
+
+	// With query attributes, the query is preceded by the attributes.
+	count, pos, ok := readLenEncInt(data, 1)
+	if !ok || count > uint64(len(data)) {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+	}
+	// The parameter set count is always 1.
+	_, pos, ok = readLenEncInt(data, pos)
+	if !ok {
+		return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter set count failed")
+	}
+
+	if count > 0 {
+		var bitMap []byte
+		bitMap, pos, ok = readBytes(data, pos, (int(count)+7)/8)
+		if !ok {
+			return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
+		}
+		var newParamsBoundFlag byte
+		newParamsBoundFlag, pos, ok = readByte(data, pos)
+		if !ok || newParamsBoundFlag != 0x01 {
+			return "", sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute types failed")
+		}
+
+		attrs := make([]queryAttribute, count)
+		for i := range attrs {
+			var err error
+			if attrs[i], pos, err = readQueryAttribute(data, pos); err != nil {
+				return "", err
+			}
+		}
+		var err error
+		if pos, err = c.parseQueryAttributes(data, pos, bitMap, 0, attrs); err != nil {
+			return "", err
+		}
+	}
+
+	return string(data[pos:]), nil
+}
+
+// queryAttribute is a query attribute whose value is still to be read.
+type queryAttribute struct {
+	name string
+	typ  querypb.Type
+}
+
+// readParamType reads the type and flags of a parameter.
+func readParamType(data []byte, pos int) (querypb.Type, int, error) {
+	mysqlType, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+	}
+
+	flags, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+	}
+
+	// convert MySQL type to internal type.
+	valType, err := sqltypes.MySQLToType(int64(mysqlType), int64(flags))
+	if err != nil {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+	}
+	return valType, pos, nil
+}
+
+// readQueryAttribute reads the type, flags and name of a parameter, as
+// they are sent with CapabilityClientQueryAttributes.
+func readQueryAttribute(data []byte, pos int) (queryAttribute, int, error) {
+	typ, pos, err := readParamType(data, pos)
+	if err != nil {
+		return queryAttribute{}, 0, err
+	}
+	name, pos, ok := readLenEncString(data, pos)
+	if !ok {
+		return queryAttribute{}, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter name failed")
+	}
+	return queryAttribute{name: name, typ: typ}, pos, nil
+}
+
+// parseQueryAttributes reads the values of the query attributes, and
+// stores them in c.QueryAttributes. The NULL-bitmap covers the bind
+// parameters first, offset is their count.
+func (c *Conn) parseQueryAttributes(data []byte, pos int, bitMap []byte, offset int, attrs []queryAttribute) (int, error) {
+	c.QueryAttributes = make(map[string]string, len(attrs))
+	for i, attr := range attrs {
+		bit := offset + i
+		if (bitMap[bit/8] & (1 << uint(bit%8))) > 0 {
+			continue
+		}
+		var val sqltypes.Value
+		var ok bool
+		val, pos, ok = c.parseStmtArgs(data, attr.typ, pos)
+		if !ok {
+			return 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding query attribute value failed: %v", attr.name)
+		}
+		c.QueryAttributes[attr.name] = val.ToString()
+	}
+	return pos, nil
 }
 
 func (c *Conn) parseComSetOption(data []byte) (uint16, bool) {
@@ -574,35 +675,53 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "iteration count is not equal to 1")
 	}
 
-	if prepare.ParamsCount > 0 {
-		bitMap, pos, ok = readBytes(payload, pos, (int(prepare.ParamsCount)+7)/8)
+	// With query attributes, the parameter count may be sent, and then
+	// includes the query attributes, which follow the bind parameters.
+	queryAttributes := c.Capabilities&CapabilityClientQueryAttributes != 0
+	paramsCount := int(prepare.ParamsCount)
+	if queryAttributes && cursorType&ParameterCountAvailable != 0 {
+		var count uint64
+		count, pos, ok = readLenEncInt(payload, pos)
+		if !ok || count < uint64(prepare.ParamsCount) || count > uint64(len(payload)) {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+		}
+		paramsCount = int(count)
+	}
+
+	if paramsCount > 0 {
+		bitMap, pos, ok = readBytes(payload, pos, (paramsCount+7)/8)
 		if !ok {
 			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
 		}
 	}
 
+	var attrs []queryAttribute
 	newParamsBoundFlag, pos, ok := readByte(payload, pos)
 	if ok && newParamsBoundFlag == 0x01 {
-		var mysqlType, flags byte
-		for i := uint16(0); i < prepare.ParamsCount; i++ {
-			mysqlType, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
-			}
-
-			flags, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+		for i := 0; i < paramsCount; i++ {
+			if !queryAttributes {
+				valType, newPos, err := readParamType(payload, pos)
+				if err != nil {
+					return stmtID, 0, err
+				}
+				pos = newPos
+				prepare.ParamsType[i] = int32(valType)
+				continue
 			}
 
-			// convert MySQL type to internal type.
-			valType, err := sqltypes.MySQLToType(int64(mysqlType), int64(flags))
+			attr, newPos, err := readQueryAttribute(payload, pos)
 			if err != nil {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+				return stmtID, 0, err
+			}
+			pos = newPos
+			if i < int(prepare.ParamsCount) {
+				prepare.ParamsType[i] = int32(attr.typ)
+			} else {
+				attrs = append(attrs, attr)
 			}
-
-			prepare.ParamsType[i] = int32(valType)
 		}
+	} else if paramsCount > int(prepare.ParamsCount) {
+		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading query attribute types failed")
 	}
 
 	for i := 0; i < len(prepare.ParamsType); i++ {
@@ -626,6 +745,12 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		prepare.BindVars[parameterID] = sqltypes.ValueBindVariable(val)
 	}
 
+	if len(attrs) > 0 {
+		if _, err := c.parseQueryAttributes(payload, pos, bitMap, int(prepare.ParamsCount), attrs); err != nil {
+			return stmtID, 0, err
+		}
+	}
+
 	return stmtID, cursorType, nil
 }
 
@@ -885,6 +1010,15 @@ func (c *Conn) parseComStmtReset(data []byte) (uint32, bool) {
 	return val, ok
 }
 
+func (c *Conn) parseComStmtFetch(data []byte) (uint32, uint32, bool) {
+	stmtID, pos, ok := readUint32(data, 1)
+	if !ok {
+		return 0, 0, false
+	}
+	numRows, _, ok := readUint32(data, pos)
+	return stmtID, numRows, ok
+}
+
 func (c *Conn) parseComInitDB(data []byte) string {
 	return string(data[1:])
 }
@@ -254,6 +254,77 @@ func TestComStmtExecute(t *testing.T) {
 
 }
 
+func TestComQueryAttributes(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	// Without CapabilityClientQueryAttributes, the packet is the query.
+	query, err := sConn.parseComQuery(append([]byte{ComQuery}, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, sConn.QueryAttributes)
+
+	sConn.Capabilities |= CapabilityClientQueryAttributes
+
+	// No query attributes.
+	query, err = sConn.parseComQuery(append([]byte{ComQuery, 0x00, 0x01}, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, sConn.QueryAttributes)
+
+	// A string attribute, and a NULL one.
+	data := []byte{ComQuery, 0x02, 0x01, 0x02, 0x01, 0xfe, 0x00, 0x0b}
+	data = append(data, "traceparent"...)
+	data = append(data, 0xfe, 0x00, 0x0a)
+	data = append(data, "tracestate"...)
+	data = append(data, 0x37)
+	data = append(data, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"...)
+	data = append(data, "select 1"...)
+	query, err = sConn.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Equal(t, map[string]string{
+		"traceparent": "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+	}, sConn.QueryAttributes)
+
+	// The types must be sent.
+	_, err = sConn.parseComQuery([]byte{ComQuery, 0x01, 0x01, 0x00, 0x00})
+	require.Error(t, err)
+}
+
+func TestComStmtExecuteQueryAttributes(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	prepare, _ := MockPrepareData(t)
+	prepare.BindVars = map[string]*querypb.BindVariable{}
+	prepareDataMap := map[uint32]*PrepareData{prepare.StatementID: prepare}
+	sConn.Capabilities |= CapabilityClientQueryAttributes
+
+	// This is `select * from test_table where id = ?` executed with 1,
+	// and the tracestate query attribute.
+	data := []byte{ComStmtExecute, 18, 0, 0, 0, ParameterCountAvailable, 1, 0, 0, 0, 0x02, 0x00, 0x01, 0x01, 0x80, 0x00, 0xfe, 0x00, 0x0a}
+	data = append(data, "tracestate"...)
+	data = append(data, 0x01, 0x07)
+	data = append(data, "foo=bar"...)
+
+	stmtID, cursorType, err := sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	assert.EqualValues(t, 18, stmtID)
+	assert.EqualValues(t, ParameterCountAvailable, cursorType)
+	assert.EqualValues(t, querypb.Type_INT8, prepare.ParamsType[0])
+	assert.Equal(t, sqltypes.Int64BindVariable(1), prepare.BindVars["v1"])
+	assert.Equal(t, map[string]string{"tracestate": "foo=bar"}, sConn.QueryAttributes)
+}
+
 func TestComStmtExecuteUpdStmt(t *testing.T) {
 	listener, sConn, cConn := createSocketPair(t)
 	defer func() {
@@ -133,6 +133,13 @@ type Handler interface {
 	WarningCount(c *Conn) uint16
 
 	ComResetConnection(c *Conn)
+
+	// ComChangeUser is called after a connection successfully
+	// re-authenticated as a different user through COM_CHANGE_USER.
+	// The User and UserData fields of the Conn have already been
+	// updated, the handler is expected to drop any session state
+	// it holds for the connection.
+	ComChangeUser(c *Conn)
 }
 
 // UnimplementedHandler implemnts all of the optional callbacks so as to satisy
@@ -145,6 +152,7 @@ func (UnimplementedHandler) NewConnection(*Conn)      {}
 func (UnimplementedHandler) ConnectionReady(*Conn)    {}
 func (UnimplementedHandler) ConnectionClosed(*Conn)   {}
 func (UnimplementedHandler) ComResetConnection(*Conn) {}
+func (UnimplementedHandler) ComChangeUser(*Conn)      {}
 
 // Listener is the MySQL server protocol listener.
 type Listener struct {
@@ -206,6 +214,17 @@ type Listener struct {
 	// RequireSecureTransport configures the server to reject connections from insecure clients
 	RequireSecureTransport bool
 
+	// EnableCompression makes the server advertise the zlib and zstd
+	// compressed protocols, so clients can request them.
+	EnableCompression bool
+
+	// CursorMaxMaterializedRows and CursorMaxMaterializedBytes limit the rows
+	// of an open cursor that are read into memory when the connection runs
+	// another command: the command fails if the cursor has more rows left.
+	// Zero means no limit.
+	CursorMaxMaterializedRows  int
+	CursorMaxMaterializedBytes int64
+
 	// PreHandleFunc is called for each incoming connection, immediately after
 	// accepting a new connection. By default it's no-op. Useful for custom
 	// connection inspection or TLS termination. The returned connection is
@@ -370,12 +389,14 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	// Tell the handler about the connection coming and going.
 	l.handler.NewConnection(c)
 	defer l.handler.ConnectionClosed(c)
+	// The open cursors are closed before the handler is told about it.
+	defer c.closeCursors()
 
 	// Adjust the count of open connections
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, l.TLSConfig.Load() != nil, l.EnableCompression)
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -434,6 +455,79 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		defer connCountByTLSVer.Add(versionNoTLS, -1)
 	}
 
+	// Remember the salt we sent, a later COM_CHANGE_USER is scrambled with it.
+	c.salt = serverAuthPluginData
+
+	userData, ok := l.authenticateUser(c, user, clientAuthMethod, serverAuthPluginData, clientAuthResponse)
+	if !ok {
+		return
+	}
+
+	c.User = user
+	c.UserData = userData
+
+	defer func() {
+		if c.User != "" {
+			connCountPerUser.Add(c.User, -1)
+		}
+	}()
+	if c.User != "" {
+		connCountPerUser.Add(c.User, 1)
+	}
+
+	// Set initial db name.
+	if c.schemaName != "" {
+		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
+			return nil
+		})
+		if err != nil {
+			c.writeErrorPacketFromError(err)
+			return
+		}
+	}
+
+	// Negotiation worked, send OK packet.
+	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
+		log.Errorf("Cannot write OK packet to %s: %v", c, err)
+		return
+	}
+
+	// Everything after the OK packet is compressed, if requested.
+	if algorithm := c.negotiatedCompression(); algorithm != "" {
+		if err := c.enableCompression(algorithm, c.zstdCompressionLevel); err != nil {
+			log.Errorf("Cannot enable %v compression for %s: %v", algorithm, c, err)
+			return
+		}
+	}
+
+	// Record how long we took to establish the connection
+	timings.Record(connectTimingKey, acceptTime)
+
+	// Log a warning if it took too long to connect
+	connectTime := time.Since(acceptTime).Nanoseconds()
+	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
+		connSlow.Add(1)
+		log.Warningf("Slow connection from %s: %v", c, connectTime)
+	}
+
+	// Tell our handler that we're finished handshake and are ready to
+	// process commands.
+	l.handler.ConnectionReady(c)
+
+	for {
+		kontinue := c.handleNextCommand(l.handler)
+		// before going for next command check if the connection should be closed or not.
+		if !kontinue || c.IsMarkedForClose() {
+			return
+		}
+	}
+}
+
+// authenticateUser negotiates the auth method with the client for the given
+// user and validates the auth response. It is used both during the initial
+// handshake and when processing a COM_CHANGE_USER. On failure, it takes care
+// of reporting the error to the client and returns false.
+func (l *Listener) authenticateUser(c *Conn, user string, clientAuthMethod AuthMethodDescription, serverAuthPluginData []byte, clientAuthResponse []byte) (Getter, bool) {
 	// See what auth method the AuthServer wants to use for that user.
 	negotiatedAuthMethod, err := negotiateAuthMethod(c, l.authServer, user, clientAuthMethod)
 
@@ -459,86 +553,40 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 
 		if negotiatedAuthMethod == nil {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "No authentication methods available for authentication.")
-			return
+			return nil, false
 		}
 
 		if !l.AllowClearTextWithoutTLS.Load() && !c.TLSEnabled() && !negotiatedAuthMethod.AllowClearTextWithoutTLS() {
 			c.writeErrorPacket(sqlerror.CRServerHandshakeErr, sqlerror.SSUnknownSQLState, "Cannot use clear text authentication over non-SSL connections.")
-			return
+			return nil, false
 		}
 
 		serverAuthPluginData, err = negotiatedAuthMethod.AuthPluginData()
 		if err != nil {
 			log.Errorf("Error generating auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		if err := c.writeAuthSwitchRequest(string(negotiatedAuthMethod.Name()), serverAuthPluginData); err != nil {
 			log.Errorf("Error writing auth switch packet for %s: %v", c, err)
-			return
+			return nil, false
 		}
 
 		clientAuthResponse, err = c.readEphemeralPacket()
 		if err != nil {
 			log.Errorf("Error reading auth switch response for %s: %v", c, err)
-			return
+			return nil, false
 		}
 		c.recycleReadPacket()
 	}
 
-	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, conn.RemoteAddr())
+	userData, err := negotiatedAuthMethod.HandleAuthPluginData(c, user, serverAuthPluginData, clientAuthResponse, c.RemoteAddr())
 	if err != nil {
 		log.Warningf("Error authenticating user %s using: %s", user, negotiatedAuthMethod.Name())
 		c.writeErrorPacketFromError(err)
-		return
-	}
-
-	c.User = user
-	c.UserData = userData
-
-	if c.User != "" {
-		connCountPerUser.Add(c.User, 1)
-		defer connCountPerUser.Add(c.User, -1)
-	}
-
-	// Set initial db name.
-	if c.schemaName != "" {
-		err = l.handler.ComQuery(c, "use "+sqlescape.EscapeID(c.schemaName), func(result *sqltypes.Result) error {
-			return nil
-		})
-		if err != nil {
-			c.writeErrorPacketFromError(err)
-			return
-		}
-	}
-
-	// Negotiation worked, send OK packet.
-	if err := c.writeOKPacket(&PacketOK{statusFlags: c.StatusFlags}); err != nil {
-		log.Errorf("Cannot write OK packet to %s: %v", c, err)
-		return
-	}
-
-	// Record how long we took to establish the connection
-	timings.Record(connectTimingKey, acceptTime)
-
-	// Log a warning if it took too long to connect
-	connectTime := time.Since(acceptTime).Nanoseconds()
-	if threshold := l.SlowConnectWarnThreshold.Load(); threshold != 0 && connectTime > threshold {
-		connSlow.Add(1)
-		log.Warningf("Slow connection from %s: %v", c, connectTime)
-	}
-
-	// Tell our handler that we're finished handshake and are ready to
-	// process commands.
-	l.handler.ConnectionReady(c)
-
-	for {
-		kontinue := c.handleNextCommand(l.handler)
-		// before going for next command check if the connection should be closed or not.
-		if !kontinue || c.IsMarkedForClose() {
-			return
-		}
+		return nil, false
 	}
+	return userData, true
 }
 
 // Close stops the listener, which prevents accept of any new connections. Existing connections won't be closed.
@@ -556,7 +604,7 @@ func (l *Listener) Shutdown() {
 
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, enableTLS bool) ([]byte, error) {
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, enableTLS bool, enableCompression bool) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -569,10 +617,14 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, en
 		CapabilityClientPluginAuth |
 		CapabilityClientPluginAuthLenencClientData |
 		CapabilityClientDeprecateEOF |
-		CapabilityClientConnAttr
+		CapabilityClientConnAttr |
+		CapabilityClientQueryAttributes
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	if enableCompression {
+		capabilities |= CapabilityClientCompress | CapabilityClientZstdCompressionAlgorithm
+	}
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -693,11 +745,28 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows)
 	}
 
+	// Only honor a compression request if we advertised it. If the
+	// client asks for both, zstd wins.
+	if l.EnableCompression {
+		switch {
+		case clientFlags&CapabilityClientZstdCompressionAlgorithm != 0:
+			c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+		case clientFlags&CapabilityClientCompress != 0:
+			c.Capabilities |= CapabilityClientCompress
+		}
+	}
+
 	// set connection capability for executing multi statements
 	if clientFlags&CapabilityClientMultiStatements > 0 {
 		c.Capabilities |= CapabilityClientMultiStatements
 	}
 
+	// The query attributes change the format of COM_QUERY and
+	// COM_STMT_EXECUTE.
+	if clientFlags&CapabilityClientQueryAttributes != 0 {
+		c.Capabilities |= CapabilityClientQueryAttributes
+	}
+
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
@@ -790,14 +859,83 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		_, attrsEnd, err := parseConnAttrs(data, pos)
+		if err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
 		}
+		pos = attrsEnd
+	}
+
+	// zstd compression level, only present if zstd was requested.
+	// Without it, the default level is used.
+	if clientFlags&CapabilityClientZstdCompressionAlgorithm != 0 && pos > 0 {
+		if level, _, ok := readByte(data, pos); ok {
+			c.zstdCompressionLevel = int(level)
+		}
 	}
 
 	return username, AuthMethodDescription(authMethod), authResponse, nil
 }
 
+// parseComChangeUser parses a COM_CHANGE_USER packet sent by the client.
+// Returns the username, auth method, auth data, schema name, error.
+// All protocol 4.1 clients use CLIENT_SECURE_CONNECTION, so the
+// auth-response is always length-prefixed by a single byte.
+// The original data is not pointed at, and can be freed.
+func (c *Conn) parseComChangeUser(data []byte) (string, AuthMethodDescription, []byte, string, error) {
+	pos := 1
+
+	// username
+	username, pos, ok := readNullString(data, pos)
+	if !ok {
+		return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read username")
+	}
+
+	// auth-response
+	authResponseLen, pos, ok := readByte(data, pos)
+	if !ok {
+		return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read auth-response length")
+	}
+	authResponse, pos, ok := readBytesCopy(data, pos, int(authResponseLen))
+	if !ok {
+		return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read auth-response")
+	}
+
+	// db name
+	dbname, pos, ok := readNullString(data, pos)
+	if !ok {
+		return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read dbname")
+	}
+
+	// The remaining fields are optional, older clients stop here.
+	authMethod := MysqlNativePassword
+	if pos < len(data) {
+		characterSet, newPos, ok := readUint16(data, pos)
+		if !ok {
+			return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read characterSet")
+		}
+		pos = newPos
+		c.CharacterSet = collations.ID(characterSet)
+	}
+	if pos < len(data) {
+		var authMethodStr string
+		authMethodStr, pos, ok = readNullString(data, pos)
+		if !ok {
+			return "", "", nil, "", vterrors.Errorf(vtrpc.Code_INTERNAL, "parseComChangeUser: can't read authMethod")
+		}
+		if authMethodStr != "" {
+			authMethod = AuthMethodDescription(authMethodStr)
+		}
+	}
+	if pos < len(data) {
+		if _, _, err := parseConnAttrs(data, pos); err != nil {
+			log.Warningf("Decode connection attributes send by the client: %v", err)
+		}
+	}
+
+	return username, authMethod, authResponse, dbname, nil
+}
+
 func parseConnAttrs(data []byte, pos int) (map[string]string, int, error) {
 	var attrLen uint64
 
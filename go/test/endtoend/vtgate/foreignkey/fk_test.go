@@ -48,9 +48,12 @@ func TestInsertWithFK(t *testing.T) {
 	_, err := utils.ExecAllowError(t, conn, `insert into t2(id, col) values (1310, 125)`)
 	assert.ErrorContains(t, err, "Cannot add or update a child row: a foreign key constraint fails")
 
-	// Verify that insertion fails if the table has cross-shard foreign keys (even if the data follows the constraints).
-	_, err = utils.ExecAllowError(t, conn, `insert into t3(id, col) values (100, 100)`)
-	assert.ErrorContains(t, err, "VT12002: unsupported: cross-shard foreign keys")
+	// Verify that inserting data into a table that has cross-shard foreign keys works.
+	utils.Exec(t, conn, `insert into t3(id, col) values (100, 100)`)
+
+	// Verify that insertion fails at vtgate if the data doesn't follow the cross-shard fk constraint.
+	_, err = utils.ExecAllowError(t, conn, `insert into t3(id, col) values (101, 42)`)
+	assert.ErrorContains(t, err, "Cannot add or update a child row: a foreign key constraint fails")
 
 	// insert some data in a table with multicol vindex.
 	utils.Exec(t, conn, `insert into multicol_tbl1(cola, colb, colc, msg) values (100, 'a', 'b', 'msg'), (101, 'c', 'd', 'msg2')`)
@@ -84,9 +87,15 @@ func TestDeleteWithFK(t *testing.T) {
 	qr := utils.Exec(t, conn, `delete from t2 where col = 125`)
 	assert.EqualValues(t, 1, qr.RowsAffected)
 
-	// table's child foreign key has cross shard fk, so query will fail at vtgate.
-	_, err = utils.ExecAllowError(t, conn, `delete from t1 where id = 42`)
-	assert.ErrorContains(t, err, "VT12002: unsupported: cross-shard foreign keys (errno 1235) (sqlstate 42000)")
+	// table's child foreign key has cross shard fk, the child rows are verified at vtgate.
+	// child row does not exist so query will succeed.
+	qr = utils.Exec(t, conn, `delete from t1 where id = 42`)
+	assert.EqualValues(t, 0, qr.RowsAffected)
+
+	// child row exists in the cross shard child table, so query will fail at vtgate.
+	utils.Exec(t, conn, `insert into t3(id, col) values (100, 100)`)
+	_, err = utils.ExecAllowError(t, conn, `delete from t1 where id = 100`)
+	assert.ErrorContains(t, err, "Cannot delete or update a parent row: a foreign key constraint fails")
 
 	// child foreign key is cascade, so this should work as expected.
 	qr = utils.Exec(t, conn, `delete from multicol_tbl1 where cola = 100`)
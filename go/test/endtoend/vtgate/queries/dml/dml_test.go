@@ -39,3 +39,86 @@ func TestMultiEqual(t *testing.T) {
 	qr = mcmp.Exec("delete from user_tbl where (id, region_id) in ((1,1), (2,4))")
 	assert.EqualValues(t, 1, qr.RowsAffected)
 }
+
+// TestReplaceWithOwnedVindex tests that a replace maintains the owned vindexes of the replaced rows.
+func TestReplaceWithOwnedVindex(t *testing.T) {
+	mcmp, closer := start(t)
+	defer closer()
+
+	mcmp.Exec("insert into order_tbl(region_id, oid, cust_no) values (1,1,100),(1,2,200)")
+
+	// the existing row is deleted and the new row inserted
+	qr := mcmp.Exec("replace into order_tbl(region_id, oid, cust_no) values (1,1,150)")
+	assert.EqualValues(t, 2, qr.RowsAffected)
+
+	// a new row is inserted
+	qr = mcmp.Exec("replace into order_tbl(region_id, oid, cust_no) values (2,3,300)")
+	assert.EqualValues(t, 1, qr.RowsAffected)
+
+	mcmp.AssertMatches("select region_id, oid, cust_no from order_tbl order by oid", `[[INT64(1) INT64(1) INT64(150)] [INT64(1) INT64(2) INT64(200)] [INT64(2) INT64(3) INT64(300)]]`)
+	// the lookup vindex routes to the new rows
+	mcmp.AssertMatches("select region_id, cust_no from order_tbl where oid = 3", `[[INT64(2) INT64(300)]]`)
+}
+
+// TestUpsertChangingVindex tests an insert on duplicate key update changing an owned vindex column.
+func TestUpsertChangingVindex(t *testing.T) {
+	mcmp, closer := start(t)
+	defer closer()
+
+	mcmp.Exec("insert into order_tbl(region_id, oid, cust_no) values (1,1,100),(1,2,200)")
+
+	// the existing row is updated, and the new row inserted
+	qr := mcmp.Exec("insert into order_tbl(region_id, oid, cust_no) values (1,1,100),(1,3,300) on duplicate key update oid = oid + 10")
+	assert.EqualValues(t, 3, qr.RowsAffected)
+
+	mcmp.AssertMatches("select region_id, oid, cust_no from order_tbl order by oid", `[[INT64(1) INT64(2) INT64(200)] [INT64(1) INT64(3) INT64(300)] [INT64(1) INT64(11) INT64(100)]]`)
+	// the lookup vindex routes to the updated row
+	mcmp.AssertMatches("select region_id, cust_no from order_tbl where oid = 11", `[[INT64(1) INT64(100)]]`)
+	mcmp.AssertIsEmpty("select region_id, cust_no from order_tbl where oid = 1")
+}
+
+// TestUpdatePrimaryVindex tests that an update of the primary vindex column moves the rows to their new shard.
+func TestUpdatePrimaryVindex(t *testing.T) {
+	mcmp, closer := start(t)
+	defer closer()
+
+	mcmp.Exec("insert into order_tbl(region_id, oid, cust_no) values (1,1,100),(1,2,200),(1,3,300)")
+
+	qr := mcmp.Exec("update order_tbl set region_id = region_id + 1 where oid in (1, 2)")
+	assert.EqualValues(t, 2, qr.RowsAffected)
+
+	// the rows left unchanged are not affected
+	qr = mcmp.Exec("update order_tbl set region_id = 2 where oid = 1")
+	assert.EqualValues(t, 0, qr.RowsAffected)
+
+	mcmp.AssertMatches("select region_id, oid, cust_no from order_tbl order by oid", `[[INT64(2) INT64(1) INT64(100)] [INT64(2) INT64(2) INT64(200)] [INT64(1) INT64(3) INT64(300)]]`)
+	// the rows are routed to their new shard
+	mcmp.AssertMatches("select oid, cust_no from order_tbl where region_id = 2 order by oid", `[[INT64(1) INT64(100)] [INT64(2) INT64(200)]]`)
+	// the owned lookup vindex routes to the moved rows
+	mcmp.AssertMatches("select region_id, cust_no from order_tbl where oid = 2", `[[INT64(2) INT64(200)]]`)
+}
+
+// TestMultiTableDML tests updates and deletes joining sharded tables, or using subqueries and common table expressions.
+func TestMultiTableDML(t *testing.T) {
+	mcmp, closer := start(t)
+	defer closer()
+
+	mcmp.Exec("insert into order_tbl(region_id, oid, cust_no) values (1,1,100),(1,2,200),(2,3,300),(2,4,400)")
+	mcmp.Exec("insert into oevent_tbl(oid, ename) values (1,'a'),(2,'b'),(3,'a'),(4,'c')")
+
+	qr := mcmp.Exec("update order_tbl o join oevent_tbl e on o.oid = e.oid set o.cust_no = o.cust_no + 1 where e.ename = 'a'")
+	assert.EqualValues(t, 2, qr.RowsAffected)
+	mcmp.AssertMatches("select oid, cust_no from order_tbl order by oid", `[[INT64(1) INT64(101)] [INT64(2) INT64(200)] [INT64(3) INT64(301)] [INT64(4) INT64(400)]]`)
+
+	qr = mcmp.Exec("delete from oevent_tbl where oid in (select oid from order_tbl where cust_no > 300)")
+	assert.EqualValues(t, 2, qr.RowsAffected)
+	mcmp.AssertMatches("select oid, ename from oevent_tbl order by oid", `[[INT64(1) VARCHAR("a")] [INT64(2) VARCHAR("b")]]`)
+
+	qr = mcmp.Exec("with e as (select oid from oevent_tbl where ename = 'b') delete o from order_tbl o join e on o.oid = e.oid")
+	assert.EqualValues(t, 1, qr.RowsAffected)
+	mcmp.AssertMatches("select oid from order_tbl order by oid", `[[INT64(1)] [INT64(3)] [INT64(4)]]`)
+
+	qr = mcmp.Exec("update order_tbl o join oevent_tbl e on o.oid = e.oid set o.cust_no = length(e.ename) + o.oid")
+	assert.EqualValues(t, 1, qr.RowsAffected)
+	mcmp.AssertMatches("select oid, cust_no from order_tbl order by oid", `[[INT64(1) INT64(2)] [INT64(3) INT64(301)] [INT64(4) INT64(400)]]`)
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// The W3C Trace Context headers, see https://www.w3.org/TR/trace-context/
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+)
+
+var _ Span = (*openTelemetrySpan)(nil)
+
+type openTelemetrySpan struct {
+	otelSpan oteltrace.Span
+}
+
+// Finish will mark a span as finished
+func (s openTelemetrySpan) Finish() {
+	s.otelSpan.End()
+}
+
+// Annotate will add information to an existing span. Like the error tag
+// of OpenTracing, an error annotation set to true marks the span as failed.
+func (s openTelemetrySpan) Annotate(key string, value any) {
+	if isError, ok := value.(bool); ok && key == "error" {
+		if isError {
+			s.otelSpan.SetStatus(codes.Error, "")
+		}
+		return
+	}
+	s.otelSpan.SetAttributes(otelAttribute(key, value))
+}
+
+// otelAttribute converts an annotation to an OpenTelemetry attribute.
+func otelAttribute(key string, value any) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int8:
+		return attribute.Int64(key, int64(v))
+	case int16:
+		return attribute.Int64(key, int64(v))
+	case int32:
+		return attribute.Int64(key, int64(v))
+	case int64:
+		return attribute.Int64(key, v)
+	case uint8:
+		return attribute.Int64(key, int64(v))
+	case uint16:
+		return attribute.Int64(key, int64(v))
+	case uint32:
+		return attribute.Int64(key, int64(v))
+	case float32:
+		return attribute.Float64(key, float64(v))
+	case float64:
+		return attribute.Float64(key, v)
+	case []string:
+		return attribute.StringSlice(key, v)
+	case fmt.Stringer:
+		return attribute.String(key, v.String())
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
+
+var _ tracingService = (*openTelemetryService)(nil)
+
+// openTelemetryService creates the spans with an OpenTelemetry tracer
+// provider, and propagates them with its propagator.
+type openTelemetryService struct {
+	provider   oteltrace.TracerProvider
+	tracer     oteltrace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func newOpenTelemetryService(provider oteltrace.TracerProvider, propagator propagation.TextMapPropagator) openTelemetryService {
+	return openTelemetryService{
+		provider:   provider,
+		tracer:     provider.Tracer("vitess.io/vitess/go/trace"),
+		propagator: propagator,
+	}
+}
+
+// AddGrpcServerOptions is part of an interface implementation
+func (s openTelemetryService) AddGrpcServerOptions(addInterceptors func(s grpc.StreamServerInterceptor, u grpc.UnaryServerInterceptor)) {
+	opts := []otelgrpc.Option{otelgrpc.WithTracerProvider(s.provider), otelgrpc.WithPropagators(s.propagator)}
+	addInterceptors(otelgrpc.StreamServerInterceptor(opts...), otelgrpc.UnaryServerInterceptor(opts...))
+}
+
+// AddGrpcClientOptions is part of an interface implementation
+func (s openTelemetryService) AddGrpcClientOptions(addInterceptors func(s grpc.StreamClientInterceptor, u grpc.UnaryClientInterceptor)) {
+	opts := []otelgrpc.Option{otelgrpc.WithTracerProvider(s.provider), otelgrpc.WithPropagators(s.propagator)}
+	addInterceptors(otelgrpc.StreamClientInterceptor(opts...), otelgrpc.UnaryClientInterceptor(opts...))
+}
+
+// New is part of an interface implementation
+func (s openTelemetryService) New(parent Span, label string) Span {
+	ctx := context.Background()
+	if parent != nil {
+		ctx = oteltrace.ContextWithSpan(ctx, parent.(openTelemetrySpan).otelSpan)
+	}
+	_, span := s.tracer.Start(ctx, label)
+	return openTelemetrySpan{otelSpan: span}
+}
+
+// NewFromString is part of an interface implementation. The parent is
+// the base64 encoded JSON map of the W3C Trace Context headers.
+func (s openTelemetryService) NewFromString(parent, label string) (Span, error) {
+	carrier, err := extractMapFromString(parent)
+	if err != nil {
+		return nil, err
+	}
+	ctx := s.propagator.Extract(context.Background(), propagation.MapCarrier(carrier))
+	if !oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return nil, vterrors.New(vtrpcpb.Code_INVALID_ARGUMENT, "failed to deserialize span context")
+	}
+	_, span := s.tracer.Start(ctx, label)
+	return openTelemetrySpan{otelSpan: span}, nil
+}
+
+// FromContext is part of an interface implementation
+func (s openTelemetryService) FromContext(ctx context.Context) (Span, bool) {
+	span := oteltrace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil, false
+	}
+	return openTelemetrySpan{otelSpan: span}, true
+}
+
+// NewContext is part of an interface implementation
+func (s openTelemetryService) NewContext(parent context.Context, span Span) context.Context {
+	otelSpan, ok := span.(openTelemetrySpan)
+	if !ok {
+		return nil
+	}
+	return oteltrace.ContextWithSpan(parent, otelSpan.otelSpan)
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+const (
+	otlpProtocolGRPC = "grpc"
+	otlpProtocolHTTP = "http/protobuf"
+
+	otlpDefaultGRPCEndpoint = "localhost:4317"
+	otlpDefaultHTTPEndpoint = "http://localhost:4318/v1/traces"
+	otlpHTTPTracesPath      = "/v1/traces"
+)
+
+// newOtlpExporter returns the OTLP exporter for the given protocol. The
+// headers are a comma separated list of key=value pairs, which are sent
+// with every request.
+func newOtlpExporter(ctx context.Context, protocol, endpoint, headers string, insecureTransport bool) (*otlptrace.Exporter, error) {
+	headerMap, err := parseOtlpHeaders(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	switch protocol {
+	case otlpProtocolGRPC:
+		if endpoint == "" {
+			endpoint = otlpDefaultGRPCEndpoint
+		}
+		// The endpoint may be a URL, in which case the scheme decides of TLS.
+		if u, err := url.Parse(endpoint); err == nil && u.Host != "" {
+			endpoint = u.Host
+			insecureTransport = u.Scheme == "http"
+		}
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithHeaders(headerMap)}
+		if insecureTransport {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	case otlpProtocolHTTP:
+		if endpoint == "" {
+			endpoint = otlpDefaultHTTPEndpoint
+		}
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("invalid OTLP endpoint %q, expected an http or https URL", endpoint)
+		}
+		// Like the OpenTelemetry SDKs, default to the standard path.
+		if u.Path == "" || u.Path == "/" {
+			u.Path = otlpHTTPTracesPath
+		}
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(u.Host), otlptracehttp.WithURLPath(u.Path), otlptracehttp.WithHeaders(headerMap)}
+		if u.Scheme == "http" {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q, possible values are '%s' and '%s'", protocol, otlpProtocolGRPC, otlpProtocolHTTP)
+	}
+}
+
+func parseOtlpHeaders(headers string) (map[string]string, error) {
+	headerMap := make(map[string]string)
+	for _, header := range strings.Split(headers, ",") {
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(header, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid OTLP header %q, expected key=value", header)
+		}
+		key, err := url.QueryUnescape(strings.TrimSpace(key))
+		if err != nil {
+			return nil, err
+		}
+		value, err = url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, err
+		}
+		headerMap[key] = value
+	}
+	return headerMap, nil
+}
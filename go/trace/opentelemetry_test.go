@@ -0,0 +1,270 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+// newTestOpenTelemetryService returns a service that keeps the finished
+// spans in memory.
+func newTestOpenTelemetryService(samplingRate float64) (openTelemetryService, *tracetest.SpanRecorder) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(recorder),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))),
+	)
+	return newOpenTelemetryService(provider, propagation.TraceContext{}), recorder
+}
+
+func TestOpenTelemetrySpans(t *testing.T) {
+	service, recorder := newTestOpenTelemetryService(1)
+
+	ctx := context.Background()
+	_, ok := service.FromContext(ctx)
+	assert.False(t, ok)
+
+	parent := service.New(nil, "Executor.Execute")
+	parent.Annotate("sql-statement-type", "SELECT")
+	ctx = service.NewContext(ctx, parent)
+
+	fromCtx, ok := service.FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, parent, fromCtx)
+	child := service.New(fromCtx, "ScatterConn.Execute")
+	child.Annotate("shards", 4)
+	child.Annotate("error", true)
+	child.Finish()
+	parent.Finish()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "ScatterConn.Execute", spans[0].Name())
+	assert.Equal(t, "Executor.Execute", spans[1].Name())
+	assert.Equal(t, spans[1].SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.False(t, spans[1].Parent().IsValid())
+
+	assert.Equal(t, "shards", string(spans[0].Attributes()[0].Key))
+	assert.EqualValues(t, 4, spans[0].Attributes()[0].Value.AsInt64())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "SELECT", spans[1].Attributes()[0].Value.AsString())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+}
+
+func TestOpenTelemetrySampling(t *testing.T) {
+	unsampled, recorder := newTestOpenTelemetryService(0)
+	span := unsampled.New(nil, "root")
+	assert.False(t, span.(openTelemetrySpan).otelSpan.SpanContext().IsSampled())
+
+	// Child spans follow their parent.
+	child := unsampled.New(span, "child")
+	assert.False(t, child.(openTelemetrySpan).otelSpan.SpanContext().IsSampled())
+	child.Finish()
+	span.Finish()
+	assert.Empty(t, recorder.Ended())
+
+	sampled, err := unsampled.NewFromString("eyJ0cmFjZXBhcmVudCI6IjAwLTRiZjkyZjM1NzdiMzRkYTZhM2NlOTI5ZDBlMGU0NzM2LTAwZjA2N2FhMGJhOTAyYjctMDEifQ==", "Executor.Execute")
+	require.NoError(t, err)
+	sampled.Finish()
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestNewFromStringTraceparent(t *testing.T) {
+	service, recorder := newTestOpenTelemetryService(0)
+
+	// This is the VT_SPAN_CONTEXT of a span, with the W3C traceparent.
+	span, err := service.NewFromString("eyJ0cmFjZXBhcmVudCI6IjAwLTRiZjkyZjM1NzdiMzRkYTZhM2NlOTI5ZDBlMGU0NzM2LTAwZjA2N2FhMGJhOTAyYjctMDEifQ==", "Executor.Execute")
+	require.NoError(t, err)
+	span.Finish()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+}
+
+func TestNewFromTraceContext(t *testing.T) {
+	service, recorder := newTestOpenTelemetryService(0)
+
+	previous := currentTracer
+	assert.False(t, SupportsTraceContext())
+	currentTracer = service
+	defer func() { currentTracer = previous }()
+	assert.True(t, SupportsTraceContext())
+
+	_, _, err := NewFromTraceContext(context.Background(), "not a traceparent", "", "vtgateHandler.ComQuery")
+	require.Error(t, err)
+
+	span, ctx, err := NewFromTraceContext(context.Background(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", "congo=t61rcWkgMzE", "vtgateHandler.ComQuery")
+	require.NoError(t, err)
+	fromCtx, ok := FromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, span, fromCtx)
+	span.Finish()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, "congo=t61rcWkgMzE", spans[0].SpanContext().TraceState().String())
+}
+
+func TestOtlpExporterOptions(t *testing.T) {
+	ctx := context.Background()
+	_, err := newOtlpExporter(ctx, "zipkin", "", "", false)
+	assert.ErrorContains(t, err, `unsupported OTLP protocol "zipkin"`)
+	_, err = newOtlpExporter(ctx, otlpProtocolHTTP, "localhost:4318", "", false)
+	assert.ErrorContains(t, err, "expected an http or https URL")
+	_, err = newOtlpExporter(ctx, otlpProtocolGRPC, "", "Authorization", false)
+	assert.ErrorContains(t, err, "expected key=value")
+
+	headers, err := parseOtlpHeaders("Authorization=Bearer%20token, x-tenant = vitess,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"Authorization": "Bearer token", "x-tenant": "vitess"}, headers)
+}
+
+// otlpCollector is an in-process OTLP collector, which keeps the
+// requests it receives.
+type otlpCollector struct {
+	coltracepb.UnimplementedTraceServiceServer
+
+	mu       sync.Mutex
+	requests []*coltracepb.ExportTraceServiceRequest
+	headers  []string
+}
+
+func (c *otlpCollector) receive(request *coltracepb.ExportTraceServiceRequest, authorization string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests = append(c.requests, request)
+	c.headers = append(c.headers, authorization)
+}
+
+// Export is part of the TraceServiceServer interface.
+func (c *otlpCollector) Export(ctx context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	c.receive(request, md.Get("authorization")[0])
+	return &coltracepb.ExportTraceServiceResponse{}, nil
+}
+
+func startOtlpHTTPCollector(t *testing.T) (*otlpCollector, string) {
+	collector := &otlpCollector{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, otlpHTTPTracesPath, r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		request := &coltracepb.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, request))
+		collector.receive(request, r.Header.Get("Authorization"))
+
+		response, err := proto.Marshal(&coltracepb.ExportTraceServiceResponse{})
+		assert.NoError(t, err)
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.Write(response)
+	}))
+	t.Cleanup(server.Close)
+	return collector, server.URL
+}
+
+func startOtlpGRPCCollector(t *testing.T) (*otlpCollector, string) {
+	collector := &otlpCollector{}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	coltracepb.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+	return collector, listener.Addr().String()
+}
+
+func TestOpenTelemetryExport(t *testing.T) {
+	for _, protocol := range []string{otlpProtocolGRPC, otlpProtocolHTTP} {
+		t.Run(protocol, func(t *testing.T) {
+			var collector *otlpCollector
+			var endpoint string
+			if protocol == otlpProtocolGRPC {
+				collector, endpoint = startOtlpGRPCCollector(t)
+			} else {
+				collector, endpoint = startOtlpHTTPCollector(t)
+			}
+
+			ctx := context.Background()
+			exporter, err := newOtlpExporter(ctx, protocol, endpoint, "Authorization=Bearer%20token", true)
+			require.NoError(t, err)
+			provider, err := newOpenTelemetryProvider(exporter, "vtgate", 1)
+			require.NoError(t, err)
+			service := newOpenTelemetryService(provider, propagation.TraceContext{})
+
+			parent := service.New(nil, "Executor.Execute")
+			child := service.New(parent, "ScatterConn.Execute")
+			child.Annotate("shards", 4)
+			child.Finish()
+			parent.Finish()
+
+			closer := &otelProviderCloser{provider: provider}
+			require.NoError(t, closer.Close())
+
+			collector.mu.Lock()
+			defer collector.mu.Unlock()
+			require.NotEmpty(t, collector.requests)
+			assert.Equal(t, "Bearer token", collector.headers[0])
+
+			var names []string
+			var traceIDs []string
+			for _, request := range collector.requests {
+				for _, resourceSpans := range request.ResourceSpans {
+					var serviceName string
+					for _, attr := range resourceSpans.Resource.Attributes {
+						if attr.Key == "service.name" {
+							serviceName = attr.Value.GetStringValue()
+						}
+					}
+					assert.Equal(t, "vtgate", serviceName)
+					for _, scopeSpans := range resourceSpans.ScopeSpans {
+						for _, span := range scopeSpans.Spans {
+							names = append(names, span.Name)
+							traceIDs = append(traceIDs, hex.EncodeToString(span.TraceId))
+						}
+					}
+				}
+			}
+			assert.Equal(t, []string{"ScatterConn.Execute", "Executor.Execute"}, names)
+			assert.Equal(t, traceIDs[0], traceIDs[1])
+		})
+	}
+}
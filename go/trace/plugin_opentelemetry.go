@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package trace
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+
+	"vitess.io/vitess/go/viperutil"
+	"vitess.io/vitess/go/vt/log"
+)
+
+const otelShutdownTimeout = 10 * time.Second
+
+var (
+	otelConfigKey = viperutil.KeyPrefixFunc(configKey("opentelemetry"))
+
+	otelEndpoint = viperutil.Configure(
+		otelConfigKey("endpoint"),
+		viperutil.Options[string]{
+			EnvVars:  []string{"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "OTEL_EXPORTER_OTLP_ENDPOINT"},
+			FlagName: "otel-exporter-otlp-endpoint",
+		},
+	)
+	otelProtocol = viperutil.Configure(
+		otelConfigKey("protocol"),
+		viperutil.Options[string]{
+			Default:  otlpProtocolGRPC,
+			EnvVars:  []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"},
+			FlagName: "otel-exporter-otlp-protocol",
+		},
+	)
+	otelInsecure = viperutil.Configure(
+		otelConfigKey("insecure"),
+		viperutil.Options[bool]{
+			EnvVars:  []string{"OTEL_EXPORTER_OTLP_TRACES_INSECURE", "OTEL_EXPORTER_OTLP_INSECURE"},
+			FlagName: "otel-exporter-otlp-insecure",
+		},
+	)
+	otelHeaders = viperutil.Configure(
+		otelConfigKey("headers"),
+		viperutil.Options[string]{
+			EnvVars:  []string{"OTEL_EXPORTER_OTLP_TRACES_HEADERS", "OTEL_EXPORTER_OTLP_HEADERS"},
+			FlagName: "otel-exporter-otlp-headers",
+		},
+	)
+)
+
+func init() {
+	// If compiled with plugin_opentelemetry, ensure that trace.RegisterFlags
+	// includes the OpenTelemetry tracing flags.
+	pluginFlags = append(pluginFlags, func(fs *pflag.FlagSet) {
+		fs.String("otel-exporter-otlp-endpoint", otelEndpoint.Default(), "endpoint of the OpenTelemetry collector to send spans to. defaults to localhost:4317 for grpc, and http://localhost:4318/v1/traces for http/protobuf")
+		fs.String("otel-exporter-otlp-protocol", otelProtocol.Default(), "protocol used to send spans to the OpenTelemetry collector. possible values are 'grpc' and 'http/protobuf'")
+		fs.Bool("otel-exporter-otlp-insecure", otelInsecure.Default(), "whether to disable TLS when sending spans to the OpenTelemetry collector with grpc")
+		fs.String("otel-exporter-otlp-headers", otelHeaders.Default(), "comma separated list of key=value headers to send to the OpenTelemetry collector")
+
+		viperutil.BindFlags(fs, otelEndpoint, otelProtocol, otelInsecure, otelHeaders)
+	})
+}
+
+// newOpenTelemetryTracer will instantiate a tracingService that sends the
+// spans to an OpenTelemetry collector with the OTLP exporters, and propagates
+// them with the W3C Trace Context headers. Root spans are sampled with
+// --tracing-sampling-rate, and the other spans follow the decision of their
+// parent. OTEL_SERVICE_NAME overrides the service name used in code.
+func newOpenTelemetryTracer(serviceName string) (tracingService, io.Closer, error) {
+	if name := os.Getenv("OTEL_SERVICE_NAME"); name != "" {
+		serviceName = name
+	}
+
+	exporter, err := newOtlpExporter(context.Background(), otelProtocol.Get(), otelEndpoint.Get(), otelHeaders.Get(), otelInsecure.Get())
+	if err != nil {
+		return nil, &nilCloser{}, err
+	}
+	log.Infof("Tracing to OpenTelemetry collector with %v as %v", otelProtocol.Get(), serviceName)
+
+	provider, err := newOpenTelemetryProvider(exporter, serviceName, samplingRate.Get())
+	if err != nil {
+		return nil, &nilCloser{}, err
+	}
+	propagator := propagation.TraceContext{}
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagator)
+
+	return newOpenTelemetryService(provider, propagator), &otelProviderCloser{provider: provider}, nil
+}
+
+// newOpenTelemetryProvider returns a tracer provider that samples root spans
+// with the given rate, and exports the sampled spans in batches.
+func newOpenTelemetryProvider(exporter sdktrace.SpanExporter, serviceName string, samplingRate float64) (*sdktrace.TracerProvider, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", serviceName)))
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRate))),
+	), nil
+}
+
+// otelProviderCloser flushes the spans that are not exported yet, and
+// shuts down the tracer provider.
+type otelProviderCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (c *otelProviderCloser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), otelShutdownTimeout)
+	defer cancel()
+	return c.provider.Shutdown(ctx)
+}
+
+func init() {
+	tracingBackendFactories["opentelemetry"] = newOpenTelemetryTracer
+}
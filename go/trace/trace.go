@@ -21,6 +21,8 @@ package trace
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -65,6 +67,28 @@ func NewFromString(inCtx context.Context, parent, label string) (Span, context.C
 	return span, outCtx, nil
 }
 
+// NewFromTraceContext creates a new Span with the currently installed tracing plugin, extracting the span context
+// from the W3C Trace Context traceparent and tracestate values. The tracestate is optional.
+// Only the tracing plugins propagating the W3C Trace Context, like opentelemetry, can extract it.
+func NewFromTraceContext(inCtx context.Context, traceparent, tracestate, label string) (Span, context.Context, error) {
+	carrier := map[string]string{traceparentHeader: traceparent}
+	if tracestate != "" {
+		carrier[tracestateHeader] = tracestate
+	}
+	parent, err := json.Marshal(carrier)
+	if err != nil {
+		return nil, nil, err
+	}
+	return NewFromString(inCtx, base64.StdEncoding.EncodeToString(parent), label)
+}
+
+// SupportsTraceContext returns true if the currently installed tracing plugin can extract
+// the span context from the W3C Trace Context, see NewFromTraceContext.
+func SupportsTraceContext() bool {
+	_, ok := currentTracer.(openTelemetryService)
+	return ok
+}
+
 // AnnotateSQL annotates information about a sql query in the span. This is done in a way
 // so as to not leak personally identifying information (PII), or sensitive personal information (SPI)
 func AnnotateSQL(span Span, strippedSQL fmt.Stringer) {
@@ -79,6 +79,7 @@ type DBConfigs struct {
 	Charset                    string        `json:"charset,omitempty"`
 	Flags                      uint64        `json:"flags,omitempty"`
 	Flavor                     string        `json:"flavor,omitempty"`
+	Compression                string        `json:"compression,omitempty"`
 	SslMode                    vttls.SslMode `json:"sslMode,omitempty"`
 	SslCa                      string        `json:"sslCa,omitempty"`
 	SslCaPath                  string        `json:"sslCaPath,omitempty"`
@@ -136,6 +137,7 @@ func registerBaseFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&GlobalDBConfigs.Charset, "db_charset", "utf8mb4", "Character set used for this tablet.")
 	fs.Uint64Var(&GlobalDBConfigs.Flags, "db_flags", 0, "Flag values as defined by MySQL.")
 	fs.StringVar(&GlobalDBConfigs.Flavor, "db_flavor", "", "Flavor overrid. Valid value is FilePos.")
+	fs.StringVar(&GlobalDBConfigs.Compression, "db_compression", "", "Compression algorithm to use for the connections to mysqld, if the server supports it. Options: zlib, zstd.")
 	fs.Var(&GlobalDBConfigs.SslMode, "db_ssl_mode", "SSL mode to connect with. One of disabled, preferred, required, verify_ca & verify_identity.")
 	fs.StringVar(&GlobalDBConfigs.SslCa, "db_ssl_ca", "", "connection ssl ca")
 	fs.StringVar(&GlobalDBConfigs.SslCaPath, "db_ssl_ca_path", "", "connection ssl ca path")
@@ -348,6 +350,9 @@ func (dbcfgs *DBConfigs) InitWithSocket(defaultSocketFile string) {
 		if userKey != ExternalRepl {
 			cp.Flavor = dbcfgs.Flavor
 		}
+		if dbcfgs.Compression != "" {
+			cp.Compression = dbcfgs.Compression
+		}
 		cp.ConnectTimeoutMs = uint64(dbcfgs.ConnectTimeoutMilliseconds)
 		cp.EnableQueryInfo = dbcfgs.EnableQueryInfo
 
@@ -50,6 +50,7 @@ var (
 		"vtadmin",
 		"vtbackup",
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtctl",
 		"vtctlclient",
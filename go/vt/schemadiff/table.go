@@ -2138,6 +2138,91 @@ func (c *CreateTableEntity) Apply(diff EntityDiff) (Entity, error) {
 	return dup, nil
 }
 
+// ApplyAlterTable applies an ALTER TABLE statement as written by users, rather than as generated by
+// schemadiff's Diff() function, onto the table defined by this entity. The options of the statement are
+// applied in order. Renames of the table and options which don't change its definition, like ALGORITHM
+// or LOCK, are ignored. This entity is unmodified. If successful, a new CREATE TABLE entity is returned.
+func (c *CreateTableEntity) ApplyAlterTable(alterTable *sqlparser.AlterTable) (*CreateTableEntity, error) {
+	dup := c.Clone().(*CreateTableEntity)
+	alterTable = sqlparser.CloneRefOfAlterTable(alterTable)
+	applyOption := func(opt sqlparser.AlterOption) error {
+		return dup.apply(&AlterTableEntityDiff{from: dup, alterTable: &sqlparser.AlterTable{
+			Table:        dup.Table,
+			AlterOptions: []sqlparser.AlterOption{opt},
+		}})
+	}
+	for _, opt := range alterTable.AlterOptions {
+		var err error
+		switch opt := opt.(type) {
+		case *sqlparser.RenameTableName, *sqlparser.LockOption, *sqlparser.Force, *sqlparser.Validation,
+			*sqlparser.KeyState, *sqlparser.OrderByOption, sqlparser.AlgorithmValue:
+			// these options don't change the definition of the table
+		case *sqlparser.AddColumns:
+			if len(opt.Columns) == 1 {
+				err = applyOption(opt)
+				break
+			}
+			for _, col := range opt.Columns {
+				if err = applyOption(&sqlparser.AddColumns{Columns: []*sqlparser.ColumnDefinition{col}}); err != nil {
+					break
+				}
+			}
+		case *sqlparser.ChangeColumn:
+			// CHANGE COLUMN redefines the column under its old name, and then renames it
+			colDefinition := sqlparser.CloneRefOfColumnDefinition(opt.NewColDefinition)
+			colDefinition.Name = opt.OldColumn.Name
+			err = applyOption(&sqlparser.ModifyColumn{NewColDefinition: colDefinition, First: opt.First, After: opt.After})
+			if err == nil && !opt.OldColumn.Name.Equal(opt.NewColDefinition.Name) {
+				err = dup.applyRenameColumn(opt.OldColumn.Name, opt.NewColDefinition.Name)
+			}
+		case *sqlparser.RenameColumn:
+			err = dup.applyRenameColumn(opt.OldName.Name, opt.NewName.Name)
+		default:
+			err = applyOption(opt)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if alterTable.PartitionSpec != nil {
+		if err := dup.apply(&AlterTableEntityDiff{from: dup, alterTable: &sqlparser.AlterTable{
+			Table:         dup.Table,
+			PartitionSpec: alterTable.PartitionSpec,
+		}}); err != nil {
+			return nil, err
+		}
+	}
+	dup.normalize()
+	return dup, nil
+}
+
+// applyRenameColumn renames a column, along with the keys and foreign keys referencing it.
+func (c *CreateTableEntity) applyRenameColumn(oldName, newName sqlparser.IdentifierCI) error {
+	for _, idx := range c.TableSpec.Indexes {
+		for _, col := range idx.Columns {
+			if col.Column.Equal(oldName) {
+				col.Column = newName
+			}
+		}
+	}
+	for _, constraint := range c.TableSpec.Constraints {
+		if fk, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition); ok {
+			for i, col := range fk.Source {
+				if col.Equal(oldName) {
+					fk.Source[i] = newName
+				}
+			}
+		}
+	}
+	return c.apply(&AlterTableEntityDiff{from: c, alterTable: &sqlparser.AlterTable{
+		Table: c.Table,
+		AlterOptions: []sqlparser.AlterOption{&sqlparser.RenameColumn{
+			OldName: &sqlparser.ColName{Name: oldName},
+			NewName: &sqlparser.ColName{Name: newName},
+		}},
+	}})
+}
+
 // postApplyNormalize runs at the end of apply() and to reorganize/edit things that
 // a MySQL will do implicitly:
 //   - edit or remove keys if referenced columns are dropped
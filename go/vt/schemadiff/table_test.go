@@ -1851,6 +1851,81 @@ func TestValidate(t *testing.T) {
 	}
 }
 
+func TestApplyAlterTable(t *testing.T) {
+	tt := []struct {
+		name      string
+		from      string
+		alter     string
+		to        string
+		expectErr error
+	}{
+		{
+			name:  "add columns",
+			from:  "create table t (id int primary key)",
+			alter: "alter table t add column (i int, v varchar(10))",
+			to:    "create table t (id int primary key, i int, v varchar(10))",
+		},
+		{
+			name:  "add column after",
+			from:  "create table t (id int primary key, v varchar(10))",
+			alter: "alter table t add column i int after id, algorithm=inplace, lock=none",
+			to:    "create table t (id int primary key, i int, v varchar(10))",
+		},
+		{
+			name:  "change column",
+			from:  "create table t (id int primary key, i int, key i_idx (i))",
+			alter: "alter table t change column i j bigint not null",
+			to:    "create table t (id int primary key, j bigint not null, key i_idx (j))",
+		},
+		{
+			name:  "rename column",
+			from:  "create table t (id int primary key, i int, key i_idx (i))",
+			alter: "alter table t rename column i to j",
+			to:    "create table t (id int primary key, j int, key i_idx (j))",
+		},
+		{
+			name:  "options in order",
+			from:  "create table t (id int primary key, i int)",
+			alter: "alter table t drop column i, add column i bigint, rename to t2",
+			to:    "create table t (id int primary key, i bigint)",
+		},
+		{
+			name:      "missing column",
+			from:      "create table t (id int primary key)",
+			alter:     "alter table t drop column i",
+			expectErr: &ApplyColumnNotFoundError{Table: "t", Column: "i"},
+		},
+	}
+	hints := DiffHints{}
+	for _, ts := range tt {
+		t.Run(ts.name, func(t *testing.T) {
+			stmt, err := sqlparser.ParseStrictDDL(ts.from)
+			require.NoError(t, err)
+			from, err := NewCreateTableEntity(stmt.(*sqlparser.CreateTable))
+			require.NoError(t, err)
+			fromStatement := from.Create().CanonicalStatementString()
+
+			stmt, err = sqlparser.ParseStrictDDL(ts.alter)
+			require.NoError(t, err)
+			applied, err := from.ApplyAlterTable(stmt.(*sqlparser.AlterTable))
+			assert.Equal(t, fromStatement, from.Create().CanonicalStatementString())
+			if ts.expectErr != nil {
+				assert.EqualError(t, err, ts.expectErr.Error())
+				return
+			}
+			require.NoError(t, err)
+
+			stmt, err = sqlparser.ParseStrictDDL(ts.to)
+			require.NoError(t, err)
+			to, err := NewCreateTableEntity(stmt.(*sqlparser.CreateTable))
+			require.NoError(t, err)
+			diff, err := applied.Diff(to, &hints)
+			require.NoError(t, err)
+			assert.Empty(t, diff, "diff found: %v.\napplied: %v\nto: %v", diff.CanonicalStatementString(), applied.Create().CanonicalStatementString(), to.Create().CanonicalStatementString())
+		})
+	}
+}
+
 func TestNormalize(t *testing.T) {
 	tt := []struct {
 		name string
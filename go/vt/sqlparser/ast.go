@@ -324,8 +324,9 @@ type (
 	// Replace is the counterpart to `INSERT IGNORE`, and works exactly like a
 	// normal INSERT except if the row exists. In that case it first deletes
 	// the row and re-inserts with new values. For that reason we keep it as an Insert struct.
-	// Replaces are currently disallowed in sharded schemas because
-	// of the implications the deletion part may have on vindexes.
+	// In sharded schemas, because of the implications the deletion part may have
+	// on vindexes, Replaces on tables with owned vindexes are executed by vtgate
+	// as a delete followed by an insert.
 	// If you add fields here, consider adding them to calls to validateUnshardedRoute.
 	Insert struct {
 		Action   InsertAction
@@ -2917,6 +2918,8 @@ type (
 	Sum struct {
 		Arg      Expr
 		Distinct bool
+		// OverClause is set when SUM is used as a window function.
+		OverClause *OverClause
 	}
 
 	BitAnd struct {
@@ -4456,7 +4456,8 @@ func (cmp *Comparator) RefOfSum(a, b *Sum) bool {
 		return false
 	}
 	return a.Distinct == b.Distinct &&
-		cmp.Expr(a.Arg, b.Arg)
+		cmp.Expr(a.Arg, b.Arg) &&
+		cmp.RefOfOverClause(a.OverClause, b.OverClause)
 }
 
 // TableExprs does deep equals between the two objects.
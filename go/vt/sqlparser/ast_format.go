@@ -2720,6 +2720,9 @@ func (node *Sum) Format(buf *TrackedBuffer) {
 		buf.literal(DistinctStr)
 	}
 	buf.astPrintf(node, "%v)", node.Arg)
+	if node.OverClause != nil {
+		buf.astPrintf(node, " %v", node.OverClause)
+	}
 }
 
 func (node *BitAnd) Format(buf *TrackedBuffer) {
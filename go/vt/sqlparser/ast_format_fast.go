@@ -3598,6 +3598,10 @@ func (node *Sum) formatFast(buf *TrackedBuffer) {
 	}
 	buf.printExpr(node, node.Arg, true)
 	buf.WriteByte(')')
+	if node.OverClause != nil {
+		buf.WriteByte(' ')
+		node.OverClause.formatFast(buf)
+	}
 }
 
 func (node *BitAnd) formatFast(buf *TrackedBuffer) {
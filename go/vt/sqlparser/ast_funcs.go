@@ -1069,6 +1069,14 @@ func (node *Select) SetInto(into *SelectInto) {
 	node.Into = into
 }
 
+// CTEs returns the common table expressions of the with clause
+func (node *With) CTEs() []*CommonTableExpr {
+	if node == nil {
+		return nil
+	}
+	return node.ctes
+}
+
 // SetWith sets the with clause to a select statement
 func (node *Select) SetWith(with *With) {
 	node.With = with
@@ -2063,6 +2071,10 @@ func ContainsAggregation(e SQLNode) bool {
 			// so we don't need to worry about aggregation in the original
 			return false, nil
 		case AggrFunc:
+			if IsWindowFunction(node) {
+				// an aggregate function used as a window function does not aggregate the rows
+				return true, nil
+			}
 			hasAggregates = true
 			return false, io.EOF
 		}
@@ -2072,6 +2084,57 @@ func ContainsAggregation(e SQLNode) bool {
 	return hasAggregates
 }
 
+// IsWindowFunction returns true if the node is a window function, or an
+// aggregate function used as a window function with an OVER clause
+func IsWindowFunction(node SQLNode) bool {
+	switch node := node.(type) {
+	case *ArgumentLessWindowExpr, *FirstOrLastValueExpr, *NtileExpr, *NTHValueExpr, *LagLeadExpr:
+		return true
+	case *Sum:
+		return node.OverClause != nil
+	}
+	return false
+}
+
+// ContainsWindowFunction returns true if the expression contains a window function
+func ContainsWindowFunction(e SQLNode) bool {
+	hasWindowFunction := false
+	_ = Walk(func(node SQLNode) (kontinue bool, err error) {
+		switch node.(type) {
+		case *Offset:
+			// offsets here indicate that a possible window function has already been handled by an input
+			return false, nil
+		case *Subquery:
+			return false, nil
+		}
+		if IsWindowFunction(node) {
+			hasWindowFunction = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, e)
+	return hasWindowFunction
+}
+
+// GetOverClause returns the OVER clause of a window function
+func GetOverClause(node SQLNode) *OverClause {
+	switch node := node.(type) {
+	case *ArgumentLessWindowExpr:
+		return node.OverClause
+	case *FirstOrLastValueExpr:
+		return node.OverClause
+	case *NtileExpr:
+		return node.OverClause
+	case *NTHValueExpr:
+		return node.OverClause
+	case *LagLeadExpr:
+		return node.OverClause
+	case *Sum:
+		return node.OverClause
+	}
+	return nil
+}
+
 // GetFirstSelect gets the first select statement
 func GetFirstSelect(selStmt SelectStatement) *Select {
 	if selStmt == nil {
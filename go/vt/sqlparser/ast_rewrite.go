@@ -8220,6 +8220,11 @@ func (a *application) rewriteRefOfSum(parent SQLNode, node *Sum, replacer replac
 	}) {
 		return false
 	}
+	if !a.rewriteRefOfOverClause(node, node.OverClause, func(newNode, parent SQLNode) {
+		parent.(*Sum).OverClause = newNode.(*OverClause)
+	}) {
+		return false
+	}
 	if a.post != nil {
 		a.cur.replacer = replacer
 		a.cur.parent = parent
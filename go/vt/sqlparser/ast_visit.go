@@ -3823,6 +3823,9 @@ func VisitRefOfSum(in *Sum, f Visit) error {
 	if err := VisitExpr(in.Arg, f); err != nil {
 		return err
 	}
+	if err := VisitRefOfOverClause(in.OverClause, f); err != nil {
+		return err
+	}
 	return nil
 }
 func VisitTableExprs(in TableExprs, f Visit) error {
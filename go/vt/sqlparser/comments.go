@@ -56,6 +56,9 @@ const (
 	// DirectivePriority specifies the priority of a workload. It should be an integer between 0 and MaxPriorityValue,
 	// where 0 is the highest priority, and MaxPriorityValue is the lowest one.
 	DirectivePriority = "PRIORITY"
+	// DirectiveResultCacheTTL caches the results of a SELECT in vtgate for the given duration in milliseconds.
+	// 0 disables the cache, even for the tables whose VSchema enables it.
+	DirectiveResultCacheTTL = "RESULT_CACHE_TTL_MS"
 
 	// MaxPriorityValue specifies the maximum value allowed for the priority query directive. Valid priority values are
 	// between zero and MaxPriorityValue.
@@ -17,6 +17,7 @@ limitations under the License.
 package sqlparser
 
 import (
+	"maps"
 	"strconv"
 	"strings"
 )
@@ -150,6 +151,14 @@ func (r *ReservedVars) nextUnusedVar() string {
 	}
 }
 
+// Clone returns a copy of the ReservedVars that keeps track of the variable names it reserves on its own.
+func (r *ReservedVars) Clone() *ReservedVars {
+	clone := *r
+	clone.reserved = maps.Clone(r.reserved)
+	clone.next = append([]byte(nil), r.next...)
+	return &clone
+}
+
 // NewReservedVars allocates a ReservedVar instance that will generate unique
 // variable names starting with the given `prefix` and making sure that they
 // don't conflict with the given set of `known` variables.
@@ -434,7 +434,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %type <framePoint> frame_point
 %type <frameClause> frame_clause frame_clause_opt
 %type <windowSpecification> window_spec
-%type <overClause> over_clause
+%type <overClause> over_clause over_clause_opt
 %type <nullTreatmentType> null_treatment_type
 %type <nullTreatmentClause> null_treatment_clause null_treatment_clause_opt
 %type <fromFirstLastType> from_first_last_type
@@ -5629,6 +5629,7 @@ window_partition_clause_opt:
 
 sql_id_opt:
   {
+    $$ = IdentifierCI{}
   }
 | sql_id
   {
@@ -5641,6 +5642,15 @@ sql_id_opt window_partition_clause_opt order_by_opt frame_clause_opt
     $$ = &WindowSpecification{ Name: $1, PartitionClause: $2, OrderClause: $3, FrameClause: $4}
   }
 
+over_clause_opt:
+  {
+    $$ = nil
+  }
+| over_clause
+  {
+    $$ = $1
+  }
+
 over_clause:
   OVER openb window_spec closeb
   {
@@ -5945,9 +5955,9 @@ UTC_DATE func_paren_opt
   {
     $$ = &Min{Distinct:$3, Arg:$4}
   }
-| SUM openb distinct_opt expression closeb
+| SUM openb distinct_opt expression closeb over_clause_opt
   {
-    $$ = &Sum{Distinct:$3, Arg:$4}
+    $$ = &Sum{Distinct:$3, Arg:$4, OverClause:$6}
   }
 | AVG openb distinct_opt expression closeb
   {
@@ -89,6 +89,7 @@ const (
 	ShardsPath            = "shards"
 	TabletsPath           = "tablets"
 	MetadataPath          = "metadata"
+	VStreamCursorsPath    = "vstream_cursors"
 	ExternalClusterVitess = "vitess"
 )
 
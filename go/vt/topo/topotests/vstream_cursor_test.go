@@ -0,0 +1,88 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topotests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// This file tests the VStream cursor part of the topo.Server API.
+
+func TestVStreamCursor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "cell1")
+	defer ts.Close()
+
+	names, err := ts.GetVStreamCursorNames(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, _, err = ts.GetVStreamCursor(ctx, "orders")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	vgtid := func(gtid string) *binlogdatapb.VGtid {
+		return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: gtid}}}
+	}
+	version, err := ts.UpdateVStreamCursor(ctx, "orders", vgtid("MySQL56/a:1-10"), nil)
+	require.NoError(t, err)
+
+	// a cursor can only be created once
+	_, err = ts.UpdateVStreamCursor(ctx, "orders", vgtid("MySQL56/a:1-5"), nil)
+	assert.True(t, topo.IsErrType(err, topo.NodeExists), err)
+
+	_, err = ts.UpdateVStreamCursor(ctx, "orders", vgtid("MySQL56/a:1-11"), version)
+	require.NoError(t, err)
+	// the version of a cursor changes when it is updated
+	_, err = ts.UpdateVStreamCursor(ctx, "orders", vgtid("MySQL56/a:1-12"), version)
+	assert.True(t, topo.IsErrType(err, topo.BadVersion), err)
+
+	position, _, err := ts.GetVStreamCursor(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-11", position.ShardGtids[0].Gtid)
+
+	require.NoError(t, ts.SaveVStreamCursor(ctx, "customers", vgtid("MySQL56/a:1-3")))
+	require.NoError(t, ts.SaveVStreamCursor(ctx, "customers", vgtid("MySQL56/a:1-4")))
+	names, err = ts.GetVStreamCursorNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customers", "orders"}, names)
+
+	require.NoError(t, ts.DeleteVStreamCursor(ctx, "orders"))
+	names, err = ts.GetVStreamCursorNames(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"customers"}, names)
+
+	err = ts.SaveVStreamCursor(ctx, "a/b", vgtid("MySQL56/a:1-3"))
+	assert.EqualError(t, err, `invalid VStream cursor name: "a/b"`)
+	_, _, err = ts.GetVStreamCursor(ctx, "")
+	assert.EqualError(t, err, `invalid VStream cursor name: ""`)
+	for _, name := range []string{".", "..", "a b", "a\\b"} {
+		err = ts.SaveVStreamCursor(ctx, name, vgtid("MySQL56/a:1-3"))
+		assert.EqualError(t, err, fmt.Sprintf("invalid VStream cursor name: %q", name))
+	}
+	require.NoError(t, topo.ValidateVStreamCursorName("orders.v2_eu-west"))
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topo
+
+import (
+	"context"
+	"path"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// VStream cursors are the positions of the consumers of named VStreams. They
+// are stored in the global cell, so that all the vtgates share them.
+
+// GetVStreamCursorPath returns the node path of the named VStream cursor
+func GetVStreamCursorPath(name string) string {
+	return path.Join(VStreamCursorsPath, name)
+}
+
+// ValidateVStreamCursorName returns an error if the name can't be the name of a VStream cursor.
+// The name is a node of the global cell, so it may only hold letters, digits, '-', '_' and '.',
+// and can't be "." or "..", which would resolve to another node.
+func ValidateVStreamCursorName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.IndexFunc(name, invalidVStreamCursorNameRune) >= 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid VStream cursor name: %q", name)
+	}
+	return nil
+}
+
+func invalidVStreamCursorNameRune(r rune) bool {
+	switch {
+	case 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z', '0' <= r && r <= '9', r == '-', r == '_', r == '.':
+		return false
+	default:
+		return true
+	}
+}
+
+// GetVStreamCursorNames returns the names of the VStream cursors
+func (ts *Server) GetVStreamCursorNames(ctx context.Context) ([]string, error) {
+	children, err := ts.globalCell.ListDir(ctx, VStreamCursorsPath, false /*full*/)
+	switch {
+	case err == nil:
+		return DirEntriesToStringArray(children), nil
+	case IsErrType(err, NoNode):
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+// GetVStreamCursor returns the position of the named VStream cursor, and its
+// version. It returns a NoNode error if the cursor doesn't exist.
+func (ts *Server) GetVStreamCursor(ctx context.Context, name string) (*binlogdatapb.VGtid, Version, error) {
+	if err := ValidateVStreamCursorName(name); err != nil {
+		return nil, nil, err
+	}
+	data, version, err := ts.globalCell.Get(ctx, GetVStreamCursorPath(name))
+	if err != nil {
+		return nil, nil, err
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	if err := vgtid.UnmarshalVT(data); err != nil {
+		return nil, nil, vterrors.Wrapf(err, "bad VStream cursor data for %s", name)
+	}
+	return vgtid, version, nil
+}
+
+// UpdateVStreamCursor sets the position of the named VStream cursor. The
+// cursor is created if version is nil, and otherwise only updated if it
+// still is at version. It returns the new version of the cursor.
+func (ts *Server) UpdateVStreamCursor(ctx context.Context, name string, vgtid *binlogdatapb.VGtid, version Version) (Version, error) {
+	if err := ValidateVStreamCursorName(name); err != nil {
+		return nil, err
+	}
+	data, err := vgtid.MarshalVT()
+	if err != nil {
+		return nil, err
+	}
+	if version == nil {
+		return ts.globalCell.Create(ctx, GetVStreamCursorPath(name), data)
+	}
+	return ts.globalCell.Update(ctx, GetVStreamCursorPath(name), data, version)
+}
+
+// SaveVStreamCursor sets the position of the named VStream cursor, creating
+// it if it doesn't exist.
+func (ts *Server) SaveVStreamCursor(ctx context.Context, name string, vgtid *binlogdatapb.VGtid) error {
+	if err := ValidateVStreamCursorName(name); err != nil {
+		return err
+	}
+	data, err := vgtid.MarshalVT()
+	if err != nil {
+		return err
+	}
+	_, err = ts.globalCell.Update(ctx, GetVStreamCursorPath(name), data, nil)
+	return err
+}
+
+// DeleteVStreamCursor deletes the named VStream cursor
+func (ts *Server) DeleteVStreamCursor(ctx context.Context, name string) error {
+	if err := ValidateVStreamCursorName(name); err != nil {
+		return err
+	}
+	return ts.globalCell.Delete(ctx, GetVStreamCursorPath(name), nil)
+}
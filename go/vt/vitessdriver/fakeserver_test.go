@@ -168,6 +168,11 @@ func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.T
 	return nil
 }
 
+// VStreamAck is part of the VTGateService interface
+func (f *fakeVTGateService) VStreamAck(ctx context.Context, cursor string, vgtid *binlogdatapb.VGtid) error {
+	return nil
+}
+
 // HandlePanic is part of the VTGateService interface
 func (f *fakeVTGateService) HandlePanic(err *error) {
 	if x := recover(); x != nil {
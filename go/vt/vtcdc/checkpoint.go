@@ -0,0 +1,104 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"vitess.io/vitess/go/json2"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// Checkpointer saves the position of the published changes
+type Checkpointer interface {
+	// Load returns the saved position, or nil if nothing has been saved yet
+	Load(ctx context.Context) (*binlogdatapb.VGtid, error)
+	// Save saves the position. It must be durable when it returns.
+	Save(ctx context.Context, position *binlogdatapb.VGtid) error
+}
+
+// FileCheckpointer saves the position in a local file, as JSON
+type FileCheckpointer struct {
+	path string
+}
+
+var _ Checkpointer = (*FileCheckpointer)(nil)
+
+// NewFileCheckpointer returns a Checkpointer saving the position in the given file
+func NewFileCheckpointer(path string) *FileCheckpointer {
+	return &FileCheckpointer{path: path}
+}
+
+// Load implements the Checkpointer interface
+func (fc *FileCheckpointer) Load(context.Context) (*binlogdatapb.VGtid, error) {
+	data, err := os.ReadFile(fc.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	position := &binlogdatapb.VGtid{}
+	if err := protojson.Unmarshal(data, position); err != nil {
+		return nil, err
+	}
+	return position, nil
+}
+
+// Save implements the Checkpointer interface. The position is written in a temporary file that
+// replaces the previous checkpoint, so the checkpoint is never partially written.
+func (fc *FileCheckpointer) Save(_ context.Context, position *binlogdatapb.VGtid) error {
+	data, err := json2.MarshalIndentPB(position, "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fc.path), filepath.Base(fc.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), fc.path); err != nil {
+		return err
+	}
+
+	// the rename is only durable once the directory is synced
+	dir, err := os.Open(filepath.Dir(fc.path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CloudEventsEncoder encodes the change events as CloudEvents 1.0, in the structured content mode
+// of the JSON event format. See https://github.com/cloudevents/spec.
+type CloudEventsEncoder struct {
+	// Name is the logical name of the source of the events
+	Name string
+}
+
+var _ Encoder = (*CloudEventsEncoder)(nil)
+
+// cloudEventTypes are the types of the events of each operation
+var cloudEventTypes = map[Operation]string{
+	OpCreate: "io.vitess.row.created",
+	OpUpdate: "io.vitess.row.updated",
+	OpDelete: "io.vitess.row.deleted",
+	OpRead:   "io.vitess.row.copied",
+}
+
+type cloudEvent struct {
+	SpecVersion     string `json:"specversion"`
+	ID              string `json:"id"`
+	Source          string `json:"source"`
+	Type            string `json:"type"`
+	Subject         string `json:"subject"`
+	Time            string `json:"time"`
+	DataContentType string `json:"datacontenttype"`
+	// PartitionKey is the extension attribute of the partitioning specification
+	PartitionKey string          `json:"partitionkey,omitempty"`
+	Data         *cloudEventData `json:"data"`
+}
+
+type cloudEventData struct {
+	Keyspace string   `json:"keyspace"`
+	Shard    string   `json:"shard"`
+	Table    string   `json:"table"`
+	Gtid     string   `json:"gtid"`
+	Before   *jsonRow `json:"before"`
+	After    *jsonRow `json:"after"`
+}
+
+// Encode implements the Encoder interface
+func (ce *CloudEventsEncoder) Encode(ev *ChangeEvent) (*Message, error) {
+	key, err := encodeKey(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := json.Marshal(&cloudEvent{
+		SpecVersion:     "1.0",
+		ID:              ev.ID(),
+		Source:          "/" + ce.Name + "/" + ev.Keyspace + "/" + ev.Shard,
+		Type:            cloudEventTypes[ev.Op],
+		Subject:         ev.Table,
+		Time:            ev.CommitTime.UTC().Format(time.RFC3339),
+		DataContentType: "application/json",
+		PartitionKey:    string(key),
+		Data: &cloudEventData{
+			Keyspace: ev.Keyspace,
+			Shard:    ev.Shard,
+			Table:    ev.Table,
+			Gtid:     ev.ShardGtid().GetGtid(),
+			Before:   newJSONRow(ev.Fields, ev.Before),
+			After:    newJSONRow(ev.Fields, ev.After),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Key:         key,
+		Value:       value,
+		ContentType: "application/cloudevents+json",
+		Time:        ev.CommitTime,
+	}, nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/json"
+)
+
+// DebeziumEncoder encodes the change events like the Debezium connector for Vitess does,
+// with the JSON converter and without schemas. The key of the messages is the JSON object
+// of the primary key of the row, and their value is the change event envelope.
+type DebeziumEncoder struct {
+	// Name is the logical name of the source of the events
+	Name string
+}
+
+var _ Encoder = (*DebeziumEncoder)(nil)
+
+type debeziumEnvelope struct {
+	Before *jsonRow        `json:"before"`
+	After  *jsonRow        `json:"after"`
+	Source *debeziumSource `json:"source"`
+	Op     Operation       `json:"op"`
+	TsMs   int64           `json:"ts_ms"`
+}
+
+type debeziumSource struct {
+	Connector string `json:"connector"`
+	Name      string `json:"name"`
+	TsMs      int64  `json:"ts_ms"`
+	Snapshot  string `json:"snapshot"`
+	DB        string `json:"db"`
+	Keyspace  string `json:"keyspace"`
+	Table     string `json:"table"`
+	Shard     string `json:"shard"`
+	// Vgtid is the JSON array of the positions of the shards of the stream
+	Vgtid string `json:"vgtid"`
+}
+
+type debeziumShardGtid struct {
+	Keyspace string `json:"keyspace"`
+	Shard    string `json:"shard"`
+	Gtid     string `json:"gtid"`
+}
+
+// Encode implements the Encoder interface
+func (de *DebeziumEncoder) Encode(ev *ChangeEvent) (*Message, error) {
+	var vgtid []debeziumShardGtid
+	for _, sgtid := range ev.Position.GetShardGtids() {
+		vgtid = append(vgtid, debeziumShardGtid{Keyspace: sgtid.Keyspace, Shard: sgtid.Shard, Gtid: sgtid.Gtid})
+	}
+	vgtidJSON, err := json.Marshal(vgtid)
+	if err != nil {
+		return nil, err
+	}
+
+	// the rows copied are snapshot reads, the last one ending the snapshot
+	snapshot := "false"
+	switch {
+	case ev.LastCopied:
+		snapshot = "last"
+	case ev.Op == OpRead:
+		snapshot = "true"
+	}
+
+	value, err := json.Marshal(&debeziumEnvelope{
+		Before: newJSONRow(ev.Fields, ev.Before),
+		After:  newJSONRow(ev.Fields, ev.After),
+		Source: &debeziumSource{
+			Connector: "vitess",
+			Name:      de.Name,
+			TsMs:      ev.CommitTime.UnixMilli(),
+			Snapshot:  snapshot,
+			DB:        ev.Keyspace,
+			Keyspace:  ev.Keyspace,
+			Table:     ev.Table,
+			Shard:     ev.Shard,
+			Vgtid:     string(vgtidJSON),
+		},
+		Op:   ev.Op,
+		TsMs: ev.StreamTime.UnixMilli(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := encodeKey(ev)
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Key:         key,
+		Value:       value,
+		ContentType: "application/json",
+		Time:        ev.CommitTime,
+	}, nil
+}
+
+// encodeKey returns the JSON object of the primary key of the row of a change, or nil if its table has no primary key
+func encodeKey(ev *ChangeEvent) ([]byte, error) {
+	fields, values := ev.Key()
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(newJSONRow(fields, values))
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// Message is a change event encoded for a sink
+type Message struct {
+	// Topic is the topic, or stream, the message is published to
+	Topic string
+	// Key identifies the row of the change, and is nil if its table has no primary key
+	Key   []byte
+	Value []byte
+	// ContentType is the media type of the value
+	ContentType string
+	Headers     []Header
+	// Time is the time of the transaction of the change
+	Time time.Time
+}
+
+// Header is a header of a message
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Encoder encodes the change events
+type Encoder interface {
+	// Encode encodes a change event. The topic of the message is set by the Streamer.
+	Encode(ev *ChangeEvent) (*Message, error)
+}
+
+// NewEncoder returns the encoder of a format, debezium or cloudevents. The name is the
+// logical name of the source of the events.
+func NewEncoder(format, name string) (Encoder, error) {
+	switch format {
+	case "debezium":
+		return &DebeziumEncoder{Name: name}, nil
+	case "cloudevents":
+		return &CloudEventsEncoder{Name: name}, nil
+	}
+	return nil, fmt.Errorf("unknown format %q, must be debezium or cloudevents", format)
+}
+
+// jsonRow is a row of a table, encoded as a JSON object whose keys are the columns of the
+// table, in the order of the table
+type jsonRow struct {
+	fields []*querypb.Field
+	values []sqltypes.Value
+}
+
+// newJSONRow returns the JSON object of a row, or nil if there is no row
+func newJSONRow(fields []*querypb.Field, values []sqltypes.Value) *jsonRow {
+	if values == nil {
+		return nil
+	}
+	return &jsonRow{fields: fields, values: values}
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (r *jsonRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range r.fields {
+		if i >= len(r.values) {
+			break
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.Name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		value, err := json.Marshal(jsonValue(r.values[i]))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// jsonValue returns the JSON representation of a value: the numbers are JSON numbers,
+// except the decimals which are strings so that they keep their precision, and the
+// binary values are base64 strings
+func jsonValue(v sqltypes.Value) any {
+	switch typ := v.Type(); {
+	case v.IsNull():
+		return nil
+	case sqltypes.IsIntegral(typ) || sqltypes.IsFloat(typ):
+		return json.RawMessage(v.Raw())
+	case typ == sqltypes.TypeJSON && json.Valid(v.Raw()):
+		return json.RawMessage(v.Raw())
+	case sqltypes.IsBinary(typ):
+		return v.Raw()
+	default:
+		return v.ToString()
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestJSONRow(t *testing.T) {
+	fields := []*querypb.Field{
+		{Name: "i", Type: sqltypes.Int64},
+		{Name: "u", Type: sqltypes.Uint32},
+		{Name: "f", Type: sqltypes.Float64},
+		{Name: "d", Type: sqltypes.Decimal},
+		{Name: "s", Type: sqltypes.VarChar},
+		{Name: "b", Type: sqltypes.VarBinary},
+		{Name: "j", Type: sqltypes.TypeJSON},
+		{Name: "dt", Type: sqltypes.Datetime},
+		{Name: "n", Type: sqltypes.Int64},
+	}
+	values := []sqltypes.Value{
+		sqltypes.NewInt64(-1),
+		sqltypes.NewUint32(2),
+		sqltypes.NewFloat64(1.5),
+		sqltypes.NewDecimal("1.10"),
+		sqltypes.NewVarChar(`a "quoted" string`),
+		sqltypes.NewVarBinary("\x00\x01"),
+		sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"a": [1, 2]}`)),
+		sqltypes.NewDatetime("2024-01-02 03:04:05"),
+		sqltypes.NULL,
+	}
+	b, err := json.Marshal(newJSONRow(fields, values))
+	require.NoError(t, err)
+	assert.Equal(t, `{"i":-1,"u":2,"f":1.5,"d":"1.10","s":"a \"quoted\" string","b":"AAE=","j":{"a":[1,2]},"dt":"2024-01-02 03:04:05","n":null}`, string(b))
+}
+
+func TestCloudEventsEncoder(t *testing.T) {
+	ev := &ChangeEvent{
+		Keyspace:   "ks",
+		Shard:      "-80",
+		Table:      "t1",
+		Op:         OpUpdate,
+		Fields:     testFields(),
+		Before:     []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("one")},
+		After:      []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("uno")},
+		CommitTime: time.Unix(1700000000, 0),
+		Position:   testPosition("MySQL56/a:1-10"),
+		Index:      3,
+	}
+	encoder, err := NewEncoder("cloudevents", "vitess")
+	require.NoError(t, err)
+	msg, err := encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Equal(t, "application/cloudevents+json", msg.ContentType)
+	assert.Equal(t, `{"id":1}`, string(msg.Key))
+	assert.Equal(t, `{"specversion":"1.0","id":"`+ev.ID()+`","source":"/vitess/ks/-80","type":"io.vitess.row.updated","subject":"t1","time":"2023-11-14T22:13:20Z","datacontenttype":"application/json","partitionkey":"{\"id\":1}","data":{"keyspace":"ks","shard":"-80","table":"t1","gtid":"MySQL56/a:1-10","before":{"id":1,"name":"one"},"after":{"id":1,"name":"uno"}}}`, string(msg.Value))
+
+	// the identifier of an event only depends on its position in the stream
+	id := ev.ID()
+	assert.Len(t, id, 32)
+	ev.Index = 4
+	assert.NotEqual(t, id, ev.ID())
+	ev.Index = 3
+	ev.Position = testPosition("MySQL56/a:1-11")
+	assert.NotEqual(t, id, ev.ID())
+	ev.Position = testPosition("MySQL56/a:1-10")
+	assert.Equal(t, id, ev.ID())
+
+	_, err = NewEncoder("avro", "vitess")
+	assert.EqualError(t, err, `unknown format "avro", must be debezium or cloudevents`)
+}
+
+func TestDebeziumEncoder(t *testing.T) {
+	ev := &ChangeEvent{
+		Keyspace:   "ks",
+		Shard:      "-80",
+		Table:      "t1",
+		Op:         OpUpdate,
+		Fields:     testFields(),
+		Before:     []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("one")},
+		After:      []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewVarChar("uno")},
+		CommitTime: time.Unix(1700000000, 0),
+		StreamTime: time.Unix(1700000001, 0),
+		Position:   testPosition("MySQL56/a:1-10"),
+	}
+	encoder, err := NewEncoder("debezium", "vitess")
+	require.NoError(t, err)
+	msg, err := encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", msg.ContentType)
+	assert.Equal(t, `{"id":1}`, string(msg.Key))
+	assert.Equal(t, ev.CommitTime, msg.Time)
+	assert.Equal(t, `{"before":{"id":1,"name":"one"},"after":{"id":1,"name":"uno"},"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"false","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-10\"}]"},"op":"u","ts_ms":1700000001000}`, string(msg.Value))
+
+	// the rows copied are snapshot reads, and the last one copied ends the snapshot
+	ev.Op = OpRead
+	ev.Before = nil
+	msg, err = encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Equal(t, `{"before":null,"after":{"id":1,"name":"uno"},"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"true","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-10\"}]"},"op":"r","ts_ms":1700000001000}`, string(msg.Value))
+
+	ev.LastCopied = true
+	msg, err = encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Contains(t, string(msg.Value), `"snapshot":"last"`)
+	assert.Contains(t, string(msg.Value), `"op":"r"`)
+
+	// the changes of a table without primary key have no key
+	ev.Fields = []*querypb.Field{{Name: "id", Type: sqltypes.Int64}, {Name: "name", Type: sqltypes.VarChar}}
+	msg, err = encoder.Encode(ev)
+	require.NoError(t, err)
+	assert.Nil(t, msg.Key)
+}
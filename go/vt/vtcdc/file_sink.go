@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+)
+
+func init() {
+	RegisterSink("file", newFileSink)
+}
+
+// FileSink appends the values of the messages to a local file, one per line
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+	// sync is false when the file is not a regular file, like a pipe, which can't be synced
+	sync bool
+}
+
+var _ Sink = (*FileSink)(nil)
+
+// NewFileSink returns a sink appending to the given file, which is created if needed
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileSink{file: file, sync: info.Mode().IsRegular()}, nil
+}
+
+// newFileSink creates a FileSink from an URL like file:///var/log/changes.json
+func newFileSink(u *url.URL) (Sink, error) {
+	path := u.Path
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("no path in file sink %q", u.Redacted())
+	}
+	return NewFileSink(path)
+}
+
+// Publish implements the Sink interface
+func (fs *FileSink) Publish(_ context.Context, msgs []*Message) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	w := bufio.NewWriter(fs.file)
+	for _, msg := range msgs {
+		w.Write(msg.Value)
+		w.WriteByte('\n')
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if fs.sync {
+		return fs.file.Sync()
+	}
+	return nil
+}
+
+// Close implements the Sink interface
+func (fs *FileSink) Close() error {
+	return fs.file.Close()
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+func init() {
+	RegisterSink("http", newHTTPSink)
+	RegisterSink("https", newHTTPSink)
+}
+
+// HTTPSink posts the messages to a webhook, one request per message. The body of a request
+// is the value of its message, and the headers of the message are sent as HTTP headers.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+	// redactedURL is the URL without its password, to be used in errors
+	redactedURL string
+}
+
+var _ Sink = (*HTTPSink)(nil)
+
+// NewHTTPSink returns a sink posting the messages to the given URL
+func NewHTTPSink(rawURL string, timeout time.Duration) *HTTPSink {
+	redactedURL := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		redactedURL = u.Redacted()
+	}
+	return &HTTPSink{
+		url:         rawURL,
+		client:      &http.Client{Timeout: timeout},
+		redactedURL: redactedURL,
+	}
+}
+
+func newHTTPSink(u *url.URL) (Sink, error) {
+	return NewHTTPSink(u.String(), 30*time.Second), nil
+}
+
+// Publish implements the Sink interface
+func (hs *HTTPSink) Publish(ctx context.Context, msgs []*Message) error {
+	for _, msg := range msgs {
+		if err := hs.post(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (hs *HTTPSink) post(ctx context.Context, msg *Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hs.url, bytes.NewReader(msg.Value))
+	if err != nil {
+		return err
+	}
+	if msg.ContentType != "" {
+		req.Header.Set("Content-Type", msg.ContentType)
+	}
+	for _, h := range msg.Headers {
+		req.Header.Add(h.Key, string(h.Value))
+	}
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// the body is read so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s answered %s", hs.redactedURL, resp.Status)
+	}
+	return nil
+}
+
+// Close implements the Sink interface
+func (hs *HTTPSink) Close() error {
+	hs.client.CloseIdleConnections()
+	return nil
+}
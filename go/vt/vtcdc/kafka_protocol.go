@@ -0,0 +1,409 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// This file implements the subset of the Kafka wire protocol needed to produce messages:
+// the Metadata (v1) and Produce (v3) requests, and the v2 record batches they carry, as well as
+// the SaslHandshake (v1) and SaslAuthenticate (v0) requests authenticating the connections.
+// See https://kafka.apache.org/protocol for the format of the messages.
+
+const (
+	kafkaProduceKey          int16 = 0
+	kafkaMetadataKey         int16 = 3
+	kafkaSaslHandshakeKey    int16 = 17
+	kafkaSaslAuthenticateKey int16 = 36
+
+	kafkaProduceVersion          int16 = 3
+	kafkaMetadataVersion         int16 = 1
+	kafkaSaslHandshakeVersion    int16 = 1
+	kafkaSaslAuthenticateVersion int16 = 0
+
+	// kafkaMaxResponseSize protects the client from allocating huge buffers when talking
+	// to something that is not a Kafka broker.
+	kafkaMaxResponseSize = 100 * 1024 * 1024
+)
+
+// kafkaErrors contains the messages of the error codes a producer can get from a broker
+var kafkaErrors = map[int16]string{
+	1:  "OFFSET_OUT_OF_RANGE",
+	2:  "CORRUPT_MESSAGE",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_OR_FOLLOWER",
+	7:  "REQUEST_TIMED_OUT",
+	10: "MESSAGE_TOO_LARGE",
+	17: "INVALID_TOPIC_EXCEPTION",
+	18: "RECORD_LIST_TOO_LARGE",
+	19: "NOT_ENOUGH_REPLICAS",
+	20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	21: "INVALID_REQUIRED_ACKS",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+	33: "UNSUPPORTED_SASL_MECHANISM",
+	34: "ILLEGAL_SASL_STATE",
+	58: "SASL_AUTHENTICATION_FAILED",
+	87: "INVALID_RECORD",
+}
+
+// kafkaErrorCode is an error code returned by a broker
+type kafkaErrorCode int16
+
+func (code kafkaErrorCode) Error() string {
+	if name, ok := kafkaErrors[int16(code)]; ok {
+		return fmt.Sprintf("kafka error %d: %s", code, name)
+	}
+	return fmt.Sprintf("kafka error %d", code)
+}
+
+// retriable returns whether the request can succeed once the metadata is reloaded, because
+// the leader of the partition moved to another broker or is being elected
+func (code kafkaErrorCode) retriable() bool {
+	return code == 5 || code == 6
+}
+
+func kafkaError(code int16) error {
+	if code == 0 {
+		return nil
+	}
+	return kafkaErrorCode(code)
+}
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// kafkaEncoder appends the primitive types of the Kafka protocol to a buffer
+type kafkaEncoder struct {
+	buf []byte
+}
+
+func (e *kafkaEncoder) int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *kafkaEncoder) int16(v int16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *kafkaEncoder) int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *kafkaEncoder) int64(v int64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v))
+}
+
+func (e *kafkaEncoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *kafkaEncoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *kafkaEncoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *kafkaEncoder) nullableString(v *string) {
+	if v == nil {
+		e.int16(-1)
+		return
+	}
+	e.string(*v)
+}
+
+func (e *kafkaEncoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// varintBytes encodes the bytes of a record, where the length is a varint and -1 means null
+func (e *kafkaEncoder) varintBytes(v []byte) {
+	if v == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// kafkaDecoder reads the primitive types of the Kafka protocol. The first error is kept,
+// and the following reads return zero values, so the error only needs to be checked at the end.
+type kafkaDecoder struct {
+	buf []byte
+	err error
+}
+
+func (d *kafkaDecoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(d.buf) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *kafkaDecoder) int8() int8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *kafkaDecoder) int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *kafkaDecoder) int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *kafkaDecoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *kafkaDecoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *kafkaDecoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf)
+	if n <= 0 {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *kafkaDecoder) string() string {
+	return string(d.next(int(d.int16())))
+}
+
+func (d *kafkaDecoder) nullableString() *string {
+	n := d.int16()
+	if n < 0 {
+		return nil
+	}
+	s := string(d.next(int(n)))
+	return &s
+}
+
+func (d *kafkaDecoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+func (d *kafkaDecoder) varintBytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// arrayLen reads the length of an array, and fails if it is obviously too large for the rest of the buffer
+func (d *kafkaDecoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.buf) {
+		d.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	return int(n)
+}
+
+// kafkaRecord is a record of a record batch
+type kafkaRecord struct {
+	Key     []byte
+	Value   []byte
+	Headers []Header
+	// Timestamp is the creation time of the record, in milliseconds since the epoch
+	Timestamp int64
+}
+
+// encodeRecordBatch encodes the records as a v2 record batch, which is neither compressed nor transactional
+func encodeRecordBatch(records []kafkaRecord) []byte {
+	first, last := records[0].Timestamp, records[0].Timestamp
+	for _, rec := range records {
+		last = max(last, rec.Timestamp)
+	}
+
+	var recs kafkaEncoder
+	for i, rec := range records {
+		var r kafkaEncoder
+		r.int8(0) // attributes
+		r.varint(rec.Timestamp - first)
+		r.varint(int64(i)) // offset delta
+		r.varintBytes(rec.Key)
+		r.varintBytes(rec.Value)
+		r.varint(int64(len(rec.Headers)))
+		for _, h := range rec.Headers {
+			r.varintBytes([]byte(h.Key))
+			r.varintBytes(h.Value)
+		}
+		recs.varint(int64(len(r.buf)))
+		recs.buf = append(recs.buf, r.buf...)
+	}
+
+	var e kafkaEncoder
+	e.int64(0)  // base offset
+	e.int32(0)  // batch length, set below
+	e.int32(-1) // partition leader epoch
+	e.int8(2)   // magic
+	e.int32(0)  // crc, set below
+	crcStart := len(e.buf)
+	e.int16(0) // attributes
+	e.int32(int32(len(records) - 1))
+	e.int64(first)
+	e.int64(last)
+	e.int64(-1) // producer id
+	e.int16(-1) // producer epoch
+	e.int32(-1) // base sequence
+	e.int32(int32(len(records)))
+	e.buf = append(e.buf, recs.buf...)
+
+	binary.BigEndian.PutUint32(e.buf[8:12], uint32(len(e.buf)-12))
+	binary.BigEndian.PutUint32(e.buf[crcStart-4:crcStart], crc32.Checksum(e.buf[crcStart:], castagnoli))
+	return e.buf
+}
+
+// decodeRecordBatches decodes the records of the record batches of a partition
+func decodeRecordBatches(data []byte) ([]kafkaRecord, error) {
+	var records []kafkaRecord
+	d := &kafkaDecoder{buf: data}
+	for len(d.buf) > 0 && d.err == nil {
+		d.int64() // base offset
+		batch := &kafkaDecoder{buf: d.next(int(d.int32()))}
+		if d.err != nil {
+			break
+		}
+		batch.int32() // partition leader epoch
+		if magic := batch.int8(); magic != 2 {
+			return nil, fmt.Errorf("unsupported record batch version %d", magic)
+		}
+		crc := uint32(batch.int32())
+		if batch.err == nil && crc != crc32.Checksum(batch.buf, castagnoli) {
+			return nil, fmt.Errorf("invalid record batch checksum")
+		}
+		if attributes := batch.int16(); attributes&0x7 != 0 {
+			return nil, fmt.Errorf("unsupported record batch compression %d", attributes&0x7)
+		}
+		batch.int32() // last offset delta
+		first := batch.int64()
+		batch.int64() // max timestamp
+		batch.int64() // producer id
+		batch.int16() // producer epoch
+		batch.int32() // base sequence
+		count := batch.arrayLen()
+		for i := 0; i < count && batch.err == nil; i++ {
+			r := &kafkaDecoder{buf: batch.next(int(batch.varint()))}
+			r.int8() // attributes
+			timestamp := first + r.varint()
+			r.varint() // offset delta
+			rec := kafkaRecord{Key: r.varintBytes(), Value: r.varintBytes(), Timestamp: timestamp}
+			headers := int(r.varint())
+			for j := 0; j < headers && r.err == nil; j++ {
+				rec.Headers = append(rec.Headers, Header{Key: string(r.varintBytes()), Value: r.varintBytes()})
+			}
+			if r.err != nil {
+				return nil, r.err
+			}
+			records = append(records, rec)
+		}
+		if batch.err != nil {
+			return nil, batch.err
+		}
+	}
+	return records, d.err
+}
+
+// murmur2 is the hash function used by the default partitioner of the Kafka clients, so the
+// messages with the same key end up in the same partition as they would with the other clients.
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+	length := len(data)
+	h := seed ^ uint32(length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return int32(h)
+}
+
+// kafkaPartition returns the partition of a key, like the default partitioner of the Kafka clients
+func kafkaPartition(key []byte, partitions int) int {
+	return int(murmur2(key)&0x7fffffff) % partitions
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The SASL mechanisms supported to authenticate the connections to the brokers
+const (
+	SASLPlain       = "PLAIN"
+	SASLScramSHA256 = "SCRAM-SHA-256"
+	SASLScramSHA512 = "SCRAM-SHA-512"
+)
+
+// scramHash returns the hash function of a SCRAM mechanism, or nil if the mechanism is not a supported SCRAM mechanism
+func scramHash(mechanism string) func() hash.Hash {
+	switch mechanism {
+	case SASLScramSHA256:
+		return sha256.New
+	case SASLScramSHA512:
+		return sha512.New
+	}
+	return nil
+}
+
+// authenticate authenticates the connection with the SASL mechanism, using the SaslHandshake (v1)
+// and SaslAuthenticate (v0) requests
+func (c *kafkaConn) authenticate(ctx context.Context, cfg *KafkaConfig) error {
+	var e kafkaEncoder
+	e.string(cfg.SASLMechanism)
+	resp, err := c.roundTrip(ctx, cfg.ClientID, kafkaSaslHandshakeKey, kafkaSaslHandshakeVersion, e.buf, cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	d := &kafkaDecoder{buf: resp}
+	code := d.int16()
+	var mechanisms []string
+	for i, n := 0, d.arrayLen(); i < n; i++ {
+		mechanisms = append(mechanisms, d.string())
+	}
+	if d.err != nil {
+		return fmt.Errorf("invalid kafka sasl handshake response: %w", d.err)
+	}
+	if err := kafkaError(code); err != nil {
+		return fmt.Errorf("sasl mechanism %s, the broker supports %s: %w", cfg.SASLMechanism, strings.Join(mechanisms, ", "), err)
+	}
+
+	if cfg.SASLMechanism == SASLPlain {
+		_, err := c.saslAuthenticate(ctx, cfg, []byte("\x00"+cfg.SASLUser+"\x00"+cfg.SASLPassword))
+		return err
+	}
+
+	scram, err := newScramClient(scramHash(cfg.SASLMechanism), cfg.SASLUser, cfg.SASLPassword)
+	if err != nil {
+		return err
+	}
+	serverFirst, err := c.saslAuthenticate(ctx, cfg, scram.first())
+	if err != nil {
+		return err
+	}
+	clientFinal, err := scram.final(serverFirst)
+	if err != nil {
+		return err
+	}
+	serverFinal, err := c.saslAuthenticate(ctx, cfg, clientFinal)
+	if err != nil {
+		return err
+	}
+	return scram.verify(serverFinal)
+}
+
+// saslAuthenticate sends the authentication bytes of a step of the SASL exchange, and returns the ones of the broker
+func (c *kafkaConn) saslAuthenticate(ctx context.Context, cfg *KafkaConfig, auth []byte) ([]byte, error) {
+	var e kafkaEncoder
+	e.bytes(auth)
+	resp, err := c.roundTrip(ctx, cfg.ClientID, kafkaSaslAuthenticateKey, kafkaSaslAuthenticateVersion, e.buf, cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	d := &kafkaDecoder{buf: resp}
+	code := d.int16()
+	msg := d.nullableString()
+	auth = d.bytes()
+	if d.err != nil {
+		return nil, fmt.Errorf("invalid kafka sasl authenticate response: %w", d.err)
+	}
+	if err := kafkaError(code); err != nil {
+		if msg != nil && *msg != "" {
+			return nil, fmt.Errorf("sasl authentication failed: %w: %s", err, *msg)
+		}
+		return nil, fmt.Errorf("sasl authentication failed: %w", err)
+	}
+	return auth, nil
+}
+
+// scramClient is the client side of a SCRAM authentication, as described in RFC 5802.
+// Kafka does not support channel binding, so the GS2 header is always "n,,".
+type scramClient struct {
+	hash     func() hash.Hash
+	user     string
+	password string
+	nonce    string
+
+	clientFirstBare string
+	serverSignature []byte
+}
+
+func newScramClient(hash func() hash.Hash, user, password string) (*scramClient, error) {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return &scramClient{
+		hash:     hash,
+		user:     user,
+		password: password,
+		nonce:    base64.RawStdEncoding.EncodeToString(nonce),
+	}, nil
+}
+
+// first returns the client-first-message
+func (s *scramClient) first() []byte {
+	user := strings.NewReplacer("=", "=3D", ",", "=2C").Replace(s.user)
+	s.clientFirstBare = "n=" + user + ",r=" + s.nonce
+	return []byte("n,," + s.clientFirstBare)
+}
+
+// final returns the client-final-message answering the server-first-message, with the proof of the password
+func (s *scramClient) final(serverFirst []byte) ([]byte, error) {
+	attrs := scramAttributes(string(serverFirst))
+	nonce, salt64, iter := attrs["r"], attrs["s"], attrs["i"]
+	if !strings.HasPrefix(nonce, s.nonce) || len(nonce) == len(s.nonce) {
+		return nil, fmt.Errorf("invalid scram server nonce %q", nonce)
+	}
+	salt, err := base64.StdEncoding.DecodeString(salt64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scram salt %q: %w", salt64, err)
+	}
+	iterations, err := strconv.Atoi(iter)
+	if err != nil || iterations <= 0 {
+		return nil, fmt.Errorf("invalid scram iteration count %q", iter)
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(s.password), salt, iterations, s.hash().Size(), s.hash)
+	clientKey := s.hmac(saltedPassword, "Client Key")
+	h := s.hash()
+	h.Write(clientKey)
+	storedKey := h.Sum(nil)
+
+	clientFinalWithoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte("n,,")) + ",r=" + nonce
+	authMessage := s.clientFirstBare + "," + string(serverFirst) + "," + clientFinalWithoutProof
+	proof := s.hmac(storedKey, authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	s.serverSignature = s.hmac(s.hmac(saltedPassword, "Server Key"), authMessage)
+	return []byte(clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof)), nil
+}
+
+// verify checks the signature of the server-final-message, which proves the server knows the password as well
+func (s *scramClient) verify(serverFinal []byte) error {
+	attrs := scramAttributes(string(serverFinal))
+	if e, ok := attrs["e"]; ok {
+		return fmt.Errorf("scram authentication failed: %s", e)
+	}
+	signature, err := base64.StdEncoding.DecodeString(attrs["v"])
+	if err != nil || !hmac.Equal(signature, s.serverSignature) {
+		return fmt.Errorf("invalid scram server signature")
+	}
+	return nil
+}
+
+func (s *scramClient) hmac(key []byte, msg string) []byte {
+	mac := hmac.New(s.hash, key)
+	mac.Write([]byte(msg))
+	return mac.Sum(nil)
+}
+
+// scramAttributes returns the attributes of a SCRAM message, by name
+func scramAttributes(msg string) map[string]string {
+	attrs := map[string]string{}
+	for _, attr := range strings.Split(msg, ",") {
+		if name, value, ok := strings.Cut(attr, "="); ok {
+			attrs[name] = value
+		}
+	}
+	return attrs
+}
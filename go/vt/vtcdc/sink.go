@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"vitess.io/vitess/go/vt/log"
+)
+
+// Sink publishes the messages to an external system
+type Sink interface {
+	// Publish publishes the messages, in order. The messages must be durably stored when it
+	// returns without error, as their position is then checkpointed. When it fails, some of the
+	// messages may have been published anyway, and they are all published again.
+	Publish(ctx context.Context, msgs []*Message) error
+	// Close releases the resources of the sink
+	Close() error
+}
+
+// SinkFactory creates a sink from its URL
+type SinkFactory func(u *url.URL) (Sink, error)
+
+var sinkFactories = make(map[string]SinkFactory)
+
+// RegisterSink registers the factory of the sinks whose URL has the given scheme
+func RegisterSink(scheme string, factory SinkFactory) {
+	if _, ok := sinkFactories[scheme]; ok {
+		log.Fatalf("Sink %s already exists", scheme)
+	}
+	sinkFactories[scheme] = factory
+}
+
+// NewSink creates the sink of an URL, using the factory registered for its scheme
+func NewSink(sinkURL string) (Sink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		// the error of url.Parse holds the whole URL, credentials included
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("invalid sink: %w", err)
+	}
+	factory, ok := sinkFactories[u.Scheme]
+	if !ok {
+		schemes := make([]string, 0, len(sinkFactories))
+		for scheme := range sinkFactories {
+			schemes = append(schemes, scheme)
+		}
+		sort.Strings(schemes)
+		return nil, fmt.Errorf("unknown sink %q, the supported schemes are %s", u.Redacted(), strings.Join(schemes, ", "))
+	}
+	return factory(u)
+}
@@ -0,0 +1,395 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtcdc publishes the row changes streamed by the VStream API of vtgate to external
+// systems. The changes are encoded by an Encoder, published by a Sink, and the position of
+// the published changes is saved by a Checkpointer, so that the stream can be resumed from it
+// after a restart. Every change is published at least once.
+package vtcdc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// Operation is the kind of change of a row, with the values used by Debezium
+type Operation string
+
+const (
+	OpCreate Operation = "c"
+	OpUpdate Operation = "u"
+	OpDelete Operation = "d"
+	// OpRead is the operation of the rows read while copying the tables, before their changes are streamed
+	OpRead Operation = "r"
+)
+
+// ChangeEvent is the change of a row of a table
+type ChangeEvent struct {
+	Keyspace string
+	Shard    string
+	Table    string
+	Op       Operation
+	Fields   []*querypb.Field
+	// Before and After are the values of the row before and after the change.
+	// Before is nil for the inserted rows, and After is nil for the deleted rows.
+	Before []sqltypes.Value
+	After  []sqltypes.Value
+	// CommitTime is the time of the transaction of the change, or the time at which the row was
+	// received for the rows copied
+	CommitTime time.Time
+	// StreamTime is the time at which the change was streamed by the tablet, or the time at which
+	// the row was received for the rows copied
+	StreamTime time.Time
+	// Position is the position of the transaction of the change in the stream
+	Position *binlogdatapb.VGtid
+	// Index is the index of the change in its transaction
+	Index int
+	// LastCopied is set for the last row copied, whose OpRead event ends the copy phase of the stream
+	LastCopied bool
+
+	// read is set for the rows without commit time, which were read from their table rather than from the binlogs
+	read bool
+}
+
+// Key returns the fields and the values of the primary key of the row, or nil if the table has no primary key
+func (ev *ChangeEvent) Key() ([]*querypb.Field, []sqltypes.Value) {
+	row := ev.After
+	if row == nil {
+		row = ev.Before
+	}
+	var fields []*querypb.Field
+	var values []sqltypes.Value
+	for i, field := range ev.Fields {
+		if field.Flags&uint32(querypb.MySqlFlag_PRI_KEY_FLAG) != 0 && i < len(row) {
+			fields = append(fields, field)
+			values = append(values, row[i])
+		}
+	}
+	return fields, values
+}
+
+// ShardGtid returns the position of the shard of the change
+func (ev *ChangeEvent) ShardGtid() *binlogdatapb.ShardGtid {
+	for _, sgtid := range ev.Position.GetShardGtids() {
+		if sgtid.Keyspace == ev.Keyspace && sgtid.Shard == ev.Shard {
+			return sgtid
+		}
+	}
+	return nil
+}
+
+// ID returns an identifier of the change, which is unique and stays the same when the change
+// is streamed again after a restart, so that the consumers can detect the duplicates
+func (ev *ChangeEvent) ID() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s/%s/%s/%d/", ev.Keyspace, ev.Shard, ev.Table, ev.Index)
+	if sgtid := ev.ShardGtid(); sgtid != nil {
+		// the position of the copy phase also contains the last primary keys copied
+		b, _ := proto.MarshalOptions{Deterministic: true}.Marshal(sgtid)
+		h.Write(b)
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// VStreamer is the part of vtgateconn.VTGateConn used to stream the changes
+type VStreamer interface {
+	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
+		filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error)
+}
+
+// Config is the configuration of a Streamer
+type Config struct {
+	TabletType topodatapb.TabletType
+	// Position is where the stream starts when nothing has been checkpointed yet
+	Position *binlogdatapb.VGtid
+	Filter   *binlogdatapb.Filter
+	Flags    *vtgatepb.VStreamFlags
+	// TopicPrefix is the prefix of the topics of the messages, which are named <prefix>.<keyspace>.<table>
+	TopicPrefix string
+	// CheckpointInterval is the minimum interval between two checkpoints
+	CheckpointInterval time.Duration
+}
+
+// Streamer streams the changes of vtgate, and publishes them to a sink
+type Streamer struct {
+	conn         VStreamer
+	cfg          Config
+	encoder      Encoder
+	sink         Sink
+	checkpointer Checkpointer
+
+	fields map[string]*binlogdatapb.FieldEvent
+	// pending are the changes whose position has not been received yet
+	pending []*ChangeEvent
+	// copied are the rows of the last transaction of the copy phase, which are published once the next
+	// events tell whether the last of them is the last row copied. copiedPosition is their position.
+	copied         []*ChangeEvent
+	copiedPosition *binlogdatapb.VGtid
+	// position is the position of the last published changes
+	position     *binlogdatapb.VGtid
+	checkpointed bool
+	lastSave     time.Time
+}
+
+// NewStreamer creates a Streamer
+func NewStreamer(conn VStreamer, cfg Config, encoder Encoder, sink Sink, checkpointer Checkpointer) *Streamer {
+	return &Streamer{
+		conn:         conn,
+		cfg:          cfg,
+		encoder:      encoder,
+		sink:         sink,
+		checkpointer: checkpointer,
+	}
+}
+
+// Run streams and publishes the changes until the stream ends or fails. It starts from the
+// checkpointed position, and checkpoints the position of the published changes before returning,
+// so it can be called again to resume the stream.
+func (s *Streamer) Run(ctx context.Context) (err error) {
+	position, err := s.checkpointer.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot load the checkpoint: %w", err)
+	}
+	if position == nil {
+		position = s.cfg.Position
+	}
+	log.Infof("Starting the stream at %v", position)
+
+	s.fields = map[string]*binlogdatapb.FieldEvent{}
+	s.pending = nil
+	s.copied = nil
+	s.position = position
+	s.checkpointed = true
+	defer func() {
+		// the stream may have been stopped by cancelling the context, but its position must still be saved
+		if cerr := s.checkpoint(context.WithoutCancel(ctx), true); err == nil {
+			err = cerr
+		}
+	}()
+
+	reader, err := s.conn.VStream(ctx, s.cfg.TabletType, position, s.cfg.Filter, s.cfg.Flags)
+	if err != nil {
+		return err
+	}
+	for {
+		events, err := reader.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.handleEvents(ctx, events); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Streamer) handleEvents(ctx context.Context, events []*binlogdatapb.VEvent) error {
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_FIELD:
+			s.fields[event.FieldEvent.TableName] = event.FieldEvent
+		case binlogdatapb.VEventType_ROW:
+			if err := s.addRowEvent(event); err != nil {
+				return err
+			}
+		case binlogdatapb.VEventType_VGTID:
+			// vtgate sends the position of a transaction after its rows
+			if err := s.publish(ctx, event.Vgtid); err != nil {
+				return err
+			}
+		case binlogdatapb.VEventType_COPY_COMPLETED:
+			// vtgate sends a COPY_COMPLETED event without shard once all the shards are copied
+			if event.Keyspace == "" && event.Shard == "" {
+				if err := s.publishCopied(ctx, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return s.checkpoint(ctx, false)
+}
+
+func (s *Streamer) addRowEvent(event *binlogdatapb.VEvent) error {
+	rowEvent := event.RowEvent
+	fieldEvent, ok := s.fields[rowEvent.TableName]
+	if !ok {
+		return fmt.Errorf("no fields received for table %s", rowEvent.TableName)
+	}
+	// vtgate prefixes the table names with their keyspace
+	table := strings.TrimPrefix(rowEvent.TableName, rowEvent.Keyspace+".")
+	commitTime, streamTime := time.Unix(event.Timestamp, 0), time.Unix(0, event.CurrentTime)
+	if event.Timestamp == 0 {
+		// the rows copied have neither commit time nor stream time, they were read when they are received
+		commitTime = time.Now()
+		streamTime = commitTime
+	}
+
+	for _, change := range rowEvent.RowChanges {
+		ev := &ChangeEvent{
+			Keyspace:   rowEvent.Keyspace,
+			Shard:      rowEvent.Shard,
+			Table:      table,
+			Fields:     fieldEvent.Fields,
+			CommitTime: commitTime,
+			StreamTime: streamTime,
+			Index:      len(s.pending),
+			read:       event.Timestamp == 0,
+		}
+		if change.Before != nil {
+			ev.Before = sqltypes.MakeRowTrusted(fieldEvent.Fields, change.Before)
+		}
+		if change.After != nil {
+			ev.After = sqltypes.MakeRowTrusted(fieldEvent.Fields, change.After)
+		}
+		switch {
+		case ev.Before == nil:
+			ev.Op = OpCreate
+		case ev.After == nil:
+			ev.Op = OpDelete
+		default:
+			ev.Op = OpUpdate
+		}
+		s.pending = append(s.pending, ev)
+	}
+	return nil
+}
+
+// publish publishes the pending changes, whose position has been received. The rows copied are
+// held until the next position, or the end of the copy phase, is received.
+func (s *Streamer) publish(ctx context.Context, position *binlogdatapb.VGtid) error {
+	copying := len(s.pending) > 0
+	for _, ev := range s.pending {
+		copying = copying && ev.read && isCopying(position, ev)
+	}
+	if copying {
+		for _, ev := range s.pending {
+			ev.Op = OpRead
+		}
+	}
+
+	if s.copied != nil && len(s.pending) == 0 {
+		// the position of a table whose copy completed, or of an empty transaction
+		s.copiedPosition = position
+		return nil
+	}
+	if err := s.publishCopied(ctx, false); err != nil {
+		return err
+	}
+	if copying {
+		s.copied, s.copiedPosition = s.pending, position
+		s.pending = nil
+		return nil
+	}
+	if err := s.send(ctx, s.pending, position); err != nil {
+		return err
+	}
+	s.pending = nil
+	return nil
+}
+
+// isCopying returns whether the table of a row is still being copied at a position, in which case the
+// position contains the last primary key copied from the table
+func isCopying(position *binlogdatapb.VGtid, ev *ChangeEvent) bool {
+	for _, sgtid := range position.GetShardGtids() {
+		if sgtid.Keyspace != ev.Keyspace || sgtid.Shard != ev.Shard {
+			continue
+		}
+		for _, tablePK := range sgtid.TablePKs {
+			// vtgate prefixes the table names with their keyspace
+			if strings.TrimPrefix(tablePK.TableName, ev.Keyspace+".") == ev.Table {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// publishCopied publishes the rows held from the copy phase, and marks the last of them as the last row
+// copied when the copy phase is complete
+func (s *Streamer) publishCopied(ctx context.Context, complete bool) error {
+	if s.copied == nil {
+		return nil
+	}
+	if complete {
+		s.copied[len(s.copied)-1].LastCopied = true
+	}
+	if err := s.send(ctx, s.copied, s.copiedPosition); err != nil {
+		return err
+	}
+	s.copied = nil
+	return nil
+}
+
+// send encodes and publishes the changes, and moves the position after them
+func (s *Streamer) send(ctx context.Context, events []*ChangeEvent, position *binlogdatapb.VGtid) error {
+	if len(events) > 0 {
+		msgs := make([]*Message, 0, len(events))
+		for _, ev := range events {
+			ev.Position = position
+			msg, err := s.encoder.Encode(ev)
+			if err != nil {
+				return fmt.Errorf("cannot encode the change of table %s.%s: %w", ev.Keyspace, ev.Table, err)
+			}
+			msg.Topic = s.topic(ev)
+			msgs = append(msgs, msg)
+		}
+		if err := s.sink.Publish(ctx, msgs); err != nil {
+			return fmt.Errorf("cannot publish the changes: %w", err)
+		}
+	}
+	s.position = position
+	s.checkpointed = false
+	return nil
+}
+
+func (s *Streamer) topic(ev *ChangeEvent) string {
+	if s.cfg.TopicPrefix == "" {
+		return ev.Keyspace + "." + ev.Table
+	}
+	return s.cfg.TopicPrefix + "." + ev.Keyspace + "." + ev.Table
+}
+
+// checkpoint saves the position of the published changes, at most once per checkpoint interval unless forced
+func (s *Streamer) checkpoint(ctx context.Context, force bool) error {
+	if s.checkpointed || !force && time.Since(s.lastSave) < s.cfg.CheckpointInterval {
+		return nil
+	}
+	if err := s.checkpointer.Save(ctx, s.position); err != nil {
+		return fmt.Errorf("cannot save the checkpoint: %w", err)
+	}
+	s.checkpointed = true
+	s.lastSave = time.Now()
+	return nil
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtcdc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeVStreamer streams the same batches of events every time, and records the positions it starts from
+type fakeVStreamer struct {
+	batches   [][]*binlogdatapb.VEvent
+	err       error
+	positions []*binlogdatapb.VGtid
+}
+
+func (fv *fakeVStreamer) VStream(_ context.Context, _ topodatapb.TabletType, vgtid *binlogdatapb.VGtid, _ *binlogdatapb.Filter, _ *vtgatepb.VStreamFlags) (vtgateconn.VStreamReader, error) {
+	fv.positions = append(fv.positions, vgtid)
+	return &fakeVStreamReader{batches: fv.batches, err: fv.err}, nil
+}
+
+type fakeVStreamReader struct {
+	batches [][]*binlogdatapb.VEvent
+	err     error
+}
+
+func (fr *fakeVStreamReader) Recv() ([]*binlogdatapb.VEvent, error) {
+	if len(fr.batches) == 0 {
+		if fr.err != nil {
+			return nil, fr.err
+		}
+		return nil, io.EOF
+	}
+	batch := fr.batches[0]
+	fr.batches = fr.batches[1:]
+	return batch, nil
+}
+
+// memorySink keeps the published messages in memory
+type memorySink struct {
+	msgs []*Message
+	err  error
+}
+
+func (ms *memorySink) Publish(_ context.Context, msgs []*Message) error {
+	if ms.err != nil {
+		return ms.err
+	}
+	ms.msgs = append(ms.msgs, msgs...)
+	return nil
+}
+
+func (ms *memorySink) Close() error {
+	return nil
+}
+
+func (ms *memorySink) values() []string {
+	var values []string
+	for _, msg := range ms.msgs {
+		values = append(values, msg.Topic+" "+string(msg.Value))
+	}
+	return values
+}
+
+func testFields() []*querypb.Field {
+	return []*querypb.Field{
+		{Name: "id", Type: sqltypes.Int64, Flags: uint32(querypb.MySqlFlag_PRI_KEY_FLAG)},
+		{Name: "name", Type: sqltypes.VarChar},
+	}
+}
+
+func makeRow(values ...sqltypes.Value) *querypb.Row {
+	return sqltypes.RowToProto3(values)
+}
+
+func testPosition(gtid string) *binlogdatapb.VGtid {
+	return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "ks", Shard: "-80", Gtid: gtid}}}
+}
+
+func testEvents() [][]*binlogdatapb.VEvent {
+	fieldEvent := &binlogdatapb.VEvent{
+		Type:       binlogdatapb.VEventType_FIELD,
+		FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.t1", Fields: testFields(), Keyspace: "ks", Shard: "-80"},
+	}
+	rowEvent := func(changes ...*binlogdatapb.RowChange) *binlogdatapb.VEvent {
+		return &binlogdatapb.VEvent{
+			Type:      binlogdatapb.VEventType_ROW,
+			Timestamp: 1700000000,
+			RowEvent:  &binlogdatapb.RowEvent{TableName: "ks.t1", Keyspace: "ks", Shard: "-80", RowChanges: changes},
+		}
+	}
+	one := makeRow(sqltypes.NewInt64(1), sqltypes.NewVarChar("one"))
+	uno := makeRow(sqltypes.NewInt64(1), sqltypes.NewVarChar("uno"))
+	two := makeRow(sqltypes.NewInt64(2), sqltypes.NewVarChar("two"))
+
+	return [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_BEGIN},
+		fieldEvent,
+		rowEvent(&binlogdatapb.RowChange{After: one}, &binlogdatapb.RowChange{After: two}),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: testPosition("MySQL56/a:1-10")},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN},
+		rowEvent(&binlogdatapb.RowChange{Before: one, After: uno}),
+		rowEvent(&binlogdatapb.RowChange{Before: two}),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: testPosition("MySQL56/a:1-11")},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, {
+		// the position of this transaction is never received
+		{Type: binlogdatapb.VEventType_BEGIN},
+		rowEvent(&binlogdatapb.RowChange{Before: uno}),
+	}}
+}
+
+func TestStreamer(t *testing.T) {
+	conn := &fakeVStreamer{batches: testEvents()}
+	sink := &memorySink{}
+	checkpointer := NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json"))
+	cfg := Config{
+		TabletType:  topodatapb.TabletType_PRIMARY,
+		Position:    testPosition("current"),
+		TopicPrefix: "vitess",
+	}
+	streamer := NewStreamer(conn, cfg, &DebeziumEncoder{Name: "vitess"}, sink, checkpointer)
+
+	require.NoError(t, streamer.Run(context.Background()))
+	assert.Equal(t, []string{
+		`vitess.ks.t1 {"before":null,"after":{"id":1,"name":"one"},"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"false","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-10\"}]"},"op":"c","ts_ms":0}`,
+		`vitess.ks.t1 {"before":null,"after":{"id":2,"name":"two"},"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"false","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-10\"}]"},"op":"c","ts_ms":0}`,
+		`vitess.ks.t1 {"before":{"id":1,"name":"one"},"after":{"id":1,"name":"uno"},"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"false","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-11\"}]"},"op":"u","ts_ms":0}`,
+		`vitess.ks.t1 {"before":{"id":2,"name":"two"},"after":null,"source":{"connector":"vitess","name":"vitess","ts_ms":1700000000000,"snapshot":"false","db":"ks","keyspace":"ks","table":"t1","shard":"-80","vgtid":"[{\"keyspace\":\"ks\",\"shard\":\"-80\",\"gtid\":\"MySQL56/a:1-11\"}]"},"op":"d","ts_ms":0}`,
+	}, sink.values())
+	assert.Equal(t, `{"id":1}`, string(sink.msgs[0].Key))
+
+	// the position of the last published transaction has been checkpointed, and the stream resumes from it
+	position, err := checkpointer.Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-11", position.ShardGtids[0].Gtid)
+
+	require.NoError(t, streamer.Run(context.Background()))
+	require.Len(t, conn.positions, 2)
+	assert.Equal(t, "current", conn.positions[0].ShardGtids[0].Gtid)
+	assert.Equal(t, "MySQL56/a:1-11", conn.positions[1].ShardGtids[0].Gtid)
+}
+
+func TestStreamerCopy(t *testing.T) {
+	fieldEvent := &binlogdatapb.VEvent{
+		Type:       binlogdatapb.VEventType_FIELD,
+		FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.t1", Fields: testFields(), Keyspace: "ks", Shard: "-80"},
+	}
+	// the rows copied have no timestamp, and their position contains the last primary key copied from their table
+	copied := func(id int64, name string) *binlogdatapb.VEvent {
+		return &binlogdatapb.VEvent{
+			Type: binlogdatapb.VEventType_ROW,
+			RowEvent: &binlogdatapb.RowEvent{TableName: "ks.t1", Keyspace: "ks", Shard: "-80", RowChanges: []*binlogdatapb.RowChange{{
+				After: makeRow(sqltypes.NewInt64(id), sqltypes.NewVarChar(name)),
+			}}},
+		}
+	}
+	copyPosition := func(lastPK int64) *binlogdatapb.VGtid {
+		position := testPosition("MySQL56/a:1-10")
+		position.ShardGtids[0].TablePKs = []*binlogdatapb.TableLastPK{{
+			TableName: "t1",
+			Lastpk:    sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), fmt.Sprint(lastPK))),
+		}}
+		return position
+	}
+	changed := &binlogdatapb.VEvent{
+		Type:      binlogdatapb.VEventType_ROW,
+		Timestamp: 1700000000,
+		RowEvent: &binlogdatapb.RowEvent{TableName: "ks.t1", Keyspace: "ks", Shard: "-80", RowChanges: []*binlogdatapb.RowChange{{
+			Before: makeRow(sqltypes.NewInt64(1), sqltypes.NewVarChar("one")),
+			After:  makeRow(sqltypes.NewInt64(1), sqltypes.NewVarChar("uno")),
+		}}},
+	}
+
+	batches := [][]*binlogdatapb.VEvent{{
+		{Type: binlogdatapb.VEventType_BEGIN},
+		fieldEvent,
+		copied(1, "one"),
+		copied(2, "two"),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: copyPosition(2)},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN},
+		copied(3, "three"),
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: copyPosition(3)},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, {
+		// the copy of the table completed
+		{Type: binlogdatapb.VEventType_BEGIN},
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: testPosition("MySQL56/a:1-10")},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, {
+		{Type: binlogdatapb.VEventType_COPY_COMPLETED, Keyspace: "ks", Shard: "-80"},
+		{Type: binlogdatapb.VEventType_COPY_COMPLETED},
+	}, {
+		{Type: binlogdatapb.VEventType_BEGIN},
+		changed,
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: testPosition("MySQL56/a:1-11")},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}}
+	sink := &memorySink{}
+	cfg := Config{Position: testPosition("")}
+	streamer := NewStreamer(&fakeVStreamer{batches: batches}, cfg, &DebeziumEncoder{Name: "vitess"}, sink, NewFileCheckpointer(filepath.Join(t.TempDir(), "checkpoint.json")))
+	start := time.Now()
+	require.NoError(t, streamer.Run(context.Background()))
+
+	type envelope struct {
+		Op     string `json:"op"`
+		TsMs   int64  `json:"ts_ms"`
+		Source struct {
+			TsMs     int64  `json:"ts_ms"`
+			Snapshot string `json:"snapshot"`
+		} `json:"source"`
+	}
+	var envelopes []envelope
+	for _, msg := range sink.msgs {
+		var env envelope
+		require.NoError(t, json.Unmarshal(msg.Value, &env))
+		envelopes = append(envelopes, env)
+	}
+	require.Len(t, envelopes, 4)
+	for i, snapshot := range []string{"true", "true", "last"} {
+		assert.Equal(t, "r", envelopes[i].Op)
+		assert.Equal(t, snapshot, envelopes[i].Source.Snapshot)
+		// the rows copied are timestamped with the time they were read
+		assert.GreaterOrEqual(t, envelopes[i].Source.TsMs, start.UnixMilli())
+		assert.GreaterOrEqual(t, envelopes[i].TsMs, start.UnixMilli())
+	}
+	assert.Equal(t, "u", envelopes[3].Op)
+	assert.Equal(t, "false", envelopes[3].Source.Snapshot)
+	assert.EqualValues(t, 1700000000000, envelopes[3].Source.TsMs)
+}
+
+func TestStreamerErrors(t *testing.T) {
+	checkpoint := filepath.Join(t.TempDir(), "checkpoint.json")
+	cfg := Config{Position: testPosition("current")}
+
+	// the position of the published changes is checkpointed when the stream fails
+	conn := &fakeVStreamer{batches: testEvents()[:1], err: errors.New("stream failed")}
+	streamer := NewStreamer(conn, cfg, &DebeziumEncoder{}, &memorySink{}, NewFileCheckpointer(checkpoint))
+	assert.EqualError(t, streamer.Run(context.Background()), "stream failed")
+	position, err := NewFileCheckpointer(checkpoint).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-10", position.ShardGtids[0].Gtid)
+
+	// nothing is checkpointed when the changes can't be published
+	conn = &fakeVStreamer{batches: testEvents()}
+	streamer = NewStreamer(conn, cfg, &DebeziumEncoder{}, &memorySink{err: errors.New("sink failed")}, NewFileCheckpointer(checkpoint))
+	assert.EqualError(t, streamer.Run(context.Background()), "cannot publish the changes: sink failed")
+	position, err = NewFileCheckpointer(checkpoint).Load(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "MySQL56/a:1-10", position.ShardGtids[0].Gtid)
+
+	// the fields of a table must be received before its rows
+	conn = &fakeVStreamer{batches: testEvents()[1:]}
+	streamer = NewStreamer(conn, cfg, &DebeziumEncoder{}, &memorySink{}, NewFileCheckpointer(checkpoint))
+	assert.EqualError(t, streamer.Run(context.Background()), "no fields received for table ks.t1")
+}
@@ -443,6 +443,15 @@ func (client *gRPCVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb.
 	return client.c.GetVSchema(ctx, in, opts...)
 }
 
+// GetVStreamCursors is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) GetVStreamCursors(ctx context.Context, in *vtctldatapb.GetVStreamCursorsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVStreamCursorsResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.GetVStreamCursors(ctx, in, opts...)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	if client.c == nil {
@@ -632,6 +641,15 @@ func (client *gRPCVtctldClient) ReparentTablet(ctx context.Context, in *vtctldat
 	return client.c.ReparentTablet(ctx, in, opts...)
 }
 
+// ResetVStreamCursor is part of the vtctlservicepb.VtctldClient interface.
+func (client *gRPCVtctldClient) ResetVStreamCursor(ctx context.Context, in *vtctldatapb.ResetVStreamCursorRequest, opts ...grpc.CallOption) (*vtctldatapb.ResetVStreamCursorResponse, error) {
+	if client.c == nil {
+		return nil, status.Error(codes.Unavailable, connClosedMsg)
+	}
+
+	return client.c.ResetVStreamCursor(ctx, in, opts...)
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *gRPCVtctldClient) ReshardCreate(ctx context.Context, in *vtctldatapb.ReshardCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	if client.c == nil {
@@ -64,6 +64,7 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -2187,6 +2188,36 @@ func (s *VtctldServer) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSche
 	}, nil
 }
 
+// GetVStreamCursors is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetVStreamCursors(ctx context.Context, req *vtctldatapb.GetVStreamCursorsRequest) (resp *vtctldatapb.GetVStreamCursorsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVStreamCursors")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	names, err := s.ts.GetVStreamCursorNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cursors := make(map[string]*binlogdatapb.VGtid, len(names))
+	for _, name := range names {
+		vgtid, _, err := s.ts.GetVStreamCursor(ctx, name)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			// The cursor was deleted since it was listed.
+			continue
+		case err != nil:
+			return nil, err
+		}
+		cursors[name] = vgtid
+	}
+
+	return &vtctldatapb.GetVStreamCursorsResponse{
+		Cursors: cursors,
+	}, nil
+}
+
 // GetWorkflows is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflowsRequest) (resp *vtctldatapb.GetWorkflowsResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetWorkflows")
@@ -3035,6 +3066,27 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
+// ResetVStreamCursor is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ResetVStreamCursor(ctx context.Context, req *vtctldatapb.ResetVStreamCursorRequest) (resp *vtctldatapb.ResetVStreamCursorResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ResetVStreamCursor")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+
+	if len(req.Vgtid.GetShardGtids()) == 0 {
+		err = s.ts.DeleteVStreamCursor(ctx, req.Name)
+	} else {
+		err = s.ts.SaveVStreamCursor(ctx, req.Name, req.Vgtid)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ResetVStreamCursorResponse{}, nil
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReshardCreate")
@@ -48,6 +48,7 @@ import (
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
 	mysqlctlpb "vitess.io/vitess/go/vt/proto/mysqlctl"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -6989,6 +6990,35 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestGetVStreamCursors(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	resp, err := vtctld.GetVStreamCursors(ctx, &vtctldatapb.GetVStreamCursorsRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Cursors)
+
+	orders := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "testkeyspace", Shard: "-", Gtid: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10"}}}
+	customers := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "testkeyspace", Shard: "-", Gtid: "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"}}}
+	require.NoError(t, ts.SaveVStreamCursor(ctx, "orders", orders))
+	require.NoError(t, ts.SaveVStreamCursor(ctx, "customers", customers))
+
+	resp, err = vtctld.GetVStreamCursors(ctx, &vtctldatapb.GetVStreamCursorsRequest{})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.GetVStreamCursorsResponse{
+		Cursors: map[string]*binlogdatapb.VGtid{
+			"orders":    orders,
+			"customers": customers,
+		},
+	}, resp)
+}
+
 func TestLaunchSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -9377,6 +9407,45 @@ func TestReparentTablet(t *testing.T) {
 	}
 }
 
+func TestResetVStreamCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ts := memorytopo.NewServer(ctx, "zone1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	vgtid := &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: "testkeyspace", Shard: "-", Gtid: "current"}}}
+	_, err := vtctld.ResetVStreamCursor(ctx, &vtctldatapb.ResetVStreamCursorRequest{
+		Name:  "orders",
+		Vgtid: vgtid,
+	})
+	require.NoError(t, err)
+	got, _, err := ts.GetVStreamCursor(ctx, "orders")
+	require.NoError(t, err)
+	utils.MustMatch(t, vgtid, got)
+
+	// an empty position deletes the cursor
+	_, err = vtctld.ResetVStreamCursor(ctx, &vtctldatapb.ResetVStreamCursorRequest{
+		Name: "orders",
+	})
+	require.NoError(t, err)
+	_, _, err = ts.GetVStreamCursor(ctx, "orders")
+	assert.True(t, topo.IsErrType(err, topo.NoNode), err)
+
+	_, err = vtctld.ResetVStreamCursor(ctx, &vtctldatapb.ResetVStreamCursorRequest{
+		Name: "orders",
+	})
+	assert.Error(t, err)
+	_, err = vtctld.ResetVStreamCursor(ctx, &vtctldatapb.ResetVStreamCursorRequest{
+		Name:  "a/b",
+		Vgtid: vgtid,
+	})
+	assert.Error(t, err)
+}
+
 func TestRestoreFromBackup(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
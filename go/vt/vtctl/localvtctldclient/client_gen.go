@@ -351,6 +351,11 @@ func (client *localVtctldClient) GetVSchema(ctx context.Context, in *vtctldatapb
 	return client.s.GetVSchema(ctx, in)
 }
 
+// GetVStreamCursors is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) GetVStreamCursors(ctx context.Context, in *vtctldatapb.GetVStreamCursorsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVStreamCursorsResponse, error) {
+	return client.s.GetVStreamCursors(ctx, in)
+}
+
 // GetVersion is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) GetVersion(ctx context.Context, in *vtctldatapb.GetVersionRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVersionResponse, error) {
 	return client.s.GetVersion(ctx, in)
@@ -456,6 +461,11 @@ func (client *localVtctldClient) ReparentTablet(ctx context.Context, in *vtctlda
 	return client.s.ReparentTablet(ctx, in)
 }
 
+// ResetVStreamCursor is part of the vtctlservicepb.VtctldClient interface.
+func (client *localVtctldClient) ResetVStreamCursor(ctx context.Context, in *vtctldatapb.ResetVStreamCursorRequest, opts ...grpc.CallOption) (*vtctldatapb.ResetVStreamCursorResponse, error) {
+	return client.s.ResetVStreamCursor(ctx, in)
+}
+
 // ReshardCreate is part of the vtctlservicepb.VtctldClient interface.
 func (client *localVtctldClient) ReshardCreate(ctx context.Context, in *vtctldatapb.ReshardCreateRequest, opts ...grpc.CallOption) (*vtctldatapb.WorkflowStatusResponse, error) {
 	return client.s.ReshardCreate(ctx, in)
@@ -1853,6 +1853,12 @@ func TestStopAfterCopyFlag(t *testing.T) {
 	ms2, _, _, err := env.ws.prepareCreateLookup(ctx, "workflow", ms.TargetKeyspace, specs, true)
 	require.NoError(t, err)
 	require.Equal(t, ms2.StopAfterCopy, false)
+
+	// An async lookup vindex is maintained by the workflow after the copy.
+	specs.Vindexes["v"].Params["async"] = "true"
+	ms3, _, _, err := env.ws.prepareCreateLookup(ctx, "workflow", ms.TargetKeyspace, specs, false)
+	require.NoError(t, err)
+	require.Equal(t, ms3.StopAfterCopy, false)
 }
 
 func TestCreateLookupVindexFailures(t *testing.T) {
@@ -2222,6 +2228,17 @@ func TestExternalizeLookupVindex(t *testing.T) {
 					"to":    "c2",
 				},
 			},
+			"async_lookup": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table":      "targetks.async_lookup",
+					"from":       "c1",
+					"to":         "c2",
+					"async":      "true",
+					"write_only": "true",
+				},
+				Owner: "t1",
+			},
 		},
 		Tables: map[string]*vschemapb.Table{
 			"t1": {
@@ -2251,6 +2268,9 @@ func TestExternalizeLookupVindex(t *testing.T) {
 		ms.SourceKeyspace, ms.SourceKeyspace)
 	unownedRunning := sqltypes.MakeTestResult(fields, "2|Running|msg|"+unownedSourceKeepRunningAfterCopy)
 	unownedStopped := sqltypes.MakeTestResult(fields, "2|Stopped|Stopped after copy|"+unownedSourceStopAfterCopy)
+	asyncSourceKeepRunningAfterCopy := fmt.Sprintf(`keyspace:"%s",shard:"0",filter:{rules:{match:"async_lookup" filter:"select * from t1 where in_keyrange(col1, '%s.xxhash', '-80')"}}`,
+		ms.SourceKeyspace, ms.SourceKeyspace)
+	asyncRunning := sqltypes.MakeTestResult(fields, "3|Running|msg|"+asyncSourceKeepRunningAfterCopy)
 
 	testcases := []struct {
 		request         *vtctldatapb.LookupVindexExternalizeRequest
@@ -2344,6 +2364,30 @@ func TestExternalizeLookupVindex(t *testing.T) {
 				},
 			},
 		},
+		{
+			request: &vtctldatapb.LookupVindexExternalizeRequest{
+				Name:          "async_lookup",
+				Keyspace:      ms.SourceKeyspace,
+				TableKeyspace: ms.TargetKeyspace,
+			},
+			vrResponse: asyncRunning,
+			expectedVschema: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"async_lookup": {
+						Type: "lookup_unique",
+						Params: map[string]string{
+							"table": "targetks.async_lookup",
+							"from":  "c1",
+							"to":    "c2",
+							"async": "true",
+						},
+						Owner: "t1",
+					},
+				},
+			},
+			// The owner doesn't maintain the lookup table, so the workflow is kept.
+			expectDelete: false,
+		},
 		{
 			request: &vtctldatapb.LookupVindexExternalizeRequest{
 				Name:          "absent_lookup",
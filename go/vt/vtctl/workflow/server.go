@@ -1005,7 +1005,7 @@ func (s *Server) LookupVindexCreate(ctx context.Context, req *vtctldatapb.Lookup
 
 // LookupVindexExternalize externalizes a lookup vindex that's
 // finished backfilling or has caught up. If the vindex has an
-// owner then the workflow will also be deleted.
+// owner and is not async then the workflow will also be deleted.
 func (s *Server) LookupVindexExternalize(ctx context.Context, req *vtctldatapb.LookupVindexExternalizeRequest) (*vtctldatapb.LookupVindexExternalizeResponse, error) {
 	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexExternalize")
 	defer span.Finish()
@@ -1099,10 +1099,11 @@ func (s *Server) LookupVindexExternalize(ctx context.Context, req *vtctldatapb.L
 
 	resp := &vtctldatapb.LookupVindexExternalizeResponse{}
 
-	if vindex.Owner != "" {
+	if vindex.Owner != "" && !IsAsyncLookupVindex(vindex) {
 		// If there is an owner, we have to delete the streams. Once we externalize it
 		// the VTGate will now be responsible for keeping the lookup table up to date
-		// with the owner table.
+		// with the owner table. An async lookup vindex keeps being maintained by the
+		// streams instead.
 		if _, derr := s.WorkflowDelete(ctx, &vtctldatapb.WorkflowDeleteRequest{
 			Keyspace:         req.TableKeyspace,
 			Workflow:         req.Name,
@@ -1122,6 +1123,12 @@ func (s *Server) LookupVindexExternalize(ctx context.Context, req *vtctldatapb.L
 	return resp, s.ts.RebuildSrvVSchema(ctx, nil)
 }
 
+// IsAsyncLookupVindex returns true if the lookup vindex is maintained by its
+// vreplication streams instead of by vtgate, even when it has an owner.
+func IsAsyncLookupVindex(vindex *vschemapb.Vindex) bool {
+	return vindex.Params["async"] == "true"
+}
+
 // Materialize performs the steps needed to materialize a list of
 // tables based on the materialization specs.
 func (s *Server) Materialize(ctx context.Context, ms *vtctldatapb.MaterializeSettings) error {
@@ -3592,7 +3599,7 @@ func (s *Server) prepareCreateLookup(ctx context.Context, workflow, keyspace str
 		MaterializationIntent: vtctldatapb.MaterializationIntent_CREATELOOKUPINDEX,
 		SourceKeyspace:        keyspace,
 		TargetKeyspace:        targetKeyspace,
-		StopAfterCopy:         vindex.Owner != "" && !continueAfterCopyWithOwner,
+		StopAfterCopy:         vindex.Owner != "" && !continueAfterCopyWithOwner && !IsAsyncLookupVindex(vindex),
 		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
 			TargetTable:      targetTableName,
 			SourceExpression: materializeQuery,
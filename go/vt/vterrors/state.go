@@ -58,6 +58,7 @@ const (
 	RowIsReferenced2
 	NoReferencedRow2
 	UnknownStmtHandler
+	CTEMaxRecursionDepth
 
 	// not found
 	BadDb
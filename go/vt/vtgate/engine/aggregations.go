@@ -42,6 +42,9 @@ type AggregateParams struct {
 	WCol        int
 	Type        sqltypes.Type
 	CollationID collations.ID
+	// HashDistinct is set when the input is not sorted by the distinct column,
+	// in which case the values already seen in the group are kept in memory.
+	HashDistinct bool `json:",omitempty"`
 
 	Alias    string `json:",omitempty"`
 	Expr     sqlparser.Expr
@@ -105,9 +108,26 @@ type aggregatorDistinct struct {
 	column int
 	last   sqltypes.Value
 	coll   collations.ID
+
+	// seen is used instead of last when the rows are not sorted by the distinct column
+	seen *probeTable
+}
+
+func newAggregatorDistinct(column int, typ sqltypes.Type, coll collations.ID, hash bool) aggregatorDistinct {
+	a := aggregatorDistinct{
+		column: column,
+		coll:   coll,
+	}
+	if hash && column >= 0 {
+		a.seen = newProbeTable([]CheckCol{{Col: 0, Type: typ, Collation: coll}})
+	}
+	return a
 }
 
 func (a *aggregatorDistinct) shouldReturn(row []sqltypes.Value) (bool, error) {
+	if a.seen != nil {
+		return a.seen.exists(sqltypes.Row{row[a.column]})
+	}
 	if a.column >= 0 {
 		if !a.last.IsNull() {
 			cmp, err := evalengine.NullsafeCompare(a.last, row[a.column], a.coll)
@@ -125,6 +145,9 @@ func (a *aggregatorDistinct) shouldReturn(row []sqltypes.Value) (bool, error) {
 
 func (a *aggregatorDistinct) reset() {
 	a.last = sqltypes.NULL
+	if a.seen != nil {
+		a.seen = newProbeTable(a.seen.checkCols)
+	}
 }
 
 type aggregatorCount struct {
@@ -375,11 +398,8 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 
 		case AggregateCount, AggregateCountDistinct:
 			ag = &aggregatorCount{
-				from: aggr.Col,
-				distinct: aggregatorDistinct{
-					column: distinct,
-					coll:   aggr.CollationID,
-				},
+				from:     aggr.Col,
+				distinct: newAggregatorDistinct(distinct, aggr.Type, aggr.CollationID, aggr.HashDistinct),
 			}
 
 		case AggregateSum, AggregateSumDistinct:
@@ -392,12 +412,9 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 			}
 
 			ag = &aggregatorSum{
-				from: aggr.Col,
-				sum:  sum,
-				distinct: aggregatorDistinct{
-					column: distinct,
-					coll:   aggr.CollationID,
-				},
+				from:     aggr.Col,
+				sum:      sum,
+				distinct: newAggregatorDistinct(distinct, aggr.Type, aggr.CollationID, aggr.HashDistinct),
 			}
 
 		case AggregateMin:
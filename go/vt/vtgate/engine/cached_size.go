@@ -105,6 +105,42 @@ func (cached *Concatenate) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+
+//go:nocheckptr
+func (cached *CorrelatedSubquery) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(64)
+	}
+	// field SubqueryResult string
+	size += hack.RuntimeAllocSize(int64(len(cached.SubqueryResult)))
+	// field Vars map[string]int
+	if cached.Vars != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.Vars)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 208))
+		if len(cached.Vars) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 208))
+		}
+		for k := range cached.Vars {
+			size += hack.RuntimeAllocSize(int64(len(k)))
+		}
+	}
+	// field Subquery vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Subquery.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Outer vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Outer.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *DBDDL) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -173,6 +209,54 @@ func (cached *DML) CachedSize(alloc bool) int64 {
 	size += cached.RoutingParameters.CachedSize(true)
 	return size
 }
+func (cached *DMLVar) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field BVName string
+	size += hack.RuntimeAllocSize(int64(len(cached.BVName)))
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
+	return size
+}
+func (cached *DMLWithInput) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(80)
+	}
+	// field Input vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Input.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field DML vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.DML.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Vars []vitess.io/vitess/go/vt/vtgate/engine.DMLVar
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Vars)) * int64(40))
+		for _, elem := range cached.Vars {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field RowVars []vitess.io/vitess/go/vt/vtgate/engine.DMLVar
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.RowVars)) * int64(40))
+		for _, elem := range cached.RowVars {
+			size += elem.CachedSize(false)
+		}
+	}
+	return size
+}
 func (cached *Delete) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -300,7 +384,25 @@ func (cached *FkVerify) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(48)
+		size += int64(80)
+	}
+	// field Selection vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Selection.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Values [][]vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Values)) * int64(24))
+		for _, elem := range cached.Values {
+			{
+				size += hack.RuntimeAllocSize(int64(cap(elem)) * int64(16))
+				for _, elem := range elem {
+					if cc, ok := elem.(cachedObject); ok {
+						size += cc.CachedSize(true)
+					}
+				}
+			}
+		}
 	}
 	// field Verify []*vitess.io/vitess/go/vt/vtgate/engine.Verify
 	{
@@ -321,12 +423,14 @@ func (cached *Generate) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(48)
+		size += int64(64)
 	}
 	// field Keyspace *vitess.io/vitess/go/vt/vtgate/vindexes.Keyspace
 	size += cached.Keyspace.CachedSize(true)
 	// field Query string
 	size += hack.RuntimeAllocSize(int64(len(cached.Query)))
+	// field Sequence string
+	size += hack.RuntimeAllocSize(int64(len(cached.Sequence)))
 	// field Values vitess.io/vitess/go/vt/vtgate/evalengine.Expr
 	if cc, ok := cached.Values.(cachedObject); ok {
 		size += cc.CachedSize(true)
@@ -449,6 +553,82 @@ func (cached *Insert) CachedSize(alloc bool) int64 {
 	return size
 }
 
+func (cached *JSONTable) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(112)
+	}
+	// field Input vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Input.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Doc vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Doc.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field ASTDoc vitess.io/vitess/go/vt/sqlparser.Expr
+	if cc, ok := cached.ASTDoc.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Path string
+	size += hack.RuntimeAllocSize(int64(len(cached.Path)))
+	// field Columns []*vitess.io/vitess/go/vt/vtgate/engine.JSONTableColumn
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(8))
+		for _, elem := range cached.Columns {
+			size += elem.CachedSize(true)
+		}
+	}
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
+	return size
+}
+func (cached *JSONTableColumn) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(96)
+	}
+	// field Name string
+	size += hack.RuntimeAllocSize(int64(len(cached.Name)))
+	// field Extract vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Extract.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Convert vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Convert.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field OnEmpty *vitess.io/vitess/go/vt/vtgate/engine.JSONTableResponse
+	size += cached.OnEmpty.CachedSize(true)
+	// field OnError *vitess.io/vitess/go/vt/vtgate/engine.JSONTableResponse
+	size += cached.OnError.CachedSize(true)
+	// field ASTDefinition string
+	size += hack.RuntimeAllocSize(int64(len(cached.ASTDefinition)))
+	return size
+}
+func (cached *JSONTableResponse) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field Default vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Default.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
+
 //go:nocheckptr
 func (cached *Join) CachedSize(alloc bool) int64 {
 	if cached == nil {
@@ -670,7 +850,7 @@ func (cached *Plan) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(144)
+		size += int64(160)
 	}
 	// field Original string
 	size += hack.RuntimeAllocSize(int64(len(cached.Original)))
@@ -726,6 +906,55 @@ func (cached *Projection) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *RecurseCTE) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(112)
+	}
+	// field Seed vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Seed.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Term vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Term.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Vars map[string]int
+	if cached.Vars != nil {
+		size += int64(48)
+		hmap := reflect.ValueOf(cached.Vars)
+		numBuckets := int(math.Pow(2, float64((*(*uint8)(unsafe.Pointer(hmap.Pointer() + uintptr(9)))))))
+		numOldBuckets := (*(*uint16)(unsafe.Pointer(hmap.Pointer() + uintptr(10))))
+		size += hack.RuntimeAllocSize(int64(numOldBuckets * 208))
+		if len(cached.Vars) > 0 || numBuckets > 1 {
+			size += hack.RuntimeAllocSize(int64(numBuckets * 208))
+		}
+		for k := range cached.Vars {
+			size += hack.RuntimeAllocSize(int64(len(k)))
+		}
+	}
+	// field Predicate vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	if cc, ok := cached.Predicate.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field ASTPredicate vitess.io/vitess/go/vt/sqlparser.Expr
+	if cc, ok := cached.ASTPredicate.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Exprs []vitess.io/vitess/go/vt/vtgate/evalengine.Expr
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Exprs)) * int64(16))
+		for _, elem := range cached.Exprs {
+			if cc, ok := elem.(cachedObject); ok {
+				size += cc.CachedSize(true)
+			}
+		}
+	}
+	return size
+}
 func (cached *RenameFields) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -751,6 +980,41 @@ func (cached *RenameFields) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *Replace) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field Replaces []*vitess.io/vitess/go/vt/vtgate/engine.ReplaceRow
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Replaces)) * int64(8))
+		for _, elem := range cached.Replaces {
+			size += elem.CachedSize(true)
+		}
+	}
+	return size
+}
+func (cached *ReplaceRow) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(32)
+	}
+	// field Delete vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Delete.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Insert vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Insert.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *ReplaceVariables) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -940,7 +1204,7 @@ func (cached *SemiJoin) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(64)
+		size += int64(80)
 	}
 	// field Left vitess.io/vitess/go/vt/vtgate/engine.Primitive
 	if cc, ok := cached.Left.(cachedObject); ok {
@@ -1188,6 +1452,47 @@ func (cached *Update) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *UpdateMove) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(128)
+	}
+	// field Input vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Input.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Update vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Update.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Delete vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Delete.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Insert vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Insert.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Columns []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Columns)) * int64(16))
+		for _, elem := range cached.Columns {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	// field KsidVindex vitess.io/vitess/go/vt/vtgate/vindexes.Vindex
+	if cc, ok := cached.KsidVindex.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field KsidColumns []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.KsidColumns)) * int64(8))
+	}
+	return size
+}
 func (cached *UpdateTarget) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -1200,6 +1505,45 @@ func (cached *UpdateTarget) CachedSize(alloc bool) int64 {
 	size += hack.RuntimeAllocSize(int64(len(cached.Target)))
 	return size
 }
+func (cached *Upsert) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(24)
+	}
+	// field Upserts []*vitess.io/vitess/go/vt/vtgate/engine.UpsertRow
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Upserts)) * int64(8))
+		for _, elem := range cached.Upserts {
+			size += elem.CachedSize(true)
+		}
+	}
+	return size
+}
+func (cached *UpsertRow) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field Select vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Select.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Insert vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Insert.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field Update vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Update.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	return size
+}
 func (cached *UserDefinedVariable) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -1256,7 +1600,7 @@ func (cached *Verify) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(32)
+		size += int64(80)
 	}
 	// field Exec vitess.io/vitess/go/vt/vtgate/engine.Primitive
 	if cc, ok := cached.Exec.(cachedObject); ok {
@@ -1264,6 +1608,12 @@ func (cached *Verify) CachedSize(alloc bool) int64 {
 	}
 	// field Typ string
 	size += hack.RuntimeAllocSize(int64(len(cached.Typ)))
+	// field BVName string
+	size += hack.RuntimeAllocSize(int64(len(cached.BVName)))
+	// field Cols []int
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Cols)) * int64(8))
+	}
 	return size
 }
 func (cached *VindexFunc) CachedSize(alloc bool) int64 {
@@ -1377,6 +1727,54 @@ func (cached *VitessMetadata) CachedSize(alloc bool) int64 {
 	return size
 }
 
+func (cached *Window) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(96)
+	}
+	// field Input vitess.io/vitess/go/vt/vtgate/engine.Primitive
+	if cc, ok := cached.Input.(cachedObject); ok {
+		size += cc.CachedSize(true)
+	}
+	// field PartitionBy []vitess.io/vitess/go/vt/vtgate/engine.CheckCol
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.PartitionBy)) * int64(22))
+		for _, elem := range cached.PartitionBy {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field OrderBy []vitess.io/vitess/go/vt/vtgate/engine.CheckCol
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.OrderBy)) * int64(22))
+		for _, elem := range cached.OrderBy {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field Functions []*vitess.io/vitess/go/vt/vtgate/engine.WindowFunction
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Functions)) * int64(8))
+		for _, elem := range cached.Functions {
+			size += elem.CachedSize(true)
+		}
+	}
+	return size
+}
+func (cached *WindowFunction) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field Alias string
+	size += hack.RuntimeAllocSize(int64(len(cached.Alias)))
+	return size
+}
+
 //go:nocheckptr
 func (cached *shardRoute) CachedSize(alloc bool) int64 {
 	if cached == nil {
@@ -0,0 +1,213 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+)
+
+var _ Primitive = (*CorrelatedSubquery)(nil)
+
+// CorrelatedSubquery evaluates a correlated subquery of the SELECT list.
+// The subquery is executed for every row of the outer primitive, with the
+// columns of that row bound to Vars, and its value is added as the first
+// column of the row.
+type CorrelatedSubquery struct {
+	// Opcode is PulloutValue for scalar subqueries, and PulloutExists for
+	// EXISTS subqueries, for which the value is 1 if the subquery returned rows
+	// and 0 if it did not.
+	Opcode PulloutOpcode
+
+	// SubqueryResult is the name of the column holding the value of the subquery
+	SubqueryResult string
+
+	// Vars are the bind variables used by the subquery, and the columns
+	// of the outer rows they are bound to.
+	Vars map[string]int
+
+	Subquery Primitive
+	Outer    Primitive
+}
+
+// RouteType implements the Primitive interface
+func (cs *CorrelatedSubquery) RouteType() string {
+	return cs.Opcode.String()
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (cs *CorrelatedSubquery) GetKeyspaceName() string {
+	return cs.Outer.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (cs *CorrelatedSubquery) GetTableName() string {
+	return cs.Outer.GetTableName()
+}
+
+// TryExecute implements the Primitive interface
+func (cs *CorrelatedSubquery) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	outer, err := vcursor.ExecutePrimitive(ctx, cs.Outer, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+	return cs.addValues(ctx, vcursor, bindVars, outer)
+}
+
+// TryStreamExecute implements the Primitive interface
+func (cs *CorrelatedSubquery) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	return vcursor.StreamExecutePrimitive(ctx, cs.Outer, bindVars, wantfields, func(outer *sqltypes.Result) error {
+		res, err := cs.addValues(ctx, vcursor, bindVars, outer)
+		if err != nil {
+			return err
+		}
+		return callback(res)
+	})
+}
+
+// addValues executes the subquery for every row of the outer result
+// and returns the rows with the value of the subquery in front of them
+func (cs *CorrelatedSubquery) addValues(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, outer *sqltypes.Result) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{Rows: make([]sqltypes.Row, 0, len(outer.Rows))}
+	wantfields := outer.Fields != nil
+	var subqueryFields []*querypb.Field
+
+	joinVars := make(map[string]*querypb.BindVariable, len(cs.Vars))
+	for _, row := range outer.Rows {
+		for k, col := range cs.Vars {
+			joinVars[k] = sqltypes.ValueBindVariable(row[col])
+		}
+		// the fields of the subquery are only needed once
+		res, err := vcursor.ExecutePrimitive(ctx, cs.Subquery, combineVars(bindVars, joinVars), wantfields && subqueryFields == nil)
+		if err != nil {
+			return nil, err
+		}
+		if subqueryFields == nil {
+			subqueryFields = res.Fields
+		}
+		value, err := cs.valueOf(res)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, append(sqltypes.Row{value}, row...))
+	}
+	if wantfields && subqueryFields == nil {
+		// there are no rows, like in the first result of a stream, so the subquery has not been executed
+		var err error
+		subqueryFields, err = cs.subqueryFields(ctx, vcursor, bindVars)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if wantfields {
+		result.Fields = append([]*querypb.Field{cs.valueField(subqueryFields)}, outer.Fields...)
+	}
+	return result, nil
+}
+
+func (cs *CorrelatedSubquery) valueOf(res *sqltypes.Result) (sqltypes.Value, error) {
+	if cs.Opcode == PulloutExists {
+		if len(res.Rows) > 0 {
+			return sqltypes.NewInt64(1), nil
+		}
+		return sqltypes.NewInt64(0), nil
+	}
+
+	switch len(res.Rows) {
+	case 0:
+		return sqltypes.NULL, nil
+	case 1:
+		return res.Rows[0][0], nil
+	default:
+		return sqltypes.NULL, errSqRow
+	}
+}
+
+// valueField returns the field of the column holding the value of the subquery
+func (cs *CorrelatedSubquery) valueField(subqueryFields []*querypb.Field) *querypb.Field {
+	if cs.Opcode == PulloutValue && len(subqueryFields) > 0 {
+		field := subqueryFields[0].CloneVT()
+		field.Name = cs.SubqueryResult
+		return field
+	}
+	typ := sqltypes.Int64
+	if cs.Opcode == PulloutValue {
+		typ = sqltypes.Null
+	}
+	return &querypb.Field{Name: cs.SubqueryResult, Type: typ}
+}
+
+// GetFields implements the Primitive interface
+func (cs *CorrelatedSubquery) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	outer, err := cs.Outer.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	subqueryFields, err := cs.subqueryFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: append([]*querypb.Field{cs.valueField(subqueryFields)}, outer.Fields...)}, nil
+}
+
+// subqueryFields returns the fields of the subquery without executing it,
+// when they are needed for the field of the value of the subquery
+func (cs *CorrelatedSubquery) subqueryFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error) {
+	if cs.Opcode != PulloutValue {
+		return nil, nil
+	}
+	subqueryVars := make(map[string]*querypb.BindVariable, len(cs.Vars))
+	for k := range cs.Vars {
+		subqueryVars[k] = sqltypes.NullBindVariable
+	}
+	res, err := cs.Subquery.GetFields(ctx, vcursor, combineVars(bindVars, subqueryVars))
+	if err != nil {
+		return nil, err
+	}
+	return res.Fields, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (cs *CorrelatedSubquery) NeedsTransaction() bool {
+	return cs.Subquery.NeedsTransaction() || cs.Outer.NeedsTransaction()
+}
+
+// Inputs implements the Primitive interface
+func (cs *CorrelatedSubquery) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{cs.Outer, cs.Subquery}, []map[string]any{{
+		inputName: "Outer",
+	}, {
+		inputName: "SubQuery",
+	}}
+}
+
+func (cs *CorrelatedSubquery) description() PrimitiveDescription {
+	other := map[string]any{
+		"PulloutVars": []string{cs.SubqueryResult},
+	}
+	if len(cs.Vars) > 0 {
+		other["JoinVars"] = orderedStringIntMap(cs.Vars)
+	}
+	return PrimitiveDescription{
+		OperatorType: "CorrelatedSubquery",
+		Variant:      cs.Opcode.String(),
+		Other:        other,
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+)
+
+var (
+	correlatedOuterFields    = sqltypes.MakeTestFields("id|col", "int64|varchar")
+	correlatedSubqueryFields = sqltypes.MakeTestFields("count(*)", "int64")
+)
+
+func TestCorrelatedSubqueryValue(t *testing.T) {
+	outer := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(correlatedOuterFields, "1|a", "2|b", "3|c")},
+	}
+	subquery := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(correlatedSubqueryFields, "4"),
+			sqltypes.MakeTestResult(correlatedSubqueryFields),
+			sqltypes.MakeTestResult(correlatedSubqueryFields, "0"),
+		},
+	}
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"u_id": 0},
+		Subquery:       subquery,
+		Outer:          outer,
+	}
+
+	result, err := cs.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	subquery.ExpectLog(t, []string{
+		`Execute u_id: type:INT64 value:"1" true`,
+		`Execute u_id: type:INT64 value:"2" false`,
+		`Execute u_id: type:INT64 value:"3" false`,
+	})
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("__sq1|id|col", "int64|int64|varchar"),
+		"4|1|a",
+		"null|2|b",
+		"0|3|c",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestCorrelatedSubqueryValueStreamExecute(t *testing.T) {
+	subqueryFields := sqltypes.MakeTestFields("name", "varchar")
+	outer := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(correlatedOuterFields, "1|a", "2|b")},
+	}
+	subquery := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(subqueryFields),
+			sqltypes.MakeTestResult(subqueryFields, "x"),
+			sqltypes.MakeTestResult(subqueryFields),
+		},
+	}
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"u_id": 0},
+		Subquery:       subquery,
+		Outer:          outer,
+	}
+
+	// the first result of the stream only has the fields, so the subquery gives its fields without being executed
+	result, err := wrapStreamExecute(cs, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	subquery.ExpectLog(t, []string{
+		`GetFields u_id: `,
+		`Execute u_id:  true`,
+		`Execute u_id: type:INT64 value:"1" false`,
+		`Execute u_id: type:INT64 value:"2" false`,
+	})
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("__sq1|id|col", "varchar|int64|varchar"),
+		"x|1|a",
+		"null|2|b",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestCorrelatedSubqueryExists(t *testing.T) {
+	outer := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(correlatedOuterFields, "1|a", "2|b")},
+	}
+	subquery := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(correlatedSubqueryFields),
+			sqltypes.MakeTestResult(correlatedSubqueryFields, "1", "1"),
+		},
+	}
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutExists,
+		SubqueryResult: "__sq_has_values",
+		Vars:           map[string]int{"u_col": 1},
+		Subquery:       subquery,
+		Outer:          outer,
+	}
+
+	result, err := wrapStreamExecute(cs, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("__sq_has_values|id|col", "int64|int64|varchar"),
+		"0|1|a",
+		"1|2|b",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestCorrelatedSubqueryMoreThanOneRow(t *testing.T) {
+	cs := &CorrelatedSubquery{
+		Opcode:         PulloutValue,
+		SubqueryResult: "__sq1",
+		Vars:           map[string]int{"u_id": 0},
+		Subquery: &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(correlatedSubqueryFields, "1", "2")},
+		},
+		Outer: &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(correlatedOuterFields, "1|a")},
+		},
+	}
+
+	_, err := cs.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.EqualError(t, err, "subquery returned more than one row")
+}
@@ -58,7 +58,7 @@ func (del *Delete) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	switch del.Opcode {
 	case Unsharded:
 		return del.execUnsharded(ctx, del, vcursor, bindVars, rss)
-	case Equal, IN, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual:
+	case Equal, IN, Scatter, ByDestination, SubShard, EqualUnique, MultiEqual, Range:
 		return del.execMultiDestination(ctx, del, vcursor, bindVars, rss, del.deleteVindexEntries)
 	default:
 		// Unreachable.
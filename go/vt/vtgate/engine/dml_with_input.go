@@ -0,0 +1,228 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/collations/charset"
+	"vitess.io/vitess/go/mysql/collations/colldata"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vthash"
+)
+
+var _ Primitive = (*DMLWithInput)(nil)
+
+// DMLVar is a list bind variable of the DML of a DMLWithInput,
+// built from the columns Cols of the rows returned by the Input.
+// The values of a single column are bound as a list of values,
+// and the values of several columns as a list of tuples.
+type DMLVar struct {
+	BVName string
+	Cols   []int // indexes
+}
+
+// DMLWithInput executes the DML primitive on the rows returned by the Input primitive.
+// It is used for multi-shard UPDATE and DELETE statements with a LIMIT, where the Input
+// selects the primary keys of the rows to modify across all shards, and the DML
+// modifies exactly those rows. Both are always executed in the same transaction.
+type DMLWithInput struct {
+	Input Primitive
+	DML   Primitive
+	Vars  []DMLVar
+
+	// RowVars are bound to the values of a single row returned by the Input.
+	// When set, the DML is executed once per selected row, as it is for an UPDATE
+	// setting values read from other tables. A row is only modified once, using the
+	// first row selected for it, as MySQL does for multi-table updates.
+	RowVars []DMLVar
+
+	txNeeded
+}
+
+// RouteType implements the Primitive interface.
+func (dml *DMLWithInput) RouteType() string {
+	return "DMLWithInput"
+}
+
+// GetKeyspaceName implements the Primitive interface.
+func (dml *DMLWithInput) GetKeyspaceName() string {
+	return dml.DML.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface.
+func (dml *DMLWithInput) GetTableName() string {
+	return dml.DML.GetTableName()
+}
+
+// GetFields implements the Primitive interface.
+func (dml *DMLWithInput) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] GetFields should not be called")
+}
+
+// TryExecute implements the Primitive interface.
+func (dml *DMLWithInput) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	// the fields give the collations to deduplicate the rows executed one by one
+	inputRes, err := vcursor.ExecutePrimitive(ctx, dml.Input, bindVars, len(dml.RowVars) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// If no rows are selected, there is nothing to modify.
+	if len(inputRes.Rows) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+	if len(dml.RowVars) > 0 {
+		return dml.executePerRow(ctx, vcursor, bindVars, inputRes)
+	}
+
+	dmlVars := make(map[string]*querypb.BindVariable, len(dml.Vars))
+	for _, v := range dml.Vars {
+		dmlVars[v.BVName] = v.bindVariable(inputRes.Rows)
+	}
+	return vcursor.ExecutePrimitive(ctx, dml.DML, combineVars(bindVars, dmlVars), wantfields)
+}
+
+// executePerRow executes the DML once for each distinct row selected by the Input.
+func (dml *DMLWithInput) executePerRow(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, inputRes *sqltypes.Result) (*sqltypes.Result, error) {
+	keys, err := dml.rowKeys(inputRes, vcursor.ConnCollation())
+	if err != nil {
+		return nil, err
+	}
+
+	res := &sqltypes.Result{}
+	seen := make(map[vthash.Hash]bool, len(inputRes.Rows))
+	for i, row := range inputRes.Rows {
+		if seen[keys[i]] {
+			continue
+		}
+		seen[keys[i]] = true
+
+		dmlVars := make(map[string]*querypb.BindVariable, len(dml.Vars)+len(dml.RowVars))
+		for _, v := range dml.Vars {
+			dmlVars[v.BVName] = v.bindVariable([]sqltypes.Row{row})
+		}
+		for _, v := range dml.RowVars {
+			dmlVars[v.BVName] = sqltypes.ValueBindVariable(row[v.Cols[0]])
+		}
+		qr, err := vcursor.ExecutePrimitive(ctx, dml.DML, combineVars(bindVars, dmlVars), false)
+		if err != nil {
+			return nil, err
+		}
+		res.RowsAffected += qr.RowsAffected
+	}
+	return res, nil
+}
+
+// rowKeys returns, for each row, the hash of the values that identify the modified row.
+// The values are hashed with the collation of their column, so that the values MySQL
+// considers equal, like the case variants of a string, have the same key.
+func (dml *DMLWithInput) rowKeys(inputRes *sqltypes.Result, connCollation collations.ID) ([]vthash.Hash, error) {
+	cols := dml.Vars[0].Cols
+	colls := make([]colldata.Collation, len(cols))
+	widths := make([]int, len(cols))
+	for i, col := range cols {
+		if !sqltypes.IsText(inputRes.Fields[col].Type) {
+			continue
+		}
+		coll := colldata.Lookup(collations.ID(inputRes.Fields[col].Charset))
+		if coll == nil {
+			coll = colldata.Lookup(connCollation)
+		}
+		colls[i] = coll
+		// The strings are hashed as if they were stored in a CHAR column wide enough
+		// for all of them, so that the PAD SPACE collations ignore trailing spaces.
+		widths[i] = 1
+		for _, row := range inputRes.Rows {
+			widths[i] = max(widths[i], charset.Length(coll.Charset(), row[col].Raw()))
+		}
+	}
+
+	keys := make([]vthash.Hash, 0, len(inputRes.Rows))
+	hasher := vthash.New()
+	for _, row := range inputRes.Rows {
+		hasher.Reset()
+		for i, col := range cols {
+			if colls[i] == nil || row[col].IsNull() {
+				if err := evalengine.NullsafeHashcode128(&hasher, row[col], collations.CollationBinaryID, inputRes.Fields[col].Type); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			colls[i].Hash(&hasher, row[col].Raw(), widths[i])
+		}
+		keys = append(keys, hasher.Sum128())
+	}
+	return keys, nil
+}
+
+// TryStreamExecute implements the Primitive interface.
+func (dml *DMLWithInput) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := dml.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// bindVariable builds the list bind variable from the given rows.
+func (v DMLVar) bindVariable(rows []sqltypes.Row) *querypb.BindVariable {
+	bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+	for _, row := range rows {
+		if len(v.Cols) == 1 {
+			bv.Values = append(bv.Values, sqltypes.ValueToProto(row[v.Cols[0]]))
+			continue
+		}
+		tuple := &querypb.Value{Type: querypb.Type_TUPLE}
+		for _, colIdx := range v.Cols {
+			tuple.Values = append(tuple.Values, sqltypes.ValueToProto(row[colIdx]))
+		}
+		bv.Values = append(bv.Values, tuple)
+	}
+	return bv
+}
+
+// Inputs implements the Primitive interface.
+func (dml *DMLWithInput) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{dml.Input, dml.DML}, nil
+}
+
+func (dml *DMLWithInput) description() PrimitiveDescription {
+	vars := make(map[string]any, len(dml.Vars))
+	for _, v := range dml.Vars {
+		vars[v.BVName] = v.Cols
+	}
+	other := map[string]any{
+		"BindVars": vars,
+	}
+	if len(dml.RowVars) > 0 {
+		rowVars := make(map[string]any, len(dml.RowVars))
+		for _, v := range dml.RowVars {
+			rowVars[v.BVName] = v.Cols[0]
+		}
+		other["RowBindVars"] = rowVars
+	}
+	return PrimitiveDescription{
+		OperatorType: dml.RouteType(),
+		Other:        other,
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// TestDeleteWithInput tests that DMLWithInput binds the selected rows to the DML.
+func TestDeleteWithInput(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|user_id", "int64|int64"), "1|10", "2|20"),
+	}}
+	del := &Delete{
+		DML: &DML{
+			Query: "delete from t where (id, user_id) in ::dml_vals and user_id in ::dml_vindex_vals",
+			RoutingParameters: &RoutingParameters{
+				Opcode:   Unsharded,
+				Keyspace: &vindexes.Keyspace{Name: "ks"},
+			},
+		},
+	}
+	dml := &DMLWithInput{
+		Input: input,
+		DML:   del,
+		Vars:  []DMLVar{{BVName: "dml_vals", Cols: []int{0, 1}}, {BVName: "dml_vindex_vals", Cols: []int{1}}},
+	}
+
+	vc := newDMLTestVCursor("0")
+	_, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: delete from t where (id, user_id) in ::dml_vals and user_id in ::dml_vindex_vals {` +
+			`dml_vals: type:TUPLE values:{type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"10"}} values:{type:TUPLE values:{type:INT64 value:"2"} values:{type:INT64 value:"20"}} ` +
+			`dml_vindex_vals: type:TUPLE values:{type:INT64 value:"10"} values:{type:INT64 value:"20"}} true true`,
+	})
+}
+
+// TestDMLWithInputNoRows tests that the DML is not executed when no rows are selected.
+func TestDMLWithInputNoRows(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64")),
+	}}
+	dml := &fakePrimitive{}
+	dwi := &DMLWithInput{
+		Input: input,
+		DML:   dml,
+		Vars:  []DMLVar{{BVName: "dml_vals", Cols: []int{0}}},
+	}
+
+	res, err := wrapStreamExecute(dwi, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	require.Zero(t, res.RowsAffected)
+	dml.ExpectLog(t, nil)
+}
+
+// TestUpdateWithInputRowVars tests that DMLWithInput executes the DML once per selected row
+// when it binds values of the rows, and only once for the rows selected several times.
+func TestUpdateWithInputRowVars(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|col", "int64|varchar"), "1|a", "2|b", "1|c"),
+	}}
+	upd := &Update{
+		DML: &DML{
+			Query: "update t set val = :dml_upd_val0 where id in ::dml_vals",
+			RoutingParameters: &RoutingParameters{
+				Opcode:   Unsharded,
+				Keyspace: &vindexes.Keyspace{Name: "ks"},
+			},
+		},
+	}
+	dml := &DMLWithInput{
+		Input:   input,
+		DML:     upd,
+		Vars:    []DMLVar{{BVName: "dml_vals", Cols: []int{0}}},
+		RowVars: []DMLVar{{BVName: "dml_upd_val0", Cols: []int{1}}},
+	}
+
+	vc := newDMLTestVCursor("0")
+	_, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: update t set val = :dml_upd_val0 where id in ::dml_vals {` +
+			`dml_upd_val0: type:VARCHAR value:"a" dml_vals: type:TUPLE values:{type:INT64 value:"1"}} true true`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: update t set val = :dml_upd_val0 where id in ::dml_vals {` +
+			`dml_upd_val0: type:VARCHAR value:"b" dml_vals: type:TUPLE values:{type:INT64 value:"2"}} true true`,
+	})
+}
+
+// TestUpdateWithInputRowVarsCollation tests that the rows whose keys are equal for
+// the collation of their column are only modified once.
+func TestUpdateWithInputRowVarsCollation(t *testing.T) {
+	fields := sqltypes.MakeTestFields("name|col", "varchar|varchar")
+	fields[0].Charset = uint32(collations.Local().LookupByName("utf8mb4_general_ci"))
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(fields, "a|x", "A|y", "a |z", "b|w"),
+	}}
+	upd := &Update{
+		DML: &DML{
+			Query: "update t set val = :dml_upd_val0 where name in ::dml_vals",
+			RoutingParameters: &RoutingParameters{
+				Opcode:   Unsharded,
+				Keyspace: &vindexes.Keyspace{Name: "ks"},
+			},
+		},
+	}
+	dml := &DMLWithInput{
+		Input:   input,
+		DML:     upd,
+		Vars:    []DMLVar{{BVName: "dml_vals", Cols: []int{0}}},
+		RowVars: []DMLVar{{BVName: "dml_upd_val0", Cols: []int{1}}},
+	}
+
+	vc := newDMLTestVCursor("0")
+	_, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: update t set val = :dml_upd_val0 where name in ::dml_vals {` +
+			`dml_upd_val0: type:VARCHAR value:"x" dml_vals: type:TUPLE values:{type:VARCHAR value:"a"}} true true`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: update t set val = :dml_upd_val0 where name in ::dml_vals {` +
+			`dml_upd_val0: type:VARCHAR value:"w" dml_vals: type:TUPLE values:{type:VARCHAR value:"b"}} true true`,
+	})
+}
@@ -101,6 +101,10 @@ func (t *noopVCursor) GetWarmingReadsChannel() chan bool {
 	panic("implement me")
 }
 
+func (t *noopVCursor) GetSequenceCache() *SequenceCache {
+	return nil
+}
+
 func (t *noopVCursor) CloneForReplicaWarming(ctx context.Context) VCursor {
 	panic("implement me")
 }
@@ -127,6 +131,11 @@ func (t *noopVCursor) ConnCollation() collations.ID {
 	return collations.Default()
 }
 
+// VReplicationLag implements VCursor
+func (t *noopVCursor) VReplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error) {
+	panic("implement me")
+}
+
 func (t *noopVCursor) TimeZone() *time.Location {
 	return nil
 }
@@ -403,6 +412,8 @@ type loggingVCursor struct {
 	ksShardMap map[string][]string
 
 	shardSession []*srvtopo.ResolvedShard
+
+	sequenceCache *SequenceCache
 }
 
 func (f *loggingVCursor) HasCreatedTempTable() {
@@ -447,8 +458,10 @@ func (f *loggingVCursor) HasSystemVariables() bool {
 	return len(f.systemVariables) > 0
 }
 
-func (f *loggingVCursor) GetSystemVariables(func(k string, v string)) {
-	panic("implement me")
+func (f *loggingVCursor) GetSystemVariables(fn func(k string, v string)) {
+	for k, v := range f.systemVariables {
+		fn(k, v)
+	}
 }
 
 func (f *loggingVCursor) SetFoundRows(u uint64) {
@@ -514,6 +527,10 @@ func (f *loggingVCursor) GetWarmingReadsChannel() chan bool {
 	return make(chan bool)
 }
 
+func (f *loggingVCursor) GetSequenceCache() *SequenceCache {
+	return f.sequenceCache
+}
+
 func (f *loggingVCursor) CloneForReplicaWarming(ctx context.Context) VCursor {
 	return f
 }
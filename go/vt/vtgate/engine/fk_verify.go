@@ -19,22 +19,37 @@ package engine
 import (
 	"context"
 	"fmt"
+	"strings"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 )
 
 // Verify contains the verification primitve and its type i.e. parent or child
 type Verify struct {
 	Exec Primitive
 	Typ  string
+
+	// BVName is set when Exec verifies the rows of the Selection or the Values of the FkVerify.
+	// It is the bind variable holding the tuples of the Cols of these rows that have no NULL,
+	// or their values if there is a single column.
+	BVName string
+	Cols   []int // indexes
 }
 
 // FkVerify is a primitive that verifies that the foreign key constraints in parent tables are satisfied.
 // It does this by executing a select distinct query on the parent table with the values that are being inserted/updated.
 type FkVerify struct {
+	// Selection, if set, finds the rows whose columns are passed to the verifications with a BVName,
+	// so that each of them verifies all the rows with a single query.
+	Selection Primitive
+	// Values, if set, are the inserted rows whose columns are passed to the verifications with a BVName.
+	// They are evaluated on execution, as they can hold bind variables.
+	Values [][]evalengine.Expr
 	Verify []*Verify
 	Exec   Primitive
 
@@ -43,8 +58,14 @@ type FkVerify struct {
 
 // constants for verification type.
 const (
+	// ParentVerify fails when its query returns rows, i.e. the child rows without a parent row.
 	ParentVerify = "VerifyParent"
-	ChildVerify  = "VerifyChild"
+	// ParentExistsVerify fails when its query returns no rows, i.e. the parent rows don't exist.
+	// Its query selects the parent columns of the parent rows of the tuples of its bind variable,
+	// and it fails unless a row is returned for each of the tuples.
+	ParentExistsVerify = "VerifyParentExists"
+	// ChildVerify fails when its query returns rows, i.e. the child rows of the modified parent rows.
+	ChildVerify = "VerifyChild"
 )
 
 // RouteType implements the Primitive interface
@@ -69,12 +90,30 @@ func (f *FkVerify) GetFields(ctx context.Context, vcursor VCursor, bindVars map[
 
 // TryExecute implements the Primitive interface
 func (f *FkVerify) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	selected, err := f.selectRows(ctx, vcursor, bindVars, func(bindVars map[string]*querypb.BindVariable) ([]sqltypes.Row, error) {
+		qr, err := vcursor.ExecutePrimitive(ctx, f.Selection, bindVars, false)
+		if err != nil {
+			return nil, err
+		}
+		return qr.Rows, nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	for _, v := range f.Verify {
-		qr, err := vcursor.ExecutePrimitive(ctx, v.Exec, bindVars, wantfields)
+		verifyVars, tuples, skip := v.bindVars(bindVars, selected)
+		if skip {
+			continue
+		}
+		qr, err := vcursor.ExecutePrimitive(ctx, v.Exec, verifyVars, wantfields)
+		if err != nil {
+			return nil, err
+		}
+		failed, err := v.fails(tuples, qr)
 		if err != nil {
 			return nil, err
 		}
-		if len(qr.Rows) > 0 {
+		if failed {
 			return nil, getError(v.Typ)
 		}
 	}
@@ -83,28 +122,175 @@ func (f *FkVerify) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 
 // TryStreamExecute implements the Primitive interface
 func (f *FkVerify) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	selected, err := f.selectRows(ctx, vcursor, bindVars, func(bindVars map[string]*querypb.BindVariable) ([]sqltypes.Row, error) {
+		var rows []sqltypes.Row
+		err := vcursor.StreamExecutePrimitive(ctx, f.Selection, bindVars, false, func(qr *sqltypes.Result) error {
+			rows = append(rows, qr.Rows...)
+			return nil
+		})
+		return rows, err
+	})
+	if err != nil {
+		return err
+	}
 	for _, v := range f.Verify {
-		err := vcursor.StreamExecutePrimitive(ctx, v.Exec, bindVars, wantfields, func(qr *sqltypes.Result) error {
-			if len(qr.Rows) > 0 {
-				return getError(v.Typ)
+		verifyVars, tuples, skip := v.bindVars(bindVars, selected)
+		if skip {
+			continue
+		}
+		qr := &sqltypes.Result{}
+		err := vcursor.StreamExecutePrimitive(ctx, v.Exec, verifyVars, wantfields, func(res *sqltypes.Result) error {
+			if len(qr.Fields) == 0 {
+				qr.Fields = res.Fields
 			}
+			qr.Rows = append(qr.Rows, res.Rows...)
 			return nil
 		})
 		if err != nil {
 			return err
 		}
+		failed, err := v.fails(tuples, qr)
+		if err != nil {
+			return err
+		}
+		if failed {
+			return getError(v.Typ)
+		}
 	}
 	return vcursor.StreamExecutePrimitive(ctx, f.Exec, bindVars, wantfields, callback)
 }
 
+// selectRows returns the rows verified by the verifications with a BVName: the rows found by the Selection
+// with the given function, or the evaluated Values.
+func (f *FkVerify) selectRows(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, selection func(map[string]*querypb.BindVariable) ([]sqltypes.Row, error)) ([]sqltypes.Row, error) {
+	if f.Selection != nil {
+		return selection(bindVars)
+	}
+	if len(f.Values) == 0 {
+		return nil, nil
+	}
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	rows := make([]sqltypes.Row, 0, len(f.Values))
+	for _, values := range f.Values {
+		row := make(sqltypes.Row, 0, len(values))
+		for _, value := range values {
+			result, err := env.Evaluate(value)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, result.Value(vcursor.ConnCollation()))
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// bindVars returns the bind variables of the verification query, and the tuples of the Cols of the selected
+// rows it verifies if it has a BVName. The bind variables of the DML are copied rather than modified.
+// skip is true if there is nothing to verify, as none of the selected rows has a tuple without NULL.
+func (v *Verify) bindVars(bindVars map[string]*querypb.BindVariable, selected []sqltypes.Row) (verifyVars map[string]*querypb.BindVariable, tuples []sqltypes.Row, skip bool) {
+	if v.BVName == "" {
+		return bindVars, nil, false
+	}
+	bv := &querypb.BindVariable{
+		Type: querypb.Type_TUPLE,
+	}
+	seen := make(map[string]bool)
+rows:
+	for _, row := range selected {
+		tuple := make(sqltypes.Row, 0, len(v.Cols))
+		for _, colIdx := range v.Cols {
+			// a NULL never references a row, so there is nothing to verify
+			if row[colIdx].IsNull() {
+				continue rows
+			}
+			tuple = append(tuple, row[colIdx])
+		}
+		key := fmt.Sprintf("%v", tuple)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		tuples = append(tuples, tuple)
+		if len(tuple) == 1 {
+			bv.Values = append(bv.Values, sqltypes.ValueToProto(tuple[0]))
+			continue
+		}
+		value := &querypb.Value{
+			Type: querypb.Type_TUPLE,
+		}
+		for _, colValue := range tuple {
+			value.Values = append(value.Values, sqltypes.ValueToProto(colValue))
+		}
+		bv.Values = append(bv.Values, value)
+	}
+	if len(tuples) == 0 {
+		return nil, nil, true
+	}
+	verifyVars = copyBindVars(bindVars)
+	verifyVars[v.BVName] = bv
+	return verifyVars, tuples, false
+}
+
+// fails returns true if the verification fails, given the tuples it verifies and the result of its query.
+func (v *Verify) fails(tuples []sqltypes.Row, qr *sqltypes.Result) (bool, error) {
+	if v.Typ != ParentExistsVerify {
+		return len(qr.Rows) > 0, nil
+	}
+	for _, tuple := range tuples {
+		found, err := tupleFound(tuple, qr)
+		if err != nil || !found {
+			return true, err
+		}
+	}
+	return false, nil
+}
+
+// tupleFound returns true if one of the rows of the result has the values of the tuple,
+// compared with the collations of the columns of the result.
+func tupleFound(tuple sqltypes.Row, qr *sqltypes.Result) (bool, error) {
+	for _, row := range qr.Rows {
+		equal := true
+		for idx, value := range tuple {
+			collation := collations.ID(collations.CollationBinaryID)
+			if idx < len(qr.Fields) {
+				collation = collations.ID(qr.Fields[idx].Charset)
+			}
+			cmp, err := evalengine.NullsafeCompare(value, row[idx], collation)
+			if err != nil {
+				return false, err
+			}
+			if cmp != 0 {
+				equal = false
+				break
+			}
+		}
+		if equal {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Inputs implements the Primitive interface
 func (f *FkVerify) Inputs() ([]Primitive, []map[string]any) {
 	var inputs []Primitive
 	var inputsMap []map[string]any
-	for idx, v := range f.Verify {
+	if f.Selection != nil {
+		inputs = append(inputs, f.Selection)
 		inputsMap = append(inputsMap, map[string]any{
-			inputName: fmt.Sprintf("%s-%d", v.Typ, idx+1),
+			inputName: "Selection",
 		})
+	}
+	for idx, v := range f.Verify {
+		verifyMap := map[string]any{
+			inputName: fmt.Sprintf("%s-%d", v.Typ, idx+1),
+		}
+		if v.BVName != "" {
+			verifyMap["BvName"] = v.BVName
+			verifyMap["Cols"] = v.Cols
+		}
+		inputsMap = append(inputsMap, verifyMap)
 		inputs = append(inputs, v.Exec)
 	}
 	inputs = append(inputs, f.Exec)
@@ -116,13 +302,25 @@ func (f *FkVerify) Inputs() ([]Primitive, []map[string]any) {
 }
 
 func (f *FkVerify) description() PrimitiveDescription {
-	return PrimitiveDescription{OperatorType: f.RouteType()}
+	var other map[string]any
+	if len(f.Values) > 0 {
+		var values []string
+		for _, row := range f.Values {
+			var exprs []string
+			for _, expr := range row {
+				exprs = append(exprs, evalengine.FormatExpr(expr))
+			}
+			values = append(values, "("+strings.Join(exprs, ", ")+")")
+		}
+		other = map[string]any{"Values": values}
+	}
+	return PrimitiveDescription{OperatorType: f.RouteType(), Other: other}
 }
 
 var _ Primitive = (*FkVerify)(nil)
 
 func getError(typ string) error {
-	if typ == ParentVerify {
+	if typ == ParentVerify || typ == ParentExistsVerify {
 		return vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.NoReferencedRow2, "Cannot add or update a child row: a foreign key constraint fails")
 	}
 	return vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.RowIsReferenced2, "Cannot delete or update a parent row: a foreign key constraint fails")
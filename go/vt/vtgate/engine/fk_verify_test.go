@@ -22,8 +22,10 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
 
@@ -123,3 +125,172 @@ func TestFKVerifyUpdate(t *testing.T) {
 		})
 	})
 }
+
+func TestFKVerifyInsert(t *testing.T) {
+	verifyP := &Route{
+		Query: "select cola from parent where cola in ::fkv_vals lock in share mode",
+		RoutingParameters: &RoutingParameters{
+			Opcode:   Unsharded,
+			Keyspace: &vindexes.Keyspace{Name: "ks"},
+		},
+	}
+	childP := &Insert{
+		Opcode:   InsertUnsharded,
+		Keyspace: &vindexes.Keyspace{Name: "ks"},
+		Query:    "insert into child(cola) values (1), (:v1), (null)",
+	}
+	fkc := &FkVerify{
+		Values: [][]evalengine.Expr{
+			{evalengine.NewLiteralInt(1)},
+			{evalengine.NewBindVar("v1", sqltypes.Unknown, collations.Unknown)},
+			{evalengine.NullExpr},
+		},
+		Verify: []*Verify{{Exec: verifyP, Typ: ParentExistsVerify, BVName: "fkv_vals", Cols: []int{0}}},
+		Exec:   childP,
+	}
+
+	t.Run("parent rows verified in a single query", func(t *testing.T) {
+		verifyRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"), "2", "1")
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{verifyRes}
+		bindVars := map[string]*querypb.BindVariable{"v1": sqltypes.Int64BindVariable(2)}
+		_, err := fkc.TryExecute(context.Background(), vc, bindVars, true)
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: select cola from parent where cola in ::fkv_vals lock in share mode {fkv_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} v1: type:INT64 value:"2"} false false`,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: insert into child(cola) values (1), (:v1), (null) {v1: type:INT64 value:"2"} true true`,
+		})
+
+		vc.Rewind()
+		err = fkc.TryStreamExecute(context.Background(), vc, bindVars, true, func(result *sqltypes.Result) error { return nil })
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`StreamExecuteMulti select cola from parent where cola in ::fkv_vals lock in share mode ks.0: {fkv_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} v1: type:INT64 value:"2"} `,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: insert into child(cola) values (1), (:v1), (null) {v1: type:INT64 value:"2"} true true`,
+		})
+	})
+
+	t.Run("parent row not found", func(t *testing.T) {
+		verifyRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"), "1")
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{verifyRes}
+		bindVars := map[string]*querypb.BindVariable{"v1": sqltypes.Int64BindVariable(2)}
+		_, err := fkc.TryExecute(context.Background(), vc, bindVars, true)
+		require.ErrorContains(t, err, "Cannot add or update a child row: a foreign key constraint fails")
+		// the bind variables of the caller are left untouched
+		require.NotContains(t, bindVars, "fkv_vals")
+
+		vc.Rewind()
+		err = fkc.TryStreamExecute(context.Background(), vc, bindVars, true, func(result *sqltypes.Result) error { return nil })
+		require.ErrorContains(t, err, "Cannot add or update a child row: a foreign key constraint fails")
+		require.NotContains(t, bindVars, "fkv_vals")
+	})
+
+	t.Run("null and duplicate values", func(t *testing.T) {
+		verifyRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"), "1")
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{verifyRes}
+		_, err := fkc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"v1": sqltypes.Int64BindVariable(1)}, true)
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: select cola from parent where cola in ::fkv_vals lock in share mode {fkv_vals: type:TUPLE values:{type:INT64 value:"1"} v1: type:INT64 value:"1"} false false`,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: insert into child(cola) values (1), (:v1), (null) {v1: type:INT64 value:"1"} true true`,
+		})
+	})
+
+	t.Run("only null values", func(t *testing.T) {
+		fkc := &FkVerify{
+			Values: [][]evalengine.Expr{{evalengine.NewBindVar("v1", sqltypes.Unknown, collations.Unknown)}},
+			Verify: []*Verify{{Exec: verifyP, Typ: ParentExistsVerify, BVName: "fkv_vals", Cols: []int{0}}},
+			Exec:   childP,
+		}
+		vc := newDMLTestVCursor("0")
+		_, err := fkc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"v1": sqltypes.NullBindVariable}, true)
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: insert into child(cola) values (1), (:v1), (null) {v1: } true true`,
+		})
+	})
+}
+
+func TestFKVerifyDelete(t *testing.T) {
+	selection := &Route{
+		Query: "select cola from parent where foo = 48 for update",
+		RoutingParameters: &RoutingParameters{
+			Opcode:   Unsharded,
+			Keyspace: &vindexes.Keyspace{Name: "ks"},
+		},
+	}
+	verifyC := &Route{
+		Query: "select 1 from child where cola in ::fkv_vals limit 1 lock in share mode",
+		RoutingParameters: &RoutingParameters{
+			Opcode:   Unsharded,
+			Keyspace: &vindexes.Keyspace{Name: "ks"},
+		},
+	}
+	parentP := &Delete{
+		DML: &DML{
+			Query: "delete from parent where foo = 48",
+			RoutingParameters: &RoutingParameters{
+				Opcode:   Unsharded,
+				Keyspace: &vindexes.Keyspace{Name: "ks"},
+			},
+		},
+	}
+	fkc := &FkVerify{
+		Selection: selection,
+		Verify:    []*Verify{{Exec: verifyC, Typ: ChildVerify, BVName: "fkv_vals", Cols: []int{0}}},
+		Exec:      parentP,
+	}
+
+	t.Run("child rows verified in a single query", func(t *testing.T) {
+		selectionRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"), "1", "2")
+		verifyRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"))
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{selectionRes, verifyRes}
+		_, err := fkc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, true)
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: select cola from parent where foo = 48 for update {} false false`,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: select 1 from child where cola in ::fkv_vals limit 1 lock in share mode {fkv_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"}} false false`,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: delete from parent where foo = 48 {} true true`,
+		})
+	})
+
+	t.Run("child row found", func(t *testing.T) {
+		selectionRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"), "1", "2")
+		verifyRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{selectionRes, verifyRes}
+		_, err := fkc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, true)
+		require.ErrorContains(t, err, "Cannot delete or update a parent row: a foreign key constraint fails")
+
+		vc.Rewind()
+		err = fkc.TryStreamExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, true, func(result *sqltypes.Result) error { return nil })
+		require.ErrorContains(t, err, "Cannot delete or update a parent row: a foreign key constraint fails")
+	})
+
+	t.Run("no rows deleted", func(t *testing.T) {
+		selectionRes := sqltypes.MakeTestResult(sqltypes.MakeTestFields("cola", "int64"))
+		vc := newDMLTestVCursor("0")
+		vc.results = []*sqltypes.Result{selectionRes}
+		_, err := fkc.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, true)
+		require.NoError(t, err)
+		vc.ExpectLog(t, []string{
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: select cola from parent where foo = 48 for update {} false false`,
+			`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+			`ExecuteMultiShard ks.0: delete from parent where foo = 48 {} true true`,
+		})
+	})
+}
@@ -162,6 +162,9 @@ func NewInsert(
 type Generate struct {
 	Keyspace *vindexes.Keyspace
 	Query    string
+	// Sequence is the name of the sequence table, under
+	// which its values are cached by the SequenceCache.
+	Sequence string
 	// Values are the supplied values for the column, which
 	// will be stored as a list within the expression. New
 	// values will be generated based on how many were not
@@ -511,6 +514,34 @@ func shouldGenerate(v sqltypes.Value) bool {
 	return false
 }
 
+// generate returns the first of count new consecutive values of the sequence.
+// The values are served by the SequenceCache of the vtgate if it has one,
+// and are otherwise fetched from the sequence tablet.
+func (ins *Insert) generate(ctx context.Context, vcursor VCursor, count int64) (int64, error) {
+	reserve := func(n int64) (int64, error) {
+		rss, _, err := vcursor.ResolveDestinations(ctx, ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
+		if err != nil {
+			return 0, err
+		}
+		if len(rss) != 1 {
+			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
+		}
+		bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(n)}
+		qr, err := vcursor.ExecuteStandalone(ctx, ins, ins.Generate.Query, bindVars, rss[0])
+		if err != nil {
+			return 0, err
+		}
+		// If no rows are returned, it's an internal error, and the code
+		// must panic, which will be caught and reported.
+		return qr.Rows[0][0].ToCastInt64()
+	}
+
+	if cache := vcursor.GetSequenceCache(); cache != nil {
+		return cache.Next(ins.Generate.Keyspace.Name+"."+ins.Generate.Sequence, count, reserve)
+	}
+	return reserve(count)
+}
+
 // processGenerateFromValues generates new values using a sequence if necessary.
 // If no value was generated, it returns 0. Values are generated only
 // for cases where none are supplied.
@@ -540,21 +571,7 @@ func (ins *Insert) processGenerateFromValues(
 
 	// If generation is needed, generate the requested number of values (as one call).
 	if count != 0 {
-		rss, _, err := vcursor.ResolveDestinations(ctx, ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
-		if err != nil {
-			return 0, err
-		}
-		if len(rss) != 1 {
-			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
-		}
-		bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(count)}
-		qr, err := vcursor.ExecuteStandalone(ctx, ins, ins.Generate.Query, bindVars, rss[0])
-		if err != nil {
-			return 0, err
-		}
-		// If no rows are returned, it's an internal error, and the code
-		// must panic, which will be caught and reported.
-		insertID, err = qr.Rows[0][0].ToCastInt64()
+		insertID, err = ins.generate(ctx, vcursor, count)
 		if err != nil {
 			return 0, err
 		}
@@ -602,21 +619,7 @@ func (ins *Insert) processGenerateFromRows(
 	}
 
 	// If generation is needed, generate the requested number of values (as one call).
-	rss, _, err := vcursor.ResolveDestinations(ctx, ins.Generate.Keyspace.Name, nil, []key.Destination{key.DestinationAnyShard{}})
-	if err != nil {
-		return 0, err
-	}
-	if len(rss) != 1 {
-		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "auto sequence generation can happen through single shard only, it is getting routed to %d shards", len(rss))
-	}
-	bindVars := map[string]*querypb.BindVariable{"n": sqltypes.Int64BindVariable(count)}
-	qr, err := vcursor.ExecuteStandalone(ctx, ins, ins.Generate.Query, bindVars, rss[0])
-	if err != nil {
-		return 0, err
-	}
-	// If no rows are returned, it's an internal error, and the code
-	// must panic, which will be caught and reported.
-	insertID, err = qr.Rows[0][0].ToCastInt64()
+	insertID, err = ins.generate(ctx, vcursor, count)
 	if err != nil {
 		return 0, err
 	}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -120,6 +121,65 @@ func TestInsertUnshardedGenerate(t *testing.T) {
 	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 4})
 }
 
+func TestInsertUnshardedGenerateWithSequenceCache(t *testing.T) {
+	ins := NewQueryInsert(
+		InsertUnsharded,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: false,
+		},
+		"dummy_insert",
+	)
+	ins.Generate = &Generate{
+		Keyspace: &vindexes.Keyspace{
+			Name:    "ks2",
+			Sharded: false,
+		},
+		Query:    "dummy_generate",
+		Sequence: "seq",
+		Values: evalengine.NewTupleExpr(
+			evalengine.NullExpr,
+			evalengine.NullExpr,
+		),
+	}
+
+	vc := newDMLTestVCursor("0")
+	vc.sequenceCache = NewSequenceCache(5)
+	vc.results = []*sqltypes.Result{
+		sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"nextval",
+				"int64",
+			),
+			"10",
+		),
+		{InsertID: 1},
+		{InsertID: 1},
+	}
+
+	// the first insert reserves a block of 5 values
+	result, err := ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks2 [] Destinations:DestinationAnyShard()`,
+		`ExecuteStandalone dummy_generate n: type:INT64 value:"5" ks2 0`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: dummy_insert {__seq0: type:INT64 value:"10" __seq1: type:INT64 value:"11"} true true`,
+	})
+	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 10})
+
+	// the second insert is served from the cache
+	vc.log = nil
+	result, err = ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.0: dummy_insert {__seq0: type:INT64 value:"12" __seq1: type:INT64 value:"13"} true true`,
+	})
+	expectResult(t, "Execute", result, &sqltypes.Result{InsertID: 12})
+	assert.EqualValues(t, 1, vc.sequenceCache.Remaining("ks2.seq"))
+}
+
 func TestInsertUnshardedGenerate_Zeros(t *testing.T) {
 	ins := NewQueryInsert(
 		InsertUnsharded,
@@ -0,0 +1,368 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/json"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*JSONTable)(nil)
+
+// JSONTable is a primitive that evaluates a JSON_TABLE expression at the vtgate level.
+// The JSON document is evaluated for every row of the Input, and every value matching
+// the Path in it produces a row, made of the columns of the input and of the json table.
+type JSONTable struct {
+	// Input produces the rows the JSON document is evaluated against.
+	// When it is nil, the JSON document is evaluated once, without any row.
+	Input Primitive
+
+	// Doc is the JSON document of the json table
+	Doc    evalengine.Expr
+	ASTDoc sqlparser.Expr
+
+	// Path is the path of the values of the document producing the rows
+	Path string
+
+	Columns []*JSONTableColumn
+
+	// Cols are the columns returned by the primitive. A non-negative value is
+	// the offset of a column of the Input, and a negative value -n-1 is the
+	// n-th column of the json table.
+	Cols []int
+}
+
+// JSONTableColumn is a column of a json table.
+type JSONTableColumn struct {
+	Name string
+	Type querypb.Type
+
+	// Ordinality is true for the FOR ORDINALITY columns, that count the rows of the json table
+	Ordinality bool
+
+	// Extract returns the value of the column from the row of the json table, which is the only
+	// column of the evaluation environment. It is the result of JSON_EXTRACT for the regular
+	// columns, and of JSON_CONTAINS_PATH for the EXISTS PATH columns.
+	Extract evalengine.Expr
+
+	// Convert casts the extracted value, which is the only column of the evaluation environment,
+	// to the type of the column. It is nil when the extracted value is returned as is.
+	Convert evalengine.Expr
+
+	// OnEmpty and OnError are used when the path of the column does not match
+	// anything in the row, and when the extracted value can't be converted
+	OnEmpty, OnError *JSONTableResponse
+
+	// ASTDefinition is the definition of the column in the query
+	ASTDefinition string
+}
+
+// JSONTableResponse is the value of a json table column when its value is missing or invalid.
+// A nil Default means NULL.
+type JSONTableResponse struct {
+	Error   bool
+	Default evalengine.Expr
+}
+
+// RouteType implements the Primitive interface
+func (jt *JSONTable) RouteType() string {
+	return "JSONTable"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (jt *JSONTable) GetKeyspaceName() string {
+	if jt.Input == nil {
+		return ""
+	}
+	return jt.Input.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (jt *JSONTable) GetTableName() string {
+	if jt.Input == nil {
+		return ""
+	}
+	return jt.Input.GetTableName()
+}
+
+// TryExecute implements the Primitive interface
+func (jt *JSONTable) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	input := &sqltypes.Result{Rows: []sqltypes.Row{nil}}
+	if jt.Input != nil {
+		var err error
+		input, err = vcursor.ExecutePrimitive(ctx, jt.Input, bindVars, wantfields)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := &sqltypes.Result{}
+	if wantfields {
+		result.Fields = jt.fields(vcursor, input.Fields)
+	}
+	var err error
+	result.Rows, err = jt.evaluate(ctx, vcursor, bindVars, input.Rows)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (jt *JSONTable) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	if jt.Input == nil {
+		res, err := jt.TryExecute(ctx, vcursor, bindVars, wantfields)
+		if err != nil {
+			return err
+		}
+		return callback(res)
+	}
+
+	return vcursor.StreamExecutePrimitive(ctx, jt.Input, bindVars, wantfields, func(input *sqltypes.Result) error {
+		result := &sqltypes.Result{}
+		if input.Fields != nil {
+			result.Fields = jt.fields(vcursor, input.Fields)
+		}
+		var err error
+		result.Rows, err = jt.evaluate(ctx, vcursor, bindVars, input.Rows)
+		if err != nil {
+			return err
+		}
+		return callback(result)
+	})
+}
+
+// GetFields implements the Primitive interface
+func (jt *JSONTable) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	var inputFields []*querypb.Field
+	if jt.Input != nil {
+		res, err := jt.Input.GetFields(ctx, vcursor, bindVars)
+		if err != nil {
+			return nil, err
+		}
+		inputFields = res.Fields
+	}
+	return &sqltypes.Result{Fields: jt.fields(vcursor, inputFields)}, nil
+}
+
+func (jt *JSONTable) fields(vcursor VCursor, inputFields []*querypb.Field) []*querypb.Field {
+	fields := make([]*querypb.Field, 0, len(jt.Cols))
+	for _, col := range jt.Cols {
+		if col >= 0 {
+			fields = append(fields, inputFields[col])
+			continue
+		}
+		column := jt.Columns[-col-1]
+		field := &querypb.Field{
+			Name:    column.Name,
+			Type:    column.Type,
+			Charset: collations.CollationBinaryID,
+		}
+		if sqltypes.IsText(column.Type) {
+			field.Charset = uint32(vcursor.ConnCollation())
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// evaluate returns the rows of the json table for each of the input rows
+func (jt *JSONTable) evaluate(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, input []sqltypes.Row) ([]sqltypes.Row, error) {
+	var pp json.PathParser
+	path, err := pp.ParseBytes([]byte(jt.Path))
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid JSON path expression '%s' in JSON_TABLE", jt.Path)
+	}
+
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	var rows []sqltypes.Row
+	for _, inputRow := range input {
+		env.Row = inputRow
+		res, err := env.Evaluate(jt.Doc)
+		if err != nil {
+			return nil, err
+		}
+		docValue := res.Value(vcursor.ConnCollation())
+		if docValue.IsNull() {
+			continue
+		}
+
+		var p json.Parser
+		doc, err := p.ParseBytes(docValue.Raw())
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Invalid JSON text in argument 1 to function json_table: %s", err.Error())
+		}
+
+		var matches []*json.Value
+		path.Match(doc, true, func(value *json.Value) {
+			matches = append(matches, value)
+		})
+
+		for idx, match := range matches {
+			jsonRow, err := jt.evaluateColumns(env, vcursor, idx+1, match)
+			if err != nil {
+				return nil, err
+			}
+			row := make(sqltypes.Row, 0, len(jt.Cols))
+			for _, col := range jt.Cols {
+				if col >= 0 {
+					row = append(row, inputRow[col])
+				} else {
+					row = append(row, jsonRow[-col-1])
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+// evaluateColumns returns the values of the columns of the json table for one of its rows
+func (jt *JSONTable) evaluateColumns(env *evalengine.ExpressionEnv, vcursor VCursor, ordinal int, match *json.Value) ([]sqltypes.Value, error) {
+	rowValue := sqltypes.MakeTrusted(sqltypes.TypeJSON, match.MarshalTo(nil))
+	values := make([]sqltypes.Value, 0, len(jt.Columns))
+	for _, col := range jt.Columns {
+		if col.Ordinality {
+			values = append(values, sqltypes.MakeTrusted(col.Type, strconv.AppendInt(nil, int64(ordinal), 10)))
+			continue
+		}
+		value, err := col.evaluate(env, vcursor, rowValue)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func (col *JSONTableColumn) evaluate(env *evalengine.ExpressionEnv, vcursor VCursor, rowValue sqltypes.Value) (sqltypes.Value, error) {
+	env.Row = []sqltypes.Value{rowValue}
+	res, err := env.Evaluate(col.Extract)
+	if err != nil {
+		return sqltypes.Value{}, err
+	}
+	extracted := res.Value(vcursor.ConnCollation())
+	if col.Convert == nil {
+		return col.toColumnType(extracted), nil
+	}
+
+	switch {
+	case extracted.IsNull():
+		if col.OnEmpty != nil && col.OnEmpty.Error {
+			return sqltypes.Value{}, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Missing value for JSON_TABLE column '%s'", col.Name)
+		}
+		return col.response(env, vcursor, col.OnEmpty)
+	case isJSONNull(extracted):
+		return sqltypes.NULL, nil
+	case isJSONContainer(extracted):
+		if col.OnError != nil && col.OnError.Error {
+			return sqltypes.Value{}, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Can't store an array or an object in the scalar column '%s' of JSON_TABLE", col.Name)
+		}
+		return col.response(env, vcursor, col.OnError)
+	}
+
+	env.Row = []sqltypes.Value{extracted}
+	res, err = env.Evaluate(col.Convert)
+	if err != nil {
+		if col.OnError != nil && col.OnError.Error {
+			return sqltypes.Value{}, err
+		}
+		return col.response(env, vcursor, col.OnError)
+	}
+	return col.toColumnType(res.Value(vcursor.ConnCollation())), nil
+}
+
+// response returns the value of the column for an ON EMPTY or ON ERROR clause
+func (col *JSONTableColumn) response(env *evalengine.ExpressionEnv, vcursor VCursor, response *JSONTableResponse) (sqltypes.Value, error) {
+	if response == nil || response.Default == nil {
+		return sqltypes.NULL, nil
+	}
+	res, err := env.Evaluate(response.Default)
+	if err != nil {
+		return sqltypes.Value{}, err
+	}
+	return col.toColumnType(res.Value(vcursor.ConnCollation())), nil
+}
+
+func (col *JSONTableColumn) toColumnType(value sqltypes.Value) sqltypes.Value {
+	if value.IsNull() || value.Type() == col.Type {
+		return value
+	}
+	return sqltypes.MakeTrusted(col.Type, value.Raw())
+}
+
+func isJSONNull(value sqltypes.Value) bool {
+	return value.Type() == sqltypes.TypeJSON && string(value.Raw()) == "null"
+}
+
+func isJSONContainer(value sqltypes.Value) bool {
+	raw := value.Raw()
+	return value.Type() == sqltypes.TypeJSON && len(raw) > 0 && (raw[0] == '[' || raw[0] == '{')
+}
+
+// NeedsTransaction implements the Primitive interface
+func (jt *JSONTable) NeedsTransaction() bool {
+	return jt.Input != nil && jt.Input.NeedsTransaction()
+}
+
+// Inputs implements the Primitive interface
+func (jt *JSONTable) Inputs() ([]Primitive, []map[string]any) {
+	if jt.Input == nil {
+		return nil, nil
+	}
+	return []Primitive{jt.Input}, nil
+}
+
+func (jt *JSONTable) description() PrimitiveDescription {
+	columns := make([]string, 0, len(jt.Columns))
+	for _, col := range jt.Columns {
+		columns = append(columns, col.ASTDefinition)
+	}
+	other := map[string]any{
+		"Document":      sqlparser.String(jt.ASTDoc),
+		"Path":          jt.Path,
+		"Columns":       columns,
+		"ColumnIndexes": jt.colsDescription(),
+	}
+	return PrimitiveDescription{
+		OperatorType: "JSONTable",
+		Other:        other,
+	}
+}
+
+func (jt *JSONTable) colsDescription() string {
+	var cols []string
+	for _, col := range jt.Cols {
+		if col < 0 {
+			cols = append(cols, fmt.Sprintf("J:%d", -col-1))
+		} else {
+			cols = append(cols, fmt.Sprintf("I:%d", col))
+		}
+	}
+	return strings.Join(cols, ",")
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func translateJSONTableExpr(t *testing.T, expr string) evalengine.Expr {
+	ast, err := sqlparser.ParseExpr(expr)
+	require.NoError(t, err)
+	e, err := evalengine.Translate(ast, &evalengine.Config{
+		Collation: collations.CollationUtf8mb4ID,
+		ResolveColumn: func(*sqlparser.ColName) (int, error) {
+			return 0, nil
+		},
+		ResolveType: func(sqlparser.Expr) (sqltypes.Type, collations.ID, bool) {
+			return sqltypes.TypeJSON, collations.CollationUtf8mb4ID, true
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func TestJSONTable(t *testing.T) {
+	columns := []*JSONTableColumn{{
+		Name:       "rn",
+		Type:       sqltypes.Uint32,
+		Ordinality: true,
+	}, {
+		Name:    "a",
+		Type:    sqltypes.Int32,
+		Extract: translateJSONTableExpr(t, "json_extract(value, '$.a')"),
+		Convert: translateJSONTableExpr(t, "cast(json_unquote(value) as signed)"),
+		OnEmpty: &JSONTableResponse{Default: translateJSONTableExpr(t, "cast(json_unquote(cast('42' as json)) as signed)")},
+	}, {
+		Name:    "b",
+		Type:    sqltypes.Int32,
+		Extract: translateJSONTableExpr(t, "json_contains_path(value, 'one', '$.b')"),
+	}}
+
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("id|doc", "int64|varchar"),
+			`1|[{"a": 1, "b": 2}, {"a": null}, {}]`,
+			`2|null`,
+			`3|[{"a": 3}]`,
+		),
+	}}
+
+	docExpr := sqlparser.NewColName("doc")
+	doc, err := evalengine.Translate(docExpr, &evalengine.Config{
+		Collation:     collations.CollationUtf8mb4ID,
+		ResolveColumn: evalengine.FieldResolver(input.results[0].Fields).Column,
+	})
+	require.NoError(t, err)
+
+	jt := &JSONTable{
+		Input:   input,
+		Doc:     doc,
+		ASTDoc:  docExpr,
+		Path:    "$[*]",
+		Columns: columns,
+		Cols:    []int{0, -1, -2, -3},
+	}
+
+	qr, err := jt.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	require.Equal(t, `[[INT64(1) UINT32(1) INT32(1) INT32(1)] [INT64(1) UINT32(2) NULL INT32(0)] [INT64(1) UINT32(3) INT32(42) INT32(0)] [INT64(3) UINT32(1) INT32(3) INT32(0)]]`, fmt.Sprintf("%v", qr.Rows))
+	require.Equal(t, []string{"id", "rn", "a", "b"}, fieldNames(qr.Fields))
+
+	input.rewind()
+	qr, err = wrapStreamExecute(jt, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	require.Len(t, qr.Rows, 4)
+}
+
+func TestJSONTableErrors(t *testing.T) {
+	jt := &JSONTable{
+		Doc:    translateJSONTableExpr(t, `'[{"a": [1]}]'`),
+		ASTDoc: sqlparser.NewStrLiteral(`[{"a": [1]}]`),
+		Path:   "$[*]",
+		Columns: []*JSONTableColumn{{
+			Name:    "a",
+			Type:    sqltypes.Int32,
+			Extract: translateJSONTableExpr(t, "json_extract(value, '$.a')"),
+			Convert: translateJSONTableExpr(t, "cast(json_unquote(value) as signed)"),
+			OnError: &JSONTableResponse{Error: true},
+		}},
+		Cols: []int{-1},
+	}
+	_, err := jt.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.EqualError(t, err, "Can't store an array or an object in the scalar column 'a' of JSON_TABLE")
+
+	jt.Columns[0].OnError = nil
+	qr, err := jt.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, `[[NULL]]`, fmt.Sprintf("%v", qr.Rows))
+
+	jt.Columns[0].Extract = translateJSONTableExpr(t, "json_extract(value, '$.b')")
+	jt.Columns[0].OnEmpty = &JSONTableResponse{Error: true}
+	_, err = jt.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.EqualError(t, err, "Missing value for JSON_TABLE column 'a'")
+}
+
+func fieldNames(fields []*querypb.Field) []string {
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	return names
+}
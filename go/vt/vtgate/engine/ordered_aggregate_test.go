@@ -882,6 +882,71 @@ func TestMultiDistinct(t *testing.T) {
 	utils.MustMatch(t, want, results)
 }
 
+func TestMultiDistinctUnsorted(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3|c3",
+		"int64|int64|int64|int64",
+	)
+	// the rows are sorted by c1 and c2, but not by c3
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"10|null|3|3",
+			"10|1|1|1",
+			"10|1|3|3",
+			"10|2|1|1",
+			"10|2|null|null",
+			"20|1|2|2",
+			"20|2|2|2",
+			"20|3|2|2",
+			"30|1|2|2",
+			"30|2|1|1",
+			"30|3|2|2",
+			"30|3|1|1",
+		)},
+	}
+
+	sumParam := NewAggregateParam(AggregateSumDistinct, 2, "sum(distinct c3)")
+	sumParam.HashDistinct = true
+	countParam := NewAggregateParam(AggregateCountDistinct, 3, "count(distinct c3)")
+	countParam.HashDistinct = true
+	oa := &OrderedAggregate{
+		Aggregates: []*AggregateParams{
+			NewAggregateParam(AggregateCountDistinct, 1, "count(distinct c2)"),
+			sumParam,
+			countParam,
+		},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input:       fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|count(distinct c2)|sum(distinct c3)|count(distinct c3)",
+			"int64|int64|decimal|int64",
+		),
+		`10|2|4|2`,
+		`20|3|2|1`,
+		`30|3|3|2`,
+	)
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, qr)
+
+	fp.rewind()
+	results := &sqltypes.Result{}
+	err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			results.Fields = qr.Fields
+		}
+		results.Rows = append(results.Rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, results)
+}
+
 func TestOrderedAggregateCollate(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"col|count(*)",
@@ -39,6 +39,10 @@ type Plan struct {
 	Warnings     []*query.QueryWarning   // Warnings that need to be yielded every time this query runs
 	TablesUsed   []string                // TablesUsed is the list of tables that this plan will query
 
+	// ResultCacheTTL is the duration for which the results of the plan can be
+	// served from the result cache of vtgate. 0 disables the cache.
+	ResultCacheTTL time.Duration
+
 	ExecCount    uint64 // Count of times this plan was executed
 	ExecTime     uint64 // Total execution time
 	ShardQueries uint64 // Total number of shard queries
@@ -87,6 +91,8 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		RowsReturned uint64                `json:",omitempty"`
 		Errors       uint64                `json:",omitempty"`
 		TablesUsed   []string              `json:",omitempty"`
+
+		ResultCacheTTL time.Duration `json:",omitempty"`
 	}{
 		QueryType:    p.Type.String(),
 		Original:     p.Original,
@@ -98,6 +104,8 @@ func (p *Plan) MarshalJSON() ([]byte, error) {
 		RowsReturned: atomic.LoadUint64(&p.RowsReturned),
 		Errors:       atomic.LoadUint64(&p.Errors),
 		TablesUsed:   p.TablesUsed,
+
+		ResultCacheTTL: p.ResultCacheTTL,
 	}
 
 	b := new(bytes.Buffer)
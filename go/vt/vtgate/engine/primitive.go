@@ -95,6 +95,9 @@ type (
 
 		LookupRowLockShardSession() vtgatepb.CommitOrder
 
+		// VReplicationLag returns the highest lag of the streams of the vreplication workflow running on the primary tablets of the keyspace
+		VReplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error)
+
 		FindRoutedTable(tablename sqlparser.TableName) (*vindexes.Table, error)
 
 		// GetDBDDLPlugin gets the configured plugin for DROP/CREATE DATABASE
@@ -126,6 +129,9 @@ type (
 		// GetWarmingReadsChannel returns the channel for executing warming reads against replicas
 		GetWarmingReadsChannel() chan bool
 
+		// GetSequenceCache returns the cache serving the sequence values, or nil if sequence values are not cached
+		GetSequenceCache() *SequenceCache
+
 		// CloneForReplicaWarming clones the VCursor for re-use in warming queries to replicas
 		CloneForReplicaWarming(ctx context.Context) VCursor
 	}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"strconv"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+// DefaultCTEMaxRecursionDepth is the number of iterations a recursive
+// common table expression can run when the session does not set
+// cte_max_recursion_depth. It is the default value used by MySQL.
+const DefaultCTEMaxRecursionDepth = 1000
+
+var _ Primitive = (*RecurseCTE)(nil)
+
+// RecurseCTE evaluates a recursive common table expression.
+// The Seed is executed once, and then the Term is executed once per iteration,
+// with the values of the columns of the rows produced by the previous iteration
+// bound to Vars as lists, until an iteration does not produce any new row.
+// The Term does not read the common table expression: each row produced by the
+// previous iteration is joined with the rows of the Term that match the Predicate,
+// and the new rows are computed from the joined rows by the Exprs.
+type RecurseCTE struct {
+	// Seed is the non-recursive part of the common table expression.
+	Seed Primitive
+	// Term is the recursive part of the common table expression,
+	// without the reference to the common table expression.
+	Term Primitive
+
+	// Vars are the list bind variables used by the Term, and the columns
+	// of the rows of the previous iteration whose values they hold.
+	Vars map[string]int
+
+	// Predicate is evaluated on a row of the previous iteration followed
+	// by a row of the Term, and tells if they are joined. When it is nil,
+	// all the rows of the Term are joined with all the rows.
+	Predicate    evalengine.Expr
+	ASTPredicate sqlparser.Expr
+
+	// Exprs compute the columns of a new row, from a row of the previous
+	// iteration followed by a row of the Term that is joined with it.
+	Exprs []evalengine.Expr
+
+	// Columns is the number of columns of the common table expression.
+	// The Seed can return more columns than that, which are dropped
+	// from the result.
+	Columns int
+
+	// Distinct is true when the seed and the recursive part are combined
+	// with UNION DISTINCT, in which case rows that have already been
+	// produced are discarded.
+	Distinct bool
+}
+
+// RouteType implements the Primitive interface
+func (r *RecurseCTE) RouteType() string {
+	return "RecurseCTE"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (r *RecurseCTE) GetKeyspaceName() string {
+	if r.Seed.GetKeyspaceName() == r.Term.GetKeyspaceName() {
+		return r.Seed.GetKeyspaceName()
+	}
+	return r.Seed.GetKeyspaceName() + "_" + r.Term.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (r *RecurseCTE) GetTableName() string {
+	return r.Seed.GetTableName()
+}
+
+// TryExecute implements the Primitive interface
+func (r *RecurseCTE) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{}
+	err := r.recurse(ctx, vcursor, bindVars, func(res *sqltypes.Result) error {
+		if res.Fields != nil {
+			result.Fields = res.Fields
+		}
+		result.Rows = append(result.Rows, res.Rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !wantfields {
+		result.Fields = nil
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (r *RecurseCTE) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	return r.recurse(ctx, vcursor, bindVars, func(res *sqltypes.Result) error {
+		if !wantfields {
+			res.Fields = nil
+		}
+		return callback(res)
+	})
+}
+
+// recurse runs the seed and the iterations of the recursive part, and
+// sends the new rows of each of them to the callback
+func (r *RecurseCTE) recurse(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
+	res, err := vcursor.ExecutePrimitive(ctx, r.Seed, bindVars, true)
+	if err != nil {
+		return err
+	}
+	fields := r.truncateFields(res.Fields)
+
+	var seen *probeTable
+	if r.Distinct {
+		seen = newProbeTable(checkColsForFields(fields))
+	}
+	current, err := r.newRows(seen, res.Rows)
+	if err != nil {
+		return err
+	}
+	if err := callback(&sqltypes.Result{Fields: fields, Rows: current}); err != nil {
+		return err
+	}
+
+	maxDepth := cteMaxRecursionDepth(vcursor)
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	for iteration := 1; len(current) > 0; iteration++ {
+		if iteration > maxDepth {
+			return vterrors.VT09017(iteration)
+		}
+
+		res, err := vcursor.ExecutePrimitive(ctx, r.Term, combineVars(bindVars, r.listVars(current)), false)
+		if err != nil {
+			return err
+		}
+		joined, err := r.join(env, vcursor, current, res.Rows)
+		if err != nil {
+			return err
+		}
+		next, err := r.newRows(seen, joined)
+		if err != nil {
+			return err
+		}
+
+		if len(next) > 0 {
+			if err := callback(&sqltypes.Result{Rows: next}); err != nil {
+				return err
+			}
+		}
+		current = next
+	}
+	return nil
+}
+
+// listVars binds the values of the columns of the rows of the previous iteration as lists
+func (r *RecurseCTE) listVars(rows []sqltypes.Row) map[string]*querypb.BindVariable {
+	vars := make(map[string]*querypb.BindVariable, len(r.Vars))
+	for name, col := range r.Vars {
+		bv := &querypb.BindVariable{Type: querypb.Type_TUPLE}
+		for _, row := range rows {
+			bv.Values = append(bv.Values, sqltypes.ValueToProto(row[col]))
+		}
+		vars[name] = bv
+	}
+	return vars
+}
+
+// join joins the rows of the previous iteration with the rows of the Term,
+// and computes the new rows from the joined ones
+func (r *RecurseCTE) join(env *evalengine.ExpressionEnv, vcursor VCursor, rows, termRows []sqltypes.Row) ([]sqltypes.Row, error) {
+	var out []sqltypes.Row
+	for _, row := range rows {
+		for _, termRow := range termRows {
+			env.Row = append(append(env.Row[:0], row...), termRow...)
+			if r.Predicate != nil {
+				res, err := env.Evaluate(r.Predicate)
+				if err != nil {
+					return nil, err
+				}
+				if !res.ToBoolean() {
+					continue
+				}
+			}
+			newRow := make(sqltypes.Row, 0, len(r.Exprs))
+			for _, expr := range r.Exprs {
+				res, err := env.Evaluate(expr)
+				if err != nil {
+					return nil, err
+				}
+				newRow = append(newRow, res.Value(vcursor.ConnCollation()))
+			}
+			out = append(out, newRow)
+		}
+	}
+	return out, nil
+}
+
+// newRows truncates the rows to the columns of the common table expression,
+// and drops the ones that have already been produced if needed
+func (r *RecurseCTE) newRows(seen *probeTable, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	out := make([]sqltypes.Row, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > r.Columns {
+			row = row[:r.Columns]
+		}
+		if seen != nil {
+			exists, err := seen.exists(row)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+func (r *RecurseCTE) truncateFields(fields []*querypb.Field) []*querypb.Field {
+	if len(fields) > r.Columns {
+		return fields[:r.Columns]
+	}
+	return fields
+}
+
+func checkColsForFields(fields []*querypb.Field) []CheckCol {
+	cols := make([]CheckCol, len(fields))
+	for i, field := range fields {
+		cols[i] = CheckCol{
+			Col:       i,
+			Type:      field.Type,
+			Collation: collations.ID(field.Charset),
+		}
+	}
+	return cols
+}
+
+// cteMaxRecursionDepth returns the value of cte_max_recursion_depth for the
+// session, or the MySQL default if it has not been changed
+func cteMaxRecursionDepth(vcursor VCursor) int {
+	depth := DefaultCTEMaxRecursionDepth
+	if !vcursor.Session().HasSystemVariables() {
+		return depth
+	}
+	vcursor.Session().GetSystemVariables(func(k string, v string) {
+		if k != "cte_max_recursion_depth" {
+			return
+		}
+		if n, err := strconv.Atoi(v); err == nil {
+			depth = n
+		}
+	})
+	return depth
+}
+
+// GetFields implements the Primitive interface
+func (r *RecurseCTE) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	res, err := r.Seed.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: r.truncateFields(res.Fields)}, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (r *RecurseCTE) NeedsTransaction() bool {
+	return r.Seed.NeedsTransaction() || r.Term.NeedsTransaction()
+}
+
+// Inputs implements the Primitive interface
+func (r *RecurseCTE) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{r.Seed, r.Term}, []map[string]any{{
+		inputName: "Seed",
+	}, {
+		inputName: "Term",
+	}}
+}
+
+func (r *RecurseCTE) description() PrimitiveDescription {
+	other := map[string]any{
+		"Columns": r.Columns,
+	}
+	if len(r.Vars) > 0 {
+		other["ListVars"] = orderedStringIntMap(r.Vars)
+	}
+	if r.ASTPredicate != nil {
+		other["Predicate"] = sqlparser.String(r.ASTPredicate)
+	}
+	var exprs []string
+	for _, expr := range r.Exprs {
+		exprs = append(exprs, evalengine.FormatExpr(expr))
+	}
+	other["Expressions"] = exprs
+	if r.Distinct {
+		other["Distinct"] = true
+	}
+	return PrimitiveDescription{
+		OperatorType: "RecurseCTE",
+		Other:        other,
+	}
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var (
+	recurseCTEFields     = sqltypes.MakeTestFields("id|name|depth", "int64|varchar|int64")
+	recurseCTETermFields = sqltypes.MakeTestFields("id|name|parent", "int64|varchar|int64")
+)
+
+// translateRecurseCTEExpr translates an expression on a row of the common table expression
+// followed by a row of the term, whose columns are named by cols
+func translateRecurseCTEExpr(t *testing.T, expr string, cols ...string) evalengine.Expr {
+	t.Helper()
+	ast, err := sqlparser.ParseExpr(expr)
+	require.NoError(t, err)
+	e, err := evalengine.Translate(ast, &evalengine.Config{
+		ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+			return slices.Index(cols, col.Name.String()), nil
+		},
+	})
+	require.NoError(t, err)
+	return e
+}
+
+func newTestRecurseCTE(t *testing.T) (*RecurseCTE, *fakePrimitive, *fakePrimitive) {
+	cols := []string{"cte_id", "cte_name", "cte_depth", "id", "name", "parent"}
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(recurseCTEFields, "1|a|0")},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(recurseCTETermFields, "2|b|1", "3|c|1", "5|e|9"),
+			sqltypes.MakeTestResult(recurseCTETermFields, "4|d|2"),
+			sqltypes.MakeTestResult(recurseCTETermFields),
+			sqltypes.MakeTestResult(recurseCTETermFields),
+		},
+	}
+	return &RecurseCTE{
+		Seed:      seed,
+		Term:      term,
+		Vars:      map[string]int{"tree_id": 0},
+		Predicate: translateRecurseCTEExpr(t, "parent = cte_id", cols...),
+		Exprs: []evalengine.Expr{
+			translateRecurseCTEExpr(t, "id", cols...),
+			translateRecurseCTEExpr(t, "name", cols...),
+			translateRecurseCTEExpr(t, "cte_depth + 1", cols...),
+		},
+		Columns: 3,
+	}, seed, term
+}
+
+func TestRecurseCTEExecute(t *testing.T) {
+	cte, _, term := newTestRecurseCTE(t)
+
+	result, err := cte.TryExecute(context.Background(), &loggingVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(recurseCTEFields,
+		"1|a|0",
+		"2|b|1",
+		"3|c|1",
+		"4|d|2",
+	)
+	utils.MustMatch(t, want, result)
+	// the term is executed once per iteration, with the rows of the previous iteration
+	term.ExpectLog(t, []string{
+		`Execute tree_id: type:TUPLE values:{type:INT64 value:"1"} false`,
+		`Execute tree_id: type:TUPLE values:{type:INT64 value:"2"} values:{type:INT64 value:"3"} false`,
+		`Execute tree_id: type:TUPLE values:{type:INT64 value:"4"} false`,
+	})
+}
+
+func TestRecurseCTEStreamExecute(t *testing.T) {
+	cte, _, _ := newTestRecurseCTE(t)
+
+	result, err := wrapStreamExecute(cte, &loggingVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(recurseCTEFields,
+		"1|a|0",
+		"2|b|1",
+		"3|c|1",
+		"4|d|2",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestRecurseCTEDistinct(t *testing.T) {
+	fields := sqltypes.MakeTestFields("id|weight_string(id)", "int64|varbinary")
+	termFields := sqltypes.MakeTestFields("id|parent", "int64|int64")
+	cte := &RecurseCTE{
+		Seed: &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(fields, "1|x")},
+		},
+		Term: &fakePrimitive{
+			// the graph has a cycle, which UNION DISTINCT stops
+			results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(termFields, "2|1"),
+				sqltypes.MakeTestResult(termFields, "1|2"),
+			},
+		},
+		Vars:      map[string]int{"graph_id": 0},
+		Predicate: translateRecurseCTEExpr(t, "parent = cte_id", "cte_id", "id", "parent"),
+		Exprs:     []evalengine.Expr{translateRecurseCTEExpr(t, "id", "cte_id", "id", "parent")},
+		Columns:   1,
+		Distinct:  true,
+	}
+
+	result, err := cte.TryExecute(context.Background(), &loggingVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2")
+	utils.MustMatch(t, want, result)
+}
+
+func TestRecurseCTEMaxRecursionDepth(t *testing.T) {
+	cte, _, _ := newTestRecurseCTE(t)
+
+	vc := &loggingVCursor{systemVariables: map[string]string{"cte_max_recursion_depth": "1"}}
+	_, err := cte.TryExecute(context.Background(), vc, nil, true)
+	require.EqualError(t, err, "VT09017: Recursive query aborted after 2 iterations. Try increasing @@cte_max_recursion_depth to a larger value.")
+
+	cte, _, _ = newTestRecurseCTE(t)
+	vc = &loggingVCursor{systemVariables: map[string]string{"cte_max_recursion_depth": "3"}}
+	result, err := cte.TryExecute(context.Background(), vc, nil, false)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 4)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+var _ Primitive = (*Replace)(nil)
+
+// ReplaceRow contains the primitives used to replace a single row.
+type ReplaceRow struct {
+	// Delete deletes the existing rows with the same values as the row for one of the unique keys.
+	// It is nil when the row can't conflict with an existing row.
+	Delete Primitive
+	// Insert inserts the row.
+	Insert Primitive
+}
+
+// Replace is a primitive that executes a REPLACE on a sharded table with owned vindexes row by row.
+// For each row, the Delete primitive deletes the existing rows with the same values for one of
+// the unique keys, which maintains the owned vindexes of the replaced rows, and the Insert primitive
+// then inserts the new row. All the primitives are executed in the same transaction.
+type Replace struct {
+	Replaces []*ReplaceRow
+
+	txNeeded
+}
+
+// RouteType implements the Primitive interface.
+func (r *Replace) RouteType() string {
+	return "Replace"
+}
+
+// GetKeyspaceName implements the Primitive interface.
+func (r *Replace) GetKeyspaceName() string {
+	return r.Replaces[0].Insert.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface.
+func (r *Replace) GetTableName() string {
+	return r.Replaces[0].Insert.GetTableName()
+}
+
+// GetFields implements the Primitive interface.
+func (r *Replace) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] GetFields should not be called")
+}
+
+// TryExecute implements the Primitive interface.
+func (r *Replace) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{}
+	for _, row := range r.Replaces {
+		// As in MySQL, the affected rows are the sum of the deleted and inserted rows.
+		if row.Delete != nil {
+			delQr, err := vcursor.ExecutePrimitive(ctx, row.Delete, bindVars, false)
+			if err != nil {
+				return nil, err
+			}
+			result.RowsAffected += delQr.RowsAffected
+		}
+		insQr, err := vcursor.ExecutePrimitive(ctx, row.Insert, bindVars, false)
+		if err != nil {
+			return nil, err
+		}
+		result.RowsAffected += insQr.RowsAffected
+		if result.InsertID == 0 {
+			result.InsertID = insQr.InsertID
+		}
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface.
+func (r *Replace) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := r.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// Inputs implements the Primitive interface.
+func (r *Replace) Inputs() ([]Primitive, []map[string]any) {
+	var inputs []Primitive
+	var inputsMap []map[string]any
+	for idx, row := range r.Replaces {
+		if row.Delete != nil {
+			inputs = append(inputs, row.Delete)
+			inputsMap = append(inputsMap, map[string]any{inputName: fmt.Sprintf("Delete-%d", idx+1)})
+		}
+		inputs = append(inputs, row.Insert)
+		inputsMap = append(inputsMap, map[string]any{inputName: fmt.Sprintf("Insert-%d", idx+1)})
+	}
+	return inputs, inputsMap
+}
+
+func (r *Replace) description() PrimitiveDescription {
+	return PrimitiveDescription{OperatorType: r.RouteType()}
+}
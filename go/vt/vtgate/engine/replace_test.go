@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// TestReplace tests that each row is inserted after the delete of the rows it replaces,
+// and that the affected rows of both are reported.
+func TestReplace(t *testing.T) {
+	del1 := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	ins1 := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	// the second row can't conflict with an existing row.
+	ins2 := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1, InsertID: 42}}}
+	replace := &Replace{Replaces: []*ReplaceRow{
+		{Delete: del1, Insert: ins1},
+		{Insert: ins2},
+	}}
+
+	res, err := wrapStreamExecute(replace, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, res.RowsAffected)
+	assert.EqualValues(t, 42, res.InsertID)
+	del1.ExpectLog(t, []string{`Execute  false`})
+	ins1.ExpectLog(t, []string{`Execute  false`})
+	ins2.ExpectLog(t, []string{`Execute  false`})
+}
+
+// TestReplaceDeleteError tests that the rows are not inserted when a delete fails.
+func TestReplaceDeleteError(t *testing.T) {
+	ins := &fakePrimitive{}
+	replace := &Replace{Replaces: []*ReplaceRow{{
+		Delete: &fakePrimitive{sendErr: errors.New("delete failed")},
+		Insert: ins,
+	}}}
+
+	_, err := replace.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "delete failed")
+	ins.ExpectLog(t, nil)
+}
@@ -715,6 +715,90 @@ func TestSelectLike(t *testing.T) {
 
 }
 
+func TestSelectRange(t *testing.T) {
+	vindex, err := vindexes.CreateVindex("range_map", "", map[string]string{
+		"json": `[{"from": "0", "keyspace_id": "10"}, {"from": "100", "keyspace_id": "50"}, {"from": "200", "keyspace_id": "90"}]`,
+	})
+	require.NoError(t, err)
+	sel := NewRoute(
+		Range,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.NewLiteralInt(50),
+		evalengine.NewLiteralInt(150),
+	}
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-60", "60-"},
+		shardForKsid: []string{"-20", "20-60"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	result, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyspaceID(10),DestinationKeyspaceID(50)`,
+		`ExecuteMultiShard ks.-20: dummy_select {} ks.20-60: dummy_select {} false false`,
+	})
+	expectResult(t, "sel.Execute", result, defaultSelectResult)
+
+	vc.Rewind()
+	result, err = wrapStreamExecute(sel, vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyspaceID(10),DestinationKeyspaceID(50)`,
+		`StreamExecuteMulti dummy_select ks.-20: {} ks.20-60: {} `,
+	})
+	expectResult(t, "sel.StreamExecute", result, defaultSelectResult)
+}
+
+func TestSelectRangeBinary(t *testing.T) {
+	vindex, _ := vindexes.CreateVindex("binary", "", nil)
+	sel := NewRoute(
+		Range,
+		&vindexes.Keyspace{
+			Name:    "ks",
+			Sharded: true,
+		},
+		"dummy_select",
+		"dummy_select_field",
+	)
+	sel.Vindex = vindex.(vindexes.SingleColumn)
+	sel.Values = []evalengine.Expr{
+		evalengine.NewLiteralString([]byte("\x10"), collations.SystemCollation),
+		evalengine.NewLiteralString([]byte("\x20"), collations.SystemCollation),
+	}
+
+	// The range is mapped to a key range on a column of the binary collation.
+	sel.Collation = collations.CollationBinaryID
+	vc := &loggingVCursor{
+		shards:       []string{"-20", "20-"},
+		shardForKsid: []string{"-20"},
+		results:      []*sqltypes.Result{defaultSelectResult},
+	}
+	_, err := sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationKeyRange(10-2000)`,
+		`ExecuteMultiShard ks.-20: dummy_select {} false false`,
+	})
+
+	// Other collations don't compare the values byte-wise, so all the shards are targeted.
+	sel.Collation = collations.CollationUtf8mb4ID
+	vc.Rewind()
+	_, err = sel.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ks.-20: dummy_select {} ks.20-: dummy_select {} false false`,
+	})
+}
+
 func TestSelectNext(t *testing.T) {
 	sel := NewRoute(
 		Next,
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"strconv"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -56,6 +57,9 @@ const (
 	MultiEqual
 	// SubShard is for when we are missing one or more columns from a composite vindex
 	SubShard
+	// Range is for routing a query using a vindex that can map a range of values.
+	// Requires: A RangeMapper Vindex, and two Values: the lower and upper bounds.
+	Range
 	// Scatter is for routing a scattered statement.
 	Scatter
 	// Next is for fetching from a sequence.
@@ -86,6 +90,7 @@ var opName = map[Opcode]string{
 	None:          "None",
 	ByDestination: "ByDestination",
 	SubShard:      "SubShard",
+	Range:         "Range",
 }
 
 // MarshalJSON serializes the Opcode as a JSON string.
@@ -119,6 +124,10 @@ type RoutingParameters struct {
 
 	// Values specifies the vindex values to use for routing.
 	Values []evalengine.Expr
+
+	// Type and Collation are the type and collation of the vindex column, for the Range opcode.
+	Type      sqltypes.Type
+	Collation collations.ID
 }
 
 func (code Opcode) IsSingleShard() bool {
@@ -164,6 +173,8 @@ func (rp *RoutingParameters) findRoute(ctx context.Context, vcursor VCursor, bin
 		default:
 			return rp.multiEqual(ctx, vcursor, bindVars)
 		}
+	case Range:
+		return rp.rangeValues(ctx, vcursor, bindVars)
 	default:
 		// Unreachable.
 		return nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unsupported opcode: %v", rp.Opcode)
@@ -349,6 +360,31 @@ func (rp *RoutingParameters) equal(ctx context.Context, vcursor VCursor, bindVar
 	return rss, multiBindVars, nil
 }
 
+func (rp *RoutingParameters) rangeValues(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	var bounds [2]sqltypes.Value
+	for i := range bounds {
+		value, err := env.Evaluate(rp.Values[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		bounds[i] = value.Value(vcursor.ConnCollation())
+	}
+	destinations, err := rp.Vindex.(vindexes.RangeMapper).RangeMap(ctx, vcursor, bounds[0], bounds[1], rp.Type, rp.Collation)
+	if err != nil {
+		return nil, nil, err
+	}
+	rss, _, err := vcursor.ResolveDestinations(ctx, rp.Keyspace.Name, nil, destinations)
+	if err != nil {
+		return nil, nil, err
+	}
+	multiBindVars := make([]map[string]*querypb.BindVariable, len(rss))
+	for i := range multiBindVars {
+		multiBindVars[i] = bindVars
+	}
+	return rss, multiBindVars, nil
+}
+
 func (rp *RoutingParameters) equalMultiCol(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]*srvtopo.ResolvedShard, []map[string]*querypb.BindVariable, error) {
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 	var rowValue []sqltypes.Value
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
@@ -295,6 +296,49 @@ func TestScalarDistinctAggrOnEngine(t *testing.T) {
 	require.Equal(t, `[[INT64(4) DECIMAL(1300)]]`, fmt.Sprintf("%v", results.Rows))
 }
 
+func TestScalarHashDistinctAggrOnEngine(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"a|b",
+		"int64|varchar",
+	)
+
+	fp := &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(
+		fields,
+		"100|x",
+		"200|y",
+		"100|X",
+		"null|x",
+		"300|null",
+		"200|z",
+	)}}
+
+	countA := NewAggregateParam(AggregateCountDistinct, 0, "count(distinct a)")
+	countA.HashDistinct = true
+	countB := NewAggregateParam(AggregateCountDistinct, 1, "count(distinct b)")
+	countB.HashDistinct = true
+	countB.Type = sqltypes.VarChar
+	countB.CollationID, _ = collations.Local().LookupID("utf8mb4_0900_ai_ci")
+	oa := &ScalarAggregate{
+		Aggregates: []*AggregateParams{countA, countB},
+		Input:      fp,
+	}
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	require.Equal(t, `[[INT64(3) INT64(3)]]`, fmt.Sprintf("%v", qr.Rows))
+
+	fp.rewind()
+	results := &sqltypes.Result{}
+	err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			results.Fields = qr.Fields
+		}
+		results.Rows = append(results.Rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, `[[INT64(3) INT64(3)]]`, fmt.Sprintf("%v", results.Rows))
+}
+
 func TestScalarDistinctPushedDown(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"count(distinct value)|sum(distinct value)",
@@ -45,6 +45,11 @@ type SemiJoin struct {
 	// be built from the LHS result before invoking
 	// the RHS subqquery.
 	Vars map[string]int `json:",omitempty"`
+
+	// Anti makes the SemiJoin return the rows from the left
+	// for which the right side does not return any rows.
+	// It is used for correlated NOT EXISTS and NOT IN subqueries.
+	Anti bool `json:",omitempty"`
 }
 
 // TryExecute performs a non-streaming exec.
@@ -63,7 +68,7 @@ func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 		if err != nil {
 			return nil, err
 		}
-		if len(rresult.Rows) > 0 {
+		if (len(rresult.Rows) > 0) != jn.Anti {
 			result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
 		}
 	}
@@ -79,17 +84,19 @@ func (jn *SemiJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 			for k, col := range jn.Vars {
 				joinVars[k] = sqltypes.ValueBindVariable(lrow[col])
 			}
-			rowAdded := false
+			rhsHasRows := false
 			err := vcursor.StreamExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false, func(rresult *sqltypes.Result) error {
-				if len(rresult.Rows) > 0 && !rowAdded {
-					result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
-					rowAdded = true
+				if len(rresult.Rows) > 0 {
+					rhsHasRows = true
 				}
 				return nil
 			})
 			if err != nil {
 				return err
 			}
+			if rhsHasRows != jn.Anti {
+				result.Rows = append(result.Rows, projectRows(lrow, jn.Cols))
+			}
 		}
 		return callback(result)
 	})
@@ -141,6 +148,9 @@ func (jn *SemiJoin) description() PrimitiveDescription {
 	if len(jn.Vars) > 0 {
 		other["JoinVars"] = orderedStringIntMap(jn.Vars)
 	}
+	if jn.Anti {
+		other["Anti"] = true
+	}
 	return PrimitiveDescription{
 		OperatorType: "SemiJoin",
 		Other:        other,
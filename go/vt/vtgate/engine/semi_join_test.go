@@ -161,3 +161,48 @@ func TestSemiJoinStreamExecute(t *testing.T) {
 		"4|d|dd",
 	))
 }
+
+func TestAntiSemiJoinExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"col1|col2",
+		"int64|varchar",
+	)
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				fields,
+				"1|a",
+				"2|b",
+				"3|c",
+			),
+		},
+	}
+	rightFields := sqltypes.MakeTestFields(
+		"col3",
+		"int64",
+	)
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(rightFields, "4"),
+			sqltypes.MakeTestResult(rightFields),
+			sqltypes.MakeTestResult(rightFields, "5", "6"),
+		},
+	}
+
+	jn := &SemiJoin{
+		Left:  leftPrim,
+		Right: rightPrim,
+		Vars: map[string]int{
+			"bv": 0,
+		},
+		Anti: true,
+	}
+	r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	rightPrim.ExpectLog(t, []string{
+		`Execute bv: type:INT64 value:"1" false`,
+		`Execute bv: type:INT64 value:"2" false`,
+		`Execute bv: type:INT64 value:"3" false`,
+	})
+	utils.MustMatch(t, sqltypes.MakeTestResult(fields, "2|b"), r)
+}
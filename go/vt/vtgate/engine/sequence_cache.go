@@ -0,0 +1,161 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sync"
+
+	"vitess.io/vitess/go/stats"
+)
+
+var (
+	sequenceCacheReservations = stats.NewCountersWithSingleLabel(
+		"SequenceCacheReservations",
+		"Number of blocks of values reserved by the sequence cache from the sequence tablets",
+		"Sequence")
+	sequenceCacheRemaining = stats.NewGaugesWithSingleLabel(
+		"SequenceCacheRemainingValues",
+		"Number of values left in the block reserved by the sequence cache",
+		"Sequence")
+	sequenceCacheDiscarded = stats.NewCountersWithSingleLabel(
+		"SequenceCacheDiscardedValues",
+		"Number of reserved values discarded by the sequence cache because an insert needed more consecutive values than were left, or the cache was cleared",
+		"Sequence")
+)
+
+// SequenceCache reserves blocks of values of the sequences used to generate
+// auto-increment values, and serves the inserts from memory. The sequence
+// tablet is only hit once per block instead of once per insert.
+//
+// The values of a block are persisted by the sequence tablet before they are
+// returned, so the blocks held by a SequenceCache stay valid when the sequence
+// shard is reparented without losing transactions: the new primary only hands
+// out values past them. They are not valid anymore if the sequence table is
+// reset or rewound, like when MoveTables initializes the sequences of the tables
+// it moves, or when a failover loses the last updates of the sequence table.
+// The blocks are discarded with Clear in that case.
+type SequenceCache struct {
+	blockSize int64
+
+	mu        sync.Mutex
+	sequences map[string]*cachedSequence
+}
+
+// cachedSequence is the block of values reserved for a sequence.
+// The values from next up to end, excluded, are available.
+// A cleared cachedSequence was discarded by Clear, and must not be used anymore.
+type cachedSequence struct {
+	mu        sync.Mutex
+	next, end int64
+	cleared   bool
+}
+
+// NewSequenceCache creates a SequenceCache that reserves blockSize values at once.
+func NewSequenceCache(blockSize int64) *SequenceCache {
+	return &SequenceCache{
+		blockSize: blockSize,
+		sequences: make(map[string]*cachedSequence),
+	}
+}
+
+// Next returns the first of count consecutive values of the sequence.
+// When the cached block doesn't have count values left, reserve is called
+// to reserve a new block of n values from the sequence tablet, and must return
+// its first value. The values left in the previous block are discarded, as the
+// values of an insert must be consecutive. The concurrent calls for a sequence
+// wait for the reservation, instead of each hitting the sequence tablet.
+func (sc *SequenceCache) Next(sequence string, count int64, reserve func(n int64) (int64, error)) (int64, error) {
+	seq := sc.lock(sequence)
+	defer seq.mu.Unlock()
+
+	if left := seq.end - seq.next; left < count {
+		n := max(count, sc.blockSize)
+		first, err := reserve(n)
+		if err != nil {
+			return 0, err
+		}
+		sequenceCacheReservations.Add(sequence, 1)
+		sequenceCacheDiscarded.Add(sequence, left)
+		seq.next, seq.end = first, first+n
+	}
+
+	first := seq.next
+	seq.next += count
+	sequenceCacheRemaining.Set(sequence, seq.end-seq.next)
+	return first, nil
+}
+
+// Remaining returns the number of values left in the block reserved for the sequence.
+func (sc *SequenceCache) Remaining(sequence string) int64 {
+	sc.mu.Lock()
+	seq, ok := sc.sequences[sequence]
+	sc.mu.Unlock()
+	if !ok {
+		return 0
+	}
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+	return seq.end - seq.next
+}
+
+// Clear discards the blocks reserved for the given sequences, so that their
+// next values are reserved anew from the sequence tablets.
+func (sc *SequenceCache) Clear(sequences []string) {
+	sc.mu.Lock()
+	cleared := make(map[string]*cachedSequence, len(sequences))
+	for _, sequence := range sequences {
+		if seq, ok := sc.sequences[sequence]; ok {
+			cleared[sequence] = seq
+			delete(sc.sequences, sequence)
+		}
+	}
+	sc.mu.Unlock()
+
+	// the concurrent calls which already got a block must not use it anymore
+	for sequence, seq := range cleared {
+		seq.mu.Lock()
+		sequenceCacheDiscarded.Add(sequence, seq.end-seq.next)
+		sequenceCacheRemaining.Set(sequence, 0)
+		seq.next, seq.end = 0, 0
+		seq.cleared = true
+		seq.mu.Unlock()
+	}
+}
+
+// lock returns the locked block of the sequence. The blocks cleared while
+// waiting for their lock are skipped, so that their values are reserved anew.
+func (sc *SequenceCache) lock(sequence string) *cachedSequence {
+	for {
+		seq := sc.get(sequence)
+		seq.mu.Lock()
+		if !seq.cleared {
+			return seq
+		}
+		seq.mu.Unlock()
+	}
+}
+
+func (sc *SequenceCache) get(sequence string) *cachedSequence {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	seq, ok := sc.sequences[sequence]
+	if !ok {
+		seq = &cachedSequence{}
+		sc.sequences[sequence] = seq
+	}
+	return seq
+}
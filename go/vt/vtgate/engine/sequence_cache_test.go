@@ -0,0 +1,169 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSequence hands out the values of a sequence like the sequence tablet does.
+type fakeSequence struct {
+	mu           sync.Mutex
+	next         int64
+	reservations []int64
+	err          error
+}
+
+func (fs *fakeSequence) reserve(n int64) (int64, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.err != nil {
+		return 0, fs.err
+	}
+	fs.reservations = append(fs.reservations, n)
+	first := fs.next
+	fs.next += n
+	return first, nil
+}
+
+func TestSequenceCacheNext(t *testing.T) {
+	sc := NewSequenceCache(10)
+	seq := &fakeSequence{next: 1}
+
+	first, err := sc.Next("ks.seq", 3, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, first)
+	assert.EqualValues(t, 7, sc.Remaining("ks.seq"))
+
+	first, err = sc.Next("ks.seq", 7, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, first)
+	assert.EqualValues(t, 0, sc.Remaining("ks.seq"))
+	assert.Equal(t, []int64{10}, seq.reservations)
+
+	// an insert needing more values than a block reserves them all at once
+	first, err = sc.Next("ks.seq", 15, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, first)
+	assert.Equal(t, []int64{10, 15}, seq.reservations)
+
+	// the values left in a block are discarded when an insert needs more consecutive values
+	_, err = sc.Next("ks.seq", 2, seq.reserve)
+	require.NoError(t, err)
+	first, err = sc.Next("ks.seq", 9, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 36, first)
+	assert.EqualValues(t, 1, sc.Remaining("ks.seq"))
+	assert.Equal(t, []int64{10, 15, 10, 10}, seq.reservations)
+
+	// sequences are cached separately
+	other := &fakeSequence{next: 100}
+	first, err = sc.Next("ks.other", 1, other.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, first)
+	assert.EqualValues(t, 1, sc.Remaining("ks.seq"))
+
+	// the sequences never used have no block
+	assert.EqualValues(t, 0, sc.Remaining("ks.unused"))
+	assert.NotContains(t, sc.sequences, "ks.unused")
+}
+
+func TestSequenceCacheReserveError(t *testing.T) {
+	sc := NewSequenceCache(10)
+	seq := &fakeSequence{next: 1}
+
+	_, err := sc.Next("ks.seq", 8, seq.reserve)
+	require.NoError(t, err)
+
+	// a failed reservation, for instance while the sequence shard reparents, leaves the cache untouched
+	seq.err = errors.New("primary is not serving")
+	_, err = sc.Next("ks.seq", 5, seq.reserve)
+	require.EqualError(t, err, "primary is not serving")
+	assert.EqualValues(t, 2, sc.Remaining("ks.seq"))
+
+	first, err := sc.Next("ks.seq", 2, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, first)
+
+	seq.err = nil
+	first, err = sc.Next("ks.seq", 5, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 11, first)
+}
+
+func TestSequenceCacheClear(t *testing.T) {
+	sc := NewSequenceCache(10)
+	seq := &fakeSequence{next: 1}
+
+	_, err := sc.Next("ks.seq", 3, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, sc.Remaining("ks.seq"))
+	other := &fakeSequence{next: 1}
+	_, err = sc.Next("ks.other_seq", 1, other.reserve)
+	require.NoError(t, err)
+
+	// the sequence table is reset past the values already handed out
+	seq.next = 100
+	cleared := sc.get("ks.seq")
+	sc.Clear([]string{"ks.seq"})
+	assert.EqualValues(t, 0, sc.Remaining("ks.seq"))
+	assert.EqualValues(t, 9, sc.Remaining("ks.other_seq"))
+
+	first, err := sc.Next("ks.seq", 3, seq.reserve)
+	require.NoError(t, err)
+	assert.EqualValues(t, 100, first)
+	assert.Equal(t, []int64{10, 10}, seq.reservations)
+
+	// the calls which got the cleared block before it was discarded use the new one instead
+	assert.True(t, cleared.cleared)
+	block := sc.lock("ks.seq")
+	block.mu.Unlock()
+	assert.NotSame(t, cleared, block)
+	assert.EqualValues(t, 7, block.end-block.next)
+}
+
+func TestSequenceCacheConcurrency(t *testing.T) {
+	sc := NewSequenceCache(100)
+	seq := &fakeSequence{next: 1}
+
+	var mu sync.Mutex
+	seen := make(map[int64]bool)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				first, err := sc.Next("ks.seq", 3, seq.reserve)
+				assert.NoError(t, err)
+				mu.Lock()
+				for v := first; v < first+3; v++ {
+					assert.False(t, seen[v], "value %d generated twice", v)
+					seen[v] = true
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, seen, 3000)
+}
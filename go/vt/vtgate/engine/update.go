@@ -68,7 +68,7 @@ func (upd *Update) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 	switch upd.Opcode {
 	case Unsharded:
 		return upd.execUnsharded(ctx, upd, vcursor, bindVars, rss)
-	case Equal, EqualUnique, IN, Scatter, ByDestination, SubShard, MultiEqual:
+	case Equal, EqualUnique, IN, Scatter, ByDestination, SubShard, MultiEqual, Range:
 		return upd.execMultiDestination(ctx, upd, vcursor, bindVars, rss, upd.updateVindexEntries)
 	default:
 		// Unreachable.
@@ -0,0 +1,198 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"context"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ Primitive = (*UpdateMove)(nil)
+
+// UpdateMove is a primitive that executes an update of the primary vindex columns of a table,
+// by moving the updated rows to their new shard. The Input selects and locks the rows to update,
+// and returns the current values of the Columns of the table, followed by their updated values.
+// The keyspace ids of the current and updated values of each changed row are computed with KsidVindex,
+// from the Columns at the KsidColumns offsets. The rows keeping their keyspace id are updated in place
+// by the Update primitive. The other ones are deleted from their current shard by the Delete primitive,
+// and inserted into their new shard by the Insert primitive, which maintains the owned vindexes of the row.
+// The values of a row are bound to the Update, Delete and Insert primitives with the names returned by
+// UpdateMoveOldVarName and UpdateMoveNewVarName. All the primitives are executed in the same transaction.
+type UpdateMove struct {
+	Input  Primitive
+	Update Primitive
+	Delete Primitive
+	Insert Primitive
+
+	Columns []string
+
+	KsidVindex  vindexes.Vindex
+	KsidColumns []int
+
+	txNeeded
+}
+
+// UpdateMoveOldVarName returns the name of the bind variable holding the current value of a column of a moved row.
+func UpdateMoveOldVarName(col string) string {
+	return "mv_old_" + col
+}
+
+// UpdateMoveNewVarName returns the name of the bind variable holding the updated value of a column of a moved row.
+func UpdateMoveNewVarName(col string) string {
+	return "mv_new_" + col
+}
+
+// RouteType implements the Primitive interface.
+func (um *UpdateMove) RouteType() string {
+	return "UpdateMove"
+}
+
+// GetKeyspaceName implements the Primitive interface.
+func (um *UpdateMove) GetKeyspaceName() string {
+	return um.Insert.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface.
+func (um *UpdateMove) GetTableName() string {
+	return um.Insert.GetTableName()
+}
+
+// GetFields implements the Primitive interface.
+func (um *UpdateMove) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] GetFields should not be called")
+}
+
+// TryExecute implements the Primitive interface.
+func (um *UpdateMove) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	inputRes, err := vcursor.ExecutePrimitive(ctx, um.Input, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{}
+	for _, row := range inputRes.Rows {
+		if len(row) != 2*len(um.Columns) {
+			return nil, vterrors.VT13001("unexpected number of columns in the rows to move")
+		}
+		// As in MySQL, the rows left unchanged by the update are not affected.
+		if unchangedRow(row[:len(um.Columns)], row[len(um.Columns):]) {
+			continue
+		}
+
+		rowVars := make(map[string]*querypb.BindVariable, len(bindVars)+len(row))
+		for k, v := range bindVars {
+			rowVars[k] = v
+		}
+		for idx, col := range um.Columns {
+			rowVars[UpdateMoveOldVarName(col)] = sqltypes.ValueBindVariable(row[idx])
+			rowVars[UpdateMoveNewVarName(col)] = sqltypes.ValueBindVariable(row[len(um.Columns)+idx])
+		}
+
+		moved, err := um.keyspaceIDChanged(ctx, vcursor, row)
+		if err != nil {
+			return nil, err
+		}
+		if !moved {
+			updRes, err := vcursor.ExecutePrimitive(ctx, um.Update, rowVars, false)
+			if err != nil {
+				return nil, err
+			}
+			result.RowsAffected += updRes.RowsAffected
+			continue
+		}
+
+		delRes, err := vcursor.ExecutePrimitive(ctx, um.Delete, rowVars, false)
+		if err != nil {
+			return nil, err
+		}
+		// The row is only inserted into its new shard once it is known to be removed from its current one.
+		if delRes.RowsAffected != 1 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "expected to delete 1 row to move it, deleted %d", delRes.RowsAffected)
+		}
+		if _, err := vcursor.ExecutePrimitive(ctx, um.Insert, rowVars, false); err != nil {
+			return nil, err
+		}
+		result.RowsAffected++
+	}
+	return result, nil
+}
+
+// keyspaceIDChanged returns true unless the current and updated values of the row map to the same keyspace id.
+func (um *UpdateMove) keyspaceIDChanged(ctx context.Context, vcursor VCursor, row sqltypes.Row) (bool, error) {
+	oldValues := make([]sqltypes.Value, 0, len(um.KsidColumns))
+	newValues := make([]sqltypes.Value, 0, len(um.KsidColumns))
+	for _, offset := range um.KsidColumns {
+		oldValues = append(oldValues, row[offset])
+		newValues = append(newValues, row[len(um.Columns)+offset])
+	}
+	destinations, err := vindexes.Map(ctx, um.KsidVindex, vcursor, [][]sqltypes.Value{oldValues, newValues})
+	if err != nil {
+		return false, err
+	}
+	oldKsid, ok := destinations[0].(key.DestinationKeyspaceID)
+	if !ok {
+		return true, nil
+	}
+	newKsid, ok := destinations[1].(key.DestinationKeyspaceID)
+	return !ok || !bytes.Equal(oldKsid, newKsid), nil
+}
+
+func unchangedRow(oldValues, newValues []sqltypes.Value) bool {
+	for idx, oldValue := range oldValues {
+		newValue := newValues[idx]
+		if oldValue.IsNull() != newValue.IsNull() || !bytes.Equal(oldValue.Raw(), newValue.Raw()) {
+			return false
+		}
+	}
+	return true
+}
+
+// TryStreamExecute implements the Primitive interface.
+func (um *UpdateMove) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := um.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// Inputs implements the Primitive interface.
+func (um *UpdateMove) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{um.Input, um.Update, um.Delete, um.Insert}, []map[string]any{
+		{inputName: "Select"},
+		{inputName: "Update"},
+		{inputName: "Delete"},
+		{inputName: "Insert"},
+	}
+}
+
+func (um *UpdateMove) description() PrimitiveDescription {
+	return PrimitiveDescription{
+		OperatorType: um.RouteType(),
+		Other: map[string]any{
+			"Columns": um.Columns,
+			"Vindex":  um.KsidVindex.String(),
+		},
+	}
+}
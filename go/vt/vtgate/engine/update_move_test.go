@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// updateMoveTestVindex maps the ids lower than 100 and the other ones to two keyspace ids.
+func updateMoveTestVindex(t *testing.T) vindexes.Vindex {
+	vindex, err := vindexes.CreateVindex("range_map", "range_map", map[string]string{
+		"json": `[{"from": "0", "keyspace_id": "40"}, {"from": "100", "keyspace_id": "c0"}]`,
+	})
+	require.NoError(t, err)
+	return vindex
+}
+
+// TestUpdateMove tests that the rows changing keyspace id are deleted and inserted with their values bound,
+// that the rows keeping their keyspace id are updated in place, and that the unchanged rows are skipped.
+func TestUpdateMove(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("col|id|col|id", "varchar|int64|varchar|int64"),
+			"a|1|a|10",
+			"b|2|b|2",
+			"c|3|c|300",
+		),
+	}}
+	upd := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	del := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	ins := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+	um := &UpdateMove{
+		Input:       input,
+		Update:      upd,
+		Delete:      del,
+		Insert:      ins,
+		Columns:     []string{"col", "id"},
+		KsidVindex:  updateMoveTestVindex(t),
+		KsidColumns: []int{1},
+	}
+
+	res, err := wrapStreamExecute(um, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, res.RowsAffected)
+	input.ExpectLog(t, []string{`Execute  false`})
+	upd.ExpectLog(t, []string{
+		`Execute mv_new_col: type:VARCHAR value:"a" mv_new_id: type:INT64 value:"10" mv_old_col: type:VARCHAR value:"a" mv_old_id: type:INT64 value:"1" false`,
+	})
+	wantLog := []string{
+		`Execute mv_new_col: type:VARCHAR value:"c" mv_new_id: type:INT64 value:"300" mv_old_col: type:VARCHAR value:"c" mv_old_id: type:INT64 value:"3" false`,
+	}
+	del.ExpectLog(t, wantLog)
+	ins.ExpectLog(t, wantLog)
+}
+
+// TestUpdateMoveDeleteError tests that a row is not inserted when its delete fails.
+func TestUpdateMoveDeleteError(t *testing.T) {
+	ins := &fakePrimitive{}
+	um := &UpdateMove{
+		Input: &fakePrimitive{results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|id", "int64|int64"), "1|100"),
+		}},
+		Delete:      &fakePrimitive{sendErr: errors.New("delete failed")},
+		Insert:      ins,
+		Columns:     []string{"id"},
+		KsidVindex:  updateMoveTestVindex(t),
+		KsidColumns: []int{0},
+	}
+
+	_, err := um.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "delete failed")
+	ins.ExpectLog(t, nil)
+}
+
+// TestUpdateMoveDeleteRowsAffected tests that a row is not inserted when its delete doesn't remove exactly that row.
+func TestUpdateMoveDeleteRowsAffected(t *testing.T) {
+	for _, rowsAffected := range []uint64{0, 2} {
+		ins := &fakePrimitive{}
+		um := &UpdateMove{
+			Input: &fakePrimitive{results: []*sqltypes.Result{
+				sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|id", "int64|int64"), "1|100"),
+			}},
+			Delete:      &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: rowsAffected}}},
+			Insert:      ins,
+			Columns:     []string{"id"},
+			KsidVindex:  updateMoveTestVindex(t),
+			KsidColumns: []int{0},
+		}
+
+		_, err := um.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+		require.ErrorContains(t, err, fmt.Sprintf("expected to delete 1 row to move it, deleted %d", rowsAffected))
+		ins.ExpectLog(t, nil)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+var _ Primitive = (*Upsert)(nil)
+
+// UpsertRow contains the primitives used to upsert a single row.
+type UpsertRow struct {
+	// Select looks for the existing row with the same values for one of the unique keys, and locks it.
+	Select Primitive
+	// Insert inserts the row when it doesn't exist.
+	Insert Primitive
+	// Update updates the existing row.
+	Update Primitive
+}
+
+// Upsert is a primitive that executes an INSERT ... ON DUPLICATE KEY UPDATE row by row.
+// It is used when the update changes vindex columns, and so can't be sent to the shards
+// along with the insert: the changed vindexes have to be maintained by the Update primitive.
+// All the primitives are executed in the same transaction.
+type Upsert struct {
+	Upserts []*UpsertRow
+
+	txNeeded
+}
+
+// RouteType implements the Primitive interface.
+func (u *Upsert) RouteType() string {
+	return "Upsert"
+}
+
+// GetKeyspaceName implements the Primitive interface.
+func (u *Upsert) GetKeyspaceName() string {
+	return u.Upserts[0].Insert.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface.
+func (u *Upsert) GetTableName() string {
+	return u.Upserts[0].Insert.GetTableName()
+}
+
+// GetFields implements the Primitive interface.
+func (u *Upsert) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] GetFields should not be called")
+}
+
+// TryExecute implements the Primitive interface.
+func (u *Upsert) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{}
+	for _, row := range u.Upserts {
+		qr, err := row.execute(ctx, vcursor, bindVars)
+		if err != nil {
+			return nil, err
+		}
+		result.RowsAffected += qr.RowsAffected
+		if result.InsertID == 0 {
+			result.InsertID = qr.InsertID
+		}
+	}
+	return result, nil
+}
+
+func (r *UpsertRow) execute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	selQr, err := vcursor.ExecutePrimitive(ctx, r.Select, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+	switch len(selQr.Rows) {
+	case 0:
+		return vcursor.ExecutePrimitive(ctx, r.Insert, bindVars, false)
+	case 1:
+	default:
+		// MySQL only updates one of the existing rows, which isn't something we can reproduce.
+		return nil, vterrors.VT12001("ON DUPLICATE KEY UPDATE changing vindex columns of a row conflicting with several existing rows")
+	}
+
+	updQr, err := vcursor.ExecutePrimitive(ctx, r.Update, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+	// As in MySQL, an updated row counts as 2 affected rows,
+	// and a row left unchanged as 0.
+	if updQr.RowsAffected > 0 {
+		updQr.RowsAffected = 2
+	}
+	return updQr, nil
+}
+
+// TryStreamExecute implements the Primitive interface.
+func (u *Upsert) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := u.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// Inputs implements the Primitive interface.
+func (u *Upsert) Inputs() ([]Primitive, []map[string]any) {
+	var inputs []Primitive
+	var inputsMap []map[string]any
+	for idx, row := range u.Upserts {
+		inputs = append(inputs, row.Select, row.Insert, row.Update)
+		inputsMap = append(inputsMap,
+			map[string]any{inputName: fmt.Sprintf("Select-%d", idx+1)},
+			map[string]any{inputName: fmt.Sprintf("Insert-%d", idx+1)},
+			map[string]any{inputName: fmt.Sprintf("Update-%d", idx+1)},
+		)
+	}
+	return inputs, inputsMap
+}
+
+func (u *Upsert) description() PrimitiveDescription {
+	return PrimitiveDescription{OperatorType: u.RouteType()}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// TestUpsert tests that each row is inserted when it doesn't exist, and updated otherwise.
+func TestUpsert(t *testing.T) {
+	fields := sqltypes.MakeTestFields("1", "int64")
+	// the first row doesn't exist, the second one does.
+	sel1 := &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(fields)}}
+	ins1 := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1, InsertID: 42}}}
+	upd1 := &fakePrimitive{}
+	sel2 := &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(fields, "1")}}
+	ins2 := &fakePrimitive{}
+	upd2 := &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 1}}}
+
+	upsert := &Upsert{Upserts: []*UpsertRow{
+		{Select: sel1, Insert: ins1, Update: upd1},
+		{Select: sel2, Insert: ins2, Update: upd2},
+	}}
+
+	res, err := wrapStreamExecute(upsert, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	// 1 for the inserted row, 2 for the updated row.
+	assert.EqualValues(t, 3, res.RowsAffected)
+	assert.EqualValues(t, 42, res.InsertID)
+
+	sel1.ExpectLog(t, []string{`Execute  false`})
+	ins1.ExpectLog(t, []string{`Execute  false`})
+	upd1.ExpectLog(t, nil)
+	sel2.ExpectLog(t, []string{`Execute  false`})
+	ins2.ExpectLog(t, nil)
+	upd2.ExpectLog(t, []string{`Execute  false`})
+}
+
+// TestUpsertUnchangedRow tests that a row left unchanged by the update counts as no affected row.
+func TestUpsertUnchangedRow(t *testing.T) {
+	upsert := &Upsert{Upserts: []*UpsertRow{{
+		Select: &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")}},
+		Insert: &fakePrimitive{},
+		Update: &fakePrimitive{results: []*sqltypes.Result{{RowsAffected: 0}}},
+	}}}
+
+	res, err := upsert.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.Zero(t, res.RowsAffected)
+}
+
+// TestUpsertError tests that the rows following a failed row are not upserted.
+func TestUpsertError(t *testing.T) {
+	sel2 := &fakePrimitive{}
+	upsert := &Upsert{Upserts: []*UpsertRow{{
+		Select: &fakePrimitive{sendErr: errors.New("select failed")},
+		Insert: &fakePrimitive{},
+		Update: &fakePrimitive{},
+	}, {
+		Select: sel2,
+		Insert: &fakePrimitive{},
+		Update: &fakePrimitive{},
+	}}}
+
+	_, err := upsert.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "select failed")
+	sel2.ExpectLog(t, nil)
+}
+
+// TestUpsertSeveralExistingRows tests that a row conflicting with several existing rows,
+// through different unique keys, is not upserted.
+func TestUpsertSeveralExistingRows(t *testing.T) {
+	upd := &fakePrimitive{}
+	upsert := &Upsert{Upserts: []*UpsertRow{{
+		Select: &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1", "1")}},
+		Insert: &fakePrimitive{},
+		Update: upd,
+	}}}
+
+	_, err := upsert.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.ErrorContains(t, err, "VT12001: unsupported: ON DUPLICATE KEY UPDATE changing vindex columns of a row conflicting with several existing rows")
+	upd.ExpectLog(t, nil)
+}
@@ -0,0 +1,414 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// WindowOpcode is the window function evaluated by a WindowFunction.
+type WindowOpcode int
+
+// These are the window functions that the Window primitive can evaluate.
+const (
+	WindowRowNumber = WindowOpcode(iota)
+	WindowRank
+	WindowDenseRank
+	WindowLag
+	WindowLead
+	WindowSum
+)
+
+var windowOpcodeName = map[WindowOpcode]string{
+	WindowRowNumber: "row_number",
+	WindowRank:      "rank",
+	WindowDenseRank: "dense_rank",
+	WindowLag:       "lag",
+	WindowLead:      "lead",
+	WindowSum:       "sum",
+}
+
+func (code WindowOpcode) String() string {
+	return windowOpcodeName[code]
+}
+
+type (
+	// Window is a primitive that evaluates window functions over the rows
+	// of its input. All the window functions share the same window, and the
+	// input must be sorted by the PARTITION BY columns and then by the
+	// ORDER BY columns of that window.
+	// The output rows start with the values of the window functions, and
+	// are followed by the input columns.
+	Window struct {
+		Input Primitive
+
+		// PartitionBy are the input columns of the PARTITION BY clause.
+		PartitionBy []CheckCol
+		// OrderBy are the input columns of the ORDER BY clause. The rows of
+		// a partition that have the same values for them are peers.
+		OrderBy []CheckCol
+
+		Functions []*WindowFunction
+	}
+
+	// WindowFunction is a window function evaluated by the Window primitive.
+	WindowFunction struct {
+		Opcode WindowOpcode
+		// Col is the input column of the argument of LAG, LEAD and SUM.
+		Col int
+		// N is the number of rows LAG and LEAD look back or forward.
+		N int
+		// DefaultCol is the input column of the default value of LAG and
+		// LEAD. It is -1 when the default value is NULL.
+		DefaultCol int
+		// Alias is the name of the output column.
+		Alias string
+	}
+)
+
+// RouteType implements the Primitive interface
+func (w *Window) RouteType() string {
+	return w.Input.RouteType()
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (w *Window) GetKeyspaceName() string {
+	return w.Input.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (w *Window) GetTableName() string {
+	return w.Input.GetTableName()
+}
+
+// TryExecute implements the Primitive interface
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	// we need the input fields types to correctly calculate the output types
+	input, err := vcursor.ExecutePrimitive(ctx, w.Input, bindVars, true)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{}
+	if wantfields {
+		result.Fields = w.fields(input.Fields)
+	}
+
+	p := w.newPartitioner(input.Fields)
+	for _, row := range input.Rows {
+		rows, err := p.add(row)
+		if err != nil {
+			return nil, err
+		}
+		result.Rows = append(result.Rows, rows...)
+	}
+	rows, err := p.flush()
+	if err != nil {
+		return nil, err
+	}
+	result.Rows = append(result.Rows, rows...)
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	var p *windowPartitioner
+
+	visitor := func(qr *sqltypes.Result) error {
+		if p == nil && len(qr.Fields) != 0 {
+			p = w.newPartitioner(qr.Fields)
+			if wantfields {
+				if err := callback(&sqltypes.Result{Fields: w.fields(qr.Fields)}); err != nil {
+					return err
+				}
+			}
+		}
+
+		var out [][]sqltypes.Value
+		for _, row := range qr.Rows {
+			rows, err := p.add(row)
+			if err != nil {
+				return err
+			}
+			out = append(out, rows...)
+		}
+		if len(out) == 0 {
+			return nil
+		}
+		return callback(&sqltypes.Result{Rows: out})
+	}
+
+	// we need the input fields types to correctly calculate the output types
+	err := vcursor.StreamExecutePrimitive(ctx, w.Input, bindVars, true, visitor)
+	if err != nil {
+		return err
+	}
+
+	if p == nil {
+		return nil
+	}
+	rows, err := p.flush()
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+	return callback(&sqltypes.Result{Rows: rows})
+}
+
+// GetFields implements the Primitive interface
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: w.fields(qr.Fields)}, nil
+}
+
+func (w *Window) fields(input []*querypb.Field) []*querypb.Field {
+	fields := make([]*querypb.Field, 0, len(w.Functions)+len(input))
+	for _, fn := range w.Functions {
+		var field *querypb.Field
+		switch fn.Opcode {
+		case WindowRowNumber, WindowRank, WindowDenseRank:
+			field = &querypb.Field{
+				Type:    sqltypes.Uint64,
+				Charset: uint32(collations.CollationBinaryID),
+				Flags:   mysql.FlagsForColumn(sqltypes.Uint64, collations.CollationBinaryID) | uint32(querypb.MySqlFlag_NOT_NULL_FLAG),
+			}
+		case WindowLag, WindowLead:
+			field = input[fn.Col].CloneVT()
+			field.Flags &^= uint32(querypb.MySqlFlag_NOT_NULL_FLAG)
+		case WindowSum:
+			field = input[fn.Col].CloneVT()
+			field.Type = opcode.AggregateSum.Type(field.Type)
+			field.Flags &^= uint32(querypb.MySqlFlag_NOT_NULL_FLAG)
+		}
+		field.Name = fn.Alias
+		fields = append(fields, field)
+	}
+	return append(fields, input...)
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+// Inputs implements the Primitive interface
+func (w *Window) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{w.Input}, nil
+}
+
+func (w *Window) description() PrimitiveDescription {
+	other := map[string]any{
+		"Functions": slice.Map(w.Functions, func(fn *WindowFunction) string {
+			return fn.String()
+		}),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = checkColsString(w.PartitionBy)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = checkColsString(w.OrderBy)
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
+
+func checkColsString(cols []CheckCol) string {
+	return strings.Join(slice.Map(cols, CheckCol.String), ", ")
+}
+
+func (fn *WindowFunction) String() string {
+	switch fn.Opcode {
+	case WindowLag, WindowLead:
+		if fn.DefaultCol >= 0 {
+			return fmt.Sprintf("%s(%d, %d, %d)", fn.Opcode, fn.Col, fn.N, fn.DefaultCol)
+		}
+		return fmt.Sprintf("%s(%d, %d)", fn.Opcode, fn.Col, fn.N)
+	case WindowSum:
+		return fmt.Sprintf("%s(%d)", fn.Opcode, fn.Col)
+	default:
+		return fmt.Sprintf("%s()", fn.Opcode)
+	}
+}
+
+// windowPartitioner collects the sorted input rows of a Window until
+// a partition is complete, and then evaluates the window functions
+// over that partition.
+type windowPartitioner struct {
+	w         *Window
+	fields    []*querypb.Field
+	partition []sqltypes.Row
+
+	// these are copies of the columns of the Window, as they can switch to
+	// comparing weight strings
+	partitionBy []CheckCol
+	orderBy     []CheckCol
+}
+
+func (w *Window) newPartitioner(fields []*querypb.Field) *windowPartitioner {
+	return &windowPartitioner{
+		w:           w,
+		fields:      fields,
+		partitionBy: append([]CheckCol(nil), w.PartitionBy...),
+		orderBy:     append([]CheckCol(nil), w.OrderBy...),
+	}
+}
+
+// add adds a row to the current partition. If the row starts a new
+// partition, the output rows of the previous partition are returned.
+func (p *windowPartitioner) add(row sqltypes.Row) ([]sqltypes.Row, error) {
+	if len(p.partition) > 0 {
+		same, err := equalCheckCols(p.partitionBy, p.partition[0], row)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			out, err := p.flush()
+			if err != nil {
+				return nil, err
+			}
+			p.partition = append(p.partition, row)
+			return out, nil
+		}
+	}
+	p.partition = append(p.partition, row)
+	return nil, nil
+}
+
+// flush evaluates the window functions over the current partition,
+// and returns its output rows.
+func (p *windowPartitioner) flush() ([]sqltypes.Row, error) {
+	rows := p.partition
+	p.partition = nil
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	out := make([]sqltypes.Row, len(rows))
+	for i, row := range rows {
+		out[i] = make(sqltypes.Row, len(p.w.Functions), len(p.w.Functions)+len(row))
+		out[i] = append(out[i], row...)
+	}
+
+	sums := make([]evalengine.Sum, len(p.w.Functions))
+	for f, fn := range p.w.Functions {
+		if fn.Opcode == WindowSum {
+			sums[f] = evalengine.NewAggregationSum(p.fields[fn.Col].Type)
+		}
+	}
+
+	// the rows are handled in groups of peers, which share the same rank
+	// and the same running sums
+	denseRank := 0
+	for start := 0; start < len(rows); {
+		end := start + 1
+		for ; end < len(rows); end++ {
+			peer, err := equalCheckCols(p.orderBy, rows[start], rows[end])
+			if err != nil {
+				return nil, err
+			}
+			if !peer {
+				break
+			}
+		}
+		denseRank++
+
+		for f, fn := range p.w.Functions {
+			if fn.Opcode != WindowSum {
+				continue
+			}
+			for _, row := range rows[start:end] {
+				if err := sums[f].Add(row[fn.Col]); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		for i := start; i < end; i++ {
+			for f, fn := range p.w.Functions {
+				switch fn.Opcode {
+				case WindowRowNumber:
+					out[i][f] = sqltypes.NewUint64(uint64(i + 1))
+				case WindowRank:
+					out[i][f] = sqltypes.NewUint64(uint64(start + 1))
+				case WindowDenseRank:
+					out[i][f] = sqltypes.NewUint64(uint64(denseRank))
+				case WindowLag:
+					out[i][f] = fn.valueAt(rows, i, i-fn.N)
+				case WindowLead:
+					out[i][f] = fn.valueAt(rows, i, i+fn.N)
+				case WindowSum:
+					out[i][f] = sums[f].Result()
+				}
+			}
+		}
+		start = end
+	}
+	return out, nil
+}
+
+// valueAt returns the argument of LAG or LEAD for the row at offset idx in
+// the partition, or the default value of the current row if there is no
+// such row.
+func (fn *WindowFunction) valueAt(rows []sqltypes.Row, current, idx int) sqltypes.Value {
+	if idx >= 0 && idx < len(rows) {
+		return rows[idx][fn.Col]
+	}
+	if fn.DefaultCol >= 0 {
+		return rows[current][fn.DefaultCol]
+	}
+	return sqltypes.NULL
+}
+
+// equalCheckCols returns true if the two rows have the same values for
+// the given columns.
+func equalCheckCols(cols []CheckCol, a, b sqltypes.Row) (bool, error) {
+	for i, col := range cols {
+		cmp, err := evalengine.NullsafeCompare(a[col.Col], b[col.Col], col.Collation)
+		if err != nil {
+			_, isComparisonErr := err.(evalengine.UnsupportedComparisonError)
+			if !isComparisonErr || col.WsCol == nil {
+				return false, err
+			}
+			col = col.SwitchToWeightString()
+			cols[i] = col
+			cmp, err = evalengine.NullsafeCompare(a[col.Col], b[col.Col], col.Collation)
+			if err != nil {
+				return false, err
+			}
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
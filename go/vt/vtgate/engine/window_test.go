@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+)
+
+func newTestWindow() (*Window, *fakePrimitive) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3",
+		"varchar|int64|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1|10",
+			"a|1|20",
+			"a|2|30",
+			"b|1|5",
+			"b|3|7",
+		)},
+	}
+
+	w := &Window{
+		Input:       fp,
+		PartitionBy: []CheckCol{{Col: 0, Type: sqltypes.VarChar, Collation: collations.CollationUtf8mb4ID}},
+		OrderBy:     []CheckCol{{Col: 1, Type: sqltypes.Int64, Collation: collations.CollationBinaryID}},
+		Functions: []*WindowFunction{
+			{Opcode: WindowRowNumber, Alias: "rn"},
+			{Opcode: WindowRank, Alias: "r"},
+			{Opcode: WindowDenseRank, Alias: "dr"},
+			{Opcode: WindowLag, Col: 2, N: 1, DefaultCol: -1, Alias: "prev"},
+			{Opcode: WindowLead, Col: 2, N: 1, DefaultCol: 1, Alias: "next"},
+			{Opcode: WindowSum, Col: 2, Alias: "total"},
+		},
+	}
+	return w, fp
+}
+
+func TestWindowExecute(t *testing.T) {
+	w, _ := newTestWindow()
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"rn|r|dr|prev|next|total|c1|c2|c3",
+			"uint64|uint64|uint64|int64|int64|decimal|varchar|int64|int64",
+		),
+		"1|1|1|null|20|30|a|1|10",
+		"2|1|1|10|30|30|a|1|20",
+		"3|3|2|20|2|60|a|2|30",
+		"1|1|1|null|7|5|b|1|5",
+		"2|2|2|5|3|12|b|3|7",
+	)
+	utils.MustMatch(t, want.Rows, result.Rows)
+	require.Len(t, result.Fields, 9)
+	require.Equal(t, "rn", result.Fields[0].Name)
+	require.Equal(t, sqltypes.Decimal, result.Fields[5].Type)
+	require.Equal(t, "c1", result.Fields[6].Name)
+}
+
+func TestWindowStreamExecute(t *testing.T) {
+	w, _ := newTestWindow()
+
+	// the fake primitive sends the rows two at a time, so the first
+	// partition spans several callbacks
+	result, err := wrapStreamExecute(w, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"rn|r|dr|prev|next|total|c1|c2|c3",
+			"uint64|uint64|uint64|int64|int64|decimal|varchar|int64|int64",
+		),
+		"1|1|1|null|20|30|a|1|10",
+		"2|1|1|10|30|30|a|1|20",
+		"3|3|2|20|2|60|a|2|30",
+		"1|1|1|null|7|5|b|1|5",
+		"2|2|2|5|3|12|b|3|7",
+	)
+	utils.MustMatch(t, want.Rows, result.Rows)
+}
+
+func TestWindowWithoutOrderBy(t *testing.T) {
+	w, _ := newTestWindow()
+	w.OrderBy = nil
+	w.Functions = []*WindowFunction{{Opcode: WindowSum, Col: 2, Alias: "total"}}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+
+	// without an ORDER BY all the rows of a partition are peers, so the
+	// sum is computed over the whole partition
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"total|c1|c2|c3",
+			"decimal|varchar|int64|int64",
+		),
+		"60|a|1|10",
+		"60|a|1|20",
+		"60|a|2|30",
+		"12|b|1|5",
+		"12|b|3|7",
+	)
+	utils.MustMatch(t, want.Rows, result.Rows)
+}
+
+func TestWindowDescription(t *testing.T) {
+	w, _ := newTestWindow()
+
+	desc := w.description()
+	require.Equal(t, "Window", desc.OperatorType)
+	require.Equal(t, []string{"row_number()", "rank()", "dense_rank()", "lag(2, 1)", "lead(2, 1, 1)", "sum(2)"}, desc.Other["Functions"])
+}
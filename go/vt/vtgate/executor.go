@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -40,6 +41,7 @@ import (
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -122,6 +124,13 @@ type Executor struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// sequenceCache serves the values of the sequences from memory, it is nil when they are not cached.
+	sequenceCache *engine.SequenceCache
+	// resultCache serves the results of the cacheable SELECT queries, it is nil when they are not cached.
+	resultCache *resultCache
+	// vreplicationLags caches the lag of the workflows maintaining the async lookup vindexes.
+	vreplicationLags *vreplicationLagCache
 }
 
 var executorOnce sync.Once
@@ -168,6 +177,10 @@ func NewExecutor(
 		plans:               plans,
 		warmingReadsPercent: warmingReadsPercent,
 		warmingReadsChannel: make(chan bool, warmingReadsConcurrency),
+		vreplicationLags:    newVReplicationLagCache(vreplicationLagTTL),
+	}
+	if sequenceCacheSize > 0 {
+		e.sequenceCache = engine.NewSequenceCache(sequenceCacheSize)
 	}
 
 	vschemaacl.Init()
@@ -891,6 +904,42 @@ func (e *Executor) showTablets(filter *sqlparser.ShowFilter) (*sqltypes.Result,
 	}, nil
 }
 
+// vreplicationLag returns the highest lag of the streams of the vreplication workflow running on the
+// primary tablets of the keyspace. The lag is read from all the shards at once, and is cached for a short time.
+func (e *Executor) vreplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error) {
+	return e.vreplicationLags.get(ctx, keyspace, workflow, func(ctx context.Context) (time.Duration, error) {
+		rss, _, err := e.resolver.resolver.ResolveDestinations(ctx, keyspace, topodatapb.TabletType_PRIMARY, nil, []key.Destination{key.DestinationAllShards{}})
+		if err != nil {
+			return 0, err
+		}
+		var (
+			wg     sync.WaitGroup
+			mu     sync.Mutex
+			lag    time.Duration
+			allErr concurrency.AllErrorRecorder
+		)
+		for _, rs := range rss {
+			wg.Add(1)
+			go func(rs *srvtopo.ResolvedShard) {
+				defer wg.Done()
+				shardLag, err := e.scatterConn.gateway.VReplicationLag(ctx, rs.Target, workflow)
+				if err != nil {
+					allErr.RecordError(err)
+					return
+				}
+				mu.Lock()
+				lag = max(lag, shardLag)
+				mu.Unlock()
+			}(rs)
+		}
+		wg.Wait()
+		if allErr.HasErrors() {
+			return 0, allErr.Error()
+		}
+		return lag, nil
+	})
+}
+
 func (e *Executor) showVitessReplicationStatus(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
 	defer cancel()
@@ -975,6 +1024,54 @@ func (e *Executor) MessageStream(ctx context.Context, keyspace string, shard str
 	return formatError(err)
 }
 
+// changedSequences returns the sequences whose tables, or the routing rules
+// leading to them, are not the same in the two VSchemas
+func changedSequences(old, new *vindexes.VSchema) []string {
+	oldUsers, newUsers := sequenceUsers(old), sequenceUsers(new)
+	var changed []string
+	for sequence, users := range oldUsers {
+		if newUsers[sequence] != users {
+			changed = append(changed, sequence)
+		}
+	}
+	return changed
+}
+
+// sequenceUsers describes, for each sequence, the tables using it and the
+// routing rules leading to them or to the sequence
+func sequenceUsers(vschema *vindexes.VSchema) map[string]string {
+	if vschema == nil {
+		return nil
+	}
+	users := make(map[string][]string)
+	sequences := make(map[*vindexes.Table]string)
+	for _, ks := range vschema.Keyspaces {
+		for _, table := range ks.Tables {
+			if table.AutoIncrement == nil || table.AutoIncrement.Sequence == nil {
+				continue
+			}
+			seq := table.AutoIncrement.Sequence
+			sequence := seq.Keyspace.Name + "." + seq.Name.String()
+			users[sequence] = append(users[sequence], "table "+table.Keyspace.Name+"."+table.Name.String())
+			sequences[table] = sequence
+			sequences[seq] = sequence
+		}
+	}
+	for name, rule := range vschema.RoutingRules {
+		for _, table := range rule.Tables {
+			if sequence, ok := sequences[table]; ok {
+				users[sequence] = append(users[sequence], "rule "+name+" => "+table.Keyspace.Name+"."+table.Name.String())
+			}
+		}
+	}
+	described := make(map[string]string, len(users))
+	for sequence, descriptions := range users {
+		slices.Sort(descriptions)
+		described[sequence] = strings.Join(descriptions, ", ")
+	}
+	return described
+}
+
 // VSchema returns the VSchema.
 func (e *Executor) VSchema() *vindexes.VSchema {
 	e.mu.Lock()
@@ -987,6 +1084,11 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	if vschema != nil {
+		// the sequence tables may have been reset along with the tables using them,
+		// like when MoveTables switches the writes of the tables it moves
+		if e.sequenceCache != nil {
+			e.sequenceCache.Clear(changedSequences(e.vschema, vschema))
+		}
 		e.vschema = vschema
 	}
 	e.vschemaStats = stats
@@ -1538,4 +1640,7 @@ func (e *Executor) Close() {
 	}
 	topo.Close()
 	e.plans.Close()
+	if e.resultCache != nil {
+		e.resultCache.Close()
+	}
 }
@@ -18,6 +18,7 @@ package vtgate
 
 import (
 	"context"
+	"maps"
 	"reflect"
 	"slices"
 	"testing"
@@ -30,6 +31,8 @@ import (
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 
 	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vtgate/vschemaacl"
 
 	"github.com/stretchr/testify/assert"
@@ -781,3 +784,24 @@ func TestPlanExecutorVindexDDLACL(t *testing.T) {
 	// restore the disallowed state
 	vschemaacl.AuthorizedDDLUsers = ""
 }
+
+func TestExecutorVSchemaChangeClearsSequenceCache(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+	executor.sequenceCache = engine.NewSequenceCache(10)
+	_, err := executor.sequenceCache.Next("TestUnsharded.user_seq", 1, func(n int64) (int64, error) {
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, executor.sequenceCache.Remaining("TestUnsharded.user_seq"))
+
+	// the sequence is used by the same tables, like after a rebuild by the schema tracker
+	executor.SaveVSchema(executor.VSchema(), executor.vschemaStats)
+	assert.EqualValues(t, 9, executor.sequenceCache.Remaining("TestUnsharded.user_seq"))
+
+	// the writes of a table using the sequence are routed elsewhere
+	vschema := *executor.VSchema()
+	vschema.RoutingRules = maps.Clone(vschema.RoutingRules)
+	vschema.RoutingRules["moved_user"] = &vindexes.RoutingRule{Tables: []*vindexes.Table{vschema.Keyspaces["TestExecutor"].Tables["user"]}}
+	executor.SaveVSchema(&vschema, executor.vschemaStats)
+	assert.EqualValues(t, 0, executor.sequenceCache.Remaining("TestUnsharded.user_seq"))
+}
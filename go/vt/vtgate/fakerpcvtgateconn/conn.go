@@ -194,6 +194,11 @@ func (conn *FakeVTGateConn) VStream(ctx context.Context, tabletType topodatapb.T
 	return nil, fmt.Errorf("NYI")
 }
 
+// VStreamAck please see vtgateconn.Impl.VStreamAck
+func (conn *FakeVTGateConn) VStreamAck(ctx context.Context, cursor string, vgtid *binlogdatapb.VGtid) error {
+	return fmt.Errorf("NYI")
+}
+
 // Close please see vtgateconn.Impl.Close
 func (conn *FakeVTGateConn) Close() {
 }
@@ -50,6 +50,7 @@ func init() {
 
 	for _, cmd := range []string{
 		"vtbench",
+		"vtcdc",
 		"vtclient",
 		"vtcombo",
 		"vtctl",
@@ -274,6 +275,16 @@ func (conn *vtgateConn) VStream(ctx context.Context, tabletType topodatapb.Table
 	}, nil
 }
 
+func (conn *vtgateConn) VStreamAck(ctx context.Context, cursor string, vgtid *binlogdatapb.VGtid) error {
+	request := &vtgatepb.VStreamAckRequest{
+		CallerId: callerid.EffectiveCallerIDFromContext(ctx),
+		Cursor:   cursor,
+		Vgtid:    vgtid,
+	}
+	_, err := conn.c.VStreamAck(ctx, request)
+	return vterrors.FromGRPC(err)
+}
+
 func (conn *vtgateConn) Close() {
 	conn.cc.Close()
 }
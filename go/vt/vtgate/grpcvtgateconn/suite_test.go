@@ -250,6 +250,10 @@ func (f *fakeVTGateService) VStream(ctx context.Context, tabletType topodatapb.T
 	panic("unimplemented")
 }
 
+func (f *fakeVTGateService) VStreamAck(ctx context.Context, cursor string, vgtid *binlogdatapb.VGtid) error {
+	panic("unimplemented")
+}
+
 // CreateFakeServer returns the fake server for the tests
 func CreateFakeServer(t *testing.T) vtgateservice.VTGateService {
 	return &fakeVTGateService{
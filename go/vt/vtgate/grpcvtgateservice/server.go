@@ -283,6 +283,18 @@ func (vtg *VTGate) VStream(request *vtgatepb.VStreamRequest, stream vtgateservic
 	return vterrors.ToGRPC(vtgErr)
 }
 
+// VStreamAck is the RPC version of vtgateservice.VTGateService method
+func (vtg *VTGate) VStreamAck(ctx context.Context, request *vtgatepb.VStreamAckRequest) (response *vtgatepb.VStreamAckResponse, err error) {
+	defer vtg.server.HandlePanic(&err)
+	ctx = withCallerIDContext(ctx, request.CallerId)
+	vtgErr := vtg.server.VStreamAck(ctx, request.Cursor, request.Vgtid)
+	response = &vtgatepb.VStreamAckResponse{}
+	if vtgErr == nil {
+		return response, nil
+	}
+	return nil, vterrors.ToGRPC(vtgErr)
+}
+
 func init() {
 	vtgate.RegisterVTGates = append(vtgate.RegisterVTGates, func(vtGate vtgateservice.VTGateService) {
 		if servenv.GRPCCheckServiceMap("vtgateservice") {
@@ -277,6 +277,10 @@ func (e *Executor) executePlan(
 	execStart time.Time,
 ) (*sqltypes.Result, error) {
 
+	if e.canCacheResult(safeSession, vcursor, plan) {
+		return e.executeCachedPlan(ctx, plan, vcursor, bindVars, logStats, execStart)
+	}
+
 	// 4: Execute!
 	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
 
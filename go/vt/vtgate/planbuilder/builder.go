@@ -20,6 +20,9 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
@@ -103,9 +106,123 @@ func BuildFromStmt(ctx context.Context, query string, stmt sqlparser.Statement,
 		BindVarNeeds: bindVarNeeds,
 		TablesUsed:   tablesUsed,
 	}
+	plan.ResultCacheTTL = resultCacheTTL(stmt, bindVarNeeds, tablesUsed, vschema)
 	return plan, nil
 }
 
+// resultCacheUnsafeFuncs are the functions whose results depend on the time, the session or
+// the state of the server, and make the results of the queries using them uncacheable.
+var resultCacheUnsafeFuncs = map[string]bool{
+	"benchmark":       true,
+	"connection_id":   true,
+	"curdate":         true,
+	"current_date":    true,
+	"current_role":    true,
+	"current_time":    true,
+	"current_user":    true,
+	"curtime":         true,
+	"database":        true,
+	"found_rows":      true,
+	"last_insert_id":  true,
+	"now":             true,
+	"rand":            true,
+	"random_bytes":    true,
+	"row_count":       true,
+	"schema":          true,
+	"session_user":    true,
+	"sleep":           true,
+	"source_pos_wait": true,
+	"master_pos_wait": true,
+	"sysdate":         true,
+	"system_user":     true,
+	"unix_timestamp":  true,
+	"user":            true,
+	"uuid":            true,
+	"uuid_short":      true,
+}
+
+// resultCacheTTL returns the duration for which the results of a statement can be cached by vtgate.
+// It is the RESULT_CACHE_TTL_MS directive of the statement if it is set, or else the shortest result
+// cache TTL of the tables read by the statement, which must all enable the cache. The results of the
+// statements which lock rows, or depend on the time or on the session, are never cached.
+func resultCacheTTL(stmt sqlparser.Statement, bindVarNeeds *sqlparser.BindVarNeeds, tablesUsed []string, vschema plancontext.VSchema) time.Duration {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Select:
+		if stmt.Into != nil {
+			return 0
+		}
+	case *sqlparser.Union:
+		if stmt.Into != nil {
+			return 0
+		}
+	default:
+		return 0
+	}
+	// the results are only invalidated when the tables they read change
+	if len(tablesUsed) == 0 || !isResultCacheable(stmt, bindVarNeeds) {
+		return 0
+	}
+
+	directives := stmt.(sqlparser.SelectStatement).GetParsedComments().Directives()
+	if val, ok := directives.GetString(sqlparser.DirectiveResultCacheTTL, ""); ok {
+		ms, err := strconv.ParseInt(val, 10, 64)
+		if err != nil || ms < 0 {
+			return 0
+		}
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	var ttl time.Duration
+	for _, name := range tablesUsed {
+		ksName, tableName, ok := strings.Cut(name, ".")
+		if !ok {
+			return 0
+		}
+		table, _, _, _, err := vschema.FindTable(sqlparser.NewTableNameWithQualifier(tableName, ksName))
+		if err != nil || table == nil || table.ResultCacheTTLMs == 0 {
+			return 0
+		}
+		if tableTTL := time.Duration(table.ResultCacheTTLMs) * time.Millisecond; ttl == 0 || tableTTL < ttl {
+			ttl = tableTTL
+		}
+	}
+	return ttl
+}
+
+// isResultCacheable returns false if a statement locks rows, or if its results depend on the time,
+// on the session, like with its variables, or on the state of the server.
+func isResultCacheable(stmt sqlparser.Statement, bindVarNeeds *sqlparser.BindVarNeeds) bool {
+	// the functions and variables replaced by the values of the session when rewriting the statement
+	if bindVarNeeds != nil && (len(bindVarNeeds.NeedFunctionResult) > 0 ||
+		len(bindVarNeeds.NeedSystemVariable) > 0 ||
+		len(bindVarNeeds.NeedUserDefinedVariables) > 0) {
+		return false
+	}
+
+	cacheable := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			if node.Lock != sqlparser.NoLock {
+				cacheable = false
+			}
+		case *sqlparser.Union:
+			if node.Lock != sqlparser.NoLock {
+				cacheable = false
+			}
+		case *sqlparser.CurTimeFuncExpr, *sqlparser.Variable, *sqlparser.LockingFunc,
+			*sqlparser.PerformanceSchemaFuncExpr, *sqlparser.GTIDFuncExpr:
+			cacheable = false
+		case *sqlparser.FuncExpr:
+			if resultCacheUnsafeFuncs[node.Name.Lowered()] {
+				cacheable = false
+			}
+		}
+		return cacheable, nil
+	}, stmt)
+	return cacheable
+}
+
 func getConfiguredPlanner(vschema plancontext.VSchema, stmt sqlparser.Statement, query string) (stmtPlanner, error) {
 	planner, found := getPlannerFromQueryHint(stmt)
 	if !found {
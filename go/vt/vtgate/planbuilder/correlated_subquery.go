@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	popcode "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*correlatedSubquery)(nil)
+
+// correlatedSubquery is the logicalPlan for engine.CorrelatedSubquery.
+// This gets built for correlated subqueries in the SELECT list that can't
+// be merged with the outer query, and are evaluated once per outer row.
+type correlatedSubquery struct {
+	subquery  logicalPlan
+	outer     logicalPlan
+	eSubquery *engine.CorrelatedSubquery
+}
+
+// newCorrelatedSubquery builds a new correlatedSubquery.
+func newCorrelatedSubquery(opcode popcode.PulloutOpcode, sqName string, vars map[string]int, subquery, outer logicalPlan) *correlatedSubquery {
+	return &correlatedSubquery{
+		subquery: subquery,
+		outer:    outer,
+		eSubquery: &engine.CorrelatedSubquery{
+			Opcode:         opcode,
+			SubqueryResult: sqName,
+			Vars:           vars,
+		},
+	}
+}
+
+// Primitive implements the logicalPlan interface
+func (ps *correlatedSubquery) Primitive() engine.Primitive {
+	ps.eSubquery.Subquery = ps.subquery.Primitive()
+	ps.eSubquery.Outer = ps.outer.Primitive()
+	return ps.eSubquery
+}
+
+// Wireup implements the logicalPlan interface
+func (ps *correlatedSubquery) Wireup(ctx *plancontext.PlanningContext) error {
+	if err := ps.outer.Wireup(ctx); err != nil {
+		return err
+	}
+	return ps.subquery.Wireup(ctx)
+}
+
+// Rewrite implements the logicalPlan interface
+func (ps *correlatedSubquery) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 2 {
+		return vterrors.VT13001("correlatedSubquery: wrong number of inputs")
+	}
+	ps.outer = inputs[0]
+	ps.subquery = inputs[1]
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (ps *correlatedSubquery) ContainsTables() semantics.TableSet {
+	return ps.outer.ContainsTables().Merge(ps.subquery.ContainsTables())
+}
+
+// Inputs implements the logicalPlan interface
+func (ps *correlatedSubquery) Inputs() []logicalPlan {
+	return []logicalPlan{ps.outer, ps.subquery}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (ps *correlatedSubquery) OutputColumns() []sqlparser.SelectExpr {
+	value := &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(ps.eSubquery.SubqueryResult)}
+	return append([]sqlparser.SelectExpr{value}, ps.outer.OutputColumns()...)
+}
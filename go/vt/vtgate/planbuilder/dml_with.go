@@ -0,0 +1,83 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// inlineCommonTableExpressions replaces the references to the common table expressions of a DML statement
+// by derived tables, so that the statement can be planned like any other DML reading from derived tables.
+// Each reference gets its own copy of the common table expression, which is fine since they are not recursive.
+func inlineCommonTableExpressions(stmt sqlparser.Statement, with *sqlparser.With, stmtType string) error {
+	if with.Recursive {
+		return vterrors.VT12001("WITH RECURSIVE expression in " + stmtType + " statement")
+	}
+
+	ctes := map[string]*sqlparser.CommonTableExpr{}
+	inline := func(node sqlparser.SQLNode) sqlparser.SQLNode {
+		return sqlparser.Rewrite(node, nil, func(cursor *sqlparser.Cursor) bool {
+			aliasedExpr, isAliased := cursor.Node().(*sqlparser.AliasedTableExpr)
+			if !isAliased {
+				return true
+			}
+			tbl, isTable := aliasedExpr.Expr.(sqlparser.TableName)
+			if !isTable || !tbl.Qualifier.IsEmpty() {
+				return true
+			}
+			cte, found := ctes[tbl.Name.String()]
+			if !found {
+				return true
+			}
+			as := aliasedExpr.As
+			if as.IsEmpty() {
+				as = tbl.Name
+			}
+			cursor.Replace(&sqlparser.AliasedTableExpr{
+				Expr:    &sqlparser.DerivedTable{Select: sqlparser.CloneSelectStatement(cte.Subquery.Select)},
+				As:      as,
+				Columns: sqlparser.CloneColumns(cte.Columns),
+			})
+			return true
+		})
+	}
+
+	// a common table expression can refer to the ones defined before it
+	for _, cte := range with.CTEs() {
+		cte.Subquery = inline(cte.Subquery).(*sqlparser.Subquery)
+		ctes[cte.ID.String()] = cte
+	}
+
+	if del, isDelete := stmt.(*sqlparser.Delete); isDelete && len(del.Targets) == 0 {
+		// the target of a single table delete can't be a common table expression
+		if target, ok := del.TableExprs[0].(*sqlparser.AliasedTableExpr); ok {
+			if tbl, ok := target.Expr.(sqlparser.TableName); ok && tbl.Qualifier.IsEmpty() && ctes[tbl.Name.String()] != nil {
+				return vterrors.VT03004(tbl.Name.String())
+			}
+		}
+	}
+
+	switch stmt := stmt.(type) {
+	case *sqlparser.Delete:
+		stmt.With = nil
+	case *sqlparser.Update:
+		stmt.With = nil
+	}
+	_ = inline(stmt)
+	return nil
+}
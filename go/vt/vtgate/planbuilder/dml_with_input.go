@@ -0,0 +1,80 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*dmlWithInput)(nil)
+
+// dmlWithInput is the logicalPlan for engine.DMLWithInput.
+type dmlWithInput struct {
+	input logicalPlan
+	dml   logicalPlan
+	vars  []engine.DMLVar
+
+	rowVars []engine.DMLVar
+}
+
+// Primitive implements the logicalPlan interface
+func (d *dmlWithInput) Primitive() engine.Primitive {
+	return &engine.DMLWithInput{
+		Input: d.input.Primitive(),
+		DML:   d.dml.Primitive(),
+		Vars:  d.vars,
+
+		RowVars: d.rowVars,
+	}
+}
+
+// Wireup implements the logicalPlan interface
+func (d *dmlWithInput) Wireup(ctx *plancontext.PlanningContext) error {
+	if err := d.input.Wireup(ctx); err != nil {
+		return err
+	}
+	return d.dml.Wireup(ctx)
+}
+
+// Rewrite implements the logicalPlan interface
+func (d *dmlWithInput) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 2 {
+		return vterrors.VT13001("dmlWithInput: wrong number of inputs")
+	}
+	d.input = inputs[0]
+	d.dml = inputs[1]
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (d *dmlWithInput) ContainsTables() semantics.TableSet {
+	return d.dml.ContainsTables()
+}
+
+// Inputs implements the logicalPlan interface
+func (d *dmlWithInput) Inputs() []logicalPlan {
+	return []logicalPlan{d.input, d.dml}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (d *dmlWithInput) OutputColumns() []sqlparser.SelectExpr {
+	return nil
+}
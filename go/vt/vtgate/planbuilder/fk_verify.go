@@ -20,6 +20,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 	"vitess.io/vitess/go/vt/vtgate/semantics"
 )
@@ -29,19 +30,25 @@ var _ logicalPlan = (*fkVerify)(nil)
 type verifyLP struct {
 	verify logicalPlan
 	typ    string
+	bvName string
+	cols   []int
 }
 
 // fkVerify is the logicalPlan for engine.FkVerify.
 type fkVerify struct {
-	input  logicalPlan
-	verify []*verifyLP
+	input     logicalPlan
+	selection logicalPlan
+	values    [][]evalengine.Expr
+	verify    []*verifyLP
 }
 
 // newFkVerify builds a new fkVerify.
-func newFkVerify(input logicalPlan, verify []*verifyLP) *fkVerify {
+func newFkVerify(input, selection logicalPlan, values [][]evalengine.Expr, verify []*verifyLP) *fkVerify {
 	return &fkVerify{
-		input:  input,
-		verify: verify,
+		input:     input,
+		selection: selection,
+		values:    values,
+		verify:    verify,
 	}
 }
 
@@ -50,13 +57,21 @@ func (fkc *fkVerify) Primitive() engine.Primitive {
 	var verify []*engine.Verify
 	for _, v := range fkc.verify {
 		verify = append(verify, &engine.Verify{
-			Exec: v.verify.Primitive(),
-			Typ:  v.typ,
+			Exec:   v.verify.Primitive(),
+			Typ:    v.typ,
+			BVName: v.bvName,
+			Cols:   v.cols,
 		})
 	}
+	var selection engine.Primitive
+	if fkc.selection != nil {
+		selection = fkc.selection.Primitive()
+	}
 	return &engine.FkVerify{
-		Exec:   fkc.input.Primitive(),
-		Verify: verify,
+		Exec:      fkc.input.Primitive(),
+		Selection: selection,
+		Values:    fkc.values,
+		Verify:    verify,
 	}
 }
 
@@ -68,11 +83,23 @@ func (fkc *fkVerify) Wireup(ctx *plancontext.PlanningContext) error {
 			return err
 		}
 	}
+	if fkc.selection != nil {
+		if err := fkc.selection.Wireup(ctx); err != nil {
+			return err
+		}
+	}
 	return fkc.input.Wireup(ctx)
 }
 
 // Rewrite implements the logicalPlan interface
 func (fkc *fkVerify) Rewrite(inputs ...logicalPlan) error {
+	if fkc.selection != nil {
+		if len(inputs) == 0 {
+			return vterrors.VT13001("fkVerify: wrong number of inputs")
+		}
+		fkc.selection = inputs[len(inputs)-1]
+		inputs = inputs[:len(inputs)-1]
+	}
 	if len(fkc.verify) != len(inputs)-1 {
 		return vterrors.VT13001("fkVerify: wrong number of inputs")
 	}
@@ -94,6 +121,9 @@ func (fkc *fkVerify) Inputs() []logicalPlan {
 	for _, v := range fkc.verify {
 		inputs = append(inputs, v.verify)
 	}
+	if fkc.selection != nil {
+		inputs = append(inputs, fkc.selection)
+	}
 	return inputs
 }
 
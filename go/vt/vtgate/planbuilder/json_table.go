@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*jsonTable)(nil)
+
+// jsonTable is the logicalPlan for engine.JSONTable.
+type jsonTable struct {
+	input   logicalPlan
+	tableID semantics.TableSet
+	columns []sqlparser.SelectExpr
+	eJSON   *engine.JSONTable
+}
+
+// Primitive implements the logicalPlan interface
+func (jt *jsonTable) Primitive() engine.Primitive {
+	if jt.input != nil {
+		jt.eJSON.Input = jt.input.Primitive()
+	}
+	return jt.eJSON
+}
+
+// Wireup implements the logicalPlan interface
+func (jt *jsonTable) Wireup(ctx *plancontext.PlanningContext) error {
+	if jt.input == nil {
+		return nil
+	}
+	return jt.input.Wireup(ctx)
+}
+
+// Rewrite implements the logicalPlan interface
+func (jt *jsonTable) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != len(jt.Inputs()) {
+		return vterrors.VT13001("jsonTable: wrong number of inputs")
+	}
+	if len(inputs) > 0 {
+		jt.input = inputs[0]
+	}
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (jt *jsonTable) ContainsTables() semantics.TableSet {
+	if jt.input == nil {
+		return jt.tableID
+	}
+	return jt.input.ContainsTables().Merge(jt.tableID)
+}
+
+// Inputs implements the logicalPlan interface
+func (jt *jsonTable) Inputs() []logicalPlan {
+	if jt.input == nil {
+		return nil
+	}
+	return []logicalPlan{jt.input}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (jt *jsonTable) OutputColumns() []sqlparser.SelectExpr {
+	return jt.columns
+}
+
+func transformJSONTable(ctx *plancontext.PlanningContext, op *operators.JSONTable) (logicalPlan, error) {
+	var input logicalPlan
+	if op.Source != nil {
+		var err error
+		input, err = transformToLogicalPlan(ctx, op.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	path, ok := op.Expr.Filter.(*sqlparser.Literal)
+	if !ok || path.Type != sqlparser.StrVal {
+		return nil, vterrors.VT12001(fmt.Sprintf("JSON_TABLE with a path that is not a string literal: %s", sqlparser.String(op.Expr.Filter)))
+	}
+
+	cfg := &evalengine.Config{
+		ResolveType: ctx.SemTable.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+	}
+	doc, err := evalengine.Translate(op.Doc, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := jsonTableColumns(ctx, op.Expr.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	selectExprs := make([]sqlparser.SelectExpr, 0, len(op.Columns))
+	for _, col := range op.Columns {
+		selectExprs = append(selectExprs, col)
+	}
+
+	return &jsonTable{
+		input:   input,
+		tableID: op.TableID,
+		columns: selectExprs,
+		eJSON: &engine.JSONTable{
+			Doc:     doc,
+			ASTDoc:  op.Expr.Expr,
+			Path:    path.Val,
+			Columns: columns,
+			Cols:    op.Offsets,
+		},
+	}, nil
+}
+
+// jsonTableColumns creates the columns of the json table evaluated at the vtgate level. Their values are
+// extracted from the rows of the json table with the evalengine JSON functions, and then cast to their type
+func jsonTableColumns(ctx *plancontext.PlanningContext, definitions []*sqlparser.JtColumnDefinition) ([]*engine.JSONTableColumn, error) {
+	// the expressions of the columns are evaluated with the JSON value they work on as the only column
+	value := sqlparser.NewColName("value")
+	cfg := &evalengine.Config{
+		ResolveColumn: func(*sqlparser.ColName) (int, error) {
+			return 0, nil
+		},
+		ResolveType: func(sqlparser.Expr) (sqltypes.Type, collations.ID, bool) {
+			return sqltypes.TypeJSON, collations.CollationUtf8mb4ID, true
+		},
+		Collation: ctx.SemTable.Collation,
+	}
+
+	var columns []*engine.JSONTableColumn
+	for _, def := range definitions {
+		switch {
+		case def.JtNestedPath != nil:
+			return nil, vterrors.VT12001("NESTED PATH in a JSON_TABLE evaluated by vtgate")
+		case def.JtOrdinal != nil:
+			columns = append(columns, &engine.JSONTableColumn{
+				Name:          def.JtOrdinal.Name.String(),
+				Type:          sqltypes.Uint32,
+				Ordinality:    true,
+				ASTDefinition: def.JtOrdinal.Name.String() + " for ordinality",
+			})
+		case def.JtPath.JtColExists:
+			extract, err := evalengine.Translate(&sqlparser.JSONContainsPathExpr{
+				JSONDoc:  value,
+				OneOrAll: sqlparser.NewStrLiteral("one"),
+				PathList: []sqlparser.Expr{def.JtPath.Path},
+			}, cfg)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, &engine.JSONTableColumn{
+				Name:          def.JtPath.Name.String(),
+				Type:          sqltypes.Int32,
+				Extract:       extract,
+				ASTDefinition: jsonTableColumnDefinition(def.JtPath),
+			})
+		default:
+			col, err := jsonTablePathColumn(cfg, value, def.JtPath)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, col)
+		}
+	}
+	return columns, nil
+}
+
+func jsonTablePathColumn(cfg *evalengine.Config, value *sqlparser.ColName, def *sqlparser.JtPathColDef) (*engine.JSONTableColumn, error) {
+	typ := def.Type.SQLType()
+	col := &engine.JSONTableColumn{
+		Name:          def.Name.String(),
+		Type:          typ,
+		ASTDefinition: jsonTableColumnDefinition(def),
+	}
+
+	var err error
+	col.Extract, err = evalengine.Translate(&sqlparser.JSONExtractExpr{
+		JSONDoc:  value,
+		PathList: []sqlparser.Expr{def.Path},
+	}, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// convert returns the expression casting a JSON value to the type of the column
+	convertType := jsonTableConvertType(def.Type, typ)
+	convert := func(expr sqlparser.Expr) sqlparser.Expr {
+		if convertType == nil {
+			return expr
+		}
+		return &sqlparser.CastExpr{Expr: &sqlparser.JSONUnquoteExpr{JSONValue: expr}, Type: convertType}
+	}
+	if convertType != nil {
+		col.Convert, err = evalengine.Translate(convert(value), cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	col.OnEmpty, err = jsonTableResponse(cfg, def.EmptyOnResponse, convert)
+	if err != nil {
+		return nil, err
+	}
+	col.OnError, err = jsonTableResponse(cfg, def.ErrorOnResponse, convert)
+	if err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+func jsonTableResponse(cfg *evalengine.Config, response *sqlparser.JtOnResponse, convert func(sqlparser.Expr) sqlparser.Expr) (*engine.JSONTableResponse, error) {
+	if response == nil {
+		return nil, nil
+	}
+	switch response.ResponseType {
+	case sqlparser.ErrorJSONType:
+		return &engine.JSONTableResponse{Error: true}, nil
+	case sqlparser.DefaultJSONType:
+		// the default value is a JSON text, that is converted like the values found in the JSON document
+		def, err := evalengine.Translate(convert(&sqlparser.CastExpr{Expr: response.Expr, Type: &sqlparser.ConvertType{Type: "json"}}), cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &engine.JSONTableResponse{Default: def}, nil
+	}
+	return nil, nil
+}
+
+// jsonTableConvertType returns the type to cast the values of a column to, or nil when the JSON values are kept as is
+func jsonTableConvertType(columnType *sqlparser.ColumnType, typ querypb.Type) *sqlparser.ConvertType {
+	switch {
+	case typ == sqltypes.TypeJSON:
+		return nil
+	case sqltypes.IsUnsigned(typ):
+		return &sqlparser.ConvertType{Type: "unsigned"}
+	case sqltypes.IsSigned(typ), typ == sqltypes.Year:
+		return &sqlparser.ConvertType{Type: "signed"}
+	case sqltypes.IsDecimal(typ):
+		return &sqlparser.ConvertType{Type: "decimal", Length: columnType.Length, Scale: columnType.Scale}
+	case sqltypes.IsFloat(typ):
+		return &sqlparser.ConvertType{Type: "double"}
+	case typ == sqltypes.Date:
+		return &sqlparser.ConvertType{Type: "date"}
+	case typ == sqltypes.Datetime, typ == sqltypes.Timestamp:
+		return &sqlparser.ConvertType{Type: "datetime", Length: columnType.Length}
+	case typ == sqltypes.Time:
+		return &sqlparser.ConvertType{Type: "time", Length: columnType.Length}
+	case sqltypes.IsBinary(typ):
+		return &sqlparser.ConvertType{Type: "binary", Length: columnType.Length}
+	default:
+		return &sqlparser.ConvertType{Type: "char", Length: columnType.Length, Charset: columnType.Charset}
+	}
+}
+
+func jsonTableColumnDefinition(def *sqlparser.JtPathColDef) string {
+	if def.JtColExists {
+		return fmt.Sprintf("%s %s exists path %s", def.Name.String(), sqlparser.String(def.Type), sqlparser.String(def.Path))
+	}
+	return fmt.Sprintf("%s %s path %s", def.Name.String(), sqlparser.String(def.Type), sqlparser.String(def.Path))
+}
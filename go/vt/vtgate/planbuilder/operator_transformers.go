@@ -62,10 +62,24 @@ func transformToLogicalPlan(ctx *plancontext.PlanningContext, op ops.Operator) (
 		return transformAggregator(ctx, op)
 	case *operators.Distinct:
 		return transformDistinct(ctx, op)
+	case *operators.Window:
+		return transformWindow(ctx, op)
+	case *operators.RecurseCTE:
+		return transformRecurseCTE(ctx, op)
+	case *operators.JSONTable:
+		return transformJSONTable(ctx, op)
 	case *operators.FkCascade:
 		return transformFkCascade(ctx, op)
 	case *operators.FkVerify:
 		return transformFkVerify(ctx, op)
+	case *operators.DMLWithInput:
+		return transformDMLWithInput(ctx, op)
+	case *operators.Upsert:
+		return transformUpsert(ctx, op)
+	case *operators.Replace:
+		return transformReplace(ctx, op)
+	case *operators.UpdateMove:
+		return transformUpdateMove(ctx, op)
 	}
 
 	return nil, vterrors.VT13001(fmt.Sprintf("unknown type encountered: %T (transformToLogicalPlan)", op))
@@ -109,6 +123,111 @@ func transformFkCascade(ctx *plancontext.PlanningContext, fkc *operators.FkCasca
 	return newFkCascade(parentLP, selLP, children), nil
 }
 
+// transformDMLWithInput transforms a DMLWithInput operator into a logical plan.
+func transformDMLWithInput(ctx *plancontext.PlanningContext, op *operators.DMLWithInput) (logicalPlan, error) {
+	input, err := transformToLogicalPlan(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	dml, err := transformToLogicalPlan(ctx, op.DML)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dmlWithInput{
+		input: input,
+		dml:   dml,
+		vars:  op.Vars,
+
+		rowVars: op.RowVars,
+	}, nil
+}
+
+// transformUpsert transforms an Upsert operator into a logical plan.
+func transformUpsert(ctx *plancontext.PlanningContext, op *operators.Upsert) (logicalPlan, error) {
+	// The operators of the rows are planned as separate statements.
+	// We set the semTable to nil, to avoid using an incorrect one.
+	ctx.SemTable = nil
+
+	up := &upsert{}
+	for _, source := range op.Sources {
+		sel, err := transformToLogicalPlan(ctx, source.Select)
+		if err != nil {
+			return nil, err
+		}
+		ins, err := transformToLogicalPlan(ctx, source.Insert)
+		if err != nil {
+			return nil, err
+		}
+		upd, err := transformToLogicalPlan(ctx, source.Update)
+		if err != nil {
+			return nil, err
+		}
+		up.rows = append(up.rows, upsertRow{sel: sel, ins: ins, upd: upd})
+	}
+	return up, nil
+}
+
+// transformReplace transforms a Replace operator into a logical plan.
+func transformReplace(ctx *plancontext.PlanningContext, op *operators.Replace) (logicalPlan, error) {
+	// The deletes and the inserts are planned as separate statements.
+	// We set the semTable to nil, to avoid using an incorrect one.
+	ctx.SemTable = nil
+
+	rp := &replace{}
+	for _, source := range op.Sources {
+		var row replaceRow
+		if source.Delete != nil {
+			del, err := transformToLogicalPlan(ctx, source.Delete)
+			if err != nil {
+				return nil, err
+			}
+			row.del = del
+		}
+		ins, err := transformToLogicalPlan(ctx, source.Insert)
+		if err != nil {
+			return nil, err
+		}
+		row.ins = ins
+		rp.rows = append(rp.rows, row)
+	}
+	return rp, nil
+}
+
+// transformUpdateMove transforms an UpdateMove operator into a logical plan.
+func transformUpdateMove(ctx *plancontext.PlanningContext, op *operators.UpdateMove) (logicalPlan, error) {
+	// The source, the update, the delete and the insert are planned as separate statements.
+	// We set the semTable to nil, to avoid using an incorrect one.
+	ctx.SemTable = nil
+
+	source, err := transformToLogicalPlan(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+	upd, err := transformToLogicalPlan(ctx, op.Update)
+	if err != nil {
+		return nil, err
+	}
+	del, err := transformToLogicalPlan(ctx, op.Delete)
+	if err != nil {
+		return nil, err
+	}
+	ins, err := transformToLogicalPlan(ctx, op.Insert)
+	if err != nil {
+		return nil, err
+	}
+	return &updateMove{
+		source:      source,
+		upd:         upd,
+		del:         del,
+		ins:         ins,
+		columns:     op.Columns,
+		ksidVindex:  op.KsidVindex,
+		ksidColumns: op.KsidColumns,
+	}, nil
+}
+
 func transformSubQuery(ctx *plancontext.PlanningContext, op *operators.SubQuery) (logicalPlan, error) {
 	outer, err := transformToLogicalPlan(ctx, op.Outer)
 	if err != nil {
@@ -129,11 +248,16 @@ func transformSubQuery(ctx *plancontext.PlanningContext, op *operators.SubQuery)
 		return newUncorrelatedSubquery(op.FilterType, op.SubqueryValueName, op.HasValuesName, inner, outer), nil
 	}
 
+	if op.IsProjection {
+		// the value of the subquery is computed for every row of the outer query
+		return newCorrelatedSubquery(op.FilterType, op.ArgName, op.Vars, inner, outer), nil
+	}
+
 	lhsCols, err := op.OuterExpressionsNeeded(ctx, op.Outer)
 	if err != nil {
 		return nil, err
 	}
-	return newSemiJoin(outer, inner, op.Vars, lhsCols), nil
+	return newSemiJoin(outer, inner, op.Vars, lhsCols, op.FilterType == opcode.PulloutNotExists), nil
 }
 
 // transformFkVerify transforms a FkVerify operator into a logical plan.
@@ -157,10 +281,20 @@ func transformFkVerify(ctx *plancontext.PlanningContext, fkv *operators.FkVerify
 		verify = append(verify, &verifyLP{
 			verify: lp,
 			typ:    v.Typ,
+			bvName: v.BVName,
+			cols:   v.Cols,
 		})
 	}
 
-	return newFkVerify(inputLP, verify), nil
+	var selLP logicalPlan
+	if fkv.Selection != nil {
+		selLP, err = transformToLogicalPlan(ctx, fkv.Selection)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newFkVerify(inputLP, selLP, fkv.Values, verify), nil
 }
 
 func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggregator) (logicalPlan, error) {
@@ -174,7 +308,9 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 	}
 
 	for _, aggr := range op.Aggregations {
-		if aggr.OpCode == opcode.AggregateUnassigned {
+		// AVG is split into SUM and COUNT before the aggregation is evaluated on vtgate,
+		// there is no engine aggregator for it
+		if aggr.OpCode == opcode.AggregateUnassigned || aggr.OpCode == opcode.AggregateAvg {
 			return nil, vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", sqlparser.String(aggr.Original)))
 		}
 		aggrParam := engine.NewAggregateParam(aggr.OpCode, aggr.ColOffset, aggr.Alias)
@@ -183,6 +319,9 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		aggrParam.OrigOpcode = aggr.OriginalOpCode
 		aggrParam.WCol = aggr.WSOffset
 		aggrParam.Type, aggrParam.CollationID = aggr.GetTypeCollation(ctx)
+		// only the input of the first distinct aggregation is sorted by its column
+		aggrParam.HashDistinct = aggr.OpCode.IsDistinct() &&
+			(op.DistinctExpr == nil || !ctx.SemTable.EqualsExpr(aggr.Func.GetArg(), op.DistinctExpr))
 		oa.aggregates = append(oa.aggregates, aggrParam)
 	}
 	for _, groupBy := range op.Grouping {
@@ -211,6 +350,59 @@ func transformDistinct(ctx *plancontext.PlanningContext, op *operators.Distinct)
 	return newDistinct(src, op.Columns, op.Truncate), nil
 }
 
+func transformWindow(ctx *plancontext.PlanningContext, op *operators.Window) (logicalPlan, error) {
+	src, err := transformToLogicalPlan(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+	return newWindow(src, op.PartitionBy, op.OrderBy, op.Params), nil
+}
+
+func transformRecurseCTE(ctx *plancontext.PlanningContext, op *operators.RecurseCTE) (logicalPlan, error) {
+	seed, err := transformToLogicalPlan(ctx, op.Seed)
+	if err != nil {
+		return nil, err
+	}
+	term, err := transformToLogicalPlan(ctx, op.Term)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &evalengine.Config{
+		ResolveType: ctx.SemTable.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+	}
+	var predicate evalengine.Expr
+	if op.Predicate != nil {
+		predicate, err = evalengine.Translate(op.Predicate, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	exprs := make([]evalengine.Expr, 0, len(op.Exprs))
+	for _, expr := range op.Exprs {
+		e, err := evalengine.Translate(expr, cfg)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, e)
+	}
+
+	return &recurseCTE{
+		seed:    seed,
+		term:    term,
+		tableID: op.TableID,
+		eRecurse: &engine.RecurseCTE{
+			Vars:         op.Vars,
+			Predicate:    predicate,
+			ASTPredicate: op.OriginalPredicate,
+			Exprs:        exprs,
+			Columns:      len(op.Columns),
+			Distinct:     op.Distinct,
+		},
+	}, nil
+}
+
 func transformOrdering(ctx *plancontext.PlanningContext, op *operators.Ordering) (logicalPlan, error) {
 	plan, err := transformToLogicalPlan(ctx, op.Source)
 	if err != nil {
@@ -515,6 +707,7 @@ func autoIncGenerate(gen *operators.Generate) *engine.Generate {
 	return &engine.Generate{
 		Keyspace: gen.Keyspace,
 		Query:    sqlparser.String(selNext),
+		Sequence: gen.TableName.Name.String(),
 		Values:   gen.Values,
 		Offset:   gen.Offset,
 	}
@@ -523,6 +716,9 @@ func autoIncGenerate(gen *operators.Generate) *engine.Generate {
 func generateInsertShardedQuery(ins *sqlparser.Insert) (prefix string, mids sqlparser.Values, suffix string) {
 	mids, isValues := ins.Rows.(sqlparser.Values)
 	prefixFormat := "insert %v%sinto %v%v "
+	if ins.Action == sqlparser.ReplaceAct {
+		prefixFormat = "replace %v%sinto %v%v "
+	}
 	if isValues {
 		// the mid values are filled differently
 		// with select uses sqlparser.String for sqlparser.Values
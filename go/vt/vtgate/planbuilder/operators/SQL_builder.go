@@ -263,7 +263,7 @@ func (qb *queryBuilder) joinOuterWith(other *queryBuilder, onCondition sqlparser
 func (qb *queryBuilder) sortTables() {
 	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		sel, isSel := node.(*sqlparser.Select)
-		if !isSel {
+		if !isSel || refersToEarlierTables(sel.From) {
 			return true, nil
 		}
 		ts := &tableSorter{
@@ -276,6 +276,22 @@ func (qb *queryBuilder) sortTables() {
 
 }
 
+// refersToEarlierTables returns true if one of the table expressions can refer to the tables before it,
+// in which case the order of the tables can't be changed
+func refersToEarlierTables(tables sqlparser.TableExprs) bool {
+	for _, tbl := range tables {
+		switch tbl := tbl.(type) {
+		case *sqlparser.JSONTableExpr:
+			return true
+		case *sqlparser.AliasedTableExpr:
+			if dt, ok := tbl.Expr.(*sqlparser.DerivedTable); ok && dt.Lateral {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 type tableSorter struct {
 	sel *sqlparser.Select
 	tbl *semantics.SemTable
@@ -328,6 +344,7 @@ func stripDownQuery(from, to sqlparser.SelectStatement) error {
 		toNode.Distinct = node.Distinct
 		toNode.GroupBy = node.GroupBy
 		toNode.Having = node.Having
+		toNode.Windows = node.Windows
 		toNode.OrderBy = node.OrderBy
 		toNode.Comments = node.Comments
 		toNode.Limit = node.Limit
@@ -379,6 +396,8 @@ func buildQuery(op ops.Operator, qb *queryBuilder) error {
 		return buildAggregation(op, qb)
 	case *Union:
 		return buildUnion(op, qb)
+	case *JSONTable:
+		return buildJSONTable(op, qb)
 	case *Distinct:
 		err := buildQuery(op.Source, qb)
 		if err != nil {
@@ -448,7 +467,11 @@ func buildAggregation(op *Aggregator, qb *queryBuilder) error {
 	if err != nil {
 		return err
 	}
+	dt := derivedSource(op.Source)
 	for _, column := range cols {
+		if dt != nil {
+			column = dt.selectThroughDerived(qb.ctx, column)
+		}
 		err := qb.addProjection(column)
 		if err != nil {
 			return err
@@ -456,8 +479,11 @@ func buildAggregation(op *Aggregator, qb *queryBuilder) error {
 	}
 
 	for _, by := range op.Grouping {
-		qb.addGroupBy(by.Inner)
-		simplified := by.SimplifiedExpr
+		inner, simplified := by.Inner, by.SimplifiedExpr
+		if dt != nil {
+			inner, simplified = dt.throughDerived(qb.ctx, inner), dt.throughDerived(qb.ctx, simplified)
+		}
+		qb.addGroupBy(inner)
 		if by.WSOffset != -1 {
 			qb.addGroupBy(weightStringFor(simplified))
 		}
@@ -518,7 +544,11 @@ func buildProjection(op *Projection, qb *queryBuilder) error {
 		if err != nil {
 			return err
 		}
+		dt := derivedSource(op.Source)
 		for _, column := range cols {
+			if ae, ok := column.(*sqlparser.AliasedExpr); ok && dt != nil {
+				column = dt.selectThroughDerived(qb.ctx, ae)
+			}
 			err := qb.addProjection(column)
 			if err != nil {
 				return err
@@ -552,6 +582,71 @@ func buildProjection(op *Projection, qb *queryBuilder) error {
 	return nil
 }
 
+// derivedSource returns the derived table the rows of op are selected from, if any
+func derivedSource(op ops.Operator) *Projection {
+	for {
+		switch src := op.(type) {
+		case *Projection:
+			if src.isDerived() {
+				return src
+			}
+			op = src.Source
+		case *Filter:
+			op = src.Source
+		case *Ordering:
+			op = src.Source
+		case *Limit:
+			op = src.Source
+		case *Distinct:
+			op = src.Source
+		default:
+			return nil
+		}
+	}
+}
+
+// throughDerived rewrites the qualified columns of the tables inside the derived table, that are
+// used on top of it, to the columns it exposes them as. These are the columns of the outer used
+// by correlated subqueries, which are evaluated outside the derived table
+func (p *Projection) throughDerived(ctx *plancontext.PlanningContext, expr sqlparser.Expr) sqlparser.Expr {
+	ap, err := p.GetAliasedProjections()
+	if err != nil {
+		return expr
+	}
+	return sqlparser.CopyOnRewrite(expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok || col.Qualifier.IsEmpty() || ctx.SemTable.DirectDeps(col).IsSolvedBy(p.DT.TableID) {
+			// unqualified columns are resolved by name through the derived table
+			return
+		}
+		for i, pe := range ap {
+			if !ctx.SemTable.EqualsExprWithDeps(pe.ColExpr, col) {
+				continue
+			}
+			name := pe.Original.ColumnName()
+			if i < len(p.DT.Columns) {
+				name = p.DT.Columns[i].String()
+			}
+			cursor.Replace(sqlparser.NewColNameWithQualifier(name, sqlparser.NewTableName(p.DT.Alias)))
+			return
+		}
+	}, nil).(sqlparser.Expr)
+}
+
+// selectThroughDerived is throughDerived for the columns selected on top of the derived table,
+// which keep the names they had before being rewritten
+func (p *Projection) selectThroughDerived(ctx *plancontext.PlanningContext, ae *sqlparser.AliasedExpr) *sqlparser.AliasedExpr {
+	expr := p.throughDerived(ctx, ae.Expr)
+	if expr == ae.Expr {
+		return ae
+	}
+	as := ae.As
+	if col, isCol := expr.(*sqlparser.ColName); as.IsEmpty() && (!isCol || col.Name.String() != ae.ColumnName()) {
+		as = sqlparser.NewIdentifierCI(ae.ColumnName())
+	}
+	return &sqlparser.AliasedExpr{Expr: expr, As: as}
+}
+
 func buildApplyJoin(op *ApplyJoin, qb *queryBuilder) error {
 	err := buildQuery(op.LHS, qb)
 	if err != nil {
@@ -640,7 +735,8 @@ func buildDerivedUnion(op *Horizon, qb *queryBuilder, union *sqlparser.Union) er
 	union.Distinct = opQuery.Distinct
 
 	qb.addTableExpr(op.Alias, op.Alias, TableID(op), &sqlparser.DerivedTable{
-		Select: union,
+		Lateral: op.Lateral,
+		Select:  union,
 	}, nil, op.ColumnAliases)
 
 	return nil
@@ -655,9 +751,11 @@ func buildDerivedSelect(op *Horizon, qb *queryBuilder, sel *sqlparser.Select) er
 	sel.OrderBy = opQuery.OrderBy
 	sel.GroupBy = opQuery.GroupBy
 	sel.Having = mergeHaving(sel.Having, opQuery.Having)
+	sel.Windows = opQuery.Windows
 	sel.SelectExprs = opQuery.SelectExprs
 	qb.addTableExpr(op.Alias, op.Alias, TableID(op), &sqlparser.DerivedTable{
-		Select: sel,
+		Lateral: op.Lateral,
+		Select:  sel,
 	}, nil, op.ColumnAliases)
 	for _, col := range op.Columns {
 		err := qb.addProjection(&sqlparser.AliasedExpr{Expr: col})
@@ -669,6 +767,37 @@ func buildDerivedSelect(op *Horizon, qb *queryBuilder, sel *sqlparser.Select) er
 
 }
 
+func buildJSONTable(op *JSONTable, qb *queryBuilder) error {
+	if op.Source != nil {
+		err := buildQuery(op.Source, qb)
+		if err != nil {
+			return err
+		}
+	}
+	if qb.stmt == nil {
+		qb.stmt = &sqlparser.Select{}
+	}
+	sel, ok := qb.stmt.(*sqlparser.Select)
+	if !ok {
+		return vterrors.VT13001(fmt.Sprintf("JSON_TABLE on top of %T", qb.stmt))
+	}
+	jtExpr := sqlparser.CloneRefOfJSONTableExpr(op.Expr)
+	sqlparser.RemoveKeyspaceFromColName(jtExpr.Expr)
+	sel.From = append(sel.From, jtExpr)
+
+	if len(op.Columns) == 0 {
+		return nil
+	}
+	qb.clearProjections()
+	for _, col := range op.Columns {
+		err := qb.addProjection(col)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func buildHorizon(op *Horizon, qb *queryBuilder) error {
 	err := buildQuery(op.Source, qb)
 	if err != nil {
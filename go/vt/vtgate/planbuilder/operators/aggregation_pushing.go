@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"slices"
 
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
@@ -34,6 +36,13 @@ func tryPushAggregator(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 	if aggregator.Pushed {
 		return aggregator, rewrite.SameTree, nil
 	}
+
+	// if the aggregation can't be pushed down as a whole, AVG has to be
+	// turned into SUM/COUNT before it can be split between MySQL and vtgate
+	if reachedPhase(ctx, delegateAggregation) && needsAvgSplitting(ctx, aggregator) {
+		return splitAvgAggregations(ctx, aggregator)
+	}
+
 	switch src := aggregator.Source.(type) {
 	case *Route:
 		// if we have a single sharded route, we can push it down
@@ -72,6 +81,82 @@ func reachedPhase(ctx *plancontext.PlanningContext, p Phase) bool {
 	return b
 }
 
+func needsAvgSplitting(ctx *plancontext.PlanningContext, aggregator *Aggregator) bool {
+	if route, isRoute := aggregator.Source.(*Route); isRoute &&
+		(route.IsSingleShard() || overlappingUniqueVindex(ctx, aggregator.Grouping)) {
+		// the whole aggregation will be pushed down to MySQL
+		return false
+	}
+	return slices.ContainsFunc(aggregator.Aggregations, func(aggr Aggr) bool {
+		return aggr.OpCode == opcode.AggregateAvg
+	})
+}
+
+// splitAvgAggregations turns every AVG(x) of the aggregator into a SUM(x) and a COUNT(x),
+// which can be split and pushed down like any other SUM and COUNT.
+// A projection on top of the aggregator divides the two to produce the average:
+//
+//	select avg(x) from t  =>  Projection(sum(x) / count(x))
+//	                            └── Aggregator(sum(x), count(x))
+func splitAvgAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator) (ops.Operator, *rewrite.ApplyResult, error) {
+	proj := newAliasedProjection(aggregator)
+	// the projection is now the operator seen by the operators above the aggregator
+	proj.DT, aggregator.DT = aggregator.DT, nil
+
+	var countColumns []*sqlparser.AliasedExpr
+	var countAggregations []Aggr
+	for offset, col := range aggregator.Columns {
+		idx := slices.IndexFunc(aggregator.Aggregations, func(aggr Aggr) bool {
+			return aggr.ColOffset == offset && aggr.OpCode == opcode.AggregateAvg
+		})
+		if idx < 0 {
+			if _, err := proj.addColumnWithoutPushing(ctx, col, false); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		aggr := aggregator.Aggregations[idx]
+		avg, ok := aggr.Func.(*sqlparser.Avg)
+		if !ok {
+			return nil, nil, vterrors.VT13001(fmt.Sprintf("expected AVG aggregation: %s", sqlparser.String(aggr.Original)))
+		}
+		sumExpr := &sqlparser.Sum{Arg: avg.Arg, Distinct: avg.Distinct}
+		countExpr := &sqlparser.Count{Args: sqlparser.Exprs{avg.Arg}, Distinct: avg.Distinct}
+		ctx.SemTable.CopyDependencies(avg, sumExpr)
+		ctx.SemTable.CopyDependencies(avg, countExpr)
+		ctx.SemTable.ExprTypes[countExpr] = semantics.Type{
+			Type:      sqltypes.Int64,
+			Collation: collations.DefaultCollationForType(sqltypes.Int64),
+		}
+
+		// the division is done on top of the aggregator, using the name of the original column
+		_, err := proj.addUnexploredExpr(col, &sqlparser.BinaryExpr{
+			Operator: sqlparser.DivOp,
+			Left:     sumExpr,
+			Right:    countExpr,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		sumAE := aeWrap(sumExpr)
+		aggregator.Columns[offset] = sumAE
+		aggregator.Aggregations[idx] = createAggrFromAggrFunc(sumExpr, sumAE)
+		aggregator.Aggregations[idx].ColOffset = offset
+
+		countAE := aeWrap(countExpr)
+		countAggr := createAggrFromAggrFunc(countExpr, countAE)
+		countAggr.ColOffset = len(aggregator.Columns) + len(countColumns)
+		countColumns = append(countColumns, countAE)
+		countAggregations = append(countAggregations, countAggr)
+	}
+	aggregator.Columns = append(aggregator.Columns, countColumns...)
+	aggregator.Aggregations = append(aggregator.Aggregations, countAggregations...)
+
+	return proj, rewrite.NewTree("split AVG into SUM and COUNT", proj), nil
+}
+
 // pushAggregationThroughSubquery pushes an aggregation under a subquery.
 // Any columns that are needed to evaluate the subquery needs to be added as
 // grouping columns to the aggregation being pushed down, and then after the
@@ -170,12 +255,10 @@ func pushAggregationThroughRoute(
 
 // pushAggregations splits aggregations between the original aggregator and the one we are pushing down
 func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator, aggrBelowRoute *Aggregator) error {
-	canPushDistinctAggr, distinctExpr, err := checkIfWeCanPush(ctx, aggregator)
-	if err != nil {
-		return err
-	}
+	canPushDistinctAggr, distinctExpr := checkIfWeCanPush(ctx, aggregator)
 
-	distinctAggrGroupByAdded := false
+	// the expressions of the distinct aggregations already added to the grouping below the route
+	var distinctGroupedBy []sqlparser.Expr
 
 	for i, aggr := range aggregator.Aggregations {
 		if !aggr.Distinct || canPushDistinctAggr {
@@ -186,18 +269,18 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 
 		// We handle a distinct aggregation by turning it into a group by and
 		// doing the aggregating on the vtgate level instead
-		aeDistinctExpr := aeWrap(distinctExpr)
+		innerExpr := aggr.Func.GetArg()
+		aeDistinctExpr := aeWrap(innerExpr)
 		aggrBelowRoute.Columns[aggr.ColOffset] = aeDistinctExpr
 
-		// We handle a distinct aggregation by turning it into a group by and
-		// doing the aggregating on the vtgate level instead
 		// Adding to group by can be done only once even though there are multiple distinct aggregation with same expression.
-		if !distinctAggrGroupByAdded {
-			groupBy := NewGroupBy(distinctExpr, distinctExpr, aeDistinctExpr)
-			groupBy.ColOffset = aggr.ColOffset
-			aggrBelowRoute.Grouping = append(aggrBelowRoute.Grouping, groupBy)
-			distinctAggrGroupByAdded = true
+		if slices.ContainsFunc(distinctGroupedBy, func(e sqlparser.Expr) bool { return ctx.SemTable.EqualsExpr(e, innerExpr) }) {
+			continue
 		}
+		groupBy := NewGroupBy(innerExpr, innerExpr, aeDistinctExpr)
+		groupBy.ColOffset = aggr.ColOffset
+		aggrBelowRoute.Grouping = append(aggrBelowRoute.Grouping, groupBy)
+		distinctGroupedBy = append(distinctGroupedBy, innerExpr)
 	}
 
 	if !canPushDistinctAggr {
@@ -207,10 +290,12 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 	return nil
 }
 
-func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator) (bool, sqlparser.Expr, error) {
+// checkIfWeCanPush returns true if the distinct aggregations can be pushed down as they are,
+// because the rows they aggregate can't be spread out over several shards. The first distinct
+// expression is returned, and will be used to sort the input of the aggregator.
+func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator) (bool, sqlparser.Expr) {
 	canPush := true
 	var distinctExpr sqlparser.Expr
-	var differentExpr *sqlparser.AliasedExpr
 
 	for _, aggr := range aggregator.Aggregations {
 		if !aggr.Distinct {
@@ -224,16 +309,9 @@ func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 		if distinctExpr == nil {
 			distinctExpr = innerExpr
 		}
-		if !ctx.SemTable.EqualsExpr(distinctExpr, innerExpr) {
-			differentExpr = aggr.Original
-		}
 	}
 
-	if !canPush && differentExpr != nil {
-		return false, nil, vterrors.VT12001(fmt.Sprintf("only one DISTINCT aggregation is allowed in a SELECT: %s", sqlparser.String(differentExpr)))
-	}
-
-	return canPush, distinctExpr, nil
+	return canPush, distinctExpr
 }
 
 func pushAggregationThroughFilter(
@@ -508,10 +586,7 @@ func splitAggrColumnsToLeftAndRight(
 		outerJoin: join.LeftJoin,
 	}
 
-	canPushDistinctAggr, distinctExpr, err := checkIfWeCanPush(ctx, aggregator)
-	if err != nil {
-		return nil, nil, err
-	}
+	canPushDistinctAggr, distinctExpr := checkIfWeCanPush(ctx, aggregator)
 
 	// Distinct aggregation cannot be pushed down in the join.
 	// We keep node of the distinct aggregation expression to be used later for ordering.
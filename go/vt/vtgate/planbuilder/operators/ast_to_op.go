@@ -29,6 +29,23 @@ import (
 
 const foreignKeyConstraintValues = "fkc_vals"
 
+// foreignKeyVerifyValues is the name of the bind variable holding the values verified by a foreign key verification.
+const foreignKeyVerifyValues = "fkv_vals"
+
+// foreignKeyVerifyCondition returns the condition matching the rows whose columns have the values verified
+// by a foreign key verification. The bind variable holds the values of a single column, so that the rows
+// can be routed by the vindex of the column, and the tuples of the values of several columns.
+func foreignKeyVerifyCondition(columns sqlparser.Columns, bvName string) sqlparser.Expr {
+	if len(columns) == 1 {
+		return sqlparser.NewComparisonExpr(sqlparser.InOp, sqlparser.NewColName(columns[0].String()), sqlparser.NewListArg(bvName), nil)
+	}
+	var valTuple sqlparser.ValTuple
+	for _, column := range columns {
+		valTuple = append(valTuple, sqlparser.NewColName(column.String()))
+	}
+	return sqlparser.NewComparisonExpr(sqlparser.InOp, valTuple, sqlparser.NewListArg(bvName), nil)
+}
+
 // translateQueryToOp creates an operator tree that represents the input SELECT or UNION query
 func translateQueryToOp(ctx *plancontext.PlanningContext, selStmt sqlparser.Statement) (op ops.Operator, err error) {
 	switch node := selStmt.(type) {
@@ -125,12 +142,15 @@ func findTablesContained(ctx *plancontext.PlanningContext, node sqlparser.SQLNod
 	return
 }
 
+// rewriteRemainingColumns replaces the columns of the outer query that are still used in the subquery
+// with arguments, and returns the columns that were replaced
 func rewriteRemainingColumns(
 	ctx *plancontext.PlanningContext,
 	stmt sqlparser.SelectStatement,
 	subqID semantics.TableSet,
-) sqlparser.SelectStatement {
-	return sqlparser.CopyOnRewrite(stmt, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+) (sqlparser.SelectStatement, []*sqlparser.ColName) {
+	var outerColumns []*sqlparser.ColName
+	result := sqlparser.CopyOnRewrite(stmt, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
 		colname, isColname := cursor.Node().(*sqlparser.ColName)
 		if !isColname {
 			return
@@ -141,7 +161,9 @@ func rewriteRemainingColumns(
 		}
 		rsv := ctx.GetReservedArgumentFor(colname)
 		cursor.Replace(sqlparser.NewArgument(rsv))
+		outerColumns = append(outerColumns, colname)
 	}, nil).(sqlparser.SelectStatement)
+	return result, outerColumns
 }
 
 // joinPredicateCollector is used to inspect the predicates inside the subquery, looking for any
@@ -224,6 +246,8 @@ func getOperatorFromTableExpr(ctx *plancontext.PlanningContext, tableExpr sqlpar
 		return getOperatorFromJoinTableExpr(ctx, tableExpr)
 	case *sqlparser.ParenTableExpr:
 		return crossJoin(ctx, tableExpr.Exprs)
+	case *sqlparser.JSONTableExpr:
+		return newJSONTable(ctx, nil, tableExpr)
 	default:
 		return nil, vterrors.VT13001(fmt.Sprintf("unable to use: %T table type", tableExpr))
 	}
@@ -234,6 +258,9 @@ func getOperatorFromJoinTableExpr(ctx *plancontext.PlanningContext, tableExpr *s
 	if err != nil {
 		return nil, err
 	}
+	if jtExpr, isJSONTable := tableExpr.RightExpr.(*sqlparser.JSONTableExpr); isJSONTable {
+		return createJSONTableJoin(ctx, tableExpr, lhs, jtExpr)
+	}
 	rhs, err := getOperatorFromTableExpr(ctx, tableExpr.RightExpr, false)
 	if err != nil {
 		return nil, err
@@ -243,7 +270,7 @@ func getOperatorFromJoinTableExpr(ctx *plancontext.PlanningContext, tableExpr *s
 	case sqlparser.NormalJoinType:
 		return createInnerJoin(ctx, tableExpr, lhs, rhs)
 	case sqlparser.LeftJoinType, sqlparser.RightJoinType:
-		return createOuterJoin(tableExpr, lhs, rhs)
+		return createOuterJoin(ctx, tableExpr, lhs, rhs)
 	default:
 		return nil, vterrors.VT13001("unsupported: %s", tableExpr.Join.ToString())
 	}
@@ -258,6 +285,10 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 			return nil, err
 		}
 
+		if cte, isCTE := tableInfo.(*semantics.CTETable); isCTE {
+			return newRecurseCTE(ctx, tableID, cte)
+		}
+
 		if vt, isVindex := tableInfo.(*semantics.VindexTable); isVindex {
 			solves := tableID
 			return &Vindex{
@@ -289,6 +320,7 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 			horizon.TableId = &tableID
 			horizon.Alias = tableExpr.As.String()
 			horizon.ColumnAliases = tableExpr.Columns
+			horizon.Lateral = tbl.Lateral
 			qp, err := CreateQPFromSelectStatement(ctx, tbl.Select)
 			if err != nil {
 				return nil, err
@@ -305,6 +337,15 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 func crossJoin(ctx *plancontext.PlanningContext, exprs sqlparser.TableExprs) (ops.Operator, error) {
 	var output ops.Operator
 	for _, tableExpr := range exprs {
+		if jtExpr, isJSONTable := tableExpr.(*sqlparser.JSONTableExpr); isJSONTable {
+			// the JSON document can refer to the tables before it, so they are its source
+			jt, err := newJSONTable(ctx, output, jtExpr)
+			if err != nil {
+				return nil, err
+			}
+			output = jt
+			continue
+		}
 		op, err := getOperatorFromTableExpr(ctx, tableExpr, len(exprs) == 1)
 		if err != nil {
 			return nil, err
@@ -312,7 +353,7 @@ func crossJoin(ctx *plancontext.PlanningContext, exprs sqlparser.TableExprs) (op
 		if output == nil {
 			output = op
 		} else {
-			output = createJoin(ctx, output, op)
+			output = createJoinForTableExpr(ctx, output, op, tableExpr)
 		}
 	}
 	return output, nil
@@ -75,6 +75,10 @@ func (d *Delete) Statement() sqlparser.Statement {
 }
 
 func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlparser.Delete) (ops.Operator, error) {
+	if needsDMLWithInput(ctx, deleteStmt.TableExprs, deleteStmt.Where) {
+		return createComplexDeleteOp(ctx, deleteStmt)
+	}
+
 	tableInfo, qt, err := createQueryTableForDML(ctx, deleteStmt.TableExprs[0], deleteStmt.Where)
 	if err != nil {
 		return nil, err
@@ -91,6 +95,10 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 	if err != nil {
 		return nil, err
 	}
+	if _, isDMLWithInput := delOp.(*DMLWithInput); isDMLWithInput {
+		// the foreign keys are handled when planning the delete of the selected rows
+		return delOp, nil
+	}
 
 	// Now we check for the foreign key mode and make changes if required.
 	ksMode, err := ctx.VSchema.ForeignKeyMode(vindexTable.Keyspace.Name)
@@ -113,7 +121,86 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 		return nil, vterrors.VT12001("foreign keys management at vitess with limit")
 	}
 
-	return createFkCascadeOpForDelete(ctx, delOp, delClone, childFks)
+	restrictChildFks, cascadeChildFks := splitChildFksForDelete(childFks)
+	op, err := createFkCascadeOpForDelete(ctx, delOp, delClone, cascadeChildFks)
+	if err != nil {
+		return nil, err
+	}
+	return createFkVerifyOpForDelete(ctx, op, delClone, restrictChildFks)
+}
+
+// splitChildFksForDelete splits the child foreign keys into restrict and cascade list as restrict is handled through Verify operator and cascade is handled through Cascade operator.
+func splitChildFksForDelete(fks []vindexes.ChildFKInfo) (restrictChildFks, cascadeChildFks []vindexes.ChildFKInfo) {
+	for _, fk := range fks {
+		// Any RESTRICT type foreign keys that arrive here are cross-shard/cross-keyspace RESTRICT cases,
+		// for which we verify on VTGate that the deleted rows have no child rows.
+		if fk.OnDelete.IsRestrict() {
+			restrictChildFks = append(restrictChildFks, fk)
+			continue
+		}
+		cascadeChildFks = append(cascadeChildFks, fk)
+	}
+	return
+}
+
+// createFkVerifyOpForDelete verifies that the deleted rows have no child rows, for the child foreign keys
+// that restrict the delete. The values of the parent columns of the deleted rows are selected once,
+// and each foreign key is verified by a single query on the child table, of the form:
+// select 1 from child_tbl where (<child columns>) in ::fkv_vals limit 1 lock in share mode
+// E.g:
+// Child (c1, c2) references Parent (p1, p2)
+// delete from Parent where id = 1
+// selection query:
+// select p1, p2 from Parent where id = 1 for update
+// verify query:
+// select 1 from Child where (c1, c2) in ::fkv_vals limit 1 lock in share mode
+func createFkVerifyOpForDelete(ctx *plancontext.PlanningContext, childOp ops.Operator, delStmt *sqlparser.Delete, restrictChildFks []vindexes.ChildFKInfo) (ops.Operator, error) {
+	if len(restrictChildFks) == 0 {
+		return childOp, nil
+	}
+
+	var verify []*VerifyOp
+	var selectExprs []sqlparser.SelectExpr
+	// This validates that the deleted rows don't exist on the child table.
+	for _, fk := range restrictChildFks {
+		cols, exprs := selectParentColumns(fk, len(selectExprs))
+		selectExprs = append(selectExprs, exprs...)
+
+		bvName := ctx.ReservedVars.ReserveVariable(foreignKeyVerifyValues)
+		op, err := createFkVerifyOpForChildFKForDelete(ctx, fk, bvName)
+		if err != nil {
+			return nil, err
+		}
+		verify = append(verify, &VerifyOp{
+			Op:     op,
+			Typ:    engine.ChildVerify,
+			BVName: bvName,
+			Cols:   cols,
+		})
+	}
+	selectionOp, err := createSelectionOp(ctx, selectExprs, sqlparser.CloneTableExprs(delStmt.TableExprs), sqlparser.CloneRefOfWhere(delStmt.Where), nil, sqlparser.ForUpdateLock)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FkVerify{
+		Selection: selectionOp,
+		Verify:    verify,
+		Input:     childOp,
+	}, nil
+}
+
+// createFkVerifyOpForChildFKForDelete creates the query selecting a child row that references
+// one of the parent rows whose values are in the bind variable.
+func createFkVerifyOpForChildFKForDelete(ctx *plancontext.PlanningContext, cFk vindexes.ChildFKInfo, bvName string) (ops.Operator, error) {
+	compExpr := foreignKeyVerifyCondition(cFk.ChildColumns, bvName)
+
+	return createSelectionOp(ctx,
+		sqlparser.SelectExprs{sqlparser.NewAliasedExpr(sqlparser.NewIntLiteral("1"), "")},
+		[]sqlparser.TableExpr{sqlparser.NewAliasedTableExpr(cFk.Table.GetTableName(), "")},
+		sqlparser.NewWhere(sqlparser.WhereClause, compExpr),
+		sqlparser.NewLimitWithoutOffset(1),
+		sqlparser.ShareModeLock)
 }
 
 func createDeleteOperator(
@@ -132,8 +219,14 @@ func createDeleteOperator(
 		Routing: routing,
 	}
 
+	sqc := &SubQueryBuilder{}
 	if !vindexTable.Keyspace.Sharded {
-		return route, nil
+		for _, predicate := range qt.Predicates {
+			if _, err := sqc.handleSubquery(ctx, predicate, qt.ID); err != nil {
+				return nil, err
+			}
+		}
+		return sqc.getRootOperator(route), nil
 	}
 
 	primaryVindex, vindexAndPredicates, err := getVindexInformation(qt.ID, vindexTable)
@@ -154,7 +247,6 @@ func createDeleteOperator(
 
 	del.OwnedVindexQuery = ovq
 
-	sqc := &SubQueryBuilder{}
 	for _, predicate := range qt.Predicates {
 		if subq, err := sqc.handleSubquery(ctx, predicate, qt.ID); err != nil {
 			return nil, err
@@ -167,24 +259,115 @@ func createDeleteOperator(
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && deleteStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard DELETE with LIMIT")
+	if deleteStmt.Limit != nil && canHitMultipleShards(routing) {
+		return createDeleteWithInputOp(ctx, deleteStmt, qt, vindexTable)
 	}
 
 	return sqc.getRootOperator(route), nil
 }
 
+// createComplexDeleteOp plans a delete that reads from more than one table or uses subqueries to find its rows.
+// The rows to delete are first selected by a SELECT planned across all the tables of the statement,
+// and then deleted using their primary key.
+func createComplexDeleteOp(ctx *plancontext.PlanningContext, del *sqlparser.Delete) (ops.Operator, error) {
+	if len(del.Targets) > 1 {
+		return nil, vterrors.VT12001("multi-table DELETE statement in a sharded keyspace")
+	}
+
+	target, isAliased := del.TableExprs[0].(*sqlparser.AliasedTableExpr)
+	if len(del.Targets) == 1 {
+		var err error
+		target, err = findDeleteTarget(del)
+		if err != nil {
+			return nil, err
+		}
+	} else if !isAliased {
+		return nil, vterrors.VT13001("expected AliasedTableExpr")
+	}
+	if _, isTable := target.Expr.(sqlparser.TableName); !isTable {
+		return nil, vterrors.VT03004(target.As.String())
+	}
+
+	tableInfo, qt, err := createQueryTableForDML(ctx, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	vindexTable, _, err := buildVindexTableForDML(ctx, tableInfo, qt, "delete")
+	if err != nil {
+		return nil, err
+	}
+	return createDeleteWithInputOp(ctx, del, qt, vindexTable)
+}
+
+// findDeleteTarget returns the table expression of a multi-table delete that its target refers to.
+func findDeleteTarget(del *sqlparser.Delete) (*sqlparser.AliasedTableExpr, error) {
+	name := del.Targets[0].Name
+	var target *sqlparser.AliasedTableExpr
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if target != nil {
+				return false, nil
+			}
+			if !node.As.IsEmpty() {
+				if sqlparser.Equals.IdentifierCS(node.As, name) {
+					target = node
+				}
+				return true, nil
+			}
+			if tbl, isTable := node.Expr.(sqlparser.TableName); isTable && sqlparser.Equals.IdentifierCS(tbl.Name, name) {
+				target = node
+			}
+		case *sqlparser.DerivedTable:
+			return false, nil
+		}
+		return true, nil
+	}, del.TableExprs)
+	if target == nil {
+		return nil, vterrors.VT03003(name.String())
+	}
+	return target, nil
+}
+
+// createDeleteWithInputOp plans a delete with a LIMIT that can hit multiple shards, or a delete that
+// reads from more than one table or uses subqueries to find its rows.
+// The rows to delete are first selected across all shards, and then deleted using their primary key.
+func createDeleteWithInputOp(ctx *plancontext.PlanningContext, del *sqlparser.Delete, qt *QueryTable, vTbl *vindexes.Table) (ops.Operator, error) {
+	sel, where, vars, err := createDMLWithInputSelection(ctx, qt, vTbl, del.TableExprs, del.Where, del.OrderBy, del.Limit)
+	if err != nil {
+		return nil, err
+	}
+	source, err := createOperatorFromSelect(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	dml, err := createOperatorFromDelete(ctx, &sqlparser.Delete{
+		Comments:   del.Comments,
+		Ignore:     del.Ignore,
+		TableExprs: sqlparser.TableExprs{qt.Alias},
+		Partitions: del.Partitions,
+		Where:      where,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DMLWithInput{
+		Source: source,
+		DML:    dml,
+		Vars:   vars,
+	}, nil
+}
+
 func createFkCascadeOpForDelete(ctx *plancontext.PlanningContext, parentOp ops.Operator, delStmt *sqlparser.Delete, childFks []vindexes.ChildFKInfo) (ops.Operator, error) {
+	if len(childFks) == 0 {
+		return parentOp, nil
+	}
+
 	var fkChildren []*FkChild
 	var selectExprs []sqlparser.SelectExpr
 	for _, fk := range childFks {
-		// Any RESTRICT type foreign keys that arrive here,
-		// are cross-shard/cross-keyspace RESTRICT cases, which we don't currently support.
-		if fk.OnDelete.IsRestrict() {
-			return nil, vterrors.VT12002()
-		}
-
 		// We need to select all the parent columns for the foreign key constraint, to use in the update of the child table.
 		cols, exprs := selectParentColumns(fk, len(selectExprs))
 		selectExprs = append(selectExprs, exprs...)
@@ -0,0 +1,308 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/rewrite"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+const (
+	dmlValues       = "dml_vals"
+	dmlVindexValues = "dml_vindex_vals"
+	dmlUpdateValue  = "dml_upd_val"
+)
+
+// DMLWithInput is used to represent a DML that modifies the rows selected by its Source.
+// It is used for UPDATE and DELETE statements with a LIMIT that can hit multiple shards,
+// and for the ones that read from more than one table or filter their rows using subqueries:
+// the Source selects the primary keys of the rows to modify across all shards,
+// and the DML only modifies the rows with these primary keys.
+type DMLWithInput struct {
+	Source ops.Operator
+	DML    ops.Operator
+	Vars   []engine.DMLVar
+
+	// RowVars are bound to the values of each selected row, the DML is then executed once per row.
+	RowVars []engine.DMLVar
+
+	noColumns
+	noPredicates
+}
+
+var _ ops.Operator = (*DMLWithInput)(nil)
+
+// Inputs implements the Operator interface
+func (d *DMLWithInput) Inputs() []ops.Operator {
+	return []ops.Operator{d.Source, d.DML}
+}
+
+// SetInputs implements the Operator interface
+func (d *DMLWithInput) SetInputs(inputs []ops.Operator) {
+	if len(inputs) != 2 {
+		panic("incorrect count of inputs for DMLWithInput")
+	}
+	d.Source = inputs[0]
+	d.DML = inputs[1]
+}
+
+// Clone implements the Operator interface
+func (d *DMLWithInput) Clone(inputs []ops.Operator) ops.Operator {
+	newD := *d
+	newD.SetInputs(inputs)
+	newD.Vars = slices.Clone(d.Vars)
+	newD.RowVars = slices.Clone(d.RowVars)
+	return &newD
+}
+
+// GetOrdering implements the Operator interface
+func (d *DMLWithInput) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+// ShortDescription implements the Operator interface
+func (d *DMLWithInput) ShortDescription() string {
+	return ""
+}
+
+// canHitMultipleShards returns true if a DML using this routing can be sent to more than one shard.
+// A LIMIT can't be sent along with such a DML, as it would be applied on every shard.
+func canHitMultipleShards(routing Routing) bool {
+	switch routing.OpCode() {
+	case engine.Equal, engine.IN, engine.MultiEqual, engine.SubShard, engine.Range, engine.Scatter:
+		return true
+	}
+	return false
+}
+
+// needsDMLWithInput returns true when the rows modified by a DML can't be found by routing on its target table alone,
+// because the DML reads from more than one table or filters its rows using subqueries that can't be merged with it.
+// A statement that only uses tables of a single unsharded keyspace can be sent as is.
+func needsDMLWithInput(ctx *plancontext.PlanningContext, tableExprs sqlparser.TableExprs, where *sqlparser.Where) bool {
+	if ks, _ := ctx.SemTable.SingleUnshardedKeyspace(); ks != nil {
+		return false
+	}
+	if len(tableExprs) != 1 {
+		return true
+	}
+	aliasedExpr, isAliased := tableExprs[0].(*sqlparser.AliasedTableExpr)
+	if !isAliased {
+		return true
+	}
+	if _, isTable := aliasedExpr.Expr.(sqlparser.TableName); !isTable {
+		return true
+	}
+	return ctx.DMLSubqueriesNotMerged && hasSubquery(where)
+}
+
+// hasSubquery returns true when the given WHERE clause uses a subquery.
+func hasSubquery(where *sqlparser.Where) bool {
+	if where == nil {
+		return false
+	}
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, isSubq := node.(*sqlparser.Subquery); isSubq {
+			found = true
+			return false, nil
+		}
+		return true, nil
+	}, where)
+	return found
+}
+
+// dmlHasSubquery returns true when the given statement is an UPDATE or a DELETE filtering its rows using subqueries.
+func dmlHasSubquery(stmt sqlparser.Statement) bool {
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		return hasSubquery(stmt.Where)
+	case *sqlparser.Delete:
+		return hasSubquery(stmt.Where)
+	}
+	return false
+}
+
+// dmlSubqueriesNotMerged returns true when a DML planned as a single route kept some subqueries
+// of its WHERE clause apart from its route.
+func dmlSubqueriesNotMerged(op ops.Operator) bool {
+	notMerged := false
+	_ = rewrite.Visit(op, func(op ops.Operator) error {
+		subq, isSubq := op.(*SubQuery)
+		if !isSubq || subq.IsProjection {
+			return nil
+		}
+		_ = rewrite.Visit(subq.Outer, func(op ops.Operator) error {
+			switch op.(type) {
+			case *Update, *Delete:
+				notMerged = true
+				return io.EOF
+			}
+			return nil
+		})
+		if notMerged {
+			return io.EOF
+		}
+		return nil
+	})
+	return notMerged
+}
+
+// planDMLWithInput plans a DML whose subqueries could not be merged with its route as a DMLWithInput.
+// The statement is analyzed again, and the given context is replaced by the one it is planned with.
+func planDMLWithInput(ctx *plancontext.PlanningContext, stmt sqlparser.Statement, reservedVars *sqlparser.ReservedVars) (ops.Operator, error) {
+	dmlCtx, err := plancontext.CreatePlanningContext(stmt, reservedVars, ctx.VSchema, ctx.PlannerVersion)
+	if err != nil {
+		return nil, err
+	}
+	dmlCtx.VerifyAllFKs = ctx.VerifyAllFKs
+	dmlCtx.ParentFKToIgnore = ctx.ParentFKToIgnore
+	dmlCtx.KeyspaceIDUnchanged = ctx.KeyspaceIDUnchanged
+	dmlCtx.DMLSubqueriesNotMerged = true
+	*ctx = *dmlCtx
+	return planStatement(ctx, stmt)
+}
+
+// findDMLTarget returns the aliased table expression with the given table id among the table expressions of a DML.
+func findDMLTarget(ctx *plancontext.PlanningContext, tableExprs sqlparser.TableExprs, id semantics.TableSet) *sqlparser.AliasedTableExpr {
+	var target *sqlparser.AliasedTableExpr
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if target == nil && ctx.SemTable.TableSetFor(node) == id {
+				target = node
+			}
+		case *sqlparser.DerivedTable:
+			return false, nil
+		}
+		return true, nil
+	}, tableExprs)
+	return target
+}
+
+// dmlFromTables returns the tables read by the FROM clause of a DML, without the ones inside derived tables.
+func dmlFromTables(ctx *plancontext.PlanningContext, tableExprs sqlparser.TableExprs) semantics.TableSet {
+	var tables semantics.TableSet
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			tables = tables.Merge(ctx.SemTable.TableSetFor(node))
+		case *sqlparser.DerivedTable:
+			return false, nil
+		}
+		return true, nil
+	}, tableExprs)
+	return tables
+}
+
+// createDMLWithInputSelection builds the SELECT ... FOR UPDATE used by a DMLWithInput to find the
+// rows to modify, and the WHERE clause that restricts the DML to the selected rows.
+// The selected rows are identified by their primary key and their primary vindex columns. When the
+// primary key is not the primary vindex column, the DML is also given the primary vindex values of the
+// selected rows, so that it is only routed to the shards that hold them.
+func createDMLWithInputSelection(
+	ctx *plancontext.PlanningContext,
+	qt *QueryTable,
+	vTbl *vindexes.Table,
+	tableExprs sqlparser.TableExprs,
+	where *sqlparser.Where,
+	orderBy sqlparser.OrderBy,
+	limit *sqlparser.Limit,
+) (*sqlparser.Select, *sqlparser.Where, []engine.DMLVar, error) {
+	if len(vTbl.PrimaryKey) == 0 {
+		return nil, nil, nil, vterrors.VT09015()
+	}
+
+	sel := &sqlparser.Select{
+		From:    tableExprs,
+		Where:   where,
+		OrderBy: orderBy,
+		Limit:   limit,
+		Lock:    sqlparser.ForUpdateLock,
+	}
+	// the selected columns are qualified when the rows are selected from more than one table
+	var qualifier sqlparser.TableName
+	if len(tableExprs) != 1 || tableExprs[0] != sqlparser.TableExpr(qt.Alias) {
+		qualifier = qt.Table
+		if !qt.Alias.As.IsEmpty() {
+			qualifier = sqlparser.NewTableName(qt.Alias.As.String())
+		}
+	}
+	bindCol := func(col *sqlparser.ColName) *sqlparser.ColName {
+		ctx.SemTable.Recursive[col] = qt.ID
+		ctx.SemTable.Direct[col] = qt.ID
+		return col
+	}
+	newCol := func(name sqlparser.IdentifierCI) *sqlparser.ColName {
+		return bindCol(sqlparser.NewColName(name.String()))
+	}
+	newSelectCol := func(name sqlparser.IdentifierCI) *sqlparser.ColName {
+		return bindCol(sqlparser.NewColNameWithQualifier(name.String(), qualifier))
+	}
+
+	// the primary vindex columns are added to the selected columns when they are not part of the primary key,
+	// as the primary key is only unique within a shard.
+	keyCols := slices.Clone(vTbl.PrimaryKey)
+	var vindexCols sqlparser.Columns
+	if len(vTbl.ColumnVindexes) > 0 {
+		vindexCols = vTbl.ColumnVindexes[0].Columns
+	}
+	routeByVindex := len(vindexCols) > 0 && !(len(keyCols) == 1 && len(vindexCols) == 1 && keyCols[0].Equal(vindexCols[0]))
+	for _, col := range vindexCols {
+		if keyCols.FindColumn(col) < 0 {
+			keyCols = append(keyCols, col)
+		}
+	}
+
+	var lhs sqlparser.ValTuple
+	var offsets []int
+	for idx, col := range keyCols {
+		sel.SelectExprs = append(sel.SelectExprs, aeWrap(newSelectCol(col)))
+		lhs = append(lhs, newCol(col))
+		offsets = append(offsets, idx)
+	}
+	var keyExpr sqlparser.Expr = lhs
+	if len(lhs) == 1 {
+		keyExpr = lhs[0]
+	}
+	predicates := []sqlparser.Expr{sqlparser.NewComparisonExpr(sqlparser.InOp, keyExpr, sqlparser.NewListArg(dmlValues), nil)}
+	vars := []engine.DMLVar{{BVName: dmlValues, Cols: offsets}}
+
+	if routeByVindex {
+		// these predicates are only there to route the DML to the shards of the selected rows,
+		// every column of a multi-column vindex is given the list of its own values.
+		for idx, col := range vindexCols {
+			bvName := dmlVindexValues
+			if len(vindexCols) > 1 {
+				bvName = fmt.Sprintf("%s_%d", dmlVindexValues, idx)
+			}
+			predicates = append(predicates, sqlparser.NewComparisonExpr(sqlparser.InOp, newCol(col), sqlparser.NewListArg(bvName), nil))
+			vars = append(vars, engine.DMLVar{BVName: bvName, Cols: []int{keyCols.FindColumn(col)}})
+		}
+	}
+
+	return sel, sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(predicates...)), vars, nil
+}
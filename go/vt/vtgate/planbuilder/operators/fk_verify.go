@@ -17,6 +17,7 @@ limitations under the License.
 package operators
 
 import (
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
 )
 
@@ -25,12 +26,20 @@ import (
 type VerifyOp struct {
 	Op  ops.Operator
 	Typ string
+
+	// BVName and Cols are set when the operation verifies the rows of the Selection or the Values of the FkVerify.
+	BVName string
+	Cols   []int // indexes
 }
 
 // FkVerify is used to represent a foreign key verification operation
 // as an operator. This operator is created for DML queries that require
 // verifications on the existence of the rows in the parent table (for example, INSERT and UPDATE).
 type FkVerify struct {
+	// Selection, if set, selects the rows verified by the verification operations with a BVName.
+	Selection ops.Operator
+	// Values, if set, are the inserted rows verified by the verification operations with a BVName.
+	Values [][]evalengine.Expr
 	Verify []*VerifyOp
 	Input  ops.Operator
 
@@ -46,12 +55,19 @@ func (fkv *FkVerify) Inputs() []ops.Operator {
 	for _, v := range fkv.Verify {
 		inputs = append(inputs, v.Op)
 	}
+	if fkv.Selection != nil {
+		inputs = append(inputs, fkv.Selection)
+	}
 	return inputs
 }
 
 // SetInputs implements the Operator interface
 func (fkv *FkVerify) SetInputs(operators []ops.Operator) {
 	fkv.Input = operators[0]
+	if fkv.Selection != nil {
+		fkv.Selection = operators[len(operators)-1]
+		operators = operators[:len(operators)-1]
+	}
 	if len(fkv.Verify) != len(operators)-1 {
 		panic("mismatched number of verify inputs")
 	}
@@ -63,7 +79,9 @@ func (fkv *FkVerify) SetInputs(operators []ops.Operator) {
 // Clone implements the Operator interface
 func (fkv *FkVerify) Clone(inputs []ops.Operator) ops.Operator {
 	newFkv := &FkVerify{
-		Verify: fkv.Verify,
+		Selection: fkv.Selection,
+		Values:    fkv.Values,
+		Verify:    fkv.Verify,
 	}
 	newFkv.SetInputs(inputs)
 	return newFkv
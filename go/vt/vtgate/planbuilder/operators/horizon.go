@@ -40,6 +40,7 @@ type Horizon struct {
 	TableId       *semantics.TableSet
 	Alias         string
 	ColumnAliases sqlparser.Columns // derived tables can have their column aliases specified outside the subquery
+	Lateral       bool              // true for the LATERAL derived tables that refer to the tables before them
 
 	// QP contains the QueryProjection for this op
 	QP *QueryProjection
@@ -107,6 +108,10 @@ func (h *Horizon) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.
 	if sqlparser.ContainsAggregation(newExpr) {
 		return &Filter{Source: h, Predicates: []sqlparser.Expr{expr}}, nil
 	}
+	if sel, isSel := h.Query.(*sqlparser.Select); isSel && sqlparser.ContainsWindowFunction(sel.SelectExprs) {
+		// filtering the rows before the window functions are evaluated would change their results
+		return &Filter{Source: h, Predicates: []sqlparser.Expr{expr}}, nil
+	}
 	h.Source, err = h.Source.AddPredicate(ctx, newExpr)
 	if err != nil {
 		return nil, err
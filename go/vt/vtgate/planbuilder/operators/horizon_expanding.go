@@ -79,17 +79,28 @@ func expandUnionHorizon(ctx *plancontext.PlanningContext, horizon *Horizon, unio
 }
 
 func expandSelectHorizon(ctx *plancontext.PlanningContext, horizon *Horizon, sel *sqlparser.Select) (ops.Operator, *rewrite.ApplyResult, error) {
-	op, err := createProjectionFromSelect(ctx, horizon)
+	qp, err := horizon.getQP(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	qp, err := horizon.getQP(ctx)
+	var extracted []string
+	if qp.HasWindow {
+		window, err := createWindow(ctx, horizon, sel, qp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if window != nil {
+			horizon.Source = window
+			extracted = append(extracted, "Window")
+		}
+	}
+
+	op, err := createProjectionFromSelect(ctx, horizon)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var extracted []string
 	if qp.HasAggr {
 		extracted = append(extracted, "Aggregation")
 	} else {
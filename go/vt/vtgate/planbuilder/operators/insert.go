@@ -17,6 +17,7 @@ limitations under the License.
 package operators
 
 import (
+	"slices"
 	"strconv"
 
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
@@ -139,6 +140,20 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 		return nil, err
 	}
 
+	if vindexTable.Keyspace.Sharded {
+		// A REPLACE can't be sent to the shards when it has to maintain the owned vindexes
+		// of the replaced rows, nor an upsert changing the vindex columns.
+		if ins.Action == sqlparser.ReplaceAct && len(vindexTable.Owned) > 0 {
+			return createReplaceOperator(ctx, ins, vindexTable)
+		}
+		if vindexChangedByUpsert(ins, vindexTable) {
+			return createUpsertOperator(ctx, ins, vindexTable)
+		}
+	}
+
+	// The rows are cloned before creating the insert operator, as it replaces
+	// the vindex column values with bind variables that are only set on execution.
+	rows := sqlparser.CloneInsertRows(ins.Rows)
 	insOp, err := createInsertOperator(ctx, ins, vindexTable, routing)
 	if err != nil {
 		return nil, err
@@ -155,7 +170,10 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 
 	parentFKsForInsert := vindexTable.ParentFKsNeedsHandling(ctx.VerifyAllFKs, ctx.ParentFKToIgnore)
 	if len(parentFKsForInsert) > 0 {
-		return nil, vterrors.VT12002()
+		insOp, err = createFkVerifyOpForInsert(ctx, insOp, rows, ins.Columns, parentFKsForInsert)
+		if err != nil {
+			return nil, err
+		}
 	}
 	if len(ins.OnDup) == 0 {
 		return insOp, nil
@@ -168,6 +186,118 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 	return nil, vterrors.VT12001("ON DUPLICATE KEY UPDATE with foreign keys")
 }
 
+// createFkVerifyOpForInsert verifies that the parent rows of the inserted rows exist, for the parent foreign keys
+// that are not shard scoped. The values of the child columns of the inserted rows are evaluated on execution,
+// and each foreign key is verified by a single query on the parent table, of the form:
+// select <parent columns> from parent_tbl where (<parent columns>) in ::fkv_vals lock in share mode
+// The verification fails unless a parent row is found for each tuple of values, the tuples having a NULL
+// needing no verification.
+// E.g:
+// Child (c1, c2) references Parent (p1, p2)
+// insert into Child (c1, c2) values (1, :v2), (3, 4)
+// verify query:
+// select p1, p2 from Parent where (p1, p2) in ::fkv_vals lock in share mode
+func createFkVerifyOpForInsert(ctx *plancontext.PlanningContext, insOp ops.Operator, rows sqlparser.InsertRows, columns sqlparser.Columns, parentFks []vindexes.ParentFKInfo) (ops.Operator, error) {
+	values, ok := rows.(sqlparser.Values)
+	if !ok {
+		return nil, vterrors.VT12001("INSERT INTO ... SELECT with cross-shard foreign keys")
+	}
+
+	var verify []*VerifyOp
+	// valueCols are the indexes in the inserted rows of the columns of the values verified
+	var valueCols []int
+	for _, fk := range parentFks {
+		cols, err := childColumnsForInsert(fk, columns, values, &valueCols)
+		if err != nil {
+			return nil, err
+		}
+		if cols == nil {
+			continue
+		}
+
+		bvName := ctx.ReservedVars.ReserveVariable(foreignKeyVerifyValues)
+		op, err := createFkVerifyOpForParentFKForInsert(ctx, fk, bvName)
+		if err != nil {
+			return nil, err
+		}
+		verify = append(verify, &VerifyOp{
+			Op:     op,
+			Typ:    engine.ParentExistsVerify,
+			BVName: bvName,
+			Cols:   cols,
+		})
+	}
+	if len(verify) == 0 {
+		return insOp, nil
+	}
+
+	fkValues := make([][]evalengine.Expr, 0, len(values))
+	for _, row := range values {
+		rowValues := make([]evalengine.Expr, 0, len(valueCols))
+		for _, colIdx := range valueCols {
+			var value sqlparser.Expr = &sqlparser.NullVal{}
+			if colIdx < len(row) {
+				value = row[colIdx]
+			}
+			expr, err := evalengine.Translate(value, nil)
+			if err != nil {
+				return nil, err
+			}
+			rowValues = append(rowValues, expr)
+		}
+		fkValues = append(fkValues, rowValues)
+	}
+
+	return &FkVerify{
+		Values: fkValues,
+		Verify: verify,
+		Input:  insOp,
+	}, nil
+}
+
+// childColumnsForInsert returns the indexes in the verified values of the child columns of the foreign key,
+// adding the indexes in the inserted rows of the columns that are not verified yet to valueCols.
+// The child columns missing from the column list get their default value, which is assumed to be NULL:
+// there is nothing to verify, and so nil is returned.
+func childColumnsForInsert(pFK vindexes.ParentFKInfo, columns sqlparser.Columns, values sqlparser.Values, valueCols *[]int) ([]int, error) {
+	var cols []int
+	for _, column := range pFK.ChildColumns {
+		colIdx := columns.FindColumn(column)
+		if colIdx < 0 {
+			return nil, nil
+		}
+		for _, row := range values {
+			if colIdx < len(row) && !sqlparser.IsNull(row[colIdx]) && !sqlparser.IsLiteral(row[colIdx]) {
+				return nil, vterrors.VT12001("insert expression with non-literal values with foreign key constraints")
+			}
+		}
+		idx := slices.Index(*valueCols, colIdx)
+		if idx < 0 {
+			idx = len(*valueCols)
+			*valueCols = append(*valueCols, colIdx)
+		}
+		cols = append(cols, idx)
+	}
+	return cols, nil
+}
+
+// createFkVerifyOpForParentFKForInsert creates the query selecting the parent rows
+// whose values are in the bind variable.
+func createFkVerifyOpForParentFKForInsert(ctx *plancontext.PlanningContext, pFK vindexes.ParentFKInfo, bvName string) (ops.Operator, error) {
+	var selectExprs sqlparser.SelectExprs
+	for _, column := range pFK.ParentColumns {
+		selectExprs = append(selectExprs, sqlparser.NewAliasedExpr(sqlparser.NewColName(column.String()), ""))
+	}
+	compExpr := foreignKeyVerifyCondition(pFK.ParentColumns, bvName)
+
+	return createSelectionOp(ctx,
+		selectExprs,
+		[]sqlparser.TableExpr{sqlparser.NewAliasedTableExpr(pFK.Table.GetTableName(), "")},
+		sqlparser.NewWhere(sqlparser.WhereClause, compExpr),
+		nil,
+		sqlparser.ShareModeLock)
+}
+
 func createInsertOperator(ctx *plancontext.PlanningContext, insStmt *sqlparser.Insert, vTbl *vindexes.Table, routing Routing) (ops.Operator, error) {
 	if _, target := routing.(*TargetedRouting); target {
 		return nil, vterrors.VT12001("INSERT with a target destination")
@@ -17,6 +17,8 @@ limitations under the License.
 package operators
 
 import (
+	"fmt"
+
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
@@ -30,6 +32,13 @@ type Join struct {
 	Predicate sqlparser.Expr
 	LeftJoin  bool
 
+	// Lateral is true when the RHS is a LATERAL derived table that refers to the tables of the LHS.
+	// Such a join can only be planned by merging both sides into a single route, and the
+	// LateralPredicates, the predicates of the derived table that use the tables of the LHS,
+	// are used to find out if that is possible.
+	Lateral           bool
+	LateralPredicates []sqlparser.Expr
+
 	noColumns
 }
 
@@ -41,10 +50,12 @@ func (j *Join) Clone(inputs []ops.Operator) ops.Operator {
 	clone.LHS = inputs[0]
 	clone.RHS = inputs[1]
 	return &Join{
-		LHS:       inputs[0],
-		RHS:       inputs[1],
-		Predicate: j.Predicate,
-		LeftJoin:  j.LeftJoin,
+		LHS:               inputs[0],
+		RHS:               inputs[1],
+		Predicate:         j.Predicate,
+		LeftJoin:          j.LeftJoin,
+		Lateral:           j.Lateral,
+		LateralPredicates: j.LateralPredicates,
 	}
 }
 
@@ -85,7 +96,7 @@ func (j *Join) Compact(ctx *plancontext.PlanningContext) (ops.Operator, *rewrite
 	return newOp, rewrite.NewTree("merge querygraphs into a single one", newOp), nil
 }
 
-func createOuterJoin(tableExpr *sqlparser.JoinTableExpr, lhs, rhs ops.Operator) (ops.Operator, error) {
+func createOuterJoin(ctx *plancontext.PlanningContext, tableExpr *sqlparser.JoinTableExpr, lhs, rhs ops.Operator) (ops.Operator, error) {
 	if tableExpr.Join == sqlparser.RightJoinType {
 		lhs, rhs = rhs, lhs
 	}
@@ -95,7 +106,47 @@ func createOuterJoin(tableExpr *sqlparser.JoinTableExpr, lhs, rhs ops.Operator)
 	}
 	predicate := tableExpr.Condition.On
 	sqlparser.RemoveKeyspaceFromColName(predicate)
-	return &Join{LHS: lhs, RHS: rhs, LeftJoin: true, Predicate: predicate}, nil
+	join := &Join{LHS: lhs, RHS: rhs, LeftJoin: true, Predicate: predicate}
+	if tableExpr.Join == sqlparser.LeftJoinType {
+		join.LateralPredicates, join.Lateral = lateralPredicates(ctx, lhs, tableExpr.RightExpr)
+	}
+	return join, nil
+}
+
+// createJSONTableJoin creates the operator for a JOIN with a JSON_TABLE on its right side.
+// The LHS is the source of the JSON_TABLE, and the join condition is evaluated on top of it
+func createJSONTableJoin(ctx *plancontext.PlanningContext, tableExpr *sqlparser.JoinTableExpr, lhs ops.Operator, jtExpr *sqlparser.JSONTableExpr) (ops.Operator, error) {
+	if tableExpr.Join != sqlparser.NormalJoinType {
+		return nil, vterrors.VT12001(fmt.Sprintf("%s with JSON_TABLE", tableExpr.Join.ToString()))
+	}
+	jt, err := newJSONTable(ctx, lhs, jtExpr)
+	if err != nil {
+		return nil, err
+	}
+	if tableExpr.Condition == nil || tableExpr.Condition.On == nil {
+		return jt, nil
+	}
+
+	var op ops.Operator = jt
+
+	sqc := &SubQueryBuilder{}
+	outerID := TableID(op)
+	joinPredicate := tableExpr.Condition.On
+	sqlparser.RemoveKeyspaceFromColName(joinPredicate)
+	for _, pred := range sqlparser.SplitAndExpression(nil, joinPredicate) {
+		subq, err := sqc.handleSubquery(ctx, pred, outerID)
+		if err != nil {
+			return nil, err
+		}
+		if subq != nil {
+			continue
+		}
+		op, err = op.AddPredicate(ctx, pred)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return sqc.getRootOperator(op), nil
 }
 
 func createJoin(ctx *plancontext.PlanningContext, LHS, RHS ops.Operator) ops.Operator {
@@ -112,8 +163,53 @@ func createJoin(ctx *plancontext.PlanningContext, LHS, RHS ops.Operator) ops.Ope
 	return &Join{LHS: LHS, RHS: RHS}
 }
 
+// createJoinForTableExpr creates a join between the two operators, taking care of the LATERAL derived tables on the RHS
+func createJoinForTableExpr(ctx *plancontext.PlanningContext, lhs, rhs ops.Operator, rhsExpr sqlparser.TableExpr) ops.Operator {
+	if predicates, isLateral := lateralPredicates(ctx, lhs, rhsExpr); isLateral {
+		return &Join{LHS: lhs, RHS: rhs, Lateral: true, LateralPredicates: predicates}
+	}
+	return createJoin(ctx, lhs, rhs)
+}
+
+// lateralPredicates returns true if the table expression is a LATERAL derived table that refers to the tables of
+// the LHS, along with the predicates of its WHERE clause that use the tables of the LHS
+func lateralPredicates(ctx *plancontext.PlanningContext, lhs ops.Operator, tableExpr sqlparser.TableExpr) ([]sqlparser.Expr, bool) {
+	aliasedExpr, ok := tableExpr.(*sqlparser.AliasedTableExpr)
+	if !ok {
+		return nil, false
+	}
+	dt, ok := aliasedExpr.Expr.(*sqlparser.DerivedTable)
+	if !ok || !dt.Lateral {
+		return nil, false
+	}
+
+	outer := TableID(lhs)
+	correlated := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		col, ok := node.(*sqlparser.ColName)
+		if ok && ctx.SemTable.DirectDeps(col).IsOverlapping(outer) {
+			correlated = true
+			return false, nil
+		}
+		return !correlated, nil
+	}, dt.Select)
+	if !correlated {
+		return nil, false
+	}
+
+	var predicates []sqlparser.Expr
+	if sel, ok := dt.Select.(*sqlparser.Select); ok && sel.Where != nil {
+		for _, pred := range sqlparser.SplitAndExpression(nil, sel.Where.Expr) {
+			if ctx.SemTable.RecursiveDeps(pred).IsOverlapping(outer) {
+				predicates = append(predicates, pred)
+			}
+		}
+	}
+	return predicates, true
+}
+
 func createInnerJoin(ctx *plancontext.PlanningContext, tableExpr *sqlparser.JoinTableExpr, lhs, rhs ops.Operator) (ops.Operator, error) {
-	op := createJoin(ctx, lhs, rhs)
+	op := createJoinForTableExpr(ctx, lhs, rhs, tableExpr.RightExpr)
 	sqc := &SubQueryBuilder{}
 	outerID := TableID(op)
 	joinPredicate := tableExpr.Condition.On
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/rewrite"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// JSONTable is a JSON_TABLE expression of the FROM clause. The Source contains the tables
+// that come before it in the FROM clause, which the JSON document can refer to, and is
+// nil when the JSON_TABLE is the first table. When the JSON_TABLE can't be pushed under a
+// route with its Source, it is evaluated at the vtgate level for every row of the Source.
+type JSONTable struct {
+	Source ops.Operator
+
+	TableID semantics.TableSet
+	Expr    *sqlparser.JSONTableExpr
+
+	// Columns are the columns produced by this operator. Offsets contains, for each one of them,
+	// the offset of the column in the Source or, when it is negative, -n-1 for the n-th column
+	// of the json table.
+	Columns []*sqlparser.AliasedExpr
+	Offsets []int
+
+	// Doc is the JSON document with the columns of the Source replaced by offsets.
+	// It is only used when the JSON_TABLE is evaluated at the vtgate level.
+	Doc sqlparser.Expr
+}
+
+var _ ops.Operator = (*JSONTable)(nil)
+
+func newJSONTable(ctx *plancontext.PlanningContext, source ops.Operator, expr *sqlparser.JSONTableExpr) (*JSONTable, error) {
+	tableID := ctx.SemTable.TableSetForJSONTable(expr)
+	if tableID.IsEmpty() {
+		return nil, vterrors.VT13001(fmt.Sprintf("unknown json table '%s'", expr.Alias.String()))
+	}
+	deps := ctx.SemTable.RecursiveDeps(expr.Expr)
+	if source == nil && !deps.IsEmpty() || source != nil && !deps.IsSolvedBy(TableID(source)) {
+		return nil, vterrors.VT12001("JSON_TABLE referring to tables that are not before it in the FROM clause")
+	}
+	return &JSONTable{
+		Source:  source,
+		TableID: tableID,
+		Expr:    expr,
+	}, nil
+}
+
+// Clone implements the Operator interface
+func (jt *JSONTable) Clone(inputs []ops.Operator) ops.Operator {
+	klone := *jt
+	klone.Source = nil
+	if len(inputs) > 0 {
+		klone.Source = inputs[0]
+	}
+	klone.Columns = slices.Clone(jt.Columns)
+	klone.Offsets = slices.Clone(jt.Offsets)
+	return &klone
+}
+
+// Inputs implements the Operator interface
+func (jt *JSONTable) Inputs() []ops.Operator {
+	if jt.Source == nil {
+		return nil
+	}
+	return []ops.Operator{jt.Source}
+}
+
+// SetInputs implements the Operator interface
+func (jt *JSONTable) SetInputs(inputs []ops.Operator) {
+	jt.Source = nil
+	if len(inputs) > 0 {
+		jt.Source = inputs[0]
+	}
+}
+
+func (jt *JSONTable) introducesTableID() semantics.TableSet {
+	return jt.TableID
+}
+
+func (jt *JSONTable) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (ops.Operator, error) {
+	if jt.Source != nil && ctx.SemTable.RecursiveDeps(expr).IsSolvedBy(TableID(jt.Source)) {
+		src, err := jt.Source.AddPredicate(ctx, expr)
+		if err != nil {
+			return nil, err
+		}
+		jt.Source = src
+		return jt, nil
+	}
+	return newFilter(jt, expr), nil
+}
+
+func (jt *JSONTable) AddColumn(ctx *plancontext.PlanningContext, reuse bool, gb bool, expr *sqlparser.AliasedExpr) (int, error) {
+	if reuse {
+		offset, err := jt.FindCol(ctx, expr.Expr, false)
+		if err != nil {
+			return 0, err
+		}
+		if offset >= 0 {
+			return offset, nil
+		}
+	}
+
+	if col, ok := expr.Expr.(*sqlparser.ColName); ok && ctx.SemTable.DirectDeps(col) == jt.TableID {
+		idx := slices.IndexFunc(jsonTableColumns(jt.Expr.Columns), func(name sqlparser.IdentifierCI) bool {
+			return name.Equal(col.Name)
+		})
+		if idx < 0 {
+			return 0, vterrors.VT13001(fmt.Sprintf("unknown column '%s' of the json table '%s'", sqlparser.String(col), jt.Expr.Alias.String()))
+		}
+		return jt.addColumn(expr, -idx-1), nil
+	}
+
+	if jt.Source == nil || !ctx.SemTable.RecursiveDeps(expr.Expr).IsSolvedBy(TableID(jt.Source)) {
+		return 0, vterrors.VT12001(fmt.Sprintf("cannot add '%s' expression to a JSON_TABLE", sqlparser.String(expr.Expr)))
+	}
+	offset, err := jt.Source.AddColumn(ctx, reuse, gb, expr)
+	if err != nil {
+		return 0, err
+	}
+	return jt.addColumn(expr, offset), nil
+}
+
+func (jt *JSONTable) addColumn(expr *sqlparser.AliasedExpr, offset int) int {
+	jt.Columns = append(jt.Columns, expr)
+	jt.Offsets = append(jt.Offsets, offset)
+	return len(jt.Columns) - 1
+}
+
+// inheritColumns makes the columns of the Source the first columns of the JSON_TABLE, so the
+// operators that were already using them can keep using the same offsets once the JSON_TABLE
+// has been pushed between them and the Source
+func (jt *JSONTable) inheritColumns(ctx *plancontext.PlanningContext) error {
+	if len(jt.Columns) > 0 || jt.Source == nil {
+		return nil
+	}
+	columns, err := jt.Source.GetColumns(ctx)
+	if err != nil {
+		return err
+	}
+	for i, col := range columns {
+		jt.addColumn(col, i)
+	}
+	return nil
+}
+
+func (jt *JSONTable) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) (int, error) {
+	for idx, col := range jt.Columns {
+		if ctx.SemTable.EqualsExprWithDeps(col.Expr, expr) {
+			return idx, nil
+		}
+	}
+	return -1, nil
+}
+
+func (jt *JSONTable) GetColumns(*plancontext.PlanningContext) ([]*sqlparser.AliasedExpr, error) {
+	return jt.Columns, nil
+}
+
+func (jt *JSONTable) GetSelectExprs(ctx *plancontext.PlanningContext) (sqlparser.SelectExprs, error) {
+	return transformColumnsToSelectExprs(ctx, jt)
+}
+
+func (jt *JSONTable) GetOrdering() ([]ops.OrderBy, error) {
+	if jt.Source == nil {
+		return nil, nil
+	}
+	return jt.Source.GetOrdering()
+}
+
+func (jt *JSONTable) ShortDescription() string {
+	return fmt.Sprintf("%s %s", jt.Expr.Alias.String(), sqlparser.String(jt.Expr.Expr))
+}
+
+func (jt *JSONTable) planOffsets(ctx *plancontext.PlanningContext) error {
+	if jt.Source == nil {
+		jt.Doc = jt.Expr.Expr
+		return nil
+	}
+	doc, err := useOffsets(ctx, jt.Expr.Expr, jt)
+	if err != nil {
+		return err
+	}
+	jt.Doc = doc
+	return nil
+}
+
+// tryPushJSONTable pushes the JSON_TABLE under the route of its Source, or to the side of
+// the join its JSON document depends on
+func tryPushJSONTable(ctx *plancontext.PlanningContext, in *JSONTable) (ops.Operator, *rewrite.ApplyResult, error) {
+	switch src := in.Source.(type) {
+	case *Route:
+		if err := in.inheritColumns(ctx); err != nil {
+			return nil, nil, err
+		}
+		return rewrite.Swap(in, src, "push json table into route")
+	case *ApplyJoin:
+		if len(in.Columns) > 0 {
+			// the operators above are already using the columns of the json table
+			return in, rewrite.SameTree, nil
+		}
+		deps := ctx.SemTable.RecursiveDeps(in.Expr.Expr)
+		switch {
+		case deps.IsSolvedBy(TableID(src.LHS)):
+			in.Source = src.LHS
+			if err := in.inheritColumns(ctx); err != nil {
+				return nil, nil, err
+			}
+			src.LHS = in
+		case !src.LeftJoin && deps.IsSolvedBy(TableID(src.RHS)):
+			in.Source = src.RHS
+			if err := in.inheritColumns(ctx); err != nil {
+				return nil, nil, err
+			}
+			src.RHS = in
+		default:
+			return in, rewrite.SameTree, nil
+		}
+		return src, rewrite.NewTree("push json table into join input", src), nil
+	}
+	return in, rewrite.SameTree, nil
+}
+
+// jsonTableColumns returns the names of the columns of a JSON_TABLE, including the ones of its nested paths
+func jsonTableColumns(columns []*sqlparser.JtColumnDefinition) []sqlparser.IdentifierCI {
+	var names []sqlparser.IdentifierCI
+	for _, col := range columns {
+		switch {
+		case col.JtOrdinal != nil:
+			names = append(names, col.JtOrdinal.Name)
+		case col.JtPath != nil:
+			names = append(names, col.JtPath.Name)
+		case col.JtNestedPath != nil:
+			names = append(names, jsonTableColumns(col.JtNestedPath.Columns)...)
+		}
+	}
+	return names
+}
@@ -59,6 +59,35 @@ type (
 
 // PlanQuery creates a query plan for a given SQL statement
 func PlanQuery(ctx *plancontext.PlanningContext, stmt sqlparser.Statement) (ops.Operator, error) {
+	var dmlCopy sqlparser.Statement
+	var reservedVars *sqlparser.ReservedVars
+	if !ctx.DMLSubqueriesNotMerged && dmlHasSubquery(stmt) {
+		// planning rewrites the subqueries of the statement and reserves their arguments,
+		// so both are copied in case it must be planned again
+		dmlCopy = sqlparser.CloneStatement(stmt)
+		reservedVars = ctx.ReservedVars.Clone()
+	}
+
+	op, err := planStatement(ctx, stmt)
+	if dmlCopy != nil && (err != nil || dmlSubqueriesNotMerged(op)) {
+		// the DML can't be sent as a single route with its subqueries,
+		// so it is planned again as a DMLWithInput selecting its rows first
+		op, err = planDMLWithInput(ctx, dmlCopy, reservedVars)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	_, isRoute := op.(*Route)
+	if !isRoute && ctx.SemTable.NotSingleRouteErr != nil {
+		// If we got here, we don't have a single shard plan
+		return nil, ctx.SemTable.NotSingleRouteErr
+	}
+
+	return op, err
+}
+
+func planStatement(ctx *plancontext.PlanningContext, stmt sqlparser.Statement) (ops.Operator, error) {
 	op, err := translateQueryToOp(ctx, stmt)
 	if err != nil {
 		return nil, err
@@ -77,17 +106,7 @@ func PlanQuery(ctx *plancontext.PlanningContext, stmt sqlparser.Statement) (ops.
 		return nil, err
 	}
 
-	if op, err = planQuery(ctx, op); err != nil {
-		return nil, err
-	}
-
-	_, isRoute := op.(*Route)
-	if !isRoute && ctx.SemTable.NotSingleRouteErr != nil {
-		// If we got here, we don't have a single shard plan
-		return nil, ctx.SemTable.NotSingleRouteErr
-	}
-
-	return op, err
+	return planQuery(ctx, op)
 }
 
 // Inputs implements the Operator interface
@@ -194,16 +194,25 @@ func addGroupByOnRHSOfJoin(root ops.Operator) (ops.Operator, error) {
 }
 
 func addLiteralGroupingToRHS(in *ApplyJoin) (ops.Operator, *rewrite.ApplyResult, error) {
-	_ = rewrite.Visit(in.RHS, func(op ops.Operator) error {
-		aggr, isAggr := op.(*Aggregator)
-		if !isAggr {
-			return nil
-		}
-		if len(aggr.Grouping) == 0 {
+	addLiteralGrouping(in.RHS)
+	return in, rewrite.SameTree, nil
+}
+
+// addLiteralGrouping adds a literal grouping to the aggregators without grouping.
+// The inner side of subqueries is not visited - a correlated subquery is evaluated
+// once per row of the outer, and must return a row even when there is nothing to aggregate
+func addLiteralGrouping(op ops.Operator) {
+	switch op := op.(type) {
+	case *Aggregator:
+		if len(op.Grouping) == 0 {
 			gb := sqlparser.NewIntLiteral(".0")
-			aggr.Grouping = append(aggr.Grouping, NewGroupBy(gb, gb, aeWrap(gb)))
+			op.Grouping = append(op.Grouping, NewGroupBy(gb, gb, aeWrap(gb)))
 		}
-		return nil
-	})
-	return in, rewrite.SameTree, nil
+	case *SubQuery:
+		addLiteralGrouping(op.Outer)
+		return
+	}
+	for _, input := range op.Inputs() {
+		addLiteralGrouping(input)
+	}
 }
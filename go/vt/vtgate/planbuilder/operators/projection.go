@@ -266,6 +266,35 @@ func (p *Projection) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Ex
 	return -1, nil
 }
 
+// evaluates returns true if the column is computed by this projection,
+// and is not just passed through from its input
+func (p *Projection) evaluates(ctx *plancontext.PlanningContext, expr sqlparser.Expr) bool {
+	ap, ok := p.Columns.(AliasedProjections)
+	if !ok {
+		return false
+	}
+	for _, pe := range ap {
+		if ctx.SemTable.EqualsExprWithDeps(pe.ColExpr, expr) {
+			return !pe.isSameInAndOut(ctx)
+		}
+	}
+	return false
+}
+
+// usesEvaluatedColumns returns true if the expression uses any of the columns computed by this projection
+func (p *Projection) usesEvaluatedColumns(ctx *plancontext.PlanningContext, expr sqlparser.Expr) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		e, ok := node.(sqlparser.Expr)
+		if !ok || found {
+			return !found, nil
+		}
+		found = p.evaluates(ctx, e)
+		return !found, nil
+	}, expr)
+	return found
+}
+
 func (p *Projection) addProjExpr(pe *ProjExpr) (int, error) {
 	ap, err := p.GetAliasedProjections()
 	if err != nil {
@@ -386,6 +415,13 @@ func (p *Projection) SetInputs(operators []ops.Operator) {
 }
 
 func (p *Projection) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (ops.Operator, error) {
+	if p.usesEvaluatedColumns(ctx, expr) {
+		// the predicate needs the values computed by this projection
+		return &Filter{
+			Source:     p,
+			Predicates: []sqlparser.Expr{expr},
+		}, nil
+	}
 	// we just pass through the predicate to our source
 	src, err := p.Source.AddPredicate(ctx, expr)
 	if err != nil {
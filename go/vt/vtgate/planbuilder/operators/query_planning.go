@@ -19,6 +19,7 @@ package operators
 import (
 	"fmt"
 	"io"
+	"slices"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
@@ -97,6 +98,8 @@ func runRewriters(ctx *plancontext.PlanningContext, root ops.Operator) (ops.Oper
 			return pushOrExpandHorizon(ctx, in)
 		case *Join:
 			return optimizeJoin(ctx, in)
+		case *JSONTable:
+			return tryPushJSONTable(ctx, in)
 		case *Projection:
 			return tryPushProjection(ctx, in)
 		case *Limit:
@@ -188,7 +191,8 @@ func pushOrExpandHorizon(ctx *plancontext.PlanningContext, in *Horizon) (ops.Ope
 		!needsOrdering &&
 		!qp.NeedsAggregation() &&
 		!in.selectStatement().IsDistinct() &&
-		in.selectStatement().GetLimit() == nil
+		in.selectStatement().GetLimit() == nil &&
+		(!qp.HasWindow || windowsArePartitionedByUniqueVindex(ctx, sel, in))
 
 	if canPush {
 		return rewrite.Swap(in, rb, "push horizon into route")
@@ -238,6 +242,11 @@ func pushProjectionToOuter(ctx *plancontext.PlanningContext, p *Projection, sq *
 		return p, rewrite.SameTree, nil
 	}
 
+	if sq.ValuePerRow || slices.ContainsFunc(ap, usesValuePerRow) {
+		// the values of subqueries evaluated for every row are only available on top of them
+		return p, rewrite.SameTree, nil
+	}
+
 	outer := TableID(sq.Outer)
 	for _, pe := range ap {
 		_, isOffset := pe.Info.(*Offset)
@@ -255,10 +264,18 @@ func pushProjectionToOuter(ctx *plancontext.PlanningContext, p *Projection, sq *
 		}
 	}
 	// all projections can be pushed to the outer
+	if err := exposeCorrelatedColumns(ctx, p, sq.Outer, sq); err != nil {
+		return nil, nil, err
+	}
 	sq.Outer, p.Source = p, sq.Outer
 	return sq, rewrite.NewTree("push projection into outer side of subquery", p), nil
 }
 
+func usesValuePerRow(pe *ProjExpr) bool {
+	se, ok := pe.Info.(SubQueryExpression)
+	return ok && slices.ContainsFunc(se, func(sq *SubQuery) bool { return sq.ValuePerRow })
+}
+
 func pushProjectionInVindex(
 	ctx *plancontext.PlanningContext,
 	p *Projection,
@@ -518,6 +535,9 @@ func setUpperLimit(in *Limit) (ops.Operator, *rewrite.ApplyResult, error) {
 		case *Join, *ApplyJoin, *SubQueryContainer, *SubQuery:
 			// we can't push limits down on either side
 			return rewrite.SkipChildren
+		case *Window:
+			// the window functions need all the rows of their partitions
+			return rewrite.SkipChildren
 		case *Route:
 			newSrc := &Limit{
 				Source: op.Source,
@@ -557,7 +577,7 @@ func tryPushOrdering(ctx *plancontext.PlanningContext, in *Ordering) (ops.Operat
 	case *Projection:
 		// we can move ordering under a projection if it's not introducing a column we're sorting by
 		for _, by := range in.Order {
-			if !fetchByOffset(by.SimplifiedExpr) {
+			if !fetchByOffset(by.SimplifiedExpr) || src.evaluates(ctx, by.SimplifiedExpr) {
 				return in, rewrite.SameTree, nil
 			}
 		}
@@ -731,6 +751,16 @@ func tryPushFilter(ctx *plancontext.PlanningContext, in *Filter) (ops.Operator,
 
 func pushFilterUnderProjection(ctx *plancontext.PlanningContext, filter *Filter, projection *Projection) (ops.Operator, *rewrite.ApplyResult, error) {
 	for _, p := range filter.Predicates {
+		// a predicate on a window function of a derived table is evaluated above the derived table,
+		// as the window function is not available below it
+		inner, err := projection.DT.RewriteExpression(ctx, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		if sqlparser.ContainsWindowFunction(inner) {
+			return filter, rewrite.SameTree, nil
+		}
+
 		cantPush := false
 		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 			if !fetchByOffset(node) {
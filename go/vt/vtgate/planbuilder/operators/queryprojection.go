@@ -46,6 +46,7 @@ type (
 		// If you change the contents here, please update the toString() method
 		SelectExprs  []SelectExpr
 		HasAggr      bool
+		HasWindow    bool
 		Distinct     bool
 		groupByExprs []GroupBy
 		OrderExprs   []ops.OrderBy
@@ -220,6 +221,7 @@ func createQPFromSelect(ctx *plancontext.PlanningContext, sel *sqlparser.Select)
 	if !qp.HasAggr && sel.Having != nil {
 		qp.HasAggr = containsAggr(sel.Having.Expr)
 	}
+	qp.HasWindow = sqlparser.ContainsWindowFunction(sel.SelectExprs) || sqlparser.ContainsWindowFunction(sel.OrderBy)
 	qp.calculateDistinct(ctx)
 
 	return qp, nil
@@ -312,12 +314,15 @@ func (qp *QueryProjection) addSelectExpressions(sel *sqlparser.Select) error {
 
 func containsAggr(e sqlparser.SQLNode) (hasAggr bool) {
 	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
-		switch node.(type) {
+		switch node := node.(type) {
 		case *sqlparser.Offset:
 			// offsets here indicate that a possible aggregation has already been handled by an input
 			// so we don't need to worry about aggregation in the original
 			return false, nil
 		case sqlparser.AggrFunc:
+			if sqlparser.IsWindowFunction(node) {
+				return true, nil
+			}
 			hasAggr = true
 			return false, io.EOF
 		case *sqlparser.Subquery:
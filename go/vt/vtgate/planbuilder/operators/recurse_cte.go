@@ -0,0 +1,478 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// RecurseCTE is a recursive common table expression that is evaluated at the vtgate level.
+// The Seed is the non-recursive part of the common table expression. The Term is the
+// recursive part, where the reference to the common table expression has been removed.
+// It is executed once per iteration, with the values of the rows of the previous iteration
+// bound to list arguments, and its rows are joined with them at the vtgate level.
+type RecurseCTE struct {
+	Seed, Term ops.Operator
+
+	TableID semantics.TableSet
+	Columns []*sqlparser.ColName
+
+	// Vars are the list arguments used by the Term, and the offsets of the
+	// columns of the common table expression whose values they are bound to
+	Vars map[string]int
+
+	// Predicate joins the rows of the previous iteration with the rows of the Term.
+	// Its columns are replaced by their offsets in a row of the previous iteration
+	// followed by a row of the Term. OriginalPredicate is the predicate before that.
+	Predicate, OriginalPredicate sqlparser.Expr
+
+	// Exprs compute the columns of the new rows, on the same rows as the Predicate
+	Exprs []sqlparser.Expr
+
+	// Distinct is true when the seed and the recursive part are combined with UNION DISTINCT
+	Distinct bool
+}
+
+var _ ops.Operator = (*RecurseCTE)(nil)
+
+func newRecurseCTE(ctx *plancontext.PlanningContext, tableID semantics.TableSet, tbl *semantics.CTETable) (*RecurseCTE, error) {
+	union, ok := tbl.CTE.Query.(*sqlparser.Union)
+	if !ok {
+		return nil, vterrors.VT12001(fmt.Sprintf("non-recursive common table expression '%s'", tbl.CTE.Name))
+	}
+	if len(union.OrderBy) > 0 || union.Limit != nil {
+		return nil, vterrors.VT12001("ORDER BY or LIMIT in a recursive common table expression")
+	}
+	term, ok := union.Right.(*sqlparser.Select)
+	if !ok {
+		return nil, vterrors.VT12001(fmt.Sprintf("recursive part of the common table expression '%s' is not a SELECT", tbl.CTE.Name))
+	}
+	if term.Distinct || term.GroupBy != nil || term.Having != nil || len(term.OrderBy) > 0 || term.Limit != nil || sqlparser.ContainsAggregation(term.SelectExprs) {
+		return nil, vterrors.VT12001("aggregation, DISTINCT, HAVING, ORDER BY or LIMIT in the recursive part of a common table expression")
+	}
+
+	names, authoritative := tbl.CTE.ColumnNames()
+	if !authoritative {
+		return nil, vterrors.VT09015()
+	}
+
+	tblName, err := tbl.Name()
+	if err != nil {
+		return nil, err
+	}
+	r := &RecurseCTE{
+		TableID:  tableID,
+		Vars:     map[string]int{},
+		Distinct: union.Distinct,
+	}
+	for _, name := range names {
+		r.Columns = append(r.Columns, sqlparser.NewColNameWithQualifier(name, tblName))
+	}
+
+	r.Seed, err = translateQueryToOp(ctx, union.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	term, err = r.rewriteTerm(ctx, tbl.CTE, term, names)
+	if err != nil {
+		return nil, err
+	}
+	r.Term, err = translateQueryToOp(ctx, term)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// rewriteTerm removes the reference to the common table expression from the recursive part.
+// The conditions and the expressions that use the common table expression are evaluated at the
+// vtgate level instead, and the equalities between its columns and the other tables are pushed
+// down to the recursive part as IN conditions on list arguments.
+func (r *RecurseCTE) rewriteTerm(ctx *plancontext.PlanningContext, cte *semantics.CTE, sel *sqlparser.Select, names []string) (*sqlparser.Select, error) {
+	var refs []*sqlparser.AliasedTableExpr
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if ate, ok := node.(*sqlparser.AliasedTableExpr); ok && isReferenceTo(ctx, ate, cte) {
+			refs = append(refs, ate)
+		}
+		return true, nil
+	}, sel)
+	if len(refs) != 1 {
+		return nil, vterrors.VT12001(fmt.Sprintf("recursive part of the common table expression '%s' does not reference it exactly once", cte.Name))
+	}
+	ref := refs[0]
+	refID := ctx.SemTable.TableSetFor(ref)
+
+	var from sqlparser.TableExprs
+	var conditions []sqlparser.Expr
+	found := false
+	for _, tableExpr := range sel.From {
+		newExpr, conds, removed, err := removeTableReference(tableExpr, ref)
+		if err != nil {
+			return nil, err
+		}
+		found = found || removed
+		conditions = append(conditions, conds...)
+		if newExpr != nil {
+			from = append(from, newExpr)
+		}
+	}
+	if !found {
+		return nil, vterrors.VT12001(fmt.Sprintf("reference to the common table expression '%s' in a subquery of its recursive part", cte.Name))
+	}
+	if len(from) == 0 {
+		return nil, vterrors.VT12001(fmt.Sprintf("recursive part of the common table expression '%s' without any other table", cte.Name))
+	}
+	isCTEColumn := func(node sqlparser.SQLNode) bool {
+		col, ok := node.(*sqlparser.ColName)
+		return ok && ctx.SemTable.DirectDeps(col) == refID
+	}
+	usesCTE := func(node sqlparser.SQLNode) bool {
+		uses := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			uses = uses || isCTEColumn(node)
+			return !uses, nil
+		}, node)
+		return uses
+	}
+	if usesCTE(from) {
+		return nil, vterrors.VT12001(fmt.Sprintf("reference to the common table expression '%s' in a join condition of its recursive part", cte.Name))
+	}
+
+	term := *sel
+	term.From = from
+	term.SelectExprs = nil
+	term.Where = nil
+
+	// addColumn adds the expression to the select list of the term, and returns its
+	// offset in a row of the previous iteration followed by a row of the term
+	addColumn := func(expr sqlparser.Expr) int {
+		for idx, selectExpr := range term.SelectExprs {
+			if ctx.SemTable.EqualsExprWithDeps(selectExpr.(*sqlparser.AliasedExpr).Expr, expr) {
+				return len(names) + idx
+			}
+		}
+		term.SelectExprs = append(term.SelectExprs, aeWrap(expr))
+		return len(names) + len(term.SelectExprs) - 1
+	}
+	cteOffset := func(col *sqlparser.ColName) int {
+		return slices.IndexFunc(names, func(name string) bool {
+			return strings.EqualFold(name, col.Name.String())
+		})
+	}
+	// useOffsets replaces the columns of the expression by their offsets, so it can be evaluated at the vtgate level
+	useOffsets := func(expr sqlparser.Expr) (sqlparser.Expr, error) {
+		var err error
+		rewritten := sqlparser.CopyOnRewrite(expr, func(node, _ sqlparser.SQLNode) bool {
+			if _, ok := node.(*sqlparser.Subquery); ok && err == nil {
+				err = vterrors.VT12001(fmt.Sprintf("subquery in an expression using the common table expression '%s' in its recursive part", cte.Name))
+			}
+			return err == nil
+		}, func(cursor *sqlparser.CopyOnWriteCursor) {
+			col, ok := cursor.Node().(*sqlparser.ColName)
+			if !ok || err != nil {
+				return
+			}
+			if !isCTEColumn(col) {
+				cursor.Replace(sqlparser.NewOffset(addColumn(col), col))
+				return
+			}
+			offset := cteOffset(col)
+			if offset < 0 {
+				err = vterrors.VT13001(fmt.Sprintf("unknown column '%s' of the common table expression '%s'", sqlparser.String(col), cte.Name))
+				return
+			}
+			cursor.Replace(sqlparser.NewOffset(offset, col))
+		}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return rewritten.(sqlparser.Expr), nil
+	}
+
+	for _, selectExpr := range sel.SelectExprs {
+		ae, ok := selectExpr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, vterrors.VT12001(fmt.Sprintf("'%s' in the recursive part of a common table expression", sqlparser.String(selectExpr)))
+		}
+		if !usesCTE(ae.Expr) {
+			r.Exprs = append(r.Exprs, sqlparser.NewOffset(addColumn(ae.Expr), ae.Expr))
+			continue
+		}
+		expr, err := useOffsets(ae.Expr)
+		if err != nil {
+			return nil, err
+		}
+		r.Exprs = append(r.Exprs, expr)
+	}
+
+	if sel.Where != nil {
+		conditions = append(conditions, sel.Where.Expr)
+	}
+	var where, predicates []sqlparser.Expr
+	for _, cond := range sqlparser.SplitAndExpression(nil, sqlparser.AndExpressions(conditions...)) {
+		if !usesCTE(cond) {
+			where = append(where, cond)
+			continue
+		}
+		predicates = append(predicates, cond)
+		if in := r.listArgComparison(ctx, cond, isCTEColumn, usesCTE, cteOffset); in != nil {
+			where = append(where, in)
+		}
+	}
+	if len(where) > 0 {
+		term.Where = sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(where...))
+	}
+	if len(predicates) > 0 {
+		r.OriginalPredicate = sqlparser.AndExpressions(predicates...)
+		var err error
+		r.Predicate, err = useOffsets(r.OriginalPredicate)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(term.SelectExprs) == 0 {
+		term.SelectExprs = sqlparser.SelectExprs{aeWrap(sqlparser.NewIntLiteral("1"))}
+	}
+	return &term, nil
+}
+
+// listArgComparison returns, for an equality between a column of the common table expression and an
+// expression on the other tables, the IN condition of that expression on the values of the column in
+// the rows of the previous iteration
+func (r *RecurseCTE) listArgComparison(
+	ctx *plancontext.PlanningContext,
+	cond sqlparser.Expr,
+	isCTEColumn, usesCTE func(sqlparser.SQLNode) bool,
+	cteOffset func(*sqlparser.ColName) int,
+) sqlparser.Expr {
+	cmp, ok := cond.(*sqlparser.ComparisonExpr)
+	if !ok || cmp.Operator != sqlparser.EqualOp {
+		return nil
+	}
+	col, other := cmp.Left, cmp.Right
+	if !isCTEColumn(col) {
+		col, other = cmp.Right, cmp.Left
+	}
+	if !isCTEColumn(col) || usesCTE(other) {
+		return nil
+	}
+	offset := cteOffset(col.(*sqlparser.ColName))
+	if offset < 0 {
+		return nil
+	}
+
+	var name string
+	for bvName, bvOffset := range r.Vars {
+		if bvOffset == offset {
+			name = bvName
+		}
+	}
+	if name == "" {
+		name = ctx.ReservedVars.ReserveColName(col.(*sqlparser.ColName))
+		r.Vars[name] = offset
+	}
+	return sqlparser.NewComparisonExpr(sqlparser.InOp, other, sqlparser.NewListArg(name), nil)
+}
+
+func isReferenceTo(ctx *plancontext.PlanningContext, tableExpr *sqlparser.AliasedTableExpr, cte *semantics.CTE) bool {
+	tableInfo, err := ctx.SemTable.TableInfoFor(ctx.SemTable.TableSetFor(tableExpr))
+	if err != nil {
+		return false
+	}
+	cteTable, ok := tableInfo.(*semantics.CTETable)
+	return ok && cteTable.CTE == cte
+}
+
+// removeTableReference removes the table from the table expression. The conditions of the inner joins
+// that used the table are returned, so they can be evaluated in the WHERE clause instead
+func removeTableReference(expr sqlparser.TableExpr, ref *sqlparser.AliasedTableExpr) (sqlparser.TableExpr, []sqlparser.Expr, bool, error) {
+	switch expr := expr.(type) {
+	case *sqlparser.AliasedTableExpr:
+		if expr == ref {
+			return nil, nil, true, nil
+		}
+		return expr, nil, false, nil
+	case *sqlparser.ParenTableExpr:
+		var exprs sqlparser.TableExprs
+		var conditions []sqlparser.Expr
+		found := false
+		for _, inner := range expr.Exprs {
+			newExpr, conds, removed, err := removeTableReference(inner, ref)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			found = found || removed
+			conditions = append(conditions, conds...)
+			if newExpr != nil {
+				exprs = append(exprs, newExpr)
+			}
+		}
+		if !found {
+			return expr, nil, false, nil
+		}
+		if len(exprs) == 0 {
+			return nil, conditions, true, nil
+		}
+		return &sqlparser.ParenTableExpr{Exprs: exprs}, conditions, true, nil
+	case *sqlparser.JoinTableExpr:
+		left, lhsConds, foundLeft, err := removeTableReference(expr.LeftExpr, ref)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		right, rhsConds, foundRight, err := removeTableReference(expr.RightExpr, ref)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		if !foundLeft && !foundRight {
+			return expr, nil, false, nil
+		}
+
+		conditions := append(lhsConds, rhsConds...)
+		outerJoin := expr.Join != sqlparser.NormalJoinType
+		switch {
+		case outerJoin && (left == nil || right == nil),
+			expr.Join == sqlparser.LeftJoinType && foundRight,
+			expr.Join == sqlparser.RightJoinType && foundLeft:
+			return nil, nil, false, vterrors.VT12001("reference to a recursive common table expression on the inner side of an outer join")
+		}
+		if expr.Condition != nil && len(expr.Condition.Using) > 0 {
+			return nil, nil, false, vterrors.VT12001("reference to a recursive common table expression in a JOIN with USING")
+		}
+		if expr.Condition != nil && expr.Condition.On != nil {
+			if left == nil || right == nil {
+				conditions = append(conditions, expr.Condition.On)
+			}
+		}
+
+		switch {
+		case left == nil:
+			return right, conditions, true, nil
+		case right == nil:
+			return left, conditions, true, nil
+		}
+		return &sqlparser.JoinTableExpr{
+			LeftExpr:  left,
+			Join:      expr.Join,
+			RightExpr: right,
+			Condition: expr.Condition,
+		}, conditions, true, nil
+	default:
+		return expr, nil, false, nil
+	}
+}
+
+// Clone implements the Operator interface
+func (r *RecurseCTE) Clone(inputs []ops.Operator) ops.Operator {
+	return &RecurseCTE{
+		Seed:     inputs[0],
+		Term:     inputs[1],
+		TableID:  r.TableID,
+		Columns:  slices.Clone(r.Columns),
+		Vars:     maps.Clone(r.Vars),
+		Distinct: r.Distinct,
+
+		Predicate:         r.Predicate,
+		OriginalPredicate: r.OriginalPredicate,
+		Exprs:             slices.Clone(r.Exprs),
+	}
+}
+
+// Inputs implements the Operator interface
+func (r *RecurseCTE) Inputs() []ops.Operator {
+	return []ops.Operator{r.Seed, r.Term}
+}
+
+// SetInputs implements the Operator interface
+func (r *RecurseCTE) SetInputs(operators []ops.Operator) {
+	r.Seed, r.Term = operators[0], operators[1]
+}
+
+func (r *RecurseCTE) introducesTableID() semantics.TableSet {
+	return r.TableID
+}
+
+func (r *RecurseCTE) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) (ops.Operator, error) {
+	// the predicate can't be pushed to the seed or to the recursive part without changing the rows they produce
+	return newFilter(r, expr), nil
+}
+
+func (r *RecurseCTE) AddColumn(ctx *plancontext.PlanningContext, _ bool, gb bool, expr *sqlparser.AliasedExpr) (int, error) {
+	if gb {
+		return 0, vterrors.VT13001("tried to add group by to a recursive common table expression")
+	}
+	offset, err := r.FindCol(ctx, expr.Expr, false)
+	if err != nil {
+		return 0, err
+	}
+	if offset < 0 {
+		return 0, vterrors.VT12001(fmt.Sprintf("cannot add '%s' expression to a recursive common table expression", sqlparser.String(expr.Expr)))
+	}
+	return offset, nil
+}
+
+func (r *RecurseCTE) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) (int, error) {
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok || ctx.SemTable.DirectDeps(col) != r.TableID {
+		return -1, nil
+	}
+	for idx, column := range r.Columns {
+		if column.Name.Equal(col.Name) {
+			return idx, nil
+		}
+	}
+	return -1, nil
+}
+
+func (r *RecurseCTE) GetColumns(*plancontext.PlanningContext) ([]*sqlparser.AliasedExpr, error) {
+	return slice.Map(r.Columns, colNameToExpr), nil
+}
+
+func (r *RecurseCTE) GetSelectExprs(ctx *plancontext.PlanningContext) (sqlparser.SelectExprs, error) {
+	return transformColumnsToSelectExprs(ctx, r)
+}
+
+func (r *RecurseCTE) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+func (r *RecurseCTE) ShortDescription() string {
+	var vars []string
+	for k := range r.Vars {
+		vars = append(vars, k)
+	}
+	slices.Sort(vars)
+	desc := strings.Join(vars, ", ")
+	if r.OriginalPredicate != nil {
+		desc += " on " + sqlparser.String(r.OriginalPredicate)
+	}
+	if r.Distinct {
+		return "distinct " + desc
+	}
+	return desc
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// ReplaceSource contains the operators used to replace a single row.
+type ReplaceSource struct {
+	// Delete deletes the existing rows with the same values as the row for one of the unique keys
+	// of the table. It is nil when the row can't conflict with an existing row.
+	Delete ops.Operator
+	Insert ops.Operator
+}
+
+// Replace is used to represent a REPLACE on a sharded table with owned vindexes.
+// It is executed row by row, as the delete of the existing rows having the same values
+// as the new row for one of the unique keys of the table, followed by the insert of the new row,
+// so that the owned vindexes of the replaced rows are maintained.
+type Replace struct {
+	Sources []ReplaceSource
+
+	noColumns
+	noPredicates
+}
+
+var _ ops.Operator = (*Replace)(nil)
+
+// Inputs implements the Operator interface
+func (r *Replace) Inputs() []ops.Operator {
+	var inputs []ops.Operator
+	for _, source := range r.Sources {
+		if source.Delete != nil {
+			inputs = append(inputs, source.Delete)
+		}
+		inputs = append(inputs, source.Insert)
+	}
+	return inputs
+}
+
+// SetInputs implements the Operator interface
+func (r *Replace) SetInputs(inputs []ops.Operator) {
+	for i := range r.Sources {
+		if r.Sources[i].Delete != nil {
+			if len(inputs) == 0 {
+				panic("incorrect count of inputs for Replace")
+			}
+			r.Sources[i].Delete, inputs = inputs[0], inputs[1:]
+		}
+		if len(inputs) == 0 {
+			panic("incorrect count of inputs for Replace")
+		}
+		r.Sources[i].Insert, inputs = inputs[0], inputs[1:]
+	}
+	if len(inputs) != 0 {
+		panic("incorrect count of inputs for Replace")
+	}
+}
+
+// Clone implements the Operator interface
+func (r *Replace) Clone(inputs []ops.Operator) ops.Operator {
+	newReplace := &Replace{Sources: slices.Clone(r.Sources)}
+	newReplace.SetInputs(inputs)
+	return newReplace
+}
+
+// GetOrdering implements the Operator interface
+func (r *Replace) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+// ShortDescription implements the Operator interface
+func (r *Replace) ShortDescription() string {
+	return ""
+}
+
+// createReplaceOperator plans a REPLACE on a sharded table with owned vindexes. For each row,
+// it plans the delete of the existing rows having the same values for one of the unique keys
+// of the table, followed by the insert of the row, so that a row replaces the previous rows of
+// the statement as well. It plans a single insert when none of the rows can replace an existing row.
+func createReplaceOperator(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, vTbl *vindexes.Table) (ops.Operator, error) {
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return nil, vterrors.VT12001("REPLACE INTO ... SELECT with owned vindexes")
+	}
+	if err := populateInsertColumnsForDML(ins, vTbl); err != nil {
+		return nil, err
+	}
+
+	conds := make([]sqlparser.Expr, len(rows))
+	conflicts := false
+	for i, row := range rows {
+		if len(row) != len(ins.Columns) {
+			return nil, vterrors.VT03006()
+		}
+		cond, err := conflictCondition(vTbl, ins.Columns, row)
+		if err != nil {
+			return nil, err
+		}
+		conds[i] = cond
+		conflicts = conflicts || cond != nil
+	}
+
+	if !conflicts {
+		insStmt := sqlparser.CloneRefOfInsert(ins)
+		insStmt.Action = sqlparser.InsertAct
+		return createOpFromStmt(ctx, insStmt, false /* verifyAllFKs */, "" /* fkToIgnore */)
+	}
+
+	replace := &Replace{}
+	for i, row := range rows {
+		var source ReplaceSource
+		if conds[i] != nil {
+			del := &sqlparser.Delete{
+				Comments:   ins.Comments,
+				TableExprs: sqlparser.TableExprs{sqlparser.CloneRefOfAliasedTableExpr(ins.Table)},
+				Where:      sqlparser.NewWhere(sqlparser.WhereClause, conds[i]),
+			}
+			delOp, err := createOpFromStmt(ctx, del, false /* verifyAllFKs */, "" /* fkToIgnore */)
+			if err != nil {
+				return nil, err
+			}
+			source.Delete = delOp
+		}
+
+		rowIns := sqlparser.CloneRefOfInsert(ins)
+		rowIns.Action = sqlparser.InsertAct
+		rowIns.Rows = sqlparser.Values{sqlparser.CloneValTuple(row)}
+		insOp, err := createOpFromStmt(ctx, rowIns, false /* verifyAllFKs */, "" /* fkToIgnore */)
+		if err != nil {
+			return nil, err
+		}
+		source.Insert = insOp
+		replace.Sources = append(replace.Sources, source)
+	}
+	return replace, nil
+}
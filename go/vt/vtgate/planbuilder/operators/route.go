@@ -22,6 +22,7 @@ import (
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
@@ -76,6 +77,9 @@ type (
 		OpCode      engine.Opcode
 		FoundVindex vindexes.Vindex
 		Cost        Cost
+		// Type and Collation are the type and collation of the vindex column, for the Range opcode.
+		Type      sqltypes.Type
+		Collation collations.ID
 	}
 
 	// Cost is used to make it easy to compare the Cost of two plans with each other
@@ -293,6 +297,11 @@ func equalOrEqualUnique(vindex *vindexes.ColumnVindex) engine.Opcode {
 	if vindex.IsPartialVindex() {
 		return engine.SubShard
 	}
+	// An async lookup vindex maps the values to all the shards while the stream
+	// maintaining its table lags behind, so it can't route to a single shard.
+	if lkp, ok := vindex.Vindex.(vindexes.LookupAsync); ok && lkp.IsAsync() {
+		return engine.Equal
+	}
 	if vindex.IsUnique() {
 		return engine.EqualUnique
 	}
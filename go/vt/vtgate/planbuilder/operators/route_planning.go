@@ -56,9 +56,32 @@ func pushDerived(ctx *plancontext.PlanningContext, op *Horizon) (ops.Operator, *
 }
 
 func optimizeJoin(ctx *plancontext.PlanningContext, op *Join) (ops.Operator, *rewrite.ApplyResult, error) {
+	if op.Lateral {
+		return mergeLateralJoin(ctx, op)
+	}
 	return mergeOrJoin(ctx, op.LHS, op.RHS, sqlparser.SplitAndExpression(nil, op.Predicate), !op.LeftJoin)
 }
 
+// mergeLateralJoin merges the two sides of a join with a LATERAL derived table into a single route.
+// The derived table is evaluated for every row of the LHS, which is only possible when both sides
+// are sent to the same shards. The predicates of the derived table that use the tables of the LHS
+// decide if that is the case, but they stay in the derived table and are not used as join predicates.
+func mergeLateralJoin(ctx *plancontext.PlanningContext, op *Join) (ops.Operator, *rewrite.ApplyResult, error) {
+	if lhs, _ := operatorsToRoutes(op.LHS, op.RHS); lhs == nil && !reachedPhase(ctx, initialPlanning) {
+		// the derived table might still be pushed under its route, with the predicates that use the LHS
+		return op, rewrite.SameTree, nil
+	}
+	joinPredicates := sqlparser.SplitAndExpression(nil, op.Predicate)
+	newPlan, err := mergeJoinInputs(ctx, op.LHS, op.RHS, op.LateralPredicates, newJoinMerge(joinPredicates, !op.LeftJoin))
+	if err != nil {
+		return nil, nil, err
+	}
+	if newPlan == nil {
+		return nil, nil, vterrors.VT12001("cross-shard LATERAL derived tables")
+	}
+	return newPlan, rewrite.NewTree("merge lateral join into a single route", newPlan), nil
+}
+
 func optimizeQueryGraph(ctx *plancontext.PlanningContext, op *QueryGraph) (result ops.Operator, changed *rewrite.ApplyResult, err error) {
 
 	switch {
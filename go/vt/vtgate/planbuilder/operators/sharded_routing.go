@@ -143,6 +143,8 @@ func (tr *ShardedRouting) UpdateRoutingParams(_ *plancontext.PlanningContext, rp
 	if tr.Selected != nil {
 		rp.Vindex = tr.Selected.FoundVindex
 		rp.Values = tr.Selected.Values
+		rp.Type = tr.Selected.Type
+		rp.Collation = tr.Selected.Collation
 	}
 	return nil
 }
@@ -214,6 +216,10 @@ func (tr *ShardedRouting) searchForNewVindexes(ctx *plancontext.PlanningContext,
 	case *sqlparser.IsExpr:
 		found := tr.planIsExpr(ctx, node)
 		newVindexFound = newVindexFound || found
+
+	case *sqlparser.BetweenExpr:
+		found := tr.planBetweenOp(ctx, node)
+		newVindexFound = newVindexFound || found
 	}
 
 	return nil, newVindexFound, nil
@@ -230,7 +236,9 @@ func (tr *ShardedRouting) planComparison(ctx *plancontext.PlanningContext, cmp *
 	case sqlparser.LikeOp:
 		found := tr.planLikeOp(ctx, cmp)
 		return nil, found, nil
-
+	case sqlparser.LessThanOp, sqlparser.LessEqualOp, sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+		found := tr.planRangeOp(ctx, cmp)
+		return nil, found, nil
 	}
 	return nil, false, nil
 }
@@ -309,6 +317,127 @@ func (tr *ShardedRouting) planLikeOp(ctx *plancontext.PlanningContext, node *sql
 	return tr.haveMatchingVindex(ctx, node, vdValue, column, val, selectEqual, vdx)
 }
 
+func (tr *ShardedRouting) planBetweenOp(ctx *plancontext.PlanningContext, node *sqlparser.BetweenExpr) bool {
+	column, ok := node.Left.(*sqlparser.ColName)
+	if !node.IsBetween || !ok {
+		return false
+	}
+	from := makeEvalEngineExpr(ctx, node.From)
+	to := makeEvalEngineExpr(ctx, node.To)
+	if from == nil || to == nil {
+		return false
+	}
+	return tr.haveMatchingRangeVindex(ctx, node, column, []sqlparser.Expr{node.From, node.To}, []evalengine.Expr{from, to})
+}
+
+// planRangeOp plans the comparisons that bound a column on one side as a range with the other side open.
+func (tr *ShardedRouting) planRangeOp(ctx *plancontext.PlanningContext, cmp *sqlparser.ComparisonExpr) bool {
+	op := cmp.Operator
+	column, ok := cmp.Left.(*sqlparser.ColName)
+	vdValue := cmp.Right
+	if !ok {
+		column, ok = cmp.Right.(*sqlparser.ColName)
+		if !ok {
+			return false
+		}
+		vdValue = cmp.Left
+		// 5 < col is the same as col > 5
+		switch op {
+		case sqlparser.LessThanOp:
+			op = sqlparser.GreaterThanOp
+		case sqlparser.LessEqualOp:
+			op = sqlparser.GreaterEqualOp
+		case sqlparser.GreaterThanOp:
+			op = sqlparser.LessThanOp
+		case sqlparser.GreaterEqualOp:
+			op = sqlparser.LessEqualOp
+		}
+	}
+	val := makeEvalEngineExpr(ctx, vdValue)
+	if val == nil {
+		return false
+	}
+
+	// the bounds are inclusive: the range of col > 5 contains 5, which only makes it larger than needed
+	valueExprs := []sqlparser.Expr{&sqlparser.NullVal{}, &sqlparser.NullVal{}}
+	values := []evalengine.Expr{evalengine.NullExpr, evalengine.NullExpr}
+	switch op {
+	case sqlparser.GreaterThanOp, sqlparser.GreaterEqualOp:
+		valueExprs[0], values[0] = vdValue, val
+	default:
+		valueExprs[1], values[1] = vdValue, val
+	}
+	return tr.haveMatchingRangeVindex(ctx, cmp, column, valueExprs, values)
+}
+
+// haveMatchingRangeVindex adds a Range option for every single column vindex on the column
+// that can map a range of values. The values are the lower and upper bounds of the range,
+// a NULL bound leaving the range open on that side. When the range is only bounded on one side,
+// it is also combined with the ranges open on the other side that were found before,
+// so that col > 5 and col < 10 is routed as the range between 5 and 10.
+func (tr *ShardedRouting) haveMatchingRangeVindex(
+	ctx *plancontext.PlanningContext,
+	node sqlparser.Expr,
+	column *sqlparser.ColName,
+	valueExprs []sqlparser.Expr,
+	values []evalengine.Expr,
+) bool {
+	newVindexFound := false
+	for _, v := range tr.VindexPreds {
+		if !ctx.SemTable.DirectDeps(column).IsSolvedBy(v.TableID) {
+			continue
+		}
+		rangeMapper, ok := v.ColVindex.Vindex.(vindexes.RangeMapper)
+		if !ok || !column.Name.Equal(v.ColVindex.Columns[0]) {
+			continue
+		}
+		typ, collation, _ := ctx.SemTable.TypeForExpr(column)
+
+		newOption := func(valueExprs []sqlparser.Expr, values []evalengine.Expr, predicates []sqlparser.Expr) *VindexOption {
+			return &VindexOption{
+				Values:      values,
+				ValueExprs:  valueExprs,
+				Predicates:  predicates,
+				OpCode:      engine.Range,
+				FoundVindex: rangeMapper,
+				Cost:        costFor(v.ColVindex, engine.Range),
+				Ready:       true,
+				Type:        typ,
+				Collation:   collation,
+			}
+		}
+		options := []*VindexOption{newOption(valueExprs, values, []sqlparser.Expr{node})}
+		if open := openRangeSide(valueExprs); open >= 0 {
+			for _, option := range v.Options {
+				if option.OpCode != engine.Range || openRangeSide(option.ValueExprs) != 1-open {
+					continue
+				}
+				combinedExprs := slices.Clone(option.ValueExprs)
+				combinedValues := slices.Clone(option.Values)
+				combinedExprs[1-open], combinedValues[1-open] = valueExprs[1-open], values[1-open]
+				predicates := append(slices.Clone(option.Predicates), node)
+				options = append(options, newOption(combinedExprs, combinedValues, predicates))
+			}
+		}
+		v.Options = append(v.Options, options...)
+		newVindexFound = true
+	}
+	return newVindexFound
+}
+
+// openRangeSide returns the index of the only open bound of a range, or -1.
+func openRangeSide(valueExprs []sqlparser.Expr) int {
+	_, startOpen := valueExprs[0].(*sqlparser.NullVal)
+	_, endOpen := valueExprs[1].(*sqlparser.NullVal)
+	switch {
+	case startOpen && !endOpen:
+		return 0
+	case endOpen && !startOpen:
+		return 1
+	}
+	return -1
+}
+
 func (tr *ShardedRouting) Cost() int {
 	switch tr.RouteOpCode {
 	case engine.EqualUnique:
@@ -319,6 +448,8 @@ func (tr *ShardedRouting) Cost() int {
 		return 10
 	case engine.MultiEqual:
 		return 10
+	case engine.Range:
+		return 15
 	case engine.Scatter:
 		return 20
 	default:
@@ -600,7 +731,7 @@ func tryMergeJoinShardedRouting(
 		// can merge via join predicates instead.
 		fallthrough
 
-	case engine.Scatter, engine.IN, engine.None:
+	case engine.Scatter, engine.IN, engine.Range, engine.None:
 		if len(joinPredicates) == 0 {
 			// If we are doing two Scatters, we have to make sure that the
 			// joins are on the correct vindex to allow them to be merged
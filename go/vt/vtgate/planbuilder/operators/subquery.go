@@ -48,10 +48,14 @@ type SubQuery struct {
 	HasValuesName     string               // Argument name passed to the subquery (uncorrelated queries).
 
 	// Fields related to correlated subqueries:
-	Vars    map[string]int // Arguments copied from outer to inner, set during offset planning.
-	outerID semantics.TableSet
+	Vars         map[string]int // Arguments copied from outer to inner, set during offset planning.
+	outerID      semantics.TableSet
+	outerColumns []*sqlparser.ColName // Columns of the outer used by the subquery outside of the predicates.
 
 	IsProjection bool
+	// ValuePerRow is set for the subqueries of the SELECT list that are evaluated for every row
+	// of the outer. Their value is the first column of the SubQuery.
+	ValuePerRow bool
 }
 
 func (sq *SubQuery) planOffsets(ctx *plancontext.PlanningContext) error {
@@ -86,6 +90,23 @@ func (sq *SubQuery) OuterExpressionsNeeded(ctx *plancontext.PlanningContext, out
 			result = append(result, col)
 		}
 	}
+	if len(sq.Predicates) == 0 || sq.IsProjection {
+		return result, nil
+	}
+	// the comparison of correlated filters is evaluated together with the subquery,
+	// so the outer columns it uses are needed as well
+	outerID := TableID(outer)
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			return false, nil
+		case *sqlparser.ColName:
+			if ctx.SemTable.RecursiveDeps(node).IsSolvedBy(outerID) {
+				result = append(result, node)
+			}
+		}
+		return true, nil
+	}, sq.Original)
 	return result, nil
 }
 
@@ -107,10 +128,44 @@ func (sq *SubQuery) GetJoinColumns(ctx *plancontext.PlanningContext, outer ops.O
 	if err != nil {
 		return nil, err
 	}
+	if len(joinPredicates) > 0 {
+		// the other columns of the outer used by the subquery have to be passed along too
+		joinPredicates = append(joinPredicates, sq.outerColumnsFrom(ctx, outerID, joinPredicates)...)
+	}
 	sq.JoinColumns = joinPredicates
 	return sq.JoinColumns, nil
 }
 
+// outerColumnsFrom returns the join columns for the columns of the outer that
+// are used by the subquery, and that are not already part of the join predicates
+func (sq *SubQuery) outerColumnsFrom(ctx *plancontext.PlanningContext, outerID semantics.TableSet, joinPredicates []JoinColumn) []JoinColumn {
+	var result []JoinColumn
+	known := func(name string) bool {
+		for _, jc := range append(joinPredicates, result...) {
+			for _, bve := range jc.LHSExprs {
+				if bve.Name == name {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, col := range sq.outerColumns {
+		if !ctx.SemTable.RecursiveDeps(col).IsSolvedBy(outerID) {
+			continue
+		}
+		name := ctx.GetReservedArgumentFor(col)
+		if known(name) {
+			continue
+		}
+		result = append(result, JoinColumn{
+			LHSExprs: []BindVarExpr{{Name: name, Expr: col}},
+			RHSExpr:  sqlparser.NewArgument(name),
+		})
+	}
+	return result
+}
+
 // Clone implements the Operator interface
 func (sq *SubQuery) Clone(inputs []ops.Operator) ops.Operator {
 	klone := *sq
@@ -126,6 +181,7 @@ func (sq *SubQuery) Clone(inputs []ops.Operator) ops.Operator {
 	klone.JoinColumns = slices.Clone(sq.JoinColumns)
 	klone.Vars = maps.Clone(sq.Vars)
 	klone.Predicates = sqlparser.CloneExprs(sq.Predicates)
+	klone.outerColumns = slices.Clone(sq.outerColumns)
 	return &klone
 }
 
@@ -181,18 +237,52 @@ func (sq *SubQuery) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparse
 }
 
 func (sq *SubQuery) AddColumn(ctx *plancontext.PlanningContext, reuseExisting bool, addToGroupBy bool, exprs *sqlparser.AliasedExpr) (int, error) {
-	return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs)
+	if !sq.ValuePerRow {
+		return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs)
+	}
+	if sq.isValueColumn(exprs.Expr) {
+		return 0, nil
+	}
+	offset, err := sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs)
+	if err != nil {
+		return 0, err
+	}
+	return offset + 1, nil
 }
 
 func (sq *SubQuery) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, underRoute bool) (int, error) {
-	return sq.Outer.FindCol(ctx, expr, underRoute)
+	if !sq.ValuePerRow {
+		return sq.Outer.FindCol(ctx, expr, underRoute)
+	}
+	if sq.isValueColumn(expr) {
+		return 0, nil
+	}
+	offset, err := sq.Outer.FindCol(ctx, expr, underRoute)
+	if err != nil || offset < 0 {
+		return offset, err
+	}
+	return offset + 1, nil
+}
+
+// isValueColumn returns true if the expression is the column that
+// the subquery expression was replaced with in the SELECT list
+func (sq *SubQuery) isValueColumn(expr sqlparser.Expr) bool {
+	col, ok := expr.(*sqlparser.ColName)
+	return ok && col.Qualifier.IsEmpty() && col.Name.EqualString(sq.ArgName)
 }
 
 func (sq *SubQuery) GetColumns(ctx *plancontext.PlanningContext) ([]*sqlparser.AliasedExpr, error) {
-	return sq.Outer.GetColumns(ctx)
+	columns, err := sq.Outer.GetColumns(ctx)
+	if err != nil || !sq.ValuePerRow {
+		return columns, err
+	}
+	return append([]*sqlparser.AliasedExpr{aeWrap(sqlparser.NewColName(sq.ArgName))}, columns...), nil
 }
 
 func (sq *SubQuery) GetSelectExprs(ctx *plancontext.PlanningContext) (sqlparser.SelectExprs, error) {
+	if sq.ValuePerRow {
+		return transformColumnsToSelectExprs(ctx, sq)
+	}
 	return sq.Outer.GetSelectExprs(ctx)
 }
 
@@ -209,9 +299,15 @@ func (sq *SubQuery) settle(ctx *plancontext.PlanningContext, outer ops.Operator)
 		return nil, subqueryNotAtTopErr
 	}
 	if sq.IsProjection {
-		if len(sq.GetMergePredicates()) > 0 {
-			// this means that we have a correlated subquery on our hands
-			return nil, correlatedSubqueryErr
+		if sq.ValuePerRow {
+			switch sq.FilterType {
+			case opcode.PulloutValue, opcode.PulloutExists:
+			default:
+				return nil, correlatedProjectionErr
+			}
+		}
+		if err := sq.checkCorrelation(ctx, outer); err != nil {
+			return nil, err
 		}
 		sq.SubqueryValueName = sq.ArgName
 		return outer, nil
@@ -219,15 +315,53 @@ func (sq *SubQuery) settle(ctx *plancontext.PlanningContext, outer ops.Operator)
 	return sq.settleFilter(ctx, outer)
 }
 
-var correlatedSubqueryErr = vterrors.VT12001("correlated subquery is only supported for EXISTS")
+// checkCorrelation makes sure that everything the subquery needs from the outer query
+// can be sent to it when it is evaluated for every row of the outer
+func (sq *SubQuery) checkCorrelation(ctx *plancontext.PlanningContext, outer ops.Operator) error {
+	if len(sq.Predicates) == 0 {
+		return nil
+	}
+	outerID := TableID(outer)
+	availableID := outerID.Merge(TableID(sq.Subquery))
+	for _, pred := range sq.Predicates {
+		if !ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(availableID) {
+			return correlatedOuterQueryErr
+		}
+	}
+	for _, col := range sq.outerColumns {
+		if !ctx.SemTable.RecursiveDeps(col).IsSolvedBy(outerID) {
+			return correlatedOuterQueryErr
+		}
+	}
+
+	columns, err := sq.GetJoinColumns(ctx, outer)
+	if err != nil {
+		return err
+	}
+	for _, jc := range columns {
+		for _, lhsExpr := range jc.LHSExprs {
+			if sqlparser.ContainsAggregation(lhsExpr.Expr) {
+				return vterrors.VT12001("aggregation of the outer query in a correlated subquery: " + sqlparser.String(lhsExpr.Expr))
+			}
+		}
+	}
+	return nil
+}
+
+var correlatedProjectionErr = vterrors.VT12001("correlated IN or NOT IN subquery in the SELECT list")
+var correlatedLimitErr = vterrors.VT12001("correlated comparison or IN subquery with LIMIT")
+var correlatedOuterQueryErr = vterrors.VT12001("correlated subquery using columns of a query that is not its direct outer query")
 var subqueryNotAtTopErr = vterrors.VT12001("unmergable subquery can not be inside complex expression")
 
+// isCorrelatedProjection returns true for correlated subqueries in the SELECT list,
+// which can only be evaluated once we know the values of the outer row
+func (sq *SubQuery) isCorrelatedProjection() bool {
+	return sq.IsProjection && len(sq.Predicates) > 0
+}
+
 func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer ops.Operator) (ops.Operator, error) {
 	if len(sq.Predicates) > 0 {
-		if sq.FilterType != opcode.PulloutExists {
-			return nil, correlatedSubqueryErr
-		}
-		return outer, nil
+		return sq.settleCorrelatedFilter(ctx, outer)
 	}
 
 	hasValuesArg := func() string {
@@ -274,6 +408,90 @@ func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer ops.Ope
 	}, nil
 }
 
+// settleCorrelatedFilter turns correlated subquery filters into a semi-join on the outer.
+// EXISTS and NOT EXISTS are evaluated as they are, and IN, NOT IN and comparison subqueries
+// are rewritten to be EXISTS and NOT EXISTS subqueries, by pushing the comparison with
+// the outer expression into the subquery:
+//
+//	outer.x IN (SELECT inner.y ...)     => EXISTS (SELECT ... HAVING inner.y = :outer_x)
+//	outer.x NOT IN (SELECT inner.y ...) => NOT EXISTS (SELECT ... HAVING inner.y = :outer_x OR inner.y IS NULL OR :outer_x IS NULL)
+//	outer.x > (SELECT inner.y ...)      => EXISTS (SELECT ... HAVING :outer_x > inner.y)
+//
+// The NULL checks of NOT IN are needed because the predicate is only true when no row
+// of the subquery can be equal to the outer expression.
+func (sq *SubQuery) settleCorrelatedFilter(ctx *plancontext.PlanningContext, outer ops.Operator) (ops.Operator, error) {
+	switch sq.FilterType {
+	case opcode.PulloutExists, opcode.PulloutNotExists:
+		if err := sq.checkCorrelation(ctx, outer); err != nil {
+			return nil, err
+		}
+		return outer, nil
+	}
+
+	if sq.originalSubquery.Select.GetLimit() != nil {
+		// the comparison can't be pushed under the LIMIT of the subquery
+		return nil, correlatedLimitErr
+	}
+
+	cmp, ok := sq.Original.(*sqlparser.ComparisonExpr)
+	if !ok {
+		return nil, vterrors.VT13001("expected a comparison with the subquery: " + sqlparser.String(sq.Original))
+	}
+	_, outside := semantics.GetSubqueryAndOtherSide(cmp)
+	if _, isTuple := outside.(sqlparser.ValTuple); isTuple || cmp.Operator == sqlparser.NullSafeEqualOp {
+		return nil, vterrors.VT12001("correlated subquery in: " + sqlparser.String(cmp))
+	}
+	// the outer predicate compares the outer expression with the first column of the subquery
+	outerPred, ok := sq.OuterPredicate.(*sqlparser.ComparisonExpr)
+	if !ok {
+		return nil, vterrors.VT12001("correlated comparison or IN subquery with '*' in the SELECT list")
+	}
+	innerExpr := outerPred.Right
+
+	// replace the subquery with the expression it returns. The semantic information of the
+	// subquery is not copied over, since the expression does not depend on the outer tables
+	pred := sqlparser.CopyOnRewrite(cmp, dontEnterSubqueries, func(cursor *sqlparser.CopyOnWriteCursor) {
+		if _, ok := cursor.Node().(*sqlparser.Subquery); ok {
+			cursor.Replace(innerExpr)
+		}
+	}, nil).(*sqlparser.ComparisonExpr)
+
+	var predicate sqlparser.Expr = pred
+	switch sq.FilterType {
+	case opcode.PulloutIn:
+		pred.Operator = sqlparser.EqualOp
+		sq.FilterType = opcode.PulloutExists
+	case opcode.PulloutNotIn:
+		pred.Operator = sqlparser.EqualOp
+		predicate = &sqlparser.OrExpr{
+			Left: &sqlparser.OrExpr{
+				Left:  pred,
+				Right: &sqlparser.IsExpr{Left: innerExpr, Right: sqlparser.IsNullOp},
+			},
+			Right: &sqlparser.IsExpr{Left: outside, Right: sqlparser.IsNullOp},
+		}
+		sq.FilterType = opcode.PulloutNotExists
+	default:
+		sq.FilterType = opcode.PulloutExists
+	}
+
+	jc, err := BreakExpressionInLHSandRHS(ctx, predicate, TableID(outer))
+	if err != nil {
+		return nil, err
+	}
+	sq.Subquery, err = sq.Subquery.AddPredicate(ctx, jc.RHSExpr)
+	if err != nil {
+		return nil, err
+	}
+	sq.Predicates = append(sq.Predicates, predicate)
+	// the join columns will be computed again with the new predicate
+	sq.JoinColumns = nil
+	if err := sq.checkCorrelation(ctx, outer); err != nil {
+		return nil, err
+	}
+	return outer, nil
+}
+
 func dontEnterSubqueries(node, _ sqlparser.SQLNode) bool {
 	if _, ok := node.(*sqlparser.Subquery); ok {
 		return false
@@ -180,6 +180,8 @@ func createSubquery(
 	original = cloneASTAndSemState(ctx, original)
 	originalSq := cloneASTAndSemState(ctx, subq)
 	subqID := findTablesContained(ctx, subq.Select)
+	// the tables of nested subqueries are part of the ID of the query they are nested in
+	outerID = outerID.Remove(subqID)
 	totalID := subqID.Merge(outerID)
 	sqc := &SubQueryBuilder{totalID: totalID, subqID: subqID, outerID: outerID}
 
@@ -188,7 +190,7 @@ func createSubquery(
 		return nil, err
 	}
 
-	stmt := rewriteRemainingColumns(ctx, subq.Select, subqID)
+	stmt, outerColumns := rewriteRemainingColumns(ctx, subq.Select, subqID)
 
 	// TODO: this should not be needed. We are using CopyOnRewrite above, but somehow this is not getting copied
 	ctx.SemTable.CopySemanticInfo(subq.Select, stmt)
@@ -209,6 +211,7 @@ func createSubquery(
 		IsProjection:     isProjection,
 		TopLevel:         topLevel,
 		JoinColumns:      joinCols,
+		outerColumns:     outerColumns,
 	}, nil
 }
 
@@ -46,6 +46,11 @@ func isMergeable(ctx *plancontext.PlanningContext, query sqlparser.SelectStateme
 		return false
 	}
 
+	// window functions can only be evaluated on a single shard if all the rows of their partitions are there
+	if !windowsArePartitionedByUniqueVindex(ctx, sel, op) {
+		return false
+	}
+
 	if len(sel.GroupBy) > 0 {
 		// iff we are grouping, we need to check that we can perform the grouping inside a single shard, and we check that
 		// by checking that one of the grouping expressions used is a unique single column vindex.
@@ -75,6 +80,13 @@ func settleSubqueries(ctx *plancontext.PlanningContext, op ops.Operator) (ops.Op
 	visit := func(op ops.Operator, lhsTables semantics.TableSet, isRoot bool) (ops.Operator, *rewrite.ApplyResult, error) {
 		switch op := op.(type) {
 		case *SubQueryContainer:
+			if slices.ContainsFunc(op.Inner, (*SubQuery).isCorrelatedProjection) {
+				// the projection using the values of the subqueries can't be pushed under them,
+				// so all the subqueries of the SELECT list are evaluated for every row of the outer
+				for _, subq := range op.Inner {
+					subq.ValuePerRow = subq.IsProjection
+				}
+			}
 			outer := op.Outer
 			for _, subq := range op.Inner {
 				newOuter, err := subq.settle(ctx, outer)
@@ -229,9 +241,11 @@ func tryPushSubQueryInJoin(
 		return outer, rewrite.NewTree("push subquery into LHS of join", inner), nil
 	}
 
-	if outer.LeftJoin || len(inner.Predicates) == 0 {
+	if outer.LeftJoin || len(inner.Predicates) == 0 || inner.IsProjection {
 		// we can't push any filters on the RHS of an outer join, and
-		// we don't want to push uncorrelated subqueries to the RHS of a join
+		// we don't want to push uncorrelated subqueries to the RHS of a join.
+		// The values of subqueries in the SELECT list are fetched from the LHS
+		// of the join, so they can't be evaluated on the RHS either.
 		return nil, rewrite.SameTree, nil
 	}
 
@@ -382,10 +396,64 @@ func pushProjectionToOuterContainer(ctx *plancontext.PlanningContext, p *Project
 		}
 	}
 	// all projections can be pushed to the outer
+	if err := exposeCorrelatedColumns(ctx, p, src.Outer, src.Inner...); err != nil {
+		return nil, nil, err
+	}
 	src.Outer, p.Source = p, src.Outer
 	return src, rewrite.NewTree("push projection into outer side of subquery container", p), nil
 }
 
+// exposeCorrelatedColumns adds the columns of the outer used by correlated subqueries to a
+// derived table that is pushed down to the outer side, since the subqueries will be evaluated
+// outside of it
+func exposeCorrelatedColumns(ctx *plancontext.PlanningContext, p *Projection, outer ops.Operator, subqueries ...*SubQuery) error {
+	if !p.isDerived() {
+		return nil
+	}
+	for _, sq := range subqueries {
+		if len(sq.Predicates) == 0 {
+			continue
+		}
+		cols, err := sq.OuterExpressionsNeeded(ctx, outer)
+		if err != nil {
+			return err
+		}
+		for _, col := range cols {
+			offset, err := p.FindCol(ctx, col, false)
+			if err != nil {
+				return err
+			}
+			if offset >= 0 {
+				continue
+			}
+			if err := p.exposeColumn(col); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// exposeColumn adds a column of a table inside the derived table to its columns. The column is
+// not rewritten by the derived table, since it is not a reference to one of its columns
+func (p *Projection) exposeColumn(col *sqlparser.ColName) error {
+	ap, err := p.GetAliasedProjections()
+	if err != nil {
+		return err
+	}
+	for i, pe := range ap {
+		if i < len(p.DT.Columns) && p.DT.Columns[i].Equal(col.Name) || col.Name.EqualString(pe.Original.ColumnName()) {
+			// the column would be mistaken for the column of the derived table with the same name
+			return vterrors.VT12001("correlated subquery using a column named like a column of its derived table: " + sqlparser.String(col))
+		}
+	}
+	if len(p.DT.Columns) > 0 {
+		p.DT.Columns = append(p.DT.Columns, col.Name)
+	}
+	_, err = p.addProjExpr(newProjExpr(aeWrap(col)))
+	return err
+}
+
 func rewriteColNameToArgument(ctx *plancontext.PlanningContext, in sqlparser.Expr, se SubQueryExpression, subqueries ...*SubQuery) sqlparser.Expr {
 	rewriteIt := func(s string) sqlparser.SQLNode {
 		for _, sq1 := range se {
@@ -532,7 +600,7 @@ func tryMergeSubqueryWithOuter(ctx *plancontext.PlanningContext, subQuery *SubQu
 		return outer, rewrite.SameTree, nil
 	}
 	if !subQuery.IsProjection {
-		op.Source = &Filter{Source: outer.Source, Predicates: []sqlparser.Expr{subQuery.Original}}
+		op.Source = addMergedPredicate(outer.Source, subQuery.Original)
 	}
 	ctx.MergedSubqueries = append(ctx.MergedSubqueries, subQuery.originalSubquery)
 	return op, rewrite.NewTree("merged subquery with outer", subQuery), nil
@@ -636,6 +704,8 @@ func (s *subqueryRouteMerger) merge(ctx *plancontext.PlanningContext, inner, out
 			Routing:       outer.Routing,
 			Ordering:      outer.Ordering,
 			ResultColumns: outer.ResultColumns,
+			Comments:      outer.Comments,
+			Lock:          outer.Lock,
 		}, nil
 
 	}
@@ -645,10 +715,7 @@ func (s *subqueryRouteMerger) merge(ctx *plancontext.PlanningContext, inner, out
 	if isSharded {
 		src = s.outer.Source
 		if !s.subq.IsProjection {
-			src = &Filter{
-				Source:     s.outer.Source,
-				Predicates: []sqlparser.Expr{s.original},
-			}
+			src = addMergedPredicate(s.outer.Source, s.original)
 		}
 	} else {
 		src, err = s.rewriteASTExpression(ctx, inner)
@@ -662,6 +729,8 @@ func (s *subqueryRouteMerger) merge(ctx *plancontext.PlanningContext, inner, out
 		Routing:       r,
 		Ordering:      s.outer.Ordering,
 		ResultColumns: s.outer.ResultColumns,
+		Comments:      s.outer.Comments,
+		Lock:          s.outer.Lock,
 	}, nil
 }
 
@@ -721,14 +790,22 @@ func (s *subqueryRouteMerger) rewriteASTExpression(ctx *plancontext.PlanningCont
 				cursor.Replace(subq)
 			}
 		}, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
-		src = &Filter{
-			Source:     s.outer.Source,
-			Predicates: []sqlparser.Expr{sQuery},
-		}
+		src = addMergedPredicate(s.outer.Source, sQuery)
 	}
 	return src, nil
 }
 
+// addMergedPredicate adds the predicate using a merged subquery on top of the source of the outer route.
+// The UPDATE and DELETE operators already send all the predicates of their statement, the subquery included,
+// so the predicate is not added a second time on top of them.
+func addMergedPredicate(src ops.Operator, pred sqlparser.Expr) ops.Operator {
+	switch src.(type) {
+	case *Update, *Delete:
+		return src
+	}
+	return &Filter{Source: src, Predicates: []sqlparser.Expr{pred}}
+}
+
 // mergeSubqueryInputs checks whether two operators can be merged into a single one.
 // If they can be merged, a new operator with the merged routing is returned
 // If they cannot be merged, nil is returned.
@@ -99,6 +99,10 @@ func (u *Update) ShortDescription() string {
 }
 
 func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlparser.Update) (ops.Operator, error) {
+	if needsDMLWithInput(ctx, updStmt.TableExprs, updStmt.Where) {
+		return createComplexUpdateOp(ctx, updStmt)
+	}
+
 	tableInfo, qt, err := createQueryTableForDML(ctx, updStmt.TableExprs[0], updStmt.Where)
 	if err != nil {
 		return nil, err
@@ -109,11 +113,19 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 		return nil, err
 	}
 
+	if vindexTable.AllowPrimaryVindexUpdate && !ctx.KeyspaceIDUnchanged && primaryVindexChanged(updStmt, vindexTable) {
+		return createUpdateMoveOperator(ctx, updStmt, vindexTable)
+	}
+
 	updClone := sqlparser.CloneRefOfUpdate(updStmt)
 	updOp, err := createUpdateOperator(ctx, updStmt, vindexTable, qt, routing)
 	if err != nil {
 		return nil, err
 	}
+	if _, isDMLWithInput := updOp.(*DMLWithInput); isDMLWithInput {
+		// the foreign keys are handled when planning the update of the selected rows
+		return updOp, nil
+	}
 
 	ksMode, err := ctx.VSchema.ForeignKeyMode(vindexTable.Keyspace.Name)
 	if err != nil {
@@ -158,7 +170,17 @@ func createUpdateOperator(ctx *plancontext.PlanningContext, updStmt *sqlparser.U
 		}
 	}
 
-	vp, cvv, ovq, subQueriesArgOnChangedVindex, err := getUpdateVindexInformation(updStmt, vindexTable, qt.ID, assignments)
+	vindexAssignments := assignments
+	if ctx.KeyspaceIDUnchanged {
+		// the rows stay on their shard, so the primary vindex is not changed
+		vindexAssignments = nil
+		for _, assignment := range assignments {
+			if sqlparser.Columns(vindexTable.ColumnVindexes[0].Columns).FindColumn(assignment.Name.Name) < 0 {
+				vindexAssignments = append(vindexAssignments, assignment)
+			}
+		}
+	}
+	vp, cvv, ovq, subQueriesArgOnChangedVindex, err := getUpdateVindexInformation(updStmt, vindexTable, qt.ID, vindexAssignments)
 	if err != nil {
 		return nil, err
 	}
@@ -180,9 +202,8 @@ func createUpdateOperator(ctx *plancontext.PlanningContext, updStmt *sqlparser.U
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && updStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard UPDATE with LIMIT")
+	if updStmt.Limit != nil && canHitMultipleShards(routing) {
+		return createUpdateWithInputOp(ctx, updStmt, qt, vindexTable, semantics.EmptyTableSet())
 	}
 
 	route := &Route{
@@ -204,6 +225,85 @@ func createUpdateOperator(ctx *plancontext.PlanningContext, updStmt *sqlparser.U
 	return sqc.getRootOperator(route), nil
 }
 
+// createComplexUpdateOp plans an update that reads from more than one table or uses subqueries to find its rows.
+// The rows to update are first selected by a SELECT planned across all the tables of the statement,
+// and then updated using their primary key. The updated values can only depend on the updated table.
+func createComplexUpdateOp(ctx *plancontext.PlanningContext, upd *sqlparser.Update) (ops.Operator, error) {
+	var targetID semantics.TableSet
+	for _, updExpr := range upd.Exprs {
+		targetID = targetID.Merge(ctx.SemTable.DirectDeps(updExpr.Name))
+	}
+	if targetID.NumberOfTables() != 1 {
+		return nil, vterrors.VT12001("multi-table UPDATE statement modifying more than one table")
+	}
+	target := findDMLTarget(ctx, upd.TableExprs, targetID)
+	if target == nil {
+		return nil, vterrors.VT13001("could not find the target table of the UPDATE")
+	}
+	if _, isTable := target.Expr.(sqlparser.TableName); !isTable {
+		return nil, &semantics.TableNotUpdatableError{Table: target.As.String()}
+	}
+
+	tableInfo, qt, err := createQueryTableForDML(ctx, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	vindexTable, _, err := buildVindexTableForDML(ctx, tableInfo, qt, "update")
+	if err != nil {
+		return nil, err
+	}
+	otherTables := dmlFromTables(ctx, upd.TableExprs).Remove(targetID)
+	return createUpdateWithInputOp(ctx, upd, qt, vindexTable, otherTables)
+}
+
+// createUpdateWithInputOp plans an update with a LIMIT that can hit multiple shards, or an update that
+// reads from more than one table or uses subqueries to find its rows.
+// The rows to update are first selected across all shards, and then updated using their primary key.
+// The values set from the other tables of a multi-table update are selected along with the rows,
+// and the update is then executed once per selected row.
+func createUpdateWithInputOp(ctx *plancontext.PlanningContext, upd *sqlparser.Update, qt *QueryTable, vTbl *vindexes.Table, otherTables semantics.TableSet) (ops.Operator, error) {
+	sel, where, vars, err := createDMLWithInputSelection(ctx, qt, vTbl, upd.TableExprs, upd.Where, upd.OrderBy, upd.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowVars []engine.DMLVar
+	updExprs := make(sqlparser.UpdateExprs, 0, len(upd.Exprs))
+	for _, updExpr := range upd.Exprs {
+		if !ctx.SemTable.RecursiveDeps(updExpr.Expr).IsOverlapping(otherTables) {
+			updExprs = append(updExprs, updExpr)
+			continue
+		}
+		bvName := fmt.Sprintf("%s%d", dmlUpdateValue, len(rowVars))
+		rowVars = append(rowVars, engine.DMLVar{BVName: bvName, Cols: []int{len(sel.SelectExprs)}})
+		sel.SelectExprs = append(sel.SelectExprs, aeWrap(updExpr.Expr))
+		updExprs = append(updExprs, &sqlparser.UpdateExpr{Name: updExpr.Name, Expr: sqlparser.NewArgument(bvName)})
+	}
+
+	source, err := createOperatorFromSelect(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	dml, err := createOperatorFromUpdate(ctx, &sqlparser.Update{
+		Comments:   upd.Comments,
+		Ignore:     upd.Ignore,
+		TableExprs: sqlparser.TableExprs{qt.Alias},
+		Exprs:      updExprs,
+		Where:      where,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &DMLWithInput{
+		Source:  source,
+		DML:     dml,
+		Vars:    vars,
+		RowVars: rowVars,
+	}, nil
+}
+
 // getFKRequirementsForUpdate analyzes update expressions to determine which foreign key constraints needs management at the VTGate.
 // It identifies parent and child foreign keys that require verification or cascade operations due to column updates.
 func getFKRequirementsForUpdate(ctx *plancontext.PlanningContext, updateExprs sqlparser.UpdateExprs, vindexTable *vindexes.Table) ([]vindexes.ParentFKInfo, []vindexes.ChildFKInfo) {
@@ -595,31 +695,13 @@ func createFkVerifyOpForParentFKForUpdate(ctx *plancontext.PlanningContext, updS
 // select 1 from Child join Parent on Parent.p1 = Child.c1 and Parent.p2 = Child.c2
 // where Parent.id = 1 and (1 IS NULL OR (child.c1) NOT IN ((1))) limit 1
 func createFkVerifyOpForChildFKForUpdate(ctx *plancontext.PlanningContext, updStmt *sqlparser.Update, cFk vindexes.ChildFKInfo) (ops.Operator, error) {
-	// ON UPDATE RESTRICT foreign keys that require validation, should only be allowed in the case where we
-	// are verifying all the FKs on vtgate level.
-	if !ctx.VerifyAllFKs {
-		return nil, vterrors.VT12002()
-	}
 	parentTblExpr := updStmt.TableExprs[0].(*sqlparser.AliasedTableExpr)
 	parentTbl, err := parentTblExpr.TableName()
 	if err != nil {
 		return nil, err
 	}
 	childTbl := cFk.Table.GetTableName()
-	var joinCond sqlparser.Expr
-	for idx := range cFk.ParentColumns {
-		joinExpr := &sqlparser.ComparisonExpr{
-			Operator: sqlparser.EqualOp,
-			Left:     sqlparser.NewColNameWithQualifier(cFk.ParentColumns[idx].String(), parentTbl),
-			Right:    sqlparser.NewColNameWithQualifier(cFk.ChildColumns[idx].String(), childTbl),
-		}
-
-		if idx == 0 {
-			joinCond = joinExpr
-			continue
-		}
-		joinCond = &sqlparser.AndExpr{Left: joinCond, Right: joinExpr}
-	}
+	joinCond := childFKJoinCondition(cFk, parentTbl, childTbl)
 
 	var whereCond sqlparser.Expr
 	// add existing where condition on the update statement
@@ -655,6 +737,26 @@ func createFkVerifyOpForChildFKForUpdate(ctx *plancontext.PlanningContext, updSt
 		sqlparser.ShareModeLock)
 }
 
+// childFKJoinCondition returns the condition joining the rows of the parent table to the rows of the child table
+// that reference them through the child foreign key constraint.
+func childFKJoinCondition(cFk vindexes.ChildFKInfo, parentTbl, childTbl sqlparser.TableName) sqlparser.Expr {
+	var joinCond sqlparser.Expr
+	for idx := range cFk.ParentColumns {
+		joinExpr := &sqlparser.ComparisonExpr{
+			Operator: sqlparser.EqualOp,
+			Left:     sqlparser.NewColNameWithQualifier(cFk.ParentColumns[idx].String(), parentTbl),
+			Right:    sqlparser.NewColNameWithQualifier(cFk.ChildColumns[idx].String(), childTbl),
+		}
+
+		if idx == 0 {
+			joinCond = joinExpr
+			continue
+		}
+		joinCond = &sqlparser.AndExpr{Left: joinCond, Right: joinExpr}
+	}
+	return joinCond
+}
+
 // nullSafeNotInComparison is used to compare the child columns in the foreign key constraint aren't the same as the updateExpressions exactly.
 // This comparison has to be null safe so we create an expression which looks like the following for a query like `update child cola = :v1 and colb = :v2` -
 // `:v1 IS NULL OR :v2 IS NULL OR (cola, colb) NOT IN ((:v1,:v2))`
@@ -0,0 +1,260 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// UpdateMove is used to represent an update of the primary vindex columns of a table.
+// The rows to update are selected with their current and updated values. Each of them
+// is updated in place if its keyspace id is unchanged, or moved to its new shard otherwise,
+// by deleting it and inserting it again.
+type UpdateMove struct {
+	Source ops.Operator
+	Update ops.Operator
+	Delete ops.Operator
+	Insert ops.Operator
+
+	Columns []string
+
+	KsidVindex  vindexes.Vindex
+	KsidColumns []int
+
+	noColumns
+	noPredicates
+}
+
+var _ ops.Operator = (*UpdateMove)(nil)
+
+// Inputs implements the Operator interface
+func (um *UpdateMove) Inputs() []ops.Operator {
+	return []ops.Operator{um.Source, um.Update, um.Delete, um.Insert}
+}
+
+// SetInputs implements the Operator interface
+func (um *UpdateMove) SetInputs(inputs []ops.Operator) {
+	if len(inputs) != 4 {
+		panic("incorrect count of inputs for UpdateMove")
+	}
+	um.Source = inputs[0]
+	um.Update = inputs[1]
+	um.Delete = inputs[2]
+	um.Insert = inputs[3]
+}
+
+// Clone implements the Operator interface
+func (um *UpdateMove) Clone(inputs []ops.Operator) ops.Operator {
+	newUm := &UpdateMove{Columns: um.Columns, KsidVindex: um.KsidVindex, KsidColumns: um.KsidColumns}
+	newUm.SetInputs(inputs)
+	return newUm
+}
+
+// GetOrdering implements the Operator interface
+func (um *UpdateMove) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+// ShortDescription implements the Operator interface
+func (um *UpdateMove) ShortDescription() string {
+	return ""
+}
+
+// primaryVindexChanged returns true if the update changes a column of the primary vindex of the table.
+func primaryVindexChanged(upd *sqlparser.Update, vTbl *vindexes.Table) bool {
+	if !vTbl.Keyspace.Sharded || len(vTbl.ColumnVindexes) == 0 {
+		return false
+	}
+	for _, updExpr := range upd.Exprs {
+		for _, col := range vTbl.ColumnVindexes[0].Columns {
+			if col.Equal(updExpr.Name.Name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// createUpdateMoveOperator plans an update of the primary vindex columns of a table, which moves the updated rows
+// to their new shard. E.g. for a table t(id, col) with the primary key id and the primary vindex column col:
+// update t set col = col + 1 where id = 1
+// Source: select id, col, id, col + 1 from t where id = 1 for update
+// Update: update t set col = :mv_new_col where id = :mv_old_id and col = :mv_old_col
+// Delete: delete from t where id = :mv_old_id and col = :mv_old_col
+// Insert: insert into t(id, col) values (:mv_new_id, :mv_new_col)
+// The rows whose keyspace id is unchanged are updated in place, the other ones are deleted and inserted.
+// The update, the delete and the insert maintain the owned vindexes of the rows.
+// The generated columns are computed again by MySQL when the rows are inserted, so they are not copied.
+func createUpdateMoveOperator(ctx *plancontext.PlanningContext, upd *sqlparser.Update, vTbl *vindexes.Table) (ops.Operator, error) {
+	if !vTbl.ColumnListAuthoritative || len(vTbl.PrimaryKey) == 0 {
+		return nil, vterrors.VT09015()
+	}
+	// The rows are deleted to be moved, which would delete or fail on their children rows.
+	// The foreign keys unknown to vtgate can't be checked, and MySQL applies their ON DELETE
+	// action, e.g. deletes the children rows of an ON DELETE CASCADE foreign key, when moving the rows.
+	if len(vTbl.ChildForeignKeys) > 0 {
+		return nil, vterrors.VT12001("UPDATE of primary vindex columns on a table with child foreign keys")
+	}
+	aTblExpr, ok := upd.TableExprs[0].(*sqlparser.AliasedTableExpr)
+	if !ok {
+		return nil, vterrors.VT12001("UPDATE on complex table expression")
+	}
+	tblName, err := aTblExpr.TableName()
+	if err != nil {
+		return nil, err
+	}
+
+	newValues, err := updateMoveNewValues(upd)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []string
+	var insCols sqlparser.Columns
+	var oldExprs, newExprs sqlparser.SelectExprs
+	var insValues sqlparser.ValTuple
+	for _, col := range vTbl.Columns {
+		if col.Generated {
+			continue
+		}
+		columns = append(columns, col.Name.String())
+		insCols = append(insCols, col.Name)
+		oldExprs = append(oldExprs, aeWrap(sqlparser.NewColName(col.Name.String())))
+		newValue, found := newValues[col.Name.Lowered()]
+		if !found {
+			newValue = sqlparser.NewColName(col.Name.String())
+		}
+		newExprs = append(newExprs, aeWrap(newValue))
+		insValues = append(insValues, sqlparser.NewArgument(engine.UpdateMoveNewVarName(col.Name.String())))
+	}
+
+	source, err := createOpFromStmt(ctx, &sqlparser.Select{
+		SelectExprs: append(oldExprs, newExprs...),
+		From:        sqlparser.TableExprs{sqlparser.CloneRefOfAliasedTableExpr(aTblExpr)},
+		Where:       sqlparser.CloneRefOfWhere(upd.Where),
+		OrderBy:     sqlparser.CloneOrderBy(upd.OrderBy),
+		Limit:       sqlparser.CloneRefOfLimit(upd.Limit),
+		Lock:        sqlparser.ForUpdateLock,
+	}, false /* verifyAllFKs */, "" /* fkToIgnore */)
+	if err != nil {
+		return nil, err
+	}
+
+	var ksidColumns []int
+	for _, col := range vTbl.ColumnVindexes[0].Columns {
+		offset := insCols.FindColumn(col)
+		if offset < 0 {
+			return nil, vterrors.VT12001("UPDATE of generated primary vindex columns")
+		}
+		ksidColumns = append(ksidColumns, offset)
+	}
+
+	// the rows are updated and deleted using their primary key, and the primary vindex columns to route them to their shard.
+	keyCols := append(sqlparser.Columns{}, vTbl.PrimaryKey...)
+	for _, col := range vTbl.ColumnVindexes[0].Columns {
+		if keyCols.FindColumn(col) < 0 {
+			keyCols = append(keyCols, col)
+		}
+	}
+	var keyPreds []sqlparser.Expr
+	for _, col := range keyCols {
+		keyPreds = append(keyPreds, sqlparser.NewComparisonExpr(sqlparser.EqualOp,
+			sqlparser.NewColName(col.String()), sqlparser.NewArgument(engine.UpdateMoveOldVarName(col.String())), nil))
+	}
+
+	var updExprs sqlparser.UpdateExprs
+	for _, updExpr := range upd.Exprs {
+		updExprs = append(updExprs, &sqlparser.UpdateExpr{
+			Name: sqlparser.NewColName(updExpr.Name.Name.String()),
+			Expr: sqlparser.NewArgument(engine.UpdateMoveNewVarName(updExpr.Name.Name.String())),
+		})
+	}
+	updInPlace := &sqlparser.Update{
+		Comments:   upd.Comments,
+		TableExprs: sqlparser.TableExprs{sqlparser.NewAliasedTableExpr(tblName, "")},
+		Exprs:      updExprs,
+		Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(keyPreds...)),
+	}
+	updCtx, err := plancontext.CreatePlanningContext(updInPlace, ctx.ReservedVars, ctx.VSchema, ctx.PlannerVersion)
+	if err != nil {
+		return nil, err
+	}
+	updCtx.KeyspaceIDUnchanged = true
+	updOp, err := PlanQuery(updCtx, updInPlace)
+	if err != nil {
+		return nil, err
+	}
+
+	del, err := createOpFromStmt(ctx, &sqlparser.Delete{
+		Comments:   upd.Comments,
+		TableExprs: sqlparser.TableExprs{sqlparser.NewAliasedTableExpr(tblName, "")},
+		Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.AndExpressions(sqlparser.CloneExprs(keyPreds)...)),
+	}, false /* verifyAllFKs */, "" /* fkToIgnore */)
+	if err != nil {
+		return nil, err
+	}
+
+	ins, err := createOpFromStmt(ctx, &sqlparser.Insert{
+		Comments: upd.Comments,
+		Table:    sqlparser.NewAliasedTableExpr(tblName, ""),
+		Columns:  insCols,
+		Rows:     sqlparser.Values{insValues},
+	}, false /* verifyAllFKs */, "" /* fkToIgnore */)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UpdateMove{
+		Source:      source,
+		Update:      updOp,
+		Delete:      del,
+		Insert:      ins,
+		Columns:     columns,
+		KsidVindex:  vTbl.ColumnVindexes[0].Vindex,
+		KsidColumns: ksidColumns,
+	}, nil
+}
+
+// updateMoveNewValues returns the updated values of the columns set by the update.
+// As in MySQL, the assignments are evaluated from left to right, so the columns
+// used in an assignment that were set by a previous one are replaced by their updated value.
+func updateMoveNewValues(upd *sqlparser.Update) (map[string]sqlparser.Expr, error) {
+	newValues := make(map[string]sqlparser.Expr, len(upd.Exprs))
+	for _, updExpr := range upd.Exprs {
+		var err error
+		expr := sqlparser.CopyOnRewrite(updExpr.Expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+			switch node := cursor.Node().(type) {
+			case *sqlparser.Subquery:
+				err = vterrors.VT12001("subqueries in UPDATE of primary vindex columns")
+			case *sqlparser.ColName:
+				if value, found := newValues[node.Name.Lowered()]; found {
+					cursor.Replace(sqlparser.CloneExpr(value))
+				}
+			}
+		}, nil).(sqlparser.Expr)
+		if err != nil {
+			return nil, err
+		}
+		newValues[updExpr.Name.Name.Lowered()] = expr
+	}
+	return newValues, nil
+}
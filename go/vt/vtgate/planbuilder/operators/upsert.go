@@ -0,0 +1,290 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// UpsertSource contains the operators used to upsert a single row.
+type UpsertSource struct {
+	Select ops.Operator
+	Insert ops.Operator
+	Update ops.Operator
+}
+
+// Upsert is used to represent an INSERT ... ON DUPLICATE KEY UPDATE that changes vindex columns.
+// Such an upsert is executed row by row: the existing row with the same values for one of the
+// unique keys of the table is selected, and the row is inserted when it doesn't exist, or updated otherwise.
+type Upsert struct {
+	Sources []UpsertSource
+
+	noColumns
+	noPredicates
+}
+
+var _ ops.Operator = (*Upsert)(nil)
+
+// Inputs implements the Operator interface
+func (u *Upsert) Inputs() []ops.Operator {
+	var inputs []ops.Operator
+	for _, source := range u.Sources {
+		inputs = append(inputs, source.Select, source.Insert, source.Update)
+	}
+	return inputs
+}
+
+// SetInputs implements the Operator interface
+func (u *Upsert) SetInputs(inputs []ops.Operator) {
+	if len(inputs) != 3*len(u.Sources) {
+		panic("incorrect count of inputs for Upsert")
+	}
+	for i := range u.Sources {
+		u.Sources[i] = UpsertSource{
+			Select: inputs[3*i],
+			Insert: inputs[3*i+1],
+			Update: inputs[3*i+2],
+		}
+	}
+}
+
+// Clone implements the Operator interface
+func (u *Upsert) Clone(inputs []ops.Operator) ops.Operator {
+	up := &Upsert{Sources: slices.Clone(u.Sources)}
+	up.SetInputs(inputs)
+	return up
+}
+
+// GetOrdering implements the Operator interface
+func (u *Upsert) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+// ShortDescription implements the Operator interface
+func (u *Upsert) ShortDescription() string {
+	return ""
+}
+
+// vindexChangedByUpsert returns true if the ON DUPLICATE KEY UPDATE clause of the insert changes
+// the value of a vindex column, in which case it can't be sent to the shards along with the insert.
+func vindexChangedByUpsert(ins *sqlparser.Insert, vTbl *vindexes.Table) bool {
+	for _, colVindex := range vTbl.ColumnVindexes {
+		for _, col := range colVindex.Columns {
+			if checkAndErrIfVindexChanging(sqlparser.UpdateExprs(ins.OnDup), col) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// createUpsertOperator plans an INSERT ... ON DUPLICATE KEY UPDATE that changes vindex columns.
+// For each row, it plans a lookup of the existing row by the unique keys of the table, the insert of the row,
+// and the update of the existing row with the ON DUPLICATE KEY UPDATE clause, where the
+// VALUES() of the columns are replaced by the values of the row.
+// The update maintains the changed vindexes, like any other update.
+func createUpsertOperator(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, vTbl *vindexes.Table) (ops.Operator, error) {
+	rows, ok := ins.Rows.(sqlparser.Values)
+	if !ok {
+		return nil, vterrors.VT12001("INSERT INTO ... SELECT with ON DUPLICATE KEY UPDATE changing vindex columns")
+	}
+	if err := populateInsertColumnsForDML(ins, vTbl); err != nil {
+		return nil, err
+	}
+
+	upsert := &Upsert{}
+	for _, row := range rows {
+		if len(row) != len(ins.Columns) {
+			return nil, vterrors.VT03006()
+		}
+		cond, err := conflictCondition(vTbl, ins.Columns, row)
+		if err != nil {
+			return nil, err
+		}
+		if cond == nil {
+			return nil, vterrors.VT12001("ON DUPLICATE KEY UPDATE changing vindex columns with a generated primary key")
+		}
+		updExprs, err := upsertUpdateExprs(ins, row)
+		if err != nil {
+			return nil, err
+		}
+
+		selOp, err := createSelectionOp(ctx,
+			sqlparser.SelectExprs{sqlparser.NewAliasedExpr(sqlparser.NewIntLiteral("1"), "")},
+			sqlparser.TableExprs{sqlparser.CloneRefOfAliasedTableExpr(ins.Table)},
+			sqlparser.NewWhere(sqlparser.WhereClause, cond),
+			nil,
+			sqlparser.ForUpdateLock)
+		if err != nil {
+			return nil, err
+		}
+
+		rowIns := sqlparser.CloneRefOfInsert(ins)
+		rowIns.Rows = sqlparser.Values{sqlparser.CloneValTuple(row)}
+		rowIns.OnDup = nil
+		insOp, err := createOpFromStmt(ctx, rowIns, false /* verifyAllFKs */, "" /* fkToIgnore */)
+		if err != nil {
+			return nil, err
+		}
+
+		upd := &sqlparser.Update{
+			Comments:   ins.Comments,
+			TableExprs: sqlparser.TableExprs{sqlparser.CloneRefOfAliasedTableExpr(ins.Table)},
+			Exprs:      updExprs,
+			Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.CloneExpr(cond)),
+		}
+		updOp, err := createOpFromStmt(ctx, upd, false /* verifyAllFKs */, "" /* fkToIgnore */)
+		if err != nil {
+			return nil, err
+		}
+
+		upsert.Sources = append(upsert.Sources, UpsertSource{
+			Select: selOp,
+			Insert: insOp,
+			Update: updOp,
+		})
+	}
+	return upsert, nil
+}
+
+// upsertUpdateExprs returns the ON DUPLICATE KEY UPDATE expressions of the insert, where VALUES(col)
+// is replaced by the value of col in the row. VALUES() of a column missing from the column list
+// is the default value of the column, which isn't known here, so it is not supported.
+func upsertUpdateExprs(ins *sqlparser.Insert, row sqlparser.ValTuple) (sqlparser.UpdateExprs, error) {
+	var exprs sqlparser.UpdateExprs
+	var err error
+	for _, ue := range ins.OnDup {
+		expr := sqlparser.CopyOnRewrite(ue.Expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+			vfExpr, ok := cursor.Node().(*sqlparser.ValuesFuncExpr)
+			if !ok {
+				return
+			}
+			idx := ins.Columns.FindColumn(vfExpr.Name.Name)
+			if idx < 0 {
+				err = vterrors.VT12001(fmt.Sprintf("VALUES() of the column '%s' missing from the column list in ON DUPLICATE KEY UPDATE changing vindex columns", vfExpr.Name.Name.String()))
+				return
+			}
+			cursor.Replace(sqlparser.CloneExpr(row[idx]))
+		}, nil).(sqlparser.Expr)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, &sqlparser.UpdateExpr{
+			Name: sqlparser.CloneRefOfColName(ue.Name),
+			Expr: expr,
+		})
+	}
+	return exprs, nil
+}
+
+// populateInsertColumnsForDML fills the column list of an insert without one, as it is needed
+// to find the values of the columns of the rows.
+func populateInsertColumnsForDML(ins *sqlparser.Insert, vTbl *vindexes.Table) error {
+	if ins.Columns != nil {
+		return nil
+	}
+	if !vTbl.ColumnListAuthoritative {
+		return vterrors.VT09004()
+	}
+	populateInsertColumnlist(ins, vTbl)
+	return nil
+}
+
+// uniqueKeys returns the unique keys of the table, starting with its primary key, followed by
+// the other unique keys reported by the schema tracker and the columns of the owned unique lookup vindexes,
+// as the insert of a row fails when it has the same values as an existing row for any of them.
+func uniqueKeys(vTbl *vindexes.Table) []sqlparser.Columns {
+	keys := []sqlparser.Columns{vTbl.PrimaryKey}
+	addKey := func(key sqlparser.Columns) {
+		for _, k := range keys {
+			if sameColumns(k, key) {
+				return
+			}
+		}
+		keys = append(keys, key)
+	}
+	for _, key := range vTbl.UniqueKeys {
+		addKey(key)
+	}
+	for _, colVindex := range vTbl.ColumnVindexes {
+		if _, isLookup := colVindex.Vindex.(vindexes.Lookup); isLookup && colVindex.Owned && colVindex.IsUnique() {
+			addKey(colVindex.Columns)
+		}
+	}
+	return keys
+}
+
+// sameColumns returns true if both lists have the same columns, in any order.
+func sameColumns(a, b sqlparser.Columns) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, col := range a {
+		if b.FindColumn(col) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictCondition returns the condition matching the existing rows that have the same values
+// as the inserted row for one of the unique keys of the table. It returns nil when the row can't
+// conflict with an existing row: its primary key is generated, as the primary key column is the
+// auto-increment column and is missing from the column list or set to NULL, and the row has a NULL
+// value in each of its other unique keys.
+func conflictCondition(vTbl *vindexes.Table, columns sqlparser.Columns, row sqlparser.ValTuple) (sqlparser.Expr, error) {
+	if len(vTbl.PrimaryKey) == 0 {
+		return nil, vterrors.VT09015()
+	}
+	var cond sqlparser.Expr
+keys:
+	for keyIdx, key := range uniqueKeys(vTbl) {
+		var preds []sqlparser.Expr
+		for _, col := range key {
+			autoInc := vTbl.AutoIncrement != nil && vTbl.AutoIncrement.Column.Equal(col)
+			idx := columns.FindColumn(col)
+			if idx < 0 {
+				switch {
+				case autoInc:
+					continue keys
+				case keyIdx == 0:
+					return nil, vterrors.VT09003(col)
+				default:
+					return nil, vterrors.VT12001(fmt.Sprintf("REPLACE or ON DUPLICATE KEY UPDATE without a value for the column '%s' of a unique key", col.String()))
+				}
+			}
+			if sqlparser.IsNull(row[idx]) {
+				continue keys
+			}
+			preds = append(preds, sqlparser.NewComparisonExpr(sqlparser.EqualOp, sqlparser.NewColName(col.String()), sqlparser.CloneExpr(row[idx]), nil))
+		}
+		keyCond := sqlparser.AndExpressions(preds...)
+		if cond == nil {
+			cond = keyCond
+			continue
+		}
+		cond = &sqlparser.OrExpr{Left: cond, Right: keyCond}
+	}
+	return cond, nil
+}
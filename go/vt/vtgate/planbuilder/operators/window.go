@@ -0,0 +1,423 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// Window evaluates window functions at the vtgate level, when they can't be
+// evaluated by MySQL because the rows of a window partition are spread over
+// several shards.
+// All the window functions share the same window, and the source is sorted by
+// the PARTITION BY and then the ORDER BY expressions of that window.
+// The columns of the Window are the window functions, followed by the columns
+// of its source.
+type Window struct {
+	Source    ops.Operator
+	Spec      *sqlparser.WindowSpecification
+	Functions []*sqlparser.AliasedExpr
+
+	// These are only filled in during offset planning
+	PartitionBy []engine.CheckCol
+	OrderBy     []engine.CheckCol
+	Params      []*engine.WindowFunction
+}
+
+func (w *Window) Clone(inputs []ops.Operator) ops.Operator {
+	return &Window{
+		Source:      inputs[0],
+		Spec:        w.Spec,
+		Functions:   slices.Clone(w.Functions),
+		PartitionBy: slices.Clone(w.PartitionBy),
+		OrderBy:     slices.Clone(w.OrderBy),
+		Params:      slices.Clone(w.Params),
+	}
+}
+
+func (w *Window) Inputs() []ops.Operator {
+	return []ops.Operator{w.Source}
+}
+
+func (w *Window) SetInputs(operators []ops.Operator) {
+	w.Source = operators[0]
+}
+
+func (w *Window) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) (ops.Operator, error) {
+	// filtering the input would change the rows of the window partitions,
+	// so the predicate has to be evaluated on the output of the window functions
+	return newFilter(w, expr), nil
+}
+
+func (w *Window) AddColumn(ctx *plancontext.PlanningContext, reuse bool, gb bool, expr *sqlparser.AliasedExpr) (int, error) {
+	if offset := w.findFunction(ctx, expr.Expr); offset >= 0 {
+		return offset, nil
+	}
+	offset, err := w.Source.AddColumn(ctx, reuse, gb, expr)
+	if err != nil {
+		return 0, err
+	}
+	return len(w.Functions) + offset, nil
+}
+
+func (w *Window) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, underRoute bool) (int, error) {
+	if offset := w.findFunction(ctx, expr); offset >= 0 {
+		return offset, nil
+	}
+	offset, err := w.Source.FindCol(ctx, expr, underRoute)
+	if err != nil || offset < 0 {
+		return offset, err
+	}
+	return len(w.Functions) + offset, nil
+}
+
+func (w *Window) findFunction(ctx *plancontext.PlanningContext, expr sqlparser.Expr) int {
+	for idx, fn := range w.Functions {
+		if ctx.SemTable.EqualsExprWithDeps(fn.Expr, expr) {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (w *Window) GetColumns(ctx *plancontext.PlanningContext) ([]*sqlparser.AliasedExpr, error) {
+	columns, err := w.Source.GetColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(slices.Clone(w.Functions), columns...), nil
+}
+
+func (w *Window) GetSelectExprs(ctx *plancontext.PlanningContext) (sqlparser.SelectExprs, error) {
+	return transformColumnsToSelectExprs(ctx, w)
+}
+
+func (w *Window) ShortDescription() string {
+	functions := slice.Map(w.Functions, func(from *sqlparser.AliasedExpr) string {
+		return sqlparser.String(from.Expr)
+	})
+	return strings.Join(functions, ", ")
+}
+
+func (w *Window) GetOrdering() ([]ops.OrderBy, error) {
+	return w.Source.GetOrdering()
+}
+
+func (w *Window) planOffsets(ctx *plancontext.PlanningContext) error {
+	addColumn := func(expr sqlparser.Expr) (engine.CheckCol, error) {
+		offset, err := w.Source.AddColumn(ctx, true, false, aeWrap(expr))
+		if err != nil {
+			return engine.CheckCol{}, err
+		}
+		typ, coll, _ := ctx.SemTable.TypeForExpr(expr)
+		checkCol := engine.CheckCol{
+			Col:       offset,
+			Type:      typ,
+			Collation: coll,
+		}
+		if ctx.SemTable.NeedsWeightString(expr) {
+			wsOffset, err := w.Source.AddColumn(ctx, true, false, aeWrap(weightStringFor(expr)))
+			if err != nil {
+				return engine.CheckCol{}, err
+			}
+			checkCol.WsCol = &wsOffset
+		}
+		return checkCol, nil
+	}
+
+	for _, expr := range w.Spec.PartitionClause {
+		checkCol, err := addColumn(expr)
+		if err != nil {
+			return err
+		}
+		w.PartitionBy = append(w.PartitionBy, checkCol)
+	}
+	for _, order := range w.Spec.OrderClause {
+		checkCol, err := addColumn(order.Expr)
+		if err != nil {
+			return err
+		}
+		w.OrderBy = append(w.OrderBy, checkCol)
+	}
+
+	for _, fn := range w.Functions {
+		param, err := newWindowFunction(fn)
+		if err != nil {
+			return err
+		}
+		switch expr := fn.Expr.(type) {
+		case *sqlparser.LagLeadExpr:
+			param.Col, err = w.Source.AddColumn(ctx, true, false, aeWrap(expr.Expr))
+			if err != nil {
+				return err
+			}
+			if expr.Default != nil {
+				param.DefaultCol, err = w.Source.AddColumn(ctx, true, false, aeWrap(expr.Default))
+				if err != nil {
+					return err
+				}
+			}
+		case *sqlparser.Sum:
+			param.Col, err = w.Source.AddColumn(ctx, true, false, aeWrap(expr.Arg))
+			if err != nil {
+				return err
+			}
+		}
+		w.Params = append(w.Params, param)
+	}
+	return nil
+}
+
+// newWindowFunction returns the engine parameters of a window function,
+// without the offsets of its arguments. It fails for the window functions
+// that can't be evaluated by the Window primitive.
+func newWindowFunction(fn *sqlparser.AliasedExpr) (*engine.WindowFunction, error) {
+	param := &engine.WindowFunction{
+		DefaultCol: -1,
+		Alias:      fn.ColumnName(),
+	}
+	switch expr := fn.Expr.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		switch expr.Type {
+		case sqlparser.RowNumberExprType:
+			param.Opcode = engine.WindowRowNumber
+			return param, nil
+		case sqlparser.RankExprType:
+			param.Opcode = engine.WindowRank
+			return param, nil
+		case sqlparser.DenseRankExprType:
+			param.Opcode = engine.WindowDenseRank
+			return param, nil
+		}
+	case *sqlparser.LagLeadExpr:
+		param.Opcode = engine.WindowLag
+		if expr.Type == sqlparser.LeadExprType {
+			param.Opcode = engine.WindowLead
+		}
+		param.N = 1
+		if expr.N != nil {
+			lit, ok := expr.N.(*sqlparser.Literal)
+			if !ok || lit.Type != sqlparser.IntVal {
+				return nil, vterrors.VT12001(fmt.Sprintf("%s with a non literal offset in a cross-shard query", sqlparser.String(expr)))
+			}
+			n, err := strconv.Atoi(lit.Val)
+			if err != nil {
+				return nil, vterrors.VT12001(fmt.Sprintf("%s with a non literal offset in a cross-shard query", sqlparser.String(expr)))
+			}
+			param.N = n
+		}
+		return param, nil
+	case *sqlparser.Sum:
+		if !expr.Distinct {
+			param.Opcode = engine.WindowSum
+			return param, nil
+		}
+	}
+	return nil, vterrors.VT12001(fmt.Sprintf("window function %s in a cross-shard query", sqlparser.String(fn.Expr)))
+}
+
+// createWindow creates the Window operator that evaluates the window functions of the query,
+// or returns nil if all the window functions can be evaluated by MySQL.
+func createWindow(ctx *plancontext.PlanningContext, horizon *Horizon, sel *sqlparser.Select, qp *QueryProjection) (*Window, error) {
+	if rb, isRoute := horizon.src().(*Route); isRoute {
+		if rb.IsSingleShard() || windowsArePartitionedByUniqueVindex(ctx, sel, horizon) {
+			return nil, nil
+		}
+	}
+
+	if qp.NeedsAggregation() {
+		return nil, vterrors.VT12001("window functions with aggregations in a cross-shard query")
+	}
+
+	var exprs []sqlparser.Expr
+	for _, expr := range qp.SelectExprs {
+		ae, err := expr.GetAliasedExpr()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, ae.Expr)
+	}
+	for _, order := range qp.OrderExprs {
+		exprs = append(exprs, order.SimplifiedExpr)
+	}
+
+	w := &Window{Source: horizon.src()}
+	for _, expr := range exprs {
+		for _, fn := range windowFunctions(expr) {
+			if w.findFunction(ctx, fn) >= 0 {
+				continue
+			}
+
+			spec, err := windowSpecification(sel, sqlparser.GetOverClause(fn))
+			if err != nil {
+				return nil, err
+			}
+			if spec.FrameClause != nil {
+				return nil, vterrors.VT12001("window frames in a cross-shard query")
+			}
+			if w.Spec == nil {
+				w.Spec = spec
+			} else if !ctx.SemTable.ASTEquals().RefOfWindowSpecification(w.Spec, spec) {
+				return nil, vterrors.VT12001("window functions over different windows in a cross-shard query")
+			}
+
+			ae := aeWrap(fn)
+			for _, selectExpr := range qp.SelectExprs {
+				if selAe, ok := selectExpr.Col.(*sqlparser.AliasedExpr); ok && ctx.SemTable.EqualsExprWithDeps(selAe.Expr, fn) {
+					ae = selAe
+					break
+				}
+			}
+			if _, err := newWindowFunction(ae); err != nil {
+				return nil, err
+			}
+			w.Functions = append(w.Functions, ae)
+		}
+	}
+
+	// the input has to be sorted by the partitions first, and then by the window ordering
+	var order []ops.OrderBy
+	for _, expr := range w.Spec.PartitionClause {
+		order = append(order, ops.OrderBy{
+			Inner:          &sqlparser.Order{Expr: expr, Direction: sqlparser.AscOrder},
+			SimplifiedExpr: expr,
+		})
+	}
+	for _, o := range w.Spec.OrderClause {
+		order = append(order, ops.OrderBy{
+			Inner:          o,
+			SimplifiedExpr: o.Expr,
+		})
+	}
+	if len(order) > 0 {
+		w.Source = &Ordering{
+			Source: w.Source,
+			Order:  order,
+		}
+	}
+
+	return w, nil
+}
+
+// windowFunctions returns the window functions used in an expression
+func windowFunctions(expr sqlparser.Expr) (functions []sqlparser.Expr) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Offset, *sqlparser.Subquery:
+			return false, nil
+		case sqlparser.Expr:
+			if sqlparser.IsWindowFunction(node) {
+				functions = append(functions, node)
+				return false, nil
+			}
+		}
+		return true, nil
+	}, expr)
+	return
+}
+
+// windowsArePartitionedByUniqueVindex returns true if the PARTITION BY clauses of all the window
+// functions of the query contain a column with a unique vindex, in which case all the rows of a
+// partition are on the same shard
+func windowsArePartitionedByUniqueVindex(ctx *plancontext.PlanningContext, sel *sqlparser.Select, op ops.Operator) bool {
+	var exprs []sqlparser.Expr
+	for _, expr := range sel.SelectExprs {
+		if ae, ok := expr.(*sqlparser.AliasedExpr); ok {
+			exprs = append(exprs, ae.Expr)
+		}
+	}
+	for _, order := range sel.OrderBy {
+		exprs = append(exprs, order.Expr)
+	}
+
+	for _, expr := range exprs {
+		for _, fn := range windowFunctions(expr) {
+			spec, err := windowSpecification(sel, sqlparser.GetOverClause(fn))
+			if err != nil {
+				return false
+			}
+			partitioned := slices.ContainsFunc(spec.PartitionClause, func(expr sqlparser.Expr) bool {
+				vindex := findColumnVindex(ctx, op, expr)
+				return vindex != nil && vindex.IsUnique()
+			})
+			if !partitioned {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// windowSpecification returns the window of an OVER clause, with the named windows it refers to resolved
+func windowSpecification(sel *sqlparser.Select, over *sqlparser.OverClause) (*sqlparser.WindowSpecification, error) {
+	if over == nil {
+		return nil, vterrors.VT13001("missing OVER clause for window function")
+	}
+	if !over.WindowName.IsEmpty() {
+		return namedWindowSpecification(sel, over.WindowName, 0)
+	}
+	return resolveWindowSpecification(sel, over.WindowSpec, 0)
+}
+
+func namedWindowSpecification(sel *sqlparser.Select, name sqlparser.IdentifierCI, depth int) (*sqlparser.WindowSpecification, error) {
+	for _, namedWindow := range sel.Windows {
+		for _, def := range namedWindow.Windows {
+			if def.Name.Equal(name) {
+				return resolveWindowSpecification(sel, def.WindowSpec, depth+1)
+			}
+		}
+	}
+	return nil, vterrors.VT03026(name.String())
+}
+
+func resolveWindowSpecification(sel *sqlparser.Select, spec *sqlparser.WindowSpecification, depth int) (*sqlparser.WindowSpecification, error) {
+	if spec.Name.IsEmpty() {
+		return spec, nil
+	}
+	if depth > len(sel.Windows) {
+		// MySQL does not allow windows to refer to each other in circles,
+		// but we don't want to loop forever if they do
+		return nil, vterrors.VT03026(spec.Name.String())
+	}
+	base, err := namedWindowSpecification(sel, spec.Name, depth)
+	if err != nil {
+		return nil, err
+	}
+	// the PARTITION BY can only come from the referenced window, but the ORDER BY and the frame can be added
+	resolved := &sqlparser.WindowSpecification{
+		PartitionClause: base.PartitionClause,
+		OrderClause:     base.OrderClause,
+		FrameClause:     base.FrameClause,
+	}
+	if len(spec.OrderClause) > 0 {
+		resolved.OrderClause = spec.OrderClause
+	}
+	if spec.FrameClause != nil {
+		resolved.FrameClause = spec.FrameClause
+	}
+	return resolved, nil
+}
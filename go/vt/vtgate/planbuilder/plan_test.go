@@ -27,8 +27,10 @@ import (
 	"runtime/debug"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nsf/jsondiff"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -95,6 +97,8 @@ func TestPlan(t *testing.T) {
 	testFile(t, "reference_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "vexplain_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "misc_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "window_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "cte_cases.json", testOutputTempDir, vschemaWrapper, false)
 }
 
 // TestForeignKeyPlanning tests the planning of foreign keys in a managed mode by Vitess.
@@ -206,6 +210,75 @@ func TestViews(t *testing.T) {
 	testFile(t, "view_cases.json", makeTestOutput(t), vschemaWrapper, false)
 }
 
+func TestResultCacheTTL(t *testing.T) {
+	vschema := loadSchema(t, "vschemas/schema.json", true)
+	vschema.Keyspaces["user"].Tables["user"].ResultCacheTTLMs = 60000
+	vschema.Keyspaces["user"].Tables["user_extra"].ResultCacheTTLMs = 1000
+	vschemaWrapper := &vschemawrapper.VSchemaWrapper{
+		V:           vschema,
+		TabletType_: topodatapb.TabletType_PRIMARY,
+	}
+
+	testcases := []struct {
+		query string
+		ttl   time.Duration
+	}{{
+		query: "select id from user where id = 1",
+		ttl:   time.Minute,
+	}, {
+		query: "select user.id from user join user_extra on user.id = user_extra.user_id",
+		ttl:   time.Second,
+	}, {
+		query: "select user.id from user join music on user.id = music.user_id",
+	}, {
+		query: "select /*vt+ RESULT_CACHE_TTL_MS=500 */ user.id from user join music on user.id = music.user_id",
+		ttl:   500 * time.Millisecond,
+	}, {
+		query: "select /*vt+ RESULT_CACHE_TTL_MS=0 */ id from user where id = 1",
+	}, {
+		query: "select /*vt+ RESULT_CACHE_TTL_MS=abc */ id from user where id = 1",
+	}, {
+		query: "select id from user where id = 1 for update",
+	}, {
+		query: "select id from user union select user_id from user_extra",
+		ttl:   time.Second,
+	}, {
+		query: "select id from user where id = 1 lock in share mode",
+	}, {
+		query: "select id from user where id in (select user_id from user_extra for update)",
+	}, {
+		query: "select now(), id from user where id = 1",
+	}, {
+		query: "select id from user where id = last_insert_id()",
+	}, {
+		query: "select database(), id from user where id = 1",
+	}, {
+		query: "select uuid(), id from user where id = 1",
+	}, {
+		query: "select rand(), id from user where id = 1",
+	}, {
+		query: "select user(), id from user where id = 1",
+	}, {
+		query: "select @@sql_mode, id from user where id = 1",
+	}, {
+		query: "select @x, id from user where id = 1",
+	}, {
+		query: "select id from user where id = 1 union select user_id from user_extra where user_id = @x",
+	}, {
+		query: "select lower(name), id from user where id = 1",
+		ttl:   time.Minute,
+	}, {
+		query: "update user set name = 'foo' where id = 1",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			plan, err := TestBuilder(tcase.query, vschemaWrapper, "user")
+			require.NoError(t, err)
+			assert.Equal(t, tcase.ttl, plan.ResultCacheTTL)
+		})
+	}
+}
+
 func TestOne(t *testing.T) {
 	reset := oprewriters.EnableDebugPrinting()
 	defer reset()
@@ -492,6 +565,22 @@ func loadSchema(t testing.TB, filename string, setCollation bool) *vindexes.VSch
 				"select user.id, user_extra.col from user join user_extra on user.id = user_extra.user_id"); err != nil {
 				t.Fatal(err)
 			}
+
+			// adding the primary keys that the schema tracker would find
+			for _, tblName := range []string{"user", "user_extra", "music", "tenant_user", "multicol_tbl"} {
+				if tbl := ks.Tables[tblName]; tbl != nil {
+					tbl.PrimaryKey = sqlparser.MakeColumns("id")
+				}
+			}
+			// and a unique key other than the primary key
+			if tbl := ks.Tables["tenant_user"]; tbl != nil {
+				tbl.UniqueKeys = []sqlparser.Columns{sqlparser.MakeColumns("tenant_id", "name")}
+			}
+		}
+		if ks.Keyspace.Name == "main" {
+			if tbl := ks.Tables["unsharded"]; tbl != nil {
+				tbl.PrimaryKey = sqlparser.MakeColumns("id")
+			}
 		}
 
 		// setting a default value to all the text columns in the tables of this keyspace
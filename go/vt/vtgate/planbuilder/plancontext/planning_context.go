@@ -48,6 +48,14 @@ type PlanningContext struct {
 	// query, we need to ignore the parent foreign key constraint that caused the cascade in question.
 	ParentFKToIgnore string
 
+	// KeyspaceIDUnchanged tells that the update being planned changes the primary vindex columns of rows
+	// without changing their keyspace id, so they can be updated in place instead of being moved.
+	KeyspaceIDUnchanged bool
+
+	// DMLSubqueriesNotMerged tells that the subqueries of the WHERE clause of the DML being planned could not
+	// be merged with the route of its target table, so the rows to modify must be selected first.
+	DMLSubqueriesNotMerged bool
+
 	// Projected subqueries that have been merged
 	MergedSubqueries []*sqlparser.Subquery
 
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*recurseCTE)(nil)
+
+// recurseCTE is the logicalPlan for engine.RecurseCTE.
+type recurseCTE struct {
+	seed, term logicalPlan
+	tableID    semantics.TableSet
+	eRecurse   *engine.RecurseCTE
+}
+
+// Primitive implements the logicalPlan interface
+func (r *recurseCTE) Primitive() engine.Primitive {
+	r.eRecurse.Seed = r.seed.Primitive()
+	r.eRecurse.Term = r.term.Primitive()
+	return r.eRecurse
+}
+
+// Wireup implements the logicalPlan interface
+func (r *recurseCTE) Wireup(ctx *plancontext.PlanningContext) error {
+	if err := r.seed.Wireup(ctx); err != nil {
+		return err
+	}
+	return r.term.Wireup(ctx)
+}
+
+// Rewrite implements the logicalPlan interface
+func (r *recurseCTE) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 2 {
+		return vterrors.VT13001("recurseCTE: wrong number of inputs")
+	}
+	r.seed = inputs[0]
+	r.term = inputs[1]
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (r *recurseCTE) ContainsTables() semantics.TableSet {
+	return r.tableID
+}
+
+// Inputs implements the logicalPlan interface
+func (r *recurseCTE) Inputs() []logicalPlan {
+	return []logicalPlan{r.seed, r.term}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (r *recurseCTE) OutputColumns() []sqlparser.SelectExpr {
+	return r.seed.OutputColumns()[:r.eRecurse.Columns]
+}
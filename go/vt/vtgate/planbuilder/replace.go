@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*replace)(nil)
+
+// replaceRow contains the logical plans used to replace a single row.
+// del is nil when the row can't conflict with an existing row.
+type replaceRow struct {
+	del, ins logicalPlan
+}
+
+// replace is the logicalPlan for engine.Replace.
+type replace struct {
+	rows []replaceRow
+}
+
+// Primitive implements the logicalPlan interface
+func (r *replace) Primitive() engine.Primitive {
+	rp := &engine.Replace{}
+	for _, row := range r.rows {
+		rr := &engine.ReplaceRow{Insert: row.ins.Primitive()}
+		if row.del != nil {
+			rr.Delete = row.del.Primitive()
+		}
+		rp.Replaces = append(rp.Replaces, rr)
+	}
+	return rp
+}
+
+// Wireup implements the logicalPlan interface
+func (r *replace) Wireup(ctx *plancontext.PlanningContext) error {
+	for _, input := range r.Inputs() {
+		if err := input.Wireup(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewrite implements the logicalPlan interface
+func (r *replace) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != len(r.Inputs()) {
+		return vterrors.VT13001("replace: wrong number of inputs")
+	}
+	for i := range r.rows {
+		if r.rows[i].del != nil {
+			r.rows[i].del, inputs = inputs[0], inputs[1:]
+		}
+		r.rows[i].ins, inputs = inputs[0], inputs[1:]
+	}
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (r *replace) ContainsTables() semantics.TableSet {
+	return r.rows[0].ins.ContainsTables()
+}
+
+// Inputs implements the logicalPlan interface
+func (r *replace) Inputs() []logicalPlan {
+	var inputs []logicalPlan
+	for _, row := range r.rows {
+		if row.del != nil {
+			inputs = append(inputs, row.del)
+		}
+		inputs = append(inputs, row.ins)
+	}
+	return inputs
+}
+
+// OutputColumns implements the logicalPlan interface
+func (r *replace) OutputColumns() []sqlparser.SelectExpr {
+	return nil
+}
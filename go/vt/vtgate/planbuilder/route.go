@@ -63,6 +63,14 @@ func (rb *route) SetLimit(limit *sqlparser.Limit) {
 	rb.Select.SetLimit(limit)
 }
 
+// isAsyncLookup returns true if the vindex is a lookup vindex maintained by vreplication.
+// Such vindexes have to check the lag of the stream before using the lookup table,
+// so the route keeps mapping the values through the vindex.
+func isAsyncLookup(vindex vindexes.LookupPlanable) bool {
+	lkp, ok := vindex.(vindexes.LookupAsync)
+	return ok && lkp.IsAsync()
+}
+
 // Wireup implements the logicalPlan interface
 func (rb *route) Wireup(ctx *plancontext.PlanningContext) error {
 	rb.prepareTheAST()
@@ -76,7 +84,7 @@ func (rb *route) Wireup(ctx *plancontext.PlanningContext) error {
 
 	// if we have a planable vindex lookup, let's extract it into its own primitive
 	planableVindex, ok := rb.eroute.RoutingParameters.Vindex.(vindexes.LookupPlanable)
-	if !ok {
+	if !ok || isAsyncLookup(planableVindex) {
 		rb.enginePrimitive = rb.eroute
 		return nil
 	}
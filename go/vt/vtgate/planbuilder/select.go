@@ -40,7 +40,7 @@ func gen4SelectStmtPlanner(
 ) (*planResult, error) {
 	switch node := stmt.(type) {
 	case *sqlparser.Select:
-		if node.With != nil {
+		if node.With != nil && !node.With.Recursive {
 			return nil, vterrors.VT12001("WITH expression in SELECT statement")
 		}
 	case *sqlparser.Union:
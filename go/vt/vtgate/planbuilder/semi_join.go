@@ -36,17 +36,21 @@ type semiJoin struct {
 
 	vars map[string]int
 
+	// anti is true for correlated NOT EXISTS and NOT IN subqueries
+	anti bool
+
 	// LHSColumns are the columns from the LHS used for the join.
 	// These are the same columns pushed on the LHS that are now used in the vars field
 	LHSColumns []*sqlparser.ColName
 }
 
 // newSemiJoin builds a new semiJoin.
-func newSemiJoin(lhs, rhs logicalPlan, vars map[string]int, lhsCols []*sqlparser.ColName) *semiJoin {
+func newSemiJoin(lhs, rhs logicalPlan, vars map[string]int, lhsCols []*sqlparser.ColName, anti bool) *semiJoin {
 	return &semiJoin{
 		rhs:        rhs,
 		lhs:        lhs,
 		vars:       vars,
+		anti:       anti,
 		LHSColumns: lhsCols,
 	}
 }
@@ -58,6 +62,7 @@ func (ps *semiJoin) Primitive() engine.Primitive {
 		Right: ps.rhs.Primitive(),
 		Vars:  ps.vars,
 		Cols:  ps.cols,
+		Anti:  ps.anti,
 	}
 }
 
@@ -20,7 +20,6 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	"vitess.io/vitess/go/vt/sqlparser"
-	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
@@ -34,7 +33,9 @@ func gen4UpdateStmtPlanner(
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
 	if updStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in UPDATE statement")
+		if err := inlineCommonTableExpressions(updStmt, updStmt.With, "UPDATE"); err != nil {
+			return nil, err
+		}
 	}
 
 	ctx, err := plancontext.CreatePlanningContext(updStmt, reservedVars, vschema, version)
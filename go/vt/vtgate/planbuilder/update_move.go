@@ -0,0 +1,89 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ logicalPlan = (*updateMove)(nil)
+
+// updateMove is the logicalPlan for engine.UpdateMove.
+type updateMove struct {
+	source      logicalPlan
+	upd         logicalPlan
+	del         logicalPlan
+	ins         logicalPlan
+	columns     []string
+	ksidVindex  vindexes.Vindex
+	ksidColumns []int
+}
+
+// Primitive implements the logicalPlan interface
+func (um *updateMove) Primitive() engine.Primitive {
+	return &engine.UpdateMove{
+		Input:       um.source.Primitive(),
+		Update:      um.upd.Primitive(),
+		Delete:      um.del.Primitive(),
+		Insert:      um.ins.Primitive(),
+		Columns:     um.columns,
+		KsidVindex:  um.ksidVindex,
+		KsidColumns: um.ksidColumns,
+	}
+}
+
+// Wireup implements the logicalPlan interface
+func (um *updateMove) Wireup(ctx *plancontext.PlanningContext) error {
+	for _, input := range um.Inputs() {
+		if err := input.Wireup(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewrite implements the logicalPlan interface
+func (um *updateMove) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 4 {
+		return vterrors.VT13001("updateMove: wrong number of inputs")
+	}
+	um.source = inputs[0]
+	um.upd = inputs[1]
+	um.del = inputs[2]
+	um.ins = inputs[3]
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (um *updateMove) ContainsTables() semantics.TableSet {
+	return um.del.ContainsTables()
+}
+
+// Inputs implements the logicalPlan interface
+func (um *updateMove) Inputs() []logicalPlan {
+	return []logicalPlan{um.source, um.upd, um.del, um.ins}
+}
+
+// OutputColumns implements the logicalPlan interface
+func (um *updateMove) OutputColumns() []sqlparser.SelectExpr {
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+var _ logicalPlan = (*upsert)(nil)
+
+// upsertRow contains the logical plans used to upsert a single row.
+type upsertRow struct {
+	sel, ins, upd logicalPlan
+}
+
+// upsert is the logicalPlan for engine.Upsert.
+type upsert struct {
+	rows []upsertRow
+}
+
+// Primitive implements the logicalPlan interface
+func (u *upsert) Primitive() engine.Primitive {
+	up := &engine.Upsert{}
+	for _, row := range u.rows {
+		up.Upserts = append(up.Upserts, &engine.UpsertRow{
+			Select: row.sel.Primitive(),
+			Insert: row.ins.Primitive(),
+			Update: row.upd.Primitive(),
+		})
+	}
+	return up
+}
+
+// Wireup implements the logicalPlan interface
+func (u *upsert) Wireup(ctx *plancontext.PlanningContext) error {
+	for _, input := range u.Inputs() {
+		if err := input.Wireup(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rewrite implements the logicalPlan interface
+func (u *upsert) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != 3*len(u.rows) {
+		return vterrors.VT13001("upsert: wrong number of inputs")
+	}
+	for i := range u.rows {
+		u.rows[i] = upsertRow{
+			sel: inputs[3*i],
+			ins: inputs[3*i+1],
+			upd: inputs[3*i+2],
+		}
+	}
+	return nil
+}
+
+// ContainsTables implements the logicalPlan interface
+func (u *upsert) ContainsTables() semantics.TableSet {
+	return u.rows[0].ins.ContainsTables()
+}
+
+// Inputs implements the logicalPlan interface
+func (u *upsert) Inputs() []logicalPlan {
+	var inputs []logicalPlan
+	for _, row := range u.rows {
+		inputs = append(inputs, row.sel, row.ins, row.upd)
+	}
+	return inputs
+}
+
+// OutputColumns implements the logicalPlan interface
+func (u *upsert) OutputColumns() []sqlparser.SelectExpr {
+	return nil
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/url"
 	"os"
 	"os/signal"
 	"regexp"
@@ -71,6 +72,10 @@ var (
 	mysqlQueryTimeout             time.Duration
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
+	mysqlServerEnableCompression  bool
+
+	mysqlServerCursorMaxMaterializedRows        = 100000
+	mysqlServerCursorMaxMaterializedBytes int64 = 64 * 1024 * 1024
 
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
@@ -96,6 +101,9 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
+	fs.BoolVar(&mysqlServerEnableCompression, "mysql-server-enable-compression", mysqlServerEnableCompression, "If set, the server will allow clients to use the zlib and zstd compressed protocol")
+	fs.IntVar(&mysqlServerCursorMaxMaterializedRows, "mysql-server-cursor-max-materialized-rows", mysqlServerCursorMaxMaterializedRows, "Maximum number of rows of an open server-side cursor that are read into memory when the connection runs another statement, which fails beyond it. 0 means no limit.")
+	fs.Int64Var(&mysqlServerCursorMaxMaterializedBytes, "mysql-server-cursor-max-materialized-bytes", mysqlServerCursorMaxMaterializedBytes, "Maximum size in bytes of the rows of an open server-side cursor that are read into memory when the connection runs another statement, which fails beyond it. 0 means no limit.")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
 }
@@ -143,6 +151,15 @@ func (vh *vtgateHandler) ComResetConnection(c *mysql.Conn) {
 	}
 }
 
+// ComChangeUser is part of the mysql.Handler interface.
+// The caller ID of subsequent queries is built from the new c.User and
+// c.UserData, so all that is left to do is to reset and drop the previous session.
+func (vh *vtgateHandler) ComChangeUser(c *mysql.Conn) {
+	vh.ComResetConnection(c)
+	c.ClientData = nil
+	fillInTxStatusFlags(c, vh.session(c))
+}
+
 func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 	// Rollback if there is an ongoing transaction. Ignore error.
 	defer func() {
@@ -169,20 +186,73 @@ func (vh *vtgateHandler) ConnectionClosed(c *mysql.Conn) {
 // Regexp to extract parent span id over the sql query
 var r = regexp.MustCompile(`/\*VT_SPAN_CONTEXT=(.*)\*/`)
 
+// sqlCommenterPair matches the key='value' pairs of a sqlcommenter comment,
+// such as /*controller='index',traceparent='00-...-01'*/. The keys and values
+// are URL encoded, and the quotes in the values are escaped with a backslash.
+var sqlCommenterPair = regexp.MustCompile(`([^\s/*=,']+)='((?:[^'\\]|\\.)*)'`)
+
+// traceContext is a W3C Trace Context, propagated by the client either
+// with a sqlcommenter comment, or with the query attributes.
+type traceContext struct {
+	traceparent string
+	tracestate  string
+}
+
+// sqlCommenterTraceContext returns the trace context of the sqlcommenter
+// comments, if any.
+func sqlCommenterTraceContext(comments string) traceContext {
+	var tc traceContext
+	for _, pair := range sqlCommenterPair.FindAllStringSubmatch(comments, -1) {
+		key, err := url.PathUnescape(pair[1])
+		if err != nil {
+			continue
+		}
+		value, err := url.PathUnescape(strings.ReplaceAll(pair[2], `\'`, `'`))
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "traceparent":
+			tc.traceparent = value
+		case "tracestate":
+			tc.tracestate = value
+		}
+	}
+	return tc
+}
+
+// getTraceContext returns the trace context given by the comments of the
+// query, or else by the query attributes.
+func getTraceContext(comments sqlparser.MarginComments, attributes map[string]string) traceContext {
+	tc := sqlCommenterTraceContext(comments.Leading + comments.Trailing)
+	if tc.traceparent != "" {
+		return tc
+	}
+	return traceContext{
+		traceparent: attributes["traceparent"],
+		tracestate:  attributes["tracestate"],
+	}
+}
+
 // this function is here to make this logic easy to test by decoupling the logic from the `trace.NewSpan` and `trace.NewFromString` functions
-func startSpanTestable(ctx context.Context, query, label string,
+func startSpanTestable(ctx context.Context, query string, attributes map[string]string, label string,
 	newSpan func(context.Context, string) (trace.Span, context.Context),
-	newSpanFromString func(context.Context, string, string) (trace.Span, context.Context, error)) (trace.Span, context.Context, error) {
+	newSpanFromString func(context.Context, string, string) (trace.Span, context.Context, error),
+	newSpanFromTraceContext func(context.Context, string, string, string) (trace.Span, context.Context, error)) (trace.Span, context.Context, error) {
 	_, comments := sqlparser.SplitMarginComments(query)
 	match := r.FindStringSubmatch(comments.Leading)
-	span, ctx := getSpan(ctx, match, newSpan, label, newSpanFromString)
+	tc := getTraceContext(comments, attributes)
+	span, ctx := getSpan(ctx, match, tc, newSpan, label, newSpanFromString, newSpanFromTraceContext)
 
 	trace.AnnotateSQL(span, sqlparser.Preview(query))
 
 	return span, ctx, nil
 }
 
-func getSpan(ctx context.Context, match []string, newSpan func(context.Context, string) (trace.Span, context.Context), label string, newSpanFromString func(context.Context, string, string) (trace.Span, context.Context, error)) (trace.Span, context.Context) {
+// getSpan creates the span of the query. Its parent is given by the
+// VT_SPAN_CONTEXT comment, or else by the W3C trace context when
+// newSpanFromTraceContext is set.
+func getSpan(ctx context.Context, match []string, tc traceContext, newSpan func(context.Context, string) (trace.Span, context.Context), label string, newSpanFromString func(context.Context, string, string) (trace.Span, context.Context, error), newSpanFromTraceContext func(context.Context, string, string, string) (trace.Span, context.Context, error)) (trace.Span, context.Context) {
 	var span trace.Span
 	if len(match) != 0 {
 		var err error
@@ -192,12 +262,25 @@ func getSpan(ctx context.Context, match []string, newSpan func(context.Context,
 		}
 		log.Warningf("Unable to parse VT_SPAN_CONTEXT: %s", err.Error())
 	}
+	if tc.traceparent != "" && newSpanFromTraceContext != nil {
+		var err error
+		span, ctx, err = newSpanFromTraceContext(ctx, tc.traceparent, tc.tracestate, label)
+		if err == nil {
+			return span, ctx
+		}
+		log.Warningf("Unable to parse traceparent: %s", err.Error())
+	}
 	span, ctx = newSpan(ctx, label)
 	return span, ctx
 }
 
-func startSpan(ctx context.Context, query, label string) (trace.Span, context.Context, error) {
-	return startSpanTestable(ctx, query, label, trace.NewSpan, trace.NewFromString)
+func startSpan(ctx context.Context, query string, attributes map[string]string, label string) (trace.Span, context.Context, error) {
+	// Only some tracers can use the W3C trace context. The others would fail to parse it on every query.
+	newSpanFromTraceContext := trace.NewFromTraceContext
+	if !trace.SupportsTraceContext() {
+		newSpanFromTraceContext = nil
+	}
+	return startSpanTestable(ctx, query, attributes, label, trace.NewSpan, trace.NewFromString, newSpanFromTraceContext)
 }
 
 func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sqltypes.Result) error) error {
@@ -209,7 +292,7 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		defer cancel()
 	}
 
-	span, ctx, err := startSpan(ctx, query, "vtgateHandler.ComQuery")
+	span, ctx, err := startSpan(ctx, query, c.QueryAttributes, "vtgateHandler.ComQuery")
 	if err != nil {
 		return vterrors.Wrap(err, "failed to extract span")
 	}
@@ -321,6 +404,12 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		defer cancel()
 	}
 
+	span, ctx, err := startSpan(ctx, prepare.PrepareStmt, c.QueryAttributes, "vtgateHandler.ComStmtExecute")
+	if err != nil {
+		return vterrors.Wrap(err, "failed to extract span")
+	}
+	defer span.Finish()
+
 	ctx = callinfo.MysqlCallInfo(ctx, c)
 
 	// Fill in the ImmediateCallerID with the UserData returned by
@@ -345,7 +434,9 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
-	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
+	// A server-side cursor only sends the rows as the client fetches
+	// them, so they are streamed rather than buffered.
+	if session.Options.Workload == querypb.ExecuteOptions_OLAP || prepare.HasCursor() {
 		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
 		if err != nil {
 			return sqlerror.NewSQLErrorFromError(err)
@@ -535,6 +626,9 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		srv.tcpListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		srv.tcpListener.EnableCompression = mysqlServerEnableCompression
+		srv.tcpListener.CursorMaxMaterializedRows = mysqlServerCursorMaxMaterializedRows
+		srv.tcpListener.CursorMaxMaterializedBytes = mysqlServerCursorMaxMaterializedBytes
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
@@ -554,6 +648,9 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 			log.Exitf("mysql.NewListener failed: %v", err)
 			return nil
 		}
+		srv.unixListener.EnableCompression = mysqlServerEnableCompression
+		srv.unixListener.CursorMaxMaterializedRows = mysqlServerCursorMaxMaterializedRows
+		srv.unixListener.CursorMaxMaterializedBytes = mysqlServerCursorMaxMaterializedBytes
 		// Listen for unix socket
 		go srv.unixListener.Accept()
 	}
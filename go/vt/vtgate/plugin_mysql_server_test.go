@@ -200,6 +200,21 @@ func newFromStringExpect(t *testing.T, expected string) func(ctx context.Context
 	}
 }
 
+func newFromTraceContextFail(t *testing.T) func(ctx context.Context, traceparent, tracestate, label string) (trace.Span, context.Context, error) {
+	return func(ctx context.Context, traceparent, tracestate, label string) (trace.Span, context.Context, error) {
+		t.Fatalf("no trace context should have been used. got: %v", traceparent)
+		return trace.NoopSpan{}, context.Background(), nil
+	}
+}
+
+func newFromTraceContextExpect(t *testing.T, traceparent, tracestate string) func(ctx context.Context, traceparent, tracestate, label string) (trace.Span, context.Context, error) {
+	return func(ctx context.Context, gotTraceparent, gotTracestate, label string) (trace.Span, context.Context, error) {
+		assert.Equal(t, traceparent, gotTraceparent)
+		assert.Equal(t, tracestate, gotTracestate)
+		return trace.NoopSpan{}, context.Background(), nil
+	}
+}
+
 func newSpanFail(t *testing.T) func(ctx context.Context, label string) (trace.Span, context.Context) {
 	return func(ctx context.Context, label string) (trace.Span, context.Context) {
 		t.Fatalf("we provided a span context but newFromString was not used as expected")
@@ -208,39 +223,121 @@ func newSpanFail(t *testing.T) func(ctx context.Context, label string) (trace.Sp
 }
 
 func TestNoSpanContextPassed(t *testing.T) {
-	_, _, err := startSpanTestable(context.Background(), "sql without comments", "someLabel", newSpanOK, newFromStringFail(t))
+	_, _, err := startSpanTestable(context.Background(), "sql without comments", nil, "someLabel", newSpanOK, newFromStringFail(t), newFromTraceContextFail(t))
 	assert.NoError(t, err)
 }
 
 func TestSpanContextNoPassedInButExistsInString(t *testing.T) {
-	_, _, err := startSpanTestable(context.Background(), "SELECT * FROM SOMETABLE WHERE COL = \"/*VT_SPAN_CONTEXT=123*/", "someLabel", newSpanOK, newFromStringFail(t))
+	_, _, err := startSpanTestable(context.Background(), "SELECT * FROM SOMETABLE WHERE COL = \"/*VT_SPAN_CONTEXT=123*/", nil, "someLabel", newSpanOK, newFromStringFail(t), newFromTraceContextFail(t))
 	assert.NoError(t, err)
 }
 
 func TestSpanContextPassedIn(t *testing.T) {
-	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SQL QUERY", "someLabel", newSpanFail(t), newFromStringOK)
+	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SQL QUERY", nil, "someLabel", newSpanFail(t), newFromStringOK, newFromTraceContextFail(t))
 	assert.NoError(t, err)
 }
 
 func TestSpanContextPassedInEvenAroundOtherComments(t *testing.T) {
-	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SELECT /*vt+ SCATTER_ERRORS_AS_WARNINGS */ col1, col2 FROM TABLE ", "someLabel",
+	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SELECT /*vt+ SCATTER_ERRORS_AS_WARNINGS */ col1, col2 FROM TABLE ", nil, "someLabel",
 		newSpanFail(t),
-		newFromStringExpect(t, "123"))
+		newFromStringExpect(t, "123"),
+		newFromTraceContextFail(t))
 	assert.NoError(t, err)
 }
 
 func TestSpanContextNotParsable(t *testing.T) {
 	hasRun := false
-	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SQL QUERY", "someLabel",
+	_, _, err := startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/SQL QUERY", nil, "someLabel",
 		func(c context.Context, s string) (trace.Span, context.Context) {
 			hasRun = true
 			return trace.NoopSpan{}, context.Background()
 		},
-		newFromStringError(t))
+		newFromStringError(t),
+		newFromTraceContextFail(t))
 	assert.NoError(t, err)
 	assert.True(t, hasRun, "Should have continued execution despite failure to parse VT_SPAN_CONTEXT")
 }
 
+func TestSQLCommenterTraceContext(t *testing.T) {
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	tests := []struct {
+		query       string
+		traceparent string
+		tracestate  string
+	}{{
+		query:       "select 1 /*traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		traceparent: traceparent,
+	}, {
+		query:       "/*action='%2Fparam%2Ad',controller='index',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01',tracestate='congo%3Dt61rcWkgMzE%2Crojo%3D00f067aa0ba902b7'*/ select 1",
+		traceparent: traceparent,
+		tracestate:  "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7",
+	}, {
+		query:       "select 1 /* vt+ PLANNER=gen4 */ /*framework='spring\\'s',traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/",
+		traceparent: traceparent,
+	}}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			_, _, err := startSpanTestable(context.Background(), test.query, nil, "someLabel",
+				newSpanFail(t),
+				newFromStringFail(t),
+				newFromTraceContextExpect(t, test.traceparent, test.tracestate))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestTraceContextFromQueryAttributes(t *testing.T) {
+	attributes := map[string]string{
+		"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"tracestate":  "congo=t61rcWkgMzE",
+	}
+	_, _, err := startSpanTestable(context.Background(), "select 1", attributes, "someLabel",
+		newSpanFail(t),
+		newFromStringFail(t),
+		newFromTraceContextExpect(t, attributes["traceparent"], attributes["tracestate"]))
+	assert.NoError(t, err)
+
+	// The comments have precedence over the query attributes.
+	_, _, err = startSpanTestable(context.Background(), "select 1 /*traceparent='00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01'*/", attributes, "someLabel",
+		newSpanFail(t),
+		newFromStringFail(t),
+		newFromTraceContextExpect(t, "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01", ""))
+	assert.NoError(t, err)
+	_, _, err = startSpanTestable(context.Background(), "/*VT_SPAN_CONTEXT=123*/select 1", attributes, "someLabel",
+		newSpanFail(t),
+		newFromStringExpect(t, "123"),
+		newFromTraceContextFail(t))
+	assert.NoError(t, err)
+}
+
+func TestTraceContextNotParsable(t *testing.T) {
+	hasRun := false
+	_, _, err := startSpanTestable(context.Background(), "select 1 /*traceparent='garbage'*/", nil, "someLabel",
+		func(c context.Context, s string) (trace.Span, context.Context) {
+			hasRun = true
+			return trace.NoopSpan{}, context.Background()
+		},
+		newFromStringFail(t),
+		func(ctx context.Context, traceparent, tracestate, label string) (trace.Span, context.Context, error) {
+			return trace.NoopSpan{}, context.Background(), fmt.Errorf("")
+		})
+	assert.NoError(t, err)
+	assert.True(t, hasRun, "Should have continued execution despite failure to parse traceparent")
+}
+
+func TestTraceContextUnsupportedTracer(t *testing.T) {
+	hasRun := false
+	_, _, err := startSpanTestable(context.Background(), "select 1 /*traceparent='00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01'*/", nil, "someLabel",
+		func(c context.Context, s string) (trace.Span, context.Context) {
+			hasRun = true
+			return trace.NoopSpan{}, context.Background()
+		},
+		newFromStringFail(t),
+		nil)
+	assert.NoError(t, err)
+	assert.True(t, hasRun, "Should have created a new span when the tracer does not use the trace context")
+}
+
 func newTestAuthServerStatic() *mysql.AuthServerStatic {
 	jsonConfig := "{\"user1\":{\"Password\":\"password1\", \"UserData\":\"userData1\", \"SourceHost\":\"localhost\"}}"
 	return mysql.NewAuthServerStatic("", jsonConfig, 0)
@@ -342,3 +439,22 @@ func TestKillMethods(t *testing.T) {
 	require.EqualError(t, cancelCtx.Err(), "context canceled")
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+// TestComChangeUser tests that the session is dropped when the user changes.
+func TestComChangeUser(t *testing.T) {
+	executor, _, _, _, _ := createExecutorEnv(t)
+	vh := newVtgateHandler(&VTGate{executor: executor})
+
+	mysqlConn := mysql.GetTestConn()
+	session := vh.session(mysqlConn)
+	session.TargetString = "TestExecutor"
+	session.Autocommit = false
+
+	vh.ComChangeUser(mysqlConn)
+
+	newSession := vh.session(mysqlConn)
+	assert.NotEqual(t, session.SessionUUID, newSession.SessionUUID)
+	assert.Empty(t, newSession.TargetString)
+	assert.True(t, newSession.Autocommit)
+	assert.NotZero(t, mysqlConn.StatusFlags&mysql.ServerStatusAutocommit)
+}
@@ -0,0 +1,373 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/cache/theine"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/logstats"
+	"vitess.io/vitess/go/vt/vthash"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+var (
+	resultCacheHits          = stats.NewCounter("ResultCacheHits", "Number of results served from the result cache")
+	resultCacheMisses        = stats.NewCounter("ResultCacheMisses", "Number of cacheable results not found in the result cache")
+	resultCacheInvalidations = stats.NewCountersWithSingleLabel("ResultCacheInvalidations", "Number of invalidations of the cached results of a table", "Table")
+
+	// resultCacheRetryDelay is the delay before restarting the stream of the changes of a keyspace after a failure
+	resultCacheRetryDelay = 5 * time.Second
+)
+
+// ResultCacheKey is the key of a result in the result cache.
+type ResultCacheKey = theine.HashKey256
+
+// resultCacheStreamer streams the changes of the tables whose results are cached.
+// It is implemented by the vstreamManager.
+type resultCacheStreamer interface {
+	CurrentVGtid(ctx context.Context, tabletType topodatapb.TabletType, keyspace string) (*binlogdatapb.VGtid, error)
+	VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error
+}
+
+// cachedResult is a result of the result cache, with the versions of the tables it was read from.
+type cachedResult struct {
+	result   *sqltypes.Result
+	expires  time.Time
+	tables   []string
+	versions []uint64
+}
+
+// CachedSize returns the size of the result in memory, which is its cost in the result cache.
+func (cr *cachedResult) CachedSize(alloc bool) int64 {
+	size := cr.result.CachedSize(true)
+	if alloc {
+		size += int64(80)
+	}
+	for _, table := range cr.tables {
+		size += int64(16 + len(table))
+	}
+	size += int64(8 * len(cr.versions))
+	return size
+}
+
+// resultCacheKeyspace streams the changes of the tables of a keyspace read by cached results.
+type resultCacheKeyspace struct {
+	tables map[string]bool
+	// ready is set once the positions of all the shards the stream starts from are known
+	ready bool
+	// generation identifies the current stream, which is restarted when a table is added
+	generation int
+	cancel     context.CancelFunc
+}
+
+// resultCache caches the results of the SELECT queries. A result is invalidated
+// when one of the tables it was read from changes: the changes of the tables are
+// streamed from the primary tablets of their keyspaces with VStream, and every
+// change increments the version of its table. The stream starts from the
+// positions of the shards read before any result of its tables is cached.
+//
+// The invalidation is asynchronous: a result read after a write, even by the
+// session that made it, can be served stale from the cache until the row event
+// of the write has been streamed to vtgate. The queries whose results depend on
+// the time, the session or a lock are never cached, see planbuilder.resultCacheTTL.
+type resultCache struct {
+	store    *theine.Store[ResultCacheKey, *cachedResult]
+	streamer resultCacheStreamer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	versions  map[string]uint64
+	keyspaces map[string]*resultCacheKeyspace
+}
+
+func newResultCache(maxMemory int64, doorkeeper bool, streamer resultCacheStreamer) *resultCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &resultCache{
+		store:     theine.NewStore[ResultCacheKey, *cachedResult](maxMemory, doorkeeper),
+		streamer:  streamer,
+		ctx:       ctx,
+		cancel:    cancel,
+		versions:  make(map[string]uint64),
+		keyspaces: make(map[string]*resultCacheKeyspace),
+	}
+}
+
+// key returns the key of the result of a query. It depends on the callers, as
+// the tablets check their access to the tables, on the target of the query, and
+// on the system variables of the session, as the settings like the time zone or
+// the sql mode change the results.
+func (rc *resultCache) key(ctx context.Context, vcursor *vcursorImpl, query string, bindVars map[string]*querypb.BindVariable) ResultCacheKey {
+	hasher := vthash.New256()
+	_, _ = hasher.WriteString("Principal:")
+	_, _ = hasher.WriteString(callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(ctx)))
+	_, _ = hasher.WriteString("+Username:")
+	_, _ = hasher.WriteString(callerid.GetUsername(callerid.ImmediateCallerIDFromContext(ctx)))
+	_, _ = hasher.WriteString("+Target:")
+	vcursor.keyForPlan(ctx, query, hasher)
+
+	var sysVars []string
+	vcursor.safeSession.GetSystemVariables(func(name, value string) {
+		sysVars = append(sysVars, name+"="+value)
+	})
+	sort.Strings(sysVars)
+	for _, sysVar := range sysVars {
+		_, _ = hasher.WriteString("+SystemVariable:")
+		_, _ = hasher.WriteString(sysVar)
+	}
+
+	names := make([]string, 0, len(bindVars))
+	for name := range bindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var size [8]byte
+	for _, name := range names {
+		value, _ := bindVars[name].MarshalVT()
+		_, _ = hasher.WriteString("+BindVar:")
+		_, _ = hasher.WriteString(name)
+		binary.BigEndian.PutUint64(size[:], uint64(len(value)))
+		_, _ = hasher.Write(size[:])
+		_, _ = hasher.Write(value)
+	}
+
+	var key ResultCacheKey
+	hasher.Sum(key[:0])
+	return key
+}
+
+// get returns a cached result, if it has not expired and its tables have not changed since it was read.
+func (rc *resultCache) get(key ResultCacheKey) (*sqltypes.Result, bool) {
+	cached, ok := rc.store.Get(key, 0)
+	if ok && time.Now().Before(cached.expires) && rc.current(cached.tables, cached.versions) {
+		resultCacheHits.Add(1)
+		return cached.result.ShallowCopy(), true
+	}
+	resultCacheMisses.Add(1)
+	return nil, false
+}
+
+// current returns true if the tables have not changed since they had the given versions.
+func (rc *resultCache) current(tables []string, versions []uint64) bool {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for i, table := range tables {
+		keyspace, _, _ := strings.Cut(table, ".")
+		if ks := rc.keyspaces[keyspace]; ks == nil || !ks.ready || rc.versions[table] != versions[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tableVersions returns the current versions of keyspace qualified tables, and starts
+// streaming the changes of the tables that are not streamed yet. The results read from
+// the tables can only be cached if the changes of all the tables are already streamed.
+func (rc *resultCache) tableVersions(tables []string) ([]uint64, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	versions := make([]uint64, len(tables))
+	ready := true
+	for i, table := range tables {
+		keyspace, name, ok := strings.Cut(table, ".")
+		if !ok {
+			return nil, false
+		}
+		if !rc.watch(keyspace, name) {
+			ready = false
+		}
+		versions[i] = rc.versions[table]
+	}
+	return versions, ready
+}
+
+// set caches the result of a query read from tables with the given versions.
+func (rc *resultCache) set(key ResultCacheKey, result *sqltypes.Result, ttl time.Duration, tables []string, versions []uint64) {
+	rc.store.Set(key, &cachedResult{
+		result:   result.Copy(),
+		expires:  time.Now().Add(ttl),
+		tables:   tables,
+		versions: versions,
+	}, 0, 0)
+}
+
+// watch streams the changes of a table, and returns true if they are already streamed.
+// It must be called with the mutex held.
+func (rc *resultCache) watch(keyspace, table string) bool {
+	ks := rc.keyspaces[keyspace]
+	if ks == nil {
+		ks = &resultCacheKeyspace{tables: make(map[string]bool)}
+		rc.keyspaces[keyspace] = ks
+	}
+	if ks.tables[table] {
+		return ks.ready
+	}
+	ks.tables[table] = true
+
+	// the stream is restarted to add the table to its filter
+	if ks.cancel != nil {
+		ks.cancel()
+	}
+	ks.ready = false
+	rc.invalidateKeyspace(keyspace, ks)
+	ks.generation++
+	ctx, cancel := context.WithCancel(rc.ctx)
+	ks.cancel = cancel
+	filter := &binlogdatapb.Filter{}
+	for table := range ks.tables {
+		filter.Rules = append(filter.Rules, &binlogdatapb.Rule{Match: table})
+	}
+	sort.Slice(filter.Rules, func(i, j int) bool {
+		return filter.Rules[i].Match < filter.Rules[j].Match
+	})
+	go rc.stream(ctx, keyspace, ks.generation, filter)
+	return false
+}
+
+// stream streams the changes of the tables of a keyspace until the context is canceled.
+func (rc *resultCache) stream(ctx context.Context, keyspace string, generation int, filter *binlogdatapb.Filter) {
+	for {
+		// the stream starts from the positions of the shards read before any result is cached,
+		// so that it gets all the changes made after the results are read
+		vgtid, err := rc.streamer.CurrentVGtid(ctx, topodatapb.TabletType_PRIMARY, keyspace)
+		if err == nil {
+			rc.mu.Lock()
+			if ks := rc.keyspaces[keyspace]; ks.generation == generation {
+				ks.ready = true
+			}
+			rc.mu.Unlock()
+			err = rc.streamer.VStream(ctx, topodatapb.TabletType_PRIMARY, vgtid, filter, &vtgatepb.VStreamFlags{}, func(events []*binlogdatapb.VEvent) error {
+				return rc.handleEvents(keyspace, generation, events)
+			})
+		}
+
+		// the changes of the tables are not streamed anymore, so their cached results can't be trusted
+		rc.mu.Lock()
+		ks := rc.keyspaces[keyspace]
+		if ks.generation == generation {
+			ks.ready = false
+			rc.invalidateKeyspace(keyspace, ks)
+		}
+		rc.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+		log.Warningf("The stream of the changes of keyspace %s for the result cache has ended, restarting it in %v: %v", keyspace, resultCacheRetryDelay, err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resultCacheRetryDelay):
+		}
+	}
+}
+
+// handleEvents invalidates the cached results of the tables changed by the events of a keyspace.
+func (rc *resultCache) handleEvents(keyspace string, generation int, events []*binlogdatapb.VEvent) error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	ks := rc.keyspaces[keyspace]
+	if ks.generation != generation {
+		return fmt.Errorf("the stream of the changes of keyspace %s has been replaced", keyspace)
+	}
+	for _, event := range events {
+		switch event.Type {
+		case binlogdatapb.VEventType_ROW:
+			rc.invalidate(event.RowEvent.TableName)
+		case binlogdatapb.VEventType_DDL:
+			rc.invalidateKeyspace(keyspace, ks)
+		}
+	}
+	return nil
+}
+
+// invalidateKeyspace invalidates the cached results of all the tables of a keyspace.
+// It must be called with the mutex held.
+func (rc *resultCache) invalidateKeyspace(keyspace string, ks *resultCacheKeyspace) {
+	for table := range ks.tables {
+		rc.invalidate(keyspace + "." + table)
+	}
+}
+
+// invalidate invalidates the cached results of a keyspace qualified table.
+// It must be called with the mutex held.
+func (rc *resultCache) invalidate(table string) {
+	rc.versions[table]++
+	resultCacheInvalidations.Add(table, 1)
+}
+
+// Close stops streaming the changes of the tables and empties the cache.
+func (rc *resultCache) Close() {
+	rc.cancel()
+	rc.store.Close()
+}
+
+// canCacheResult returns true if the result of a plan can be served from the result cache.
+// Only the reads of the primary tablets are cached, as the results are invalidated by the
+// changes streamed from the primary tablets, which replicas may not have applied yet.
+func (e *Executor) canCacheResult(safeSession *SafeSession, vcursor *vcursorImpl, plan *engine.Plan) bool {
+	return e.resultCache != nil && plan.ResultCacheTTL > 0 && plan.Type == sqlparser.StmtSelect &&
+		vcursor.TabletType() == topodatapb.TabletType_PRIMARY &&
+		!safeSession.InTransaction() && !safeSession.InReservedConn()
+}
+
+// executeCachedPlan executes a plan whose result can be cached, or serves its result from the cache.
+func (e *Executor) executeCachedPlan(
+	ctx context.Context,
+	plan *engine.Plan,
+	vcursor *vcursorImpl,
+	bindVars map[string]*querypb.BindVariable,
+	logStats *logstats.LogStats,
+	execStart time.Time,
+) (*sqltypes.Result, error) {
+	key := e.resultCache.key(ctx, vcursor, plan.Original, bindVars)
+	if qr, ok := e.resultCache.get(key); ok {
+		e.setLogStats(logStats, plan, vcursor, execStart, nil, qr)
+		return qr, nil
+	}
+
+	// the versions of the tables are read before the query, so that the
+	// result is invalidated by the changes made while it is executed
+	versions, cacheable := e.resultCache.tableVersions(plan.TablesUsed)
+	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
+	if err != nil {
+		return nil, err
+	}
+	if cacheable {
+		e.resultCache.set(key, qr, plan.ResultCacheTTL, plan.TablesUsed, versions)
+	}
+	return qr, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeResultCacheStreamer streams the events sent to it, from the positions it returns.
+type fakeResultCacheStreamer struct {
+	mu      sync.Mutex
+	vgtids  []*binlogdatapb.VGtid
+	filters []*binlogdatapb.Filter
+	events  chan []*binlogdatapb.VEvent
+}
+
+func newFakeResultCacheStreamer() *fakeResultCacheStreamer {
+	return &fakeResultCacheStreamer{events: make(chan []*binlogdatapb.VEvent)}
+}
+
+func (fs *fakeResultCacheStreamer) CurrentVGtid(ctx context.Context, tabletType topodatapb.TabletType, keyspace string) (*binlogdatapb.VGtid, error) {
+	return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+		Keyspace: keyspace,
+		Shard:    "0",
+		Gtid:     "MySQL56/a5b8f9e0-1f2d-11ee-9c4e-0242ac120002:1-10",
+	}}}, nil
+}
+
+func (fs *fakeResultCacheStreamer) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid, filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error {
+	fs.mu.Lock()
+	fs.vgtids = append(fs.vgtids, vgtid)
+	fs.filters = append(fs.filters, filter)
+	fs.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case events := <-fs.events:
+			if err := send(events); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (fs *fakeResultCacheStreamer) lastFilter() *binlogdatapb.Filter {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if len(fs.filters) == 0 {
+		return nil
+	}
+	return fs.filters[len(fs.filters)-1]
+}
+
+func (fs *fakeResultCacheStreamer) lastVGtid() *binlogdatapb.VGtid {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if len(fs.vgtids) == 0 {
+		return nil
+	}
+	return fs.vgtids[len(fs.vgtids)-1]
+}
+
+func TestResultCache(t *testing.T) {
+	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
+	streamer := newFakeResultCacheStreamer()
+	executor.resultCache = newResultCache(1024*1024, false, streamer)
+
+	session := &vtgatepb.Session{TargetString: "@primary", Autocommit: true}
+	query := "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from music_user_map where id = :id"
+	bindVars := map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(1)}
+	sbclookup.SetResults([]*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"),
+	})
+
+	// the result is not cached until the changes of the table are streamed
+	_, err := executorExec(ctx, executor, session, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sbclookup.ExecCount.Load())
+	require.Eventually(t, func() bool {
+		_, ready := executor.resultCache.tableVersions([]string{"TestUnsharded.music_user_map"})
+		return ready
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return streamer.lastFilter() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []*binlogdatapb.Rule{{Match: "music_user_map"}}, streamer.lastFilter().Rules)
+	// the stream starts from the positions read before the results are cached, not from "current"
+	assert.Equal(t, "MySQL56/a5b8f9e0-1f2d-11ee-9c4e-0242ac120002:1-10", streamer.lastVGtid().ShardGtids[0].Gtid)
+
+	hits := resultCacheHits.Get()
+	qr, err := executorExec(ctx, executor, session, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbclookup.ExecCount.Load())
+	cached, err := executorExec(ctx, executor, session, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbclookup.ExecCount.Load())
+	assert.Equal(t, qr.Rows, cached.Rows)
+	assert.EqualValues(t, hits+1, resultCacheHits.Get())
+
+	// other bind variables have their own result
+	_, err = executorExec(ctx, executor, session, query, map[string]*querypb.BindVariable{"id": sqltypes.Int64BindVariable(2)})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, sbclookup.ExecCount.Load())
+
+	// a change of the table invalidates its results
+	tables := []string{"TestUnsharded.music_user_map"}
+	versions, _ := executor.resultCache.tableVersions(tables)
+	streamer.events <- []*binlogdatapb.VEvent{{
+		Type:     binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{TableName: "TestUnsharded.music_user_map"},
+	}}
+	require.Eventually(t, func() bool {
+		return !executor.resultCache.current(tables, versions)
+	}, 5*time.Second, 10*time.Millisecond)
+	_, err = executorExec(ctx, executor, session, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, sbclookup.ExecCount.Load())
+	_, err = executorExec(ctx, executor, session, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, sbclookup.ExecCount.Load())
+
+	// the results read in transactions are not cached
+	txSession := &vtgatepb.Session{TargetString: "@primary", InTransaction: true}
+	_, err = executorExec(ctx, executor, txSession, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, sbclookup.ExecCount.Load())
+
+	// the results read from replicas are not cached, as they may lag behind the streamed changes
+	replicaSession := &vtgatepb.Session{TargetString: "@replica", Autocommit: true}
+	hits = resultCacheHits.Get()
+	_, err = executorExec(ctx, executor, replicaSession, query, bindVars)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, replicaSession, query, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, hits, resultCacheHits.Get())
+
+	// the results of the queries not enabling the cache are not cached
+	uncached := "select id from music_user_map where id = :id"
+	_, err = executorExec(ctx, executor, session, uncached, bindVars)
+	require.NoError(t, err)
+	_, err = executorExec(ctx, executor, session, uncached, bindVars)
+	require.NoError(t, err)
+	assert.EqualValues(t, 7, sbclookup.ExecCount.Load())
+}
+
+func TestResultCacheSystemVariables(t *testing.T) {
+	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
+	executor.resultCache = newResultCache(1024*1024, false, newFakeResultCacheStreamer())
+	require.Eventually(t, func() bool {
+		_, ready := executor.resultCache.tableVersions([]string{"TestUnsharded.music_user_map"})
+		return ready
+	}, 5*time.Second, 10*time.Millisecond)
+
+	query := "select /*vt+ RESULT_CACHE_TTL_MS=60000 */ id from music_user_map where id = 1"
+	utc := &vtgatepb.Session{TargetString: "@primary", Autocommit: true, SystemVariables: map[string]string{"time_zone": "'+00:00'"}}
+	paris := &vtgatepb.Session{TargetString: "@primary", Autocommit: true, SystemVariables: map[string]string{"time_zone": "'Europe/Paris'"}}
+
+	// the sessions with other settings don't share their results
+	_, err := executorExec(ctx, executor, utc, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sbclookup.ExecCount.Load())
+	_, err = executorExec(ctx, executor, utc, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, sbclookup.ExecCount.Load())
+	_, err = executorExec(ctx, executor, paris, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbclookup.ExecCount.Load())
+	_, err = executorExec(ctx, executor, paris, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbclookup.ExecCount.Load())
+
+	// the key depends on the settings even when they are not in the query,
+	// like when they are applied with the settings pool
+	utcVCursor, err := newVCursorImpl(NewSafeSession(utc), makeComments(""), executor, nil, executor.vm, executor.VSchema(), executor.resolver.resolver, nil, false, pv)
+	require.NoError(t, err)
+	parisVCursor, err := newVCursorImpl(NewSafeSession(paris), makeComments(""), executor, nil, executor.vm, executor.VSchema(), executor.resolver.resolver, nil, false, pv)
+	require.NoError(t, err)
+	assert.NotEqual(t,
+		executor.resultCache.key(ctx, utcVCursor, query, nil),
+		executor.resultCache.key(ctx, parisVCursor, query, nil),
+	)
+}
+
+func TestResultCacheExpiry(t *testing.T) {
+	rc := newResultCache(1024*1024, false, newFakeResultCacheStreamer())
+	defer rc.Close()
+
+	tables := []string{"ks.t1"}
+	require.Eventually(t, func() bool {
+		_, ready := rc.tableVersions(tables)
+		return ready
+	}, 5*time.Second, 10*time.Millisecond)
+	versions, _ := rc.tableVersions(tables)
+
+	var key1, key2 ResultCacheKey
+	key2[0] = 1
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	rc.set(key1, result, time.Hour, tables, versions)
+	rc.set(key2, result, -time.Second, tables, versions)
+
+	qr, ok := rc.get(key1)
+	require.True(t, ok)
+	assert.Equal(t, result, qr)
+	_, ok = rc.get(key2)
+	assert.False(t, ok)
+
+	// a DDL on the keyspace invalidates the results of all its tables
+	require.NoError(t, rc.handleEvents("ks", 1, []*binlogdatapb.VEvent{{Type: binlogdatapb.VEventType_DDL}}))
+	_, ok = rc.get(key1)
+	assert.False(t, ok)
+}
@@ -292,8 +292,10 @@ func (t *Tracker) updateTables(keyspace string, res map[string]string) {
 		}
 
 		cols := getColumns(ddl.TableSpec)
+		pk := getPrimaryKey(ddl.TableSpec)
+		uks := getUniqueKeys(ddl.TableSpec)
 		fks := getForeignKeys(ddl.TableSpec)
-		t.tables.set(keyspace, tableName, cols, fks)
+		t.tables.set(keyspace, tableName, cols, pk, uks, fks)
 	}
 }
 
@@ -307,11 +309,53 @@ func getColumns(tblSpec *sqlparser.TableSpec) []vindexes.Column {
 				Name:          column.Name,
 				Type:          column.Type.SQLType(),
 				CollationName: colCollation,
+				Generated:     column.Type.Options != nil && column.Type.Options.As != nil,
 			})
 	}
 	return cols
 }
 
+func getPrimaryKey(tblSpec *sqlparser.TableSpec) sqlparser.Columns {
+	for _, idx := range tblSpec.Indexes {
+		if idx.Info.Type != sqlparser.IndexTypePrimary {
+			continue
+		}
+		var pk sqlparser.Columns
+		for _, col := range idx.Columns {
+			pk = append(pk, col.Column)
+		}
+		return pk
+	}
+	// the primary key can also be declared on the column definition
+	for _, column := range tblSpec.Columns {
+		if column.Type.Options != nil && column.Type.Options.KeyOpt == sqlparser.ColKeyPrimary {
+			return sqlparser.Columns{column.Name}
+		}
+	}
+	return nil
+}
+
+func getUniqueKeys(tblSpec *sqlparser.TableSpec) []sqlparser.Columns {
+	var uks []sqlparser.Columns
+	for _, idx := range tblSpec.Indexes {
+		if idx.Info.Type != sqlparser.IndexTypeUnique {
+			continue
+		}
+		var uk sqlparser.Columns
+		for _, col := range idx.Columns {
+			uk = append(uk, col.Column)
+		}
+		uks = append(uks, uk)
+	}
+	// the unique keys can also be declared on the column definitions
+	for _, column := range tblSpec.Columns {
+		if column.Type.Options != nil && (column.Type.Options.KeyOpt == sqlparser.ColKeyUnique || column.Type.Options.KeyOpt == sqlparser.ColKeyUniqueKey) {
+			uks = append(uks, sqlparser.Columns{column.Name})
+		}
+	}
+	return uks
+}
+
 func getForeignKeys(tblSpec *sqlparser.TableSpec) []*sqlparser.ForeignKeyDefinition {
 	if tblSpec.Constraints == nil {
 		return nil
@@ -402,13 +446,13 @@ type tableMap struct {
 	m map[keyspaceStr]map[tableNameStr]*vindexes.TableInfo
 }
 
-func (tm *tableMap) set(ks, tbl string, cols []vindexes.Column, fks []*sqlparser.ForeignKeyDefinition) {
+func (tm *tableMap) set(ks, tbl string, cols []vindexes.Column, pk sqlparser.Columns, uks []sqlparser.Columns, fks []*sqlparser.ForeignKeyDefinition) {
 	m := tm.m[ks]
 	if m == nil {
 		m = make(map[tableNameStr]*vindexes.TableInfo)
 		tm.m[ks] = m
 	}
-	m[tbl] = &vindexes.TableInfo{Columns: cols, ForeignKeys: fks}
+	m[tbl] = &vindexes.TableInfo{Columns: cols, PrimaryKey: pk, UniqueKeys: uks, ForeignKeys: fks}
 }
 
 func (tm *tableMap) get(ks, tbl string) *vindexes.TableInfo {
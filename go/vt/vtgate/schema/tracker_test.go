@@ -19,6 +19,7 @@ package schema
 import (
 	"context"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -272,7 +273,8 @@ func TestTableInfoRetrieval(t *testing.T) {
 			"`name` varchar(50) CHARACTER SET latin1 COLLATE latin1_swedish_ci DEFAULT NULL," +
 			"`email` varbinary(100) DEFAULT NULL," +
 			"PRIMARY KEY (`id`)," +
-			"KEY `id` (`id`,`name`)) " +
+			"KEY `id` (`id`,`name`)," +
+			"UNIQUE KEY `email` (`email`)) " +
 			"ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci",
 	}, {
 		// initial load of view - kept empty
@@ -282,6 +284,7 @@ func TestTableInfoRetrieval(t *testing.T) {
 			"`name` varchar(50) CHARACTER SET latin1 COLLATE latin1_swedish_ci DEFAULT NULL," +
 			"`code` varchar(6) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci DEFAULT NULL," +
 			"`my_id` bigint DEFAULT NULL," +
+			"`code_len` int GENERATED ALWAYS AS (char_length(`code`)) VIRTUAL," +
 			"PRIMARY KEY (`id`)," +
 			"KEY `my_id` (`my_id`,`name`)," +
 			"CONSTRAINT `my_child_tbl_ibfk_1` FOREIGN KEY (`my_id`, `name`) REFERENCES `my_tbl` (`id`, `name`) ON DELETE CASCADE) " +
@@ -311,12 +314,20 @@ func TestTableInfoRetrieval(t *testing.T) {
 				{Name: sqlparser.NewIdentifierCI("name"), Type: querypb.Type_VARCHAR, CollationName: "latin1_swedish_ci"},
 				{Name: sqlparser.NewIdentifierCI("code"), Type: querypb.Type_VARCHAR, CollationName: "utf8mb4_0900_ai_ci"},
 				{Name: sqlparser.NewIdentifierCI("my_id"), Type: querypb.Type_INT64, CollationName: "utf8mb4_0900_ai_ci"},
+				{Name: sqlparser.NewIdentifierCI("code_len"), Type: querypb.Type_INT32, CollationName: "utf8mb4_0900_ai_ci", Generated: true},
 			},
 		},
 		expFk: map[string]string{
 			"my_tbl":       "",
 			"my_child_tbl": "foreign key (my_id, `name`) references my_tbl (id, `name`) on delete cascade",
 		},
+		expPk: map[string]string{
+			"my_tbl":       "(id)",
+			"my_child_tbl": "(id)",
+		},
+		expUk: map[string]string{
+			"my_tbl": "(email)",
+		},
 	}}
 
 	testTracker(t, schemaDefResult, testcases)
@@ -328,6 +339,8 @@ type testCases struct {
 	updTbl []string
 	expTbl map[string][]vindexes.Column
 	expFk  map[string]string
+	expPk  map[string]string
+	expUk  map[string]string
 
 	updView []string
 	expView map[string]string
@@ -376,6 +389,16 @@ func testTracker(t *testing.T, schemaDefResult []map[string]string, tcases []tes
 						utils.MustMatch(t, tcase.expFk[k], sqlparser.String(fk), "mismatch foreign keys for table: ", k)
 					}
 				}
+				if len(tcase.expPk[k]) > 0 {
+					utils.MustMatch(t, tcase.expPk[k], sqlparser.String(tracker.Tables(keyspace)[k].PrimaryKey), "mismatch primary key for table: ", k)
+				}
+				if len(tcase.expUk[k]) > 0 {
+					var uks []string
+					for _, uk := range tracker.Tables(keyspace)[k].UniqueKeys {
+						uks = append(uks, sqlparser.String(uk))
+					}
+					utils.MustMatch(t, tcase.expUk[k], strings.Join(uks, ", "), "mismatch unique keys for table: ", k)
+				}
 			}
 
 			for k, v := range tcase.expView {
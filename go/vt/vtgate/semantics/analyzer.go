@@ -147,6 +147,11 @@ func (a *analyzer) analyzeDown(cursor *sqlparser.Cursor) bool {
 		return true
 	}
 
+	if with, isWith := cursor.Node().(*sqlparser.With); isWith && with.Recursive {
+		// the common table expressions have already been analyzed when entering the SELECT
+		return false
+	}
+
 	if err := a.scoper.down(cursor); err != nil {
 		a.setError(err)
 		return true
@@ -165,17 +170,51 @@ func (a *analyzer) analyzeDown(cursor *sqlparser.Cursor) bool {
 	a.noteQuerySignature(cursor.Node())
 
 	a.enterProjection(cursor)
+
+	if sel, isSel := cursor.Node().(*sqlparser.Select); isSel && sel.With != nil && sel.With.Recursive {
+		a.analyzeRecursiveCTEs(sel.With)
+	}
 	// this is the visitor going down the tree. Returning false here would just not visit the children
 	// to the current node, but that is not what we want if we have encountered an error.
 	// In order to abort the whole visitation, we have to return true here and then return false in the `analyzeUp` method
 	return true
 }
 
+// analyzeRecursiveCTEs registers the common table expressions of a WITH RECURSIVE clause,
+// and analyzes their bodies. This has to be done before the FROM clause of the SELECT is visited,
+// since the tables of the FROM clause can refer to the common table expressions.
+func (a *analyzer) analyzeRecursiveCTEs(with *sqlparser.With) {
+	ctes := with.CTEs()
+	for _, cte := range ctes {
+		a.tables.ctes[cte.ID.String()] = &CTE{
+			Name:    cte.ID.String(),
+			Query:   cte.Subquery.Select,
+			Columns: cte.Columns,
+		}
+	}
+	for _, cte := range ctes {
+		// we visit the body directly, and not the subquery, since it is not a subquery of the outer query
+		res := sqlparser.Rewrite(cte.Subquery.Select, a.analyzeDown, a.analyzeUp)
+		if !a.shouldContinue() {
+			return
+		}
+		cte.Subquery.Select = res.(sqlparser.SelectStatement)
+		a.tables.ctes[cte.ID.String()].Query = cte.Subquery.Select
+	}
+}
+
 func (a *analyzer) analyzeUp(cursor *sqlparser.Cursor) bool {
 	if !a.shouldContinue() {
 		return false
 	}
 
+	if sel, isSel := cursor.Node().(*sqlparser.Select); isSel && sel.With != nil && sel.With.Recursive {
+		// the common table expressions are not visible outside of the SELECT that defines them
+		for _, cte := range sel.With.CTEs() {
+			delete(a.tables.ctes, cte.ID.String())
+		}
+	}
+
 	if err := a.scoper.up(cursor); err != nil {
 		a.setError(err)
 		return false
@@ -308,7 +347,9 @@ func (a *analyzer) noteQuerySignature(node sqlparser.SQLNode) {
 			a.sig.Aggregation = true
 		}
 	case sqlparser.AggrFunc:
-		a.sig.Aggregation = true
+		if !sqlparser.IsWindowFunction(node) {
+			a.sig.Aggregation = true
+		}
 	}
 }
 
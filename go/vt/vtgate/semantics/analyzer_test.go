@@ -810,9 +810,6 @@ func TestInvalidQueries(t *testing.T) {
 	}, {
 		sql:  "select (select sql_calc_found_rows id from a) as t",
 		serr: "Incorrect usage/placement of 'SQL_CALC_FOUND_ROWS'",
-	}, {
-		sql:  "select id from t1 natural join t2",
-		serr: "VT12001: unsupported: natural join",
 	}, {
 		sql: "select * from music where user_id IN (select sql_calc_found_rows * from music limit 10)",
 		err: &SQLCalcFoundRowsUsageError{},
@@ -820,8 +817,8 @@ func TestInvalidQueries(t *testing.T) {
 		sql:  "select is_free_lock('xyz') from user",
 		serr: "is_free_lock('xyz') allowed only with dual",
 	}, {
-		sql: "SELECT * FROM JSON_TABLE('[ {\"c1\": null} ]','$[*]' COLUMNS( c1 INT PATH '$.c1' ERROR ON ERROR )) as jt",
-		err: &JSONTablesError{},
+		sql:  "SELECT * FROM JSON_TABLE('[ {\"c1\": null} ]','$[*]' COLUMNS( c1 INT PATH '$.c1', C1 INT PATH '$.c2' )) as jt",
+		serr: "Duplicate column name 'c1'",
 	}, {
 		sql:             "select does_not_exist from t1",
 		notUnshardedErr: "column 'does_not_exist' not found in table 't1'",
@@ -954,6 +951,13 @@ func TestScopingWDerivedTables(t *testing.T) {
 			query:                "select 1 from user uu where exists (select 1 from user where exists (select 1 from (select 1 from t1) uu where uu.user_id = uu.id))",
 			expectation:          T0,
 			recursiveExpectation: T0,
+		}, {
+			query:                "select t.x from user as u, lateral (select u.id as x from t1) as t",
+			expectation:          TS2,
+			recursiveExpectation: TS0,
+		}, {
+			query:        "select t.x from (select u.id as x) as t, user as u",
+			errorMessage: "column 'u.id' not found",
 		}}
 	for _, query := range queries {
 		t.Run(query.query, func(t *testing.T) {
@@ -1405,6 +1409,46 @@ func TestSingleUnshardedKeyspace(t *testing.T) {
 	}
 }
 
+func TestRecursiveCTEBinding(t *testing.T) {
+	query := "with recursive x(a, depth) as (select col, 0 from tabl union all select tabl.col, x.depth + 1 from tabl join x on tabl.col = x.a) select a, depth from x"
+	stmt, semTable := parseAndAnalyze(t, query, "d")
+	sel := stmt.(*sqlparser.Select)
+
+	// the tables of the common table expression are analyzed before the tables of the outer query
+	term := sel.With.CTEs()[0].Subquery.Select.(*sqlparser.Union).Right.(*sqlparser.Select)
+	assert.Equal(t, TS0, semTable.DirectDeps(extract(sqlparser.GetFirstSelect(sel.With.CTEs()[0].Subquery.Select), 0)))
+	assert.Equal(t, TS1, semTable.DirectDeps(extract(term, 0)))
+	assert.Equal(t, TS2, semTable.DirectDeps(extract(term, 1)))
+
+	assert.Equal(t, TS3, semTable.DirectDeps(extract(sel, 0)))
+	assert.Equal(t, TS3, semTable.DirectDeps(extract(sel, 1)))
+	typ, _, found := semTable.TypeForExpr(extract(sel, 1))
+	require.True(t, found)
+	assert.Equal(t, sqltypes.Int64, typ)
+}
+
+func TestJSONTableBinding(t *testing.T) {
+	query := "select jt.a, jt.rn, u.id from user as u, json_table(u.doc, '$[*]' columns(rn for ordinality, a int path '$.a')) as jt"
+	stmt, semTable := parseAndAnalyze(t, query, "d")
+	sel := stmt.(*sqlparser.Select)
+
+	// the json document refers to the table before the json table
+	jtExpr := sel.From[1].(*sqlparser.JSONTableExpr)
+	assert.Equal(t, TS0, semTable.RecursiveDeps(jtExpr.Expr))
+	assert.Equal(t, TS1, semTable.TableSetForJSONTable(jtExpr))
+
+	assert.Equal(t, TS1, semTable.DirectDeps(extract(sel, 0)))
+	assert.Equal(t, TS1, semTable.DirectDeps(extract(sel, 1)))
+	assert.Equal(t, TS0, semTable.DirectDeps(extract(sel, 2)))
+
+	typ, _, found := semTable.TypeForExpr(extract(sel, 0))
+	require.True(t, found)
+	assert.Equal(t, sqltypes.Int32, typ)
+	typ, _, found = semTable.TypeForExpr(extract(sel, 1))
+	require.True(t, found)
+	assert.Equal(t, sqltypes.Uint32, typ)
+}
+
 func TestNextErrors(t *testing.T) {
 	tests := []struct {
 		query, expectedError string
@@ -1437,9 +1481,6 @@ func TestUpdateErrors(t *testing.T) {
 		query, expectedError string
 	}{
 		{
-			query:         "update t1, t2 set id = 12",
-			expectedError: "VT12001: unsupported: multiple (2) tables in update",
-		}, {
 			query:         "update (select 1 from dual) dt set id = 1",
 			expectedError: "The target table dt of the UPDATE is not updatable",
 		},
@@ -38,17 +38,22 @@ type binder struct {
 	// that this map is joined with using USING.
 	// This information is used to expand `*` correctly, and is not available post-analysis
 	usingJoinInfo map[TableSet]map[string]TableSet
+
+	// coalescedColumns holds, for each column of the joins with USING or NATURAL, the tables whose column
+	// has been coalesced by one of these joins. It is used to resolve the column in the following joins.
+	coalescedColumns map[string][]TableSet
 }
 
 func newBinder(scoper *scoper, org originable, tc *tableCollector, typer *typer) *binder {
 	return &binder{
-		recursive:     map[sqlparser.Expr]TableSet{},
-		direct:        map[sqlparser.Expr]TableSet{},
-		scoper:        scoper,
-		org:           org,
-		tc:            tc,
-		typer:         typer,
-		usingJoinInfo: map[TableSet]map[string]TableSet{},
+		recursive:        map[sqlparser.Expr]TableSet{},
+		direct:           map[sqlparser.Expr]TableSet{},
+		scoper:           scoper,
+		org:              org,
+		tc:               tc,
+		typer:            typer,
+		usingJoinInfo:    map[TableSet]map[string]TableSet{},
+		coalescedColumns: map[string][]TableSet{},
 	}
 }
 
@@ -30,24 +30,14 @@ func (a *analyzer) checkForInvalidConstructs(cursor *sqlparser.Cursor) error {
 		return a.checkSelect(cursor, node)
 	case *sqlparser.Nextval:
 		return a.checkNextVal()
-	case *sqlparser.JoinTableExpr:
-		return a.checkJoin(node)
 	case *sqlparser.LockingFunc:
 		return &LockOnlyWithDualError{Node: node}
 	case *sqlparser.Union:
 		return checkUnion(node)
-	case *sqlparser.JSONTableExpr:
-		return &JSONTablesError{}
-	case *sqlparser.DerivedTable:
-		return checkDerived(node)
 	case *sqlparser.AssignmentExpr:
 		return vterrors.VT12001("Assignment expression")
 	case *sqlparser.Subquery:
 		return a.checkSubqueryColumns(cursor.Parent(), node)
-	case *sqlparser.Insert:
-		if node.Action == sqlparser.ReplaceAct {
-			return ShardedError{Inner: &UnsupportedConstruct{errString: "REPLACE INTO with sharded keyspace"}}
-		}
 	}
 
 	return nil
@@ -86,13 +76,6 @@ func (a *analyzer) checkSubqueryColumns(parent sqlparser.SQLNode, subq *sqlparse
 	return nil
 }
 
-func checkDerived(node *sqlparser.DerivedTable) error {
-	if node.Lateral {
-		return vterrors.VT12001("lateral derived tables")
-	}
-	return nil
-}
-
 func checkUnion(node *sqlparser.Union) error {
 	err := sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
@@ -115,12 +98,6 @@ func checkUnion(node *sqlparser.Union) error {
 	return nil
 }
 
-func (a *analyzer) checkJoin(j *sqlparser.JoinTableExpr) error {
-	if j.Join == sqlparser.NaturalJoinType || j.Join == sqlparser.NaturalRightJoinType || j.Join == sqlparser.NaturalLeftJoinType {
-		return &UnsupportedNaturalJoinError{JoinExpr: j}
-	}
-	return nil
-}
 func (a *analyzer) checkNextVal() error {
 	currScope := a.scoper.currentScope()
 	if currScope.parent != nil {
@@ -175,11 +152,12 @@ func (a *analyzer) checkSelect(cursor *sqlparser.Cursor, node *sqlparser.Select)
 
 func checkUpdate(node *sqlparser.Update) error {
 	if len(node.TableExprs) != 1 {
-		return ShardedError{Inner: &UnsupportedMultiTablesInUpdateError{ExprCount: len(node.TableExprs)}}
+		// the target of a multi-table update is found when planning it
+		return nil
 	}
 	alias, isAlias := node.TableExprs[0].(*sqlparser.AliasedTableExpr)
 	if !isAlias {
-		return ShardedError{Inner: &UnsupportedMultiTablesInUpdateError{NotAlias: true}}
+		return nil
 	}
 	_, isDerived := alias.Expr.(*sqlparser.DerivedTable)
 	if isDerived {
@@ -0,0 +1,157 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"strings"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+type (
+	// CTE contains the definition of a common table expression of a
+	// WITH RECURSIVE clause
+	CTE struct {
+		Name string
+		// Query is the body of the common table expression. For a recursive
+		// common table expression, it is a UNION of the seed and of the
+		// recursive part.
+		Query sqlparser.SelectStatement
+		// Columns is the column list given after the name, if any
+		Columns sqlparser.Columns
+	}
+
+	// CTETable is a reference to a common table expression in the FROM clause
+	CTETable struct {
+		tableName string
+		ASTNode   *sqlparser.AliasedTableExpr
+		CTE       *CTE
+	}
+)
+
+var _ TableInfo = (*CTETable)(nil)
+
+// ColumnNames returns the names of the columns of the common table expression,
+// and false if they are not known because the seed uses a '*' that could not be expanded
+func (cte *CTE) ColumnNames() ([]string, bool) {
+	if len(cte.Columns) > 0 {
+		names := make([]string, 0, len(cte.Columns))
+		for _, col := range cte.Columns {
+			names = append(names, col.String())
+		}
+		return names, true
+	}
+
+	var names []string
+	for _, expr := range sqlparser.GetFirstSelect(cte.Query).SelectExprs {
+		ae, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, false
+		}
+		names = append(names, ae.ColumnName())
+	}
+	return names, true
+}
+
+// seedExpr returns the expression of the seed for the column at the given offset
+func (cte *CTE) seedExpr(offset int) sqlparser.Expr {
+	exprs := sqlparser.GetFirstSelect(cte.Query).SelectExprs
+	if offset >= len(exprs) {
+		return nil
+	}
+	ae, ok := exprs[offset].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil
+	}
+	return ae.Expr
+}
+
+// dependencies implements the TableInfo interface
+func (c *CTETable) dependencies(colName string, org originable) (dependencies, error) {
+	ts := org.tableSetFor(c.ASTNode)
+	names, authoritative := c.CTE.ColumnNames()
+	for i, name := range names {
+		if !strings.EqualFold(name, colName) {
+			continue
+		}
+		// the type of the column is the type of the seed expression
+		var typ *Type
+		if expr := c.CTE.seedExpr(i); expr != nil {
+			_, _, typ = org.depsForExpr(expr)
+		}
+		return createCertain(ts, ts, typ), nil
+	}
+
+	if authoritative {
+		return &nothing{}, nil
+	}
+	return createUncertain(ts, ts), nil
+}
+
+// getTableSet implements the TableInfo interface
+func (c *CTETable) getTableSet(org originable) TableSet {
+	return org.tableSetFor(c.ASTNode)
+}
+
+// getExprFor implements the TableInfo interface
+func (c *CTETable) getExprFor(s string) (sqlparser.Expr, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Unknown column '%s' in 'field list'", s)
+}
+
+// IsInfSchema implements the TableInfo interface
+func (c *CTETable) IsInfSchema() bool {
+	return false
+}
+
+// getColumns implements the TableInfo interface
+func (c *CTETable) getColumns() []ColumnInfo {
+	names, _ := c.CTE.ColumnNames()
+	cols := make([]ColumnInfo, 0, len(names))
+	for _, name := range names {
+		cols = append(cols, ColumnInfo{Name: name})
+	}
+	return cols
+}
+
+// GetExpr implements the TableInfo interface
+func (c *CTETable) GetExpr() *sqlparser.AliasedTableExpr {
+	return c.ASTNode
+}
+
+// GetVindexTable implements the TableInfo interface
+func (c *CTETable) GetVindexTable() *vindexes.Table {
+	return nil
+}
+
+// Name implements the TableInfo interface
+func (c *CTETable) Name() (sqlparser.TableName, error) {
+	return c.ASTNode.TableName()
+}
+
+// authoritative implements the TableInfo interface
+func (c *CTETable) authoritative() bool {
+	_, authoritative := c.CTE.ColumnNames()
+	return authoritative
+}
+
+// matches implements the TableInfo interface
+func (c *CTETable) matches(name sqlparser.TableName) bool {
+	return c.tableName == name.Name.String() && name.Qualifier.IsEmpty()
+}
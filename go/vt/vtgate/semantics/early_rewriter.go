@@ -18,6 +18,7 @@ package semantics
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -43,6 +44,8 @@ func (r *earlyRewriter) down(cursor *sqlparser.Cursor) error {
 		return handleSelectExprs(r, cursor, node)
 	case *sqlparser.JoinTableExpr:
 		handleJoinTableExpr(r, node)
+	case *sqlparser.JoinCondition:
+		return handleNaturalJoin(r, cursor, node)
 	case sqlparser.OrderBy:
 		handleOrderBy(r, cursor, node)
 	case *sqlparser.OrExpr:
@@ -115,13 +118,115 @@ func handleSelectExprs(r *earlyRewriter, cursor *sqlparser.Cursor, node sqlparse
 	return r.expandStar(cursor, node)
 }
 
-// handleJoinTableExpr processes JOIN table expressions and handles the Straight Join type.
+// handleJoinTableExpr processes JOIN table expressions. It handles the Straight Join type, and prepares
+// the NATURAL joins and the RIGHT joins with USING for their rewriting into JOINs with the ON condition.
 func handleJoinTableExpr(r *earlyRewriter, node *sqlparser.JoinTableExpr) {
-	if node.Join != sqlparser.StraightJoinType {
-		return
+	switch node.Join {
+	case sqlparser.StraightJoinType:
+		node.Join = sqlparser.NormalJoinType
+		r.warning = "straight join is converted to normal join"
+	case sqlparser.NaturalRightJoinType:
+		swapJoinInputs(node)
+		node.Join = sqlparser.NaturalLeftJoinType
+	case sqlparser.RightJoinType:
+		if node.Condition != nil && len(node.Condition.Using) > 0 {
+			swapJoinInputs(node)
+			node.Join = sqlparser.LeftJoinType
+		}
+	}
+
+	if isNaturalJoin(node.Join) && node.Condition == nil {
+		// the columns of the NATURAL join are only known once the tables of the join have been visited,
+		// so the USING clause will be filled when visiting this join condition
+		node.Condition = &sqlparser.JoinCondition{}
+	}
+}
+
+func isNaturalJoin(join sqlparser.JoinType) bool {
+	return join == sqlparser.NaturalJoinType || join == sqlparser.NaturalLeftJoinType || join == sqlparser.NaturalRightJoinType
+}
+
+// swapJoinInputs turns a RIGHT join into a LEFT join. The common columns of a RIGHT join with USING are
+// coalesced from its right table, and listed before the other columns of the right table when expanding `*`,
+// which is exactly what a LEFT join with the tables swapped does.
+func swapJoinInputs(node *sqlparser.JoinTableExpr) {
+	lhs := node.LeftExpr
+	if _, isJoin := lhs.(*sqlparser.JoinTableExpr); isJoin {
+		lhs = &sqlparser.ParenTableExpr{Exprs: sqlparser.TableExprs{lhs}}
+	}
+	node.LeftExpr, node.RightExpr = node.RightExpr, lhs
+}
+
+// handleNaturalJoin fills the USING clause of a NATURAL join with the columns common to its two sides.
+// The NATURAL join is then handled like any other join with USING.
+func handleNaturalJoin(r *earlyRewriter, cursor *sqlparser.Cursor, node *sqlparser.JoinCondition) error {
+	join, ok := cursor.Parent().(*sqlparser.JoinTableExpr)
+	if !ok || !isNaturalJoin(join.Join) || node.On != nil || len(node.Using) > 0 {
+		return nil
 	}
-	node.Join = sqlparser.NormalJoinType
-	r.warning = "straight join is converted to normal join"
+
+	lhs, lhsKnown := r.columnsOfTableExpr(join.LeftExpr)
+	rhs, rhsKnown := r.columnsOfTableExpr(join.RightExpr)
+	if !lhsKnown || !rhsKnown {
+		// without the columns of the tables, the NATURAL join can only be sent as is to an unsharded keyspace
+		return ShardedError{Inner: vterrors.VT09015()}
+	}
+
+	for _, col := range lhs {
+		if slices.ContainsFunc(rhs, col.Equal) {
+			node.Using = append(node.Using, col)
+		}
+	}
+
+	if join.Join == sqlparser.NaturalLeftJoinType {
+		join.Join = sqlparser.LeftJoinType
+		if len(node.Using) == 0 {
+			node.On = sqlparser.BoolVal(true)
+		}
+	} else {
+		join.Join = sqlparser.NormalJoinType
+	}
+	return nil
+}
+
+// columnsOfTableExpr returns the names of the columns of a table expression, without duplicates,
+// and false if some of them are not known
+func (r *earlyRewriter) columnsOfTableExpr(expr sqlparser.TableExpr) ([]sqlparser.IdentifierCI, bool) {
+	var columns []sqlparser.IdentifierCI
+	addColumns := func(tbl TableInfo) bool {
+		if !tbl.authoritative() {
+			return false
+		}
+		for _, col := range tbl.getColumns() {
+			name := sqlparser.NewIdentifierCI(col.Name)
+			if !slices.ContainsFunc(columns, name.Equal) {
+				columns = append(columns, name)
+			}
+		}
+		return true
+	}
+
+	known := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		var tbl TableInfo
+		switch node := node.(type) {
+		case *sqlparser.AliasedTableExpr:
+			tbl = r.binder.tc.Tables[r.binder.tc.tableSetFor(node).TableOffset()]
+		case *sqlparser.JSONTableExpr:
+			for _, info := range r.binder.tc.Tables {
+				if jt, ok := info.(*JSONTable); ok && jt.JSONTableExpr == node {
+					tbl = jt
+				}
+			}
+		case sqlparser.TableExpr, sqlparser.TableExprs:
+			return true, nil
+		default:
+			return false, nil
+		}
+		known = known && addColumns(tbl)
+		return false, nil
+	}, expr)
+	return columns, known
 }
 
 // handleOrderBy processes the ORDER BY clause.
@@ -400,12 +505,12 @@ func buildJoinPredicates(b *binder, join *sqlparser.JoinTableExpr) ([]sqlparser.
 	var predicates []sqlparser.Expr
 
 	for _, column := range join.Condition.Using {
-		foundTables, err := findTablesWithColumn(b, join, column)
+		lft, rgt, err := findTablesWithColumn(b, join, column)
 		if err != nil {
 			return nil, err
 		}
 
-		predicates = append(predicates, createComparisonPredicates(column, foundTables)...)
+		predicates = append(predicates, createComparisonBetween(column, lft, rgt))
 	}
 
 	return predicates, nil
@@ -453,49 +558,55 @@ func findOnlyOneTableInfoThatHasColumn(b *binder, tbl sqlparser.TableExpr, colum
 	}
 }
 
-// findTablesWithColumn finds the tables with the specified column in the current scope.
-func findTablesWithColumn(b *binder, join *sqlparser.JoinTableExpr, column sqlparser.IdentifierCI) ([]sqlparser.TableName, error) {
+// findTablesWithColumn finds the tables with the specified column on both sides of the join,
+// and records that the column of all of them is coalesced by the join.
+func findTablesWithColumn(b *binder, join *sqlparser.JoinTableExpr, column sqlparser.IdentifierCI) (lft, rgt sqlparser.TableName, err error) {
 	leftTableInfo, err := findOnlyOneTableInfoThatHasColumn(b, join.LeftExpr, column)
 	if err != nil {
-		return nil, err
+		return
 	}
 
 	rightTableInfo, err := findOnlyOneTableInfoThatHasColumn(b, join.RightExpr, column)
 	if err != nil {
-		return nil, err
+		return
 	}
 
 	if leftTableInfo == nil || rightTableInfo == nil {
-		return nil, ShardedError{Inner: vterrors.VT09015()}
+		err = ShardedError{Inner: vterrors.VT09015()}
+		return
 	}
-	var tableNames []sqlparser.TableName
-	for _, info := range leftTableInfo {
-		nm, err := info.Name()
-		if err != nil {
-			return nil, err
-		}
-		tableNames = append(tableNames, nm)
+	lft, err = coalescedTableName(b, leftTableInfo, column)
+	if err != nil {
+		return
 	}
-	for _, info := range rightTableInfo {
-		nm, err := info.Name()
-		if err != nil {
-			return nil, err
-		}
-		tableNames = append(tableNames, nm)
+	rgt, err = coalescedTableName(b, rightTableInfo, column)
+	if err != nil {
+		return
+	}
+
+	var coalesced TableSet
+	for _, tbl := range append(leftTableInfo, rightTableInfo...) {
+		coalesced = coalesced.Merge(tbl.getTableSet(b.org))
 	}
-	return tableNames, nil
+	b.coalescedColumns[column.Lowered()] = append(b.coalescedColumns[column.Lowered()], coalesced)
+	return
 }
 
-// createComparisonPredicates creates a list of comparison predicates between the given column and foundTables.
-func createComparisonPredicates(column sqlparser.IdentifierCI, foundTables []sqlparser.TableName) []sqlparser.Expr {
-	var predicates []sqlparser.Expr
-	for i, lft := range foundTables {
-		for j := i + 1; j < len(foundTables); j++ {
-			rgt := foundTables[j]
-			predicates = append(predicates, createComparisonBetween(column, lft, rgt))
+// coalescedTableName returns the name of the table with the column on one side of a join with USING.
+// Several tables of the side can only have the column when it has been coalesced by a previous join
+// with USING or NATURAL, in which case its value is the one of the first of them.
+// Otherwise, the column is ambiguous.
+func coalescedTableName(b *binder, tables []TableInfo, column sqlparser.IdentifierCI) (sqlparser.TableName, error) {
+	if len(tables) > 1 {
+		var ts TableSet
+		for _, tbl := range tables {
+			ts = ts.Merge(tbl.getTableSet(b.org))
+		}
+		if !slices.ContainsFunc(b.coalescedColumns[column.Lowered()], ts.IsSolvedBy) {
+			return sqlparser.TableName{}, &AmbiguousJoinColumnError{Column: column.String()}
 		}
 	}
-	return predicates
+	return tables[0].Name()
 }
 
 func createComparisonBetween(column sqlparser.IdentifierCI, lft, rgt sqlparser.TableName) *sqlparser.ComparisonExpr {
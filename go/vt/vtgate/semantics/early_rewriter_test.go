@@ -156,10 +156,35 @@ func TestExpandStar(t *testing.T) {
 		expanded: "main.t2.c1, main.t2.c2, main.t4.c4",
 	}, {
 		sql:    "select * from t2 join t4 using (c1) join t2 as X using (c1)",
-		expSQL: "select t2.c1 as c1, t2.c2 as c2, t4.c4 as c4, X.c2 as c2 from t2 join t4 on t2.c1 = t4.c1 join t2 as X on t2.c1 = t4.c1 and t2.c1 = X.c1 and t4.c1 = X.c1",
+		expSQL: "select t2.c1 as c1, t2.c2 as c2, t4.c4 as c4, X.c2 as c2 from t2 join t4 on t2.c1 = t4.c1 join t2 as X on t2.c1 = X.c1",
 	}, {
 		sql:    "select * from t2 join t4 using (c1), t2 as t2b join t4 as t4b using (c1)",
 		expSQL: "select t2.c1 as c1, t2.c2 as c2, t4.c4 as c4, t2b.c1 as c1, t2b.c2 as c2, t4b.c4 as c4 from t2 join t4 on t2.c1 = t4.c1, t2 as t2b join t4 as t4b on t2b.c1 = t4b.c1",
+	}, {
+		sql:    "select * from t2 left join t4 using (c1) join t2 as X using (c1)",
+		expSQL: "select t2.c1 as c1, t2.c2 as c2, t4.c4 as c4, X.c2 as c2 from t2 left join t4 on t2.c1 = t4.c1 join t2 as X on t2.c1 = X.c1",
+	}, {
+		sql:    "select * from t2 right join t4 using (c1)",
+		expSQL: "select t4.c1 as c1, t4.c4 as c4, t2.c2 as c2 from t4 left join t2 on t4.c1 = t2.c1",
+	}, {
+		sql:      "select * from t1 natural join t5",
+		expSQL:   "select t1.a as a, t1.b as b, t1.c as c from t1 join t5 on t1.a = t5.a and t1.b = t5.b",
+		expanded: "main.t1.a, main.t1.b, main.t1.c",
+	}, {
+		sql:    "select * from t1 natural left join t2",
+		expSQL: "select t1.a as a, t1.b as b, t1.c as c, t2.c1 as c1, t2.c2 as c2 from t1 left join t2 on true",
+	}, {
+		sql:    "select * from t2 natural right join t4",
+		expSQL: "select t4.c1 as c1, t4.c4 as c4, t2.c2 as c2 from t4 left join t2 on t4.c1 = t2.c1",
+	}, {
+		sql:    "select * from t2 join t4 using (c1) natural join t2 as X",
+		expSQL: "select t2.c1 as c1, t2.c2 as c2, t4.c4 as c4 from t2 join t4 on t2.c1 = t4.c1 join t2 as X on t2.c1 = X.c1 and t2.c2 = X.c2",
+	}, {
+		sql:    "select * from t2 join t4 on t2.c1 = t4.c1 join t2 as X using (c1)",
+		expErr: "Column 'c1' in from clause is ambiguous",
+	}, {
+		sql:    "select * from t2 join t4 on t2.c1 = t4.c1 natural join t2 as X",
+		expErr: "Column 'c1' in from clause is ambiguous",
 	}, {
 		sql:      "select * from t1 join t5 using (b)",
 		expSQL:   "select t1.b as b, t1.a as a, t1.c as c, t5.a as a from t1 join t5 on t1.b = t5.b",
@@ -281,6 +306,12 @@ func TestRewriteJoinUsingColumns(t *testing.T) {
 	}, {
 		sql:    "select 1 from t1 left join t2 using (a) where a = 42",
 		expSQL: "select 1 from t1 left join t2 on t1.a = t2.a where t1.a = 42",
+	}, {
+		sql:    "select 1 from t1 right join t2 using (a) where a = 42",
+		expSQL: "select 1 from t2 left join t1 on t2.a = t1.a where t2.a = 42",
+	}, {
+		sql:    "select a from t1 natural join t2 natural right join t3",
+		expSQL: "select t3.a from t3 left join (t1 join t2 on t1.a = t2.a and t1.b = t2.b and t1.c = t2.c) on t3.a = t1.a and t3.b = t1.b and t3.c = t1.c",
 	}}
 	for _, tcase := range tcases {
 		t.Run(tcase.sql, func(t *testing.T) {
@@ -40,22 +40,17 @@ type (
 	MissingInVSchemaError          struct{ Table TableInfo }
 	CantUseOptionHereError         struct{ Msg string }
 	TableNotUpdatableError         struct{ Table string }
-	UnsupportedNaturalJoinError    struct{ JoinExpr *sqlparser.JoinTableExpr }
 	NotSequenceTableError          struct{ Table string }
 	NextWithMultipleTablesError    struct{ CountTables int }
 	LockOnlyWithDualError          struct{ Node *sqlparser.LockingFunc }
-	JSONTablesError                struct{ Table string }
 	QualifiedOrderInUnionError     struct{ Table string }
 	BuggyError                     struct{ Msg string }
 	UnsupportedConstruct           struct{ errString string }
 	AmbiguousColumnError           struct{ Column string }
+	AmbiguousJoinColumnError       struct{ Column string }
 	SubqueryColumnCountError       struct{ Expected int }
 	ColumnsMissingInSchemaError    struct{}
 
-	UnsupportedMultiTablesInUpdateError struct {
-		ExprCount int
-		NotAlias  bool
-	}
 	UnionColumnsDoNotMatchError struct {
 		FirstProj  int
 		SecondProj int
@@ -91,25 +86,6 @@ func (e *UnionColumnsDoNotMatchError) Error() string {
 	return eprintf(e, "The used SELECT statements have a different number of columns: %v, %v", e.FirstProj, e.SecondProj)
 }
 
-// UnsupportedMultiTablesInUpdateError
-func (e *UnsupportedMultiTablesInUpdateError) Error() string {
-	switch {
-	case e.NotAlias:
-		return eprintf(e, "unaliased multiple tables in update")
-	default:
-		return eprintf(e, "multiple (%d) tables in update", e.ExprCount)
-	}
-}
-
-func (e *UnsupportedMultiTablesInUpdateError) unsupported() {}
-
-// UnsupportedNaturalJoinError
-func (e *UnsupportedNaturalJoinError) Error() string {
-	return eprintf(e, "%s", e.JoinExpr.Join.ToString())
-}
-
-func (e *UnsupportedNaturalJoinError) unsupported() {}
-
 // UnionWithSQLCalcFoundRowsError
 func (e *UnionWithSQLCalcFoundRowsError) Error() string {
 	return eprintf(e, "SQL_CALC_FOUND_ROWS not supported with union")
@@ -192,13 +168,6 @@ func (e *QualifiedOrderInUnionError) Error() string {
 	return eprintf(e, "Table `%s` from one of the SELECTs cannot be used in global ORDER clause", e.Table)
 }
 
-// JSONTablesError
-func (e *JSONTablesError) Error() string {
-	return eprintf(e, "json_table expressions")
-}
-
-func (e *JSONTablesError) unsupported() {}
-
 // BuggyError is used for checking conditions that should never occur
 func (e *BuggyError) Error() string {
 	return eprintf(e, e.Msg)
@@ -235,6 +204,19 @@ func (e *AmbiguousColumnError) ErrorCode() vtrpcpb.Code {
 	return vtrpcpb.Code_INVALID_ARGUMENT
 }
 
+// AmbiguousJoinColumnError
+func (e *AmbiguousJoinColumnError) Error() string {
+	return eprintf(e, "Column '%s' in from clause is ambiguous", e.Column)
+}
+
+func (e *AmbiguousJoinColumnError) ErrorState() vterrors.State {
+	return vterrors.NonUniqError
+}
+
+func (e *AmbiguousJoinColumnError) ErrorCode() vtrpcpb.Code {
+	return vtrpcpb.Code_INVALID_ARGUMENT
+}
+
 func (e *UnsupportedConstruct) unsupported() {}
 
 func (e *UnsupportedConstruct) ErrorCode() vtrpcpb.Code {
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"strings"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// JSONTable contains the information about a JSON_TABLE expression in the FROM clause.
+// Its columns are the ones declared in the COLUMNS clause, including the ones of the
+// NESTED PATH clauses, in the order they are declared.
+type JSONTable struct {
+	tableName string
+	// ASTNode is the aliased table expression the JSON_TABLE is known by. It is not part of the query,
+	// but it is used to identify the table, like the aliased table expressions of the other tables.
+	ASTNode *sqlparser.AliasedTableExpr
+	// JSONTableExpr is the JSON_TABLE expression of the query
+	JSONTableExpr *sqlparser.JSONTableExpr
+	columns       []ColumnInfo
+}
+
+var _ TableInfo = (*JSONTable)(nil)
+
+func newJSONTable(node *sqlparser.JSONTableExpr) *JSONTable {
+	jt := &JSONTable{
+		tableName:     node.Alias.String(),
+		ASTNode:       sqlparser.NewAliasedTableExpr(sqlparser.NewTableName(node.Alias.String()), ""),
+		JSONTableExpr: node,
+	}
+	jt.addColumns(node.Columns)
+	return jt
+}
+
+func (jt *JSONTable) addColumns(columns []*sqlparser.JtColumnDefinition) {
+	for _, col := range columns {
+		switch {
+		case col.JtOrdinal != nil:
+			jt.columns = append(jt.columns, ColumnInfo{Name: col.JtOrdinal.Name.String(), Type: Type{Type: querypb.Type_UINT32}})
+		case col.JtPath != nil && col.JtPath.JtColExists:
+			jt.columns = append(jt.columns, ColumnInfo{Name: col.JtPath.Name.String(), Type: Type{Type: querypb.Type_INT32}})
+		case col.JtPath != nil:
+			jt.columns = append(jt.columns, ColumnInfo{Name: col.JtPath.Name.String(), Type: Type{Type: col.JtPath.Type.SQLType()}})
+		case col.JtNestedPath != nil:
+			jt.addColumns(col.JtNestedPath.Columns)
+		}
+	}
+}
+
+// checkForDuplicates returns an error if two columns of the JSON_TABLE have the same name
+func (jt *JSONTable) checkForDuplicates() error {
+	for i, col := range jt.columns {
+		for j := i + 1; j < len(jt.columns); j++ {
+			if strings.EqualFold(col.Name, jt.columns[j].Name) {
+				return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.DupFieldName, "Duplicate column name '%s'", col.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// dependencies implements the TableInfo interface
+func (jt *JSONTable) dependencies(colName string, org originable) (dependencies, error) {
+	ts := org.tableSetFor(jt.ASTNode)
+	for _, col := range jt.columns {
+		if !strings.EqualFold(col.Name, colName) {
+			continue
+		}
+		typ := col.Type
+		return createCertain(ts, ts, &typ), nil
+	}
+	return &nothing{}, nil
+}
+
+// getTableSet implements the TableInfo interface
+func (jt *JSONTable) getTableSet(org originable) TableSet {
+	return org.tableSetFor(jt.ASTNode)
+}
+
+// getExprFor implements the TableInfo interface
+func (jt *JSONTable) getExprFor(s string) (sqlparser.Expr, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Unknown column '%s' in 'field list'", s)
+}
+
+// IsInfSchema implements the TableInfo interface
+func (jt *JSONTable) IsInfSchema() bool {
+	return false
+}
+
+// getColumns implements the TableInfo interface
+func (jt *JSONTable) getColumns() []ColumnInfo {
+	return jt.columns
+}
+
+// GetExpr implements the TableInfo interface
+func (jt *JSONTable) GetExpr() *sqlparser.AliasedTableExpr {
+	return jt.ASTNode
+}
+
+// GetVindexTable implements the TableInfo interface
+func (jt *JSONTable) GetVindexTable() *vindexes.Table {
+	return nil
+}
+
+// Name implements the TableInfo interface
+func (jt *JSONTable) Name() (sqlparser.TableName, error) {
+	return sqlparser.NewTableName(jt.tableName), nil
+}
+
+// authoritative implements the TableInfo interface
+func (jt *JSONTable) authoritative() bool {
+	return true
+}
+
+// matches implements the TableInfo interface
+func (jt *JSONTable) matches(name sqlparser.TableName) bool {
+	return jt.tableName == name.Name.String() && name.Qualifier.IsEmpty()
+}
@@ -133,12 +133,29 @@ func (s *scoper) enterJoinScope(cursor *sqlparser.Cursor) {
 		// can only see the two tables involved in the JOIN, and no other tables of that select statement.
 		// They are allowed to see the tables of the outer select query.
 		// To create this special context, we will find the parent scope of the select statement involved.
-		nScope := newScope(s.currentScope().findParentScopeOfStatement())
+		parent := s.currentScope().findParentScopeOfStatement()
+		if canSeeEarlierTables(cursor.Node()) {
+			// LATERAL derived tables and JSON_TABLE expressions can also refer to the
+			// tables that come before them in the FROM clause
+			parent = s.currentScope()
+		}
+		nScope := newScope(parent)
 		nScope.stmt = cursor.Parent().(*sqlparser.Select)
 		s.push(nScope)
 	}
 }
 
+func canSeeEarlierTables(node sqlparser.SQLNode) bool {
+	switch node := node.(type) {
+	case *sqlparser.JSONTableExpr:
+		return true
+	case *sqlparser.AliasedTableExpr:
+		dt, isDerived := node.Expr.(*sqlparser.DerivedTable)
+		return isDerived && dt.Lateral
+	}
+	return false
+}
+
 func (s *scoper) pushSelectScope(node *sqlparser.Select) {
 	currScope := newScope(s.currentScope())
 	currScope.stmtScope = true
@@ -245,6 +245,16 @@ func (st *SemTable) TableSetFor(t *sqlparser.AliasedTableExpr) TableSet {
 	return EmptyTableSet()
 }
 
+// TableSetForJSONTable returns the bitmask for the table of a JSON_TABLE expression
+func (st *SemTable) TableSetForJSONTable(expr *sqlparser.JSONTableExpr) TableSet {
+	for idx, t := range st.Tables {
+		if jt, ok := t.(*JSONTable); ok && jt.JSONTableExpr == expr {
+			return SingleTableSet(idx)
+		}
+	}
+	return EmptyTableSet()
+}
+
 // ReplaceTableSetFor replaces the given single TabletSet with the new *sqlparser.AliasedTableExpr
 func (st *SemTable) ReplaceTableSetFor(id TableSet, t *sqlparser.AliasedTableExpr) {
 	if st == nil {
@@ -443,8 +453,10 @@ func (st *SemTable) SingleUnshardedKeyspace() (*vindexes.Keyspace, []*vindexes.T
 
 		if vindexTable == nil {
 			_, isDT := table.GetExpr().Expr.(*sqlparser.DerivedTable)
-			if isDT {
-				// derived tables are ok, as long as all real tables are from the same unsharded keyspace
+			_, isCTE := table.(*CTETable)
+			_, isJSONTable := table.(*JSONTable)
+			if isDT || isCTE || isJSONTable {
+				// derived tables, common table expressions and json tables are ok, as long as all real tables are from the same unsharded keyspace
 				// we check the real tables inside the derived table as well for same unsharded keyspace.
 				continue
 			}
@@ -33,6 +33,9 @@ type tableCollector struct {
 	currentDb string
 	org       originable
 	unionInfo map[*sqlparser.Union]unionInfo
+
+	// ctes are the common table expressions of the WITH RECURSIVE clauses in scope
+	ctes map[string]*CTE
 }
 
 func newTableCollector(scoper *scoper, si SchemaInformation, currentDb string) *tableCollector {
@@ -41,6 +44,7 @@ func newTableCollector(scoper *scoper, si SchemaInformation, currentDb string) *
 		si:        si,
 		currentDb: currentDb,
 		unionInfo: map[*sqlparser.Union]unionInfo{},
+		ctes:      map[string]*CTE{},
 	}
 }
 
@@ -48,6 +52,8 @@ func (tc *tableCollector) up(cursor *sqlparser.Cursor) error {
 	switch node := cursor.Node().(type) {
 	case *sqlparser.AliasedTableExpr:
 		return tc.visitAliasedTableExpr(node)
+	case *sqlparser.JSONTableExpr:
+		return tc.addJSONTable(node)
 	case *sqlparser.Union:
 		firstSelect := sqlparser.GetFirstSelect(node)
 		expanded, selectExprs := getColumnNames(firstSelect.SelectExprs)
@@ -100,6 +106,10 @@ func (tc *tableCollector) visitAliasedTableExpr(node *sqlparser.AliasedTableExpr
 		}
 
 	case sqlparser.TableName:
+		if cte, isCTE := tc.ctes[t.Name.String()]; isCTE && t.Qualifier.IsEmpty() {
+			return tc.addCTETable(cte, node)
+		}
+
 		var tbl *vindexes.Table
 		var vindex vindexes.Vindex
 		isInfSchema := sqlparser.SystemSchema(t.Qualifier.String())
@@ -170,6 +180,32 @@ func (tc *tableCollector) addUnionDerivedTable(union *sqlparser.Union, node *sql
 	return scope.addTable(tableInfo)
 }
 
+func (tc *tableCollector) addCTETable(cte *CTE, node *sqlparser.AliasedTableExpr) error {
+	tableInfo := &CTETable{
+		tableName: node.As.String(),
+		ASTNode:   node,
+		CTE:       cte,
+	}
+	if node.As.IsEmpty() {
+		tableInfo.tableName = cte.Name
+	}
+
+	tc.Tables = append(tc.Tables, tableInfo)
+	scope := tc.scoper.currentScope()
+	return scope.addTable(tableInfo)
+}
+
+func (tc *tableCollector) addJSONTable(node *sqlparser.JSONTableExpr) error {
+	tableInfo := newJSONTable(node)
+	if err := tableInfo.checkForDuplicates(); err != nil {
+		return err
+	}
+
+	tc.Tables = append(tc.Tables, tableInfo)
+	scope := tc.scoper.currentScope()
+	return scope.addTable(tableInfo)
+}
+
 func newVindexTable(t sqlparser.IdentifierCS) *vindexes.Table {
 	vindexCols := []vindexes.Column{
 		{Name: sqlparser.NewIdentifierCI("id"), Type: querypb.Type_VARBINARY},
@@ -29,9 +29,12 @@ import (
 	"github.com/spf13/pflag"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sidecardb"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/srvtopo"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/topo/topoproto"
@@ -39,11 +42,14 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/buffer"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
 
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
+const sqlVReplicationLag = "select id, state, transaction_timestamp, time_heartbeat from %s.vreplication where workflow = %a and db_name = database()"
+
 var (
 	_ discovery.HealthCheck = (*discovery.HealthCheckImpl)(nil)
 	// CellsToWatch is the list of cells the healthcheck operates over. If it is empty, only the local cell is watched
@@ -428,6 +434,37 @@ func (gw *TabletGateway) TabletsCacheStatus() discovery.TabletsCacheStatusList {
 	return gw.hc.CacheStatus()
 }
 
+// VReplicationLag returns the highest lag of the streams of the vreplication workflow
+// running on the target, as recorded by the streams in the sidecar database. The lag
+// of a stream is the time since its last applied transaction or, when it is idle,
+// since its last heartbeat.
+func (gw *TabletGateway) VReplicationLag(ctx context.Context, target *querypb.Target, workflow string) (time.Duration, error) {
+	sidecarDBID, err := sidecardb.GetIdentifierForKeyspace(target.Keyspace)
+	if err != nil {
+		return 0, err
+	}
+	query := sqlparser.BuildParsedQuery(sqlVReplicationLag, sidecarDBID, ":workflow").Query
+	bindVars := map[string]*querypb.BindVariable{"workflow": sqltypes.StringBindVariable(workflow)}
+	qr, err := gw.Execute(ctx, target, query, bindVars, 0, 0, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(qr.Rows) == 0 {
+		return 0, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "no vreplication stream of workflow %s on %s/%s", workflow, target.Keyspace, target.Shard)
+	}
+	now := time.Now().Unix()
+	var lag time.Duration
+	for _, row := range sqltypes.ToNamedResult(qr).Rows {
+		if state := row.AsString("state", ""); state != binlogdatapb.VReplicationWorkflowState_Running.String() {
+			return 0, vterrors.Errorf(vtrpcpb.Code_UNAVAILABLE, "vreplication stream %d of workflow %s on %s/%s is not running: %s",
+				row.AsInt64("id", 0), workflow, target.Keyspace, target.Shard, state)
+		}
+		lastApplied := max(row.AsInt64("transaction_timestamp", 0), row.AsInt64("time_heartbeat", 0))
+		lag = max(lag, time.Duration(now-lastApplied)*time.Second)
+	}
+	return lag, nil
+}
+
 func (gw *TabletGateway) updateDefaultConnCollation(tablet *topodatapb.Tablet) {
 	if atomic.CompareAndSwapUint32(&gw.defaultConnCollation, 0, tablet.DefaultConnCollation) {
 		return
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +33,7 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sidecardb"
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
 )
@@ -177,6 +179,57 @@ func TestTabletGatewayReplicaTransactionError(t *testing.T) {
 	verifyContainsError(t, err, "query service can only be used for non-transactional queries on replicas", vtrpcpb.Code_INTERNAL)
 }
 
+func TestTabletGatewayVReplicationLag(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	if sdbc, _ := sidecardb.GetIdentifierCache(); sdbc != nil {
+		sdbc.Destroy()
+	}
+	_, created := sidecardb.NewIdentifierCache(func(ctx context.Context, keyspace string) (string, error) {
+		return "", nil
+	})
+	require.True(t, created)
+
+	target := &querypb.Target{
+		Keyspace:   "ks",
+		Shard:      "0",
+		TabletType: topodatapb.TabletType_PRIMARY,
+	}
+	hc := discovery.NewFakeHealthCheck(nil)
+	ts := &fakeTopoServer{}
+	tg := NewTabletGateway(ctx, hc, ts, "cell")
+	defer tg.Close(ctx)
+
+	_, err := tg.VReplicationLag(ctx, target, "lkp_vdx")
+	verifyContainsError(t, err, "no healthy tablet available for 'keyspace:\"ks\" shard:\"0\" tablet_type:PRIMARY'", vtrpcpb.Code_UNAVAILABLE)
+
+	sbc := hc.AddTestTablet("cell", "1.1.1.1", 1001, "ks", "0", topodatapb.TabletType_PRIMARY, true, 10, nil)
+	fields := sqltypes.MakeTestFields("id|state|transaction_timestamp|time_heartbeat", "int64|varbinary|int64|int64")
+	now := time.Now().Unix()
+	sbc.SetResults([]*sqltypes.Result{
+		sqltypes.MakeTestResult(fields),
+		sqltypes.MakeTestResult(fields, fmt.Sprintf("1|Stopped|%d|0", now)),
+		sqltypes.MakeTestResult(fields,
+			fmt.Sprintf("1|Running|%d|%d", now-60, now-3),
+			fmt.Sprintf("2|Running|%d|0", now-1),
+		),
+	})
+
+	_, err = tg.VReplicationLag(ctx, target, "lkp_vdx")
+	verifyContainsError(t, err, "no vreplication stream of workflow lkp_vdx on ks/0", vtrpcpb.Code_UNAVAILABLE)
+	assert.Equal(t, "select id, state, transaction_timestamp, time_heartbeat from _vt.vreplication where workflow = :workflow and db_name = database()", sbc.Queries[0].Sql)
+	assert.Equal(t, sqltypes.StringBindVariable("lkp_vdx"), sbc.Queries[0].BindVariables["workflow"])
+
+	_, err = tg.VReplicationLag(ctx, target, "lkp_vdx")
+	verifyContainsError(t, err, "vreplication stream 1 of workflow lkp_vdx on ks/0 is not running: Stopped", vtrpcpb.Code_UNAVAILABLE)
+
+	// an idle stream is as recent as its last heartbeat
+	lag, err := tg.VReplicationLag(ctx, target, "lkp_vdx")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, lag, 3*time.Second)
+	assert.Less(t, lag, time.Minute)
+}
+
 func testTabletGatewayGeneric(t *testing.T, ctx context.Context, f func(ctx context.Context, tg *TabletGateway, target *querypb.Target) error) {
 	t.Helper()
 	keyspace := "ks"
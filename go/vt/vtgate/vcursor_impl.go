@@ -78,6 +78,7 @@ type iExecute interface {
 	showTablets(filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	showVitessMetadata(ctx context.Context, filter *sqlparser.ShowFilter) (*sqltypes.Result, error)
 	setVitessMetadata(ctx context.Context, name, value string) error
+	vreplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error)
 
 	// TODO: remove when resolver is gone
 	ParseDestinationTarget(targetString string) (string, topodatapb.TabletType, key.Destination, error)
@@ -116,6 +117,8 @@ type vcursorImpl struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	sequenceCache *engine.SequenceCache
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -162,9 +165,11 @@ func newVCursorImpl(
 
 	warmingReadsPct := 0
 	var warmingReadsChan chan bool
+	var sequenceCache *engine.SequenceCache
 	if executor != nil {
 		warmingReadsPct = executor.warmingReadsPercent
 		warmingReadsChan = executor.warmingReadsChannel
+		sequenceCache = executor.sequenceCache
 	}
 	return &vcursorImpl{
 		safeSession:         safeSession,
@@ -183,6 +188,7 @@ func newVCursorImpl(
 		pv:                  pv,
 		warmingReadsPercent: warmingReadsPct,
 		warmingReadsChannel: warmingReadsChan,
+		sequenceCache:       sequenceCache,
 	}, nil
 }
 
@@ -646,6 +652,11 @@ func (vc *vcursorImpl) LookupRowLockShardSession() vtgatepb.CommitOrder {
 	return vtgatepb.CommitOrder_PRE
 }
 
+// VReplicationLag implements the VCursor interface
+func (vc *vcursorImpl) VReplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error) {
+	return vc.executor.vreplicationLag(ctx, keyspace, workflow)
+}
+
 // AutocommitApproval is part of the engine.VCursor interface.
 func (vc *vcursorImpl) AutocommitApproval() bool {
 	return vc.safeSession.AutocommitApproval()
@@ -1292,6 +1303,10 @@ func (vc *vcursorImpl) GetWarmingReadsChannel() chan bool {
 	return vc.warmingReadsChannel
 }
 
+func (vc *vcursorImpl) GetSequenceCache() *engine.SequenceCache {
+	return vc.sequenceCache
+}
+
 func (vc *vcursorImpl) CloneForReplicaWarming(ctx context.Context) engine.VCursor {
 	callerId := callerid.EffectiveCallerIDFromContext(ctx)
 	immediateCallerId := callerid.ImmediateCallerIDFromContext(ctx)
@@ -21,14 +21,18 @@ import (
 	"context"
 	"fmt"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var (
 	_ SingleColumn    = (*Binary)(nil)
 	_ Reversible      = (*Binary)(nil)
 	_ Hashing         = (*Binary)(nil)
+	_ RangeMapper     = (*Binary)(nil)
 	_ ParamValidating = (*Binary)(nil)
 )
 
@@ -108,6 +112,40 @@ func (*Binary) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
 	return reverseIds, nil
 }
 
+// RangeMap returns the key range of the ids between start and end.
+// MySQL only compares string bounds byte-wise with a column of the binary collation,
+// other columns and bounds target the whole keyspace.
+func (vind *Binary) RangeMap(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, _ sqltypes.Type, collation collations.ID) ([]key.Destination, error) {
+	if collation != collations.CollationBinaryID {
+		return []key.Destination{key.DestinationAllShards{}}, nil
+	}
+	for _, bound := range []sqltypes.Value{start, end} {
+		if !bound.IsNull() && !bound.IsText() && !bound.IsBinary() {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+	}
+	kr := &topodatapb.KeyRange{}
+	if !start.IsNull() {
+		ksid, err := vind.Hash(start)
+		if err != nil {
+			return nil, err
+		}
+		kr.Start = ksid
+	}
+	if !end.IsNull() {
+		ksid, err := vind.Hash(end)
+		if err != nil {
+			return nil, err
+		}
+		if bytes.Compare(ksid, kr.Start) < 0 {
+			return []key.Destination{key.DestinationNone{}}, nil
+		}
+		// the end of a key range is exclusive, the next keyspace id is the end id followed by a zero byte
+		kr.End = append(bytes.Clone(ksid), 0)
+	}
+	return []key.Destination{key.DestinationKeyRange{KeyRange: kr}}, nil
+}
+
 // UnknownParams implements the ParamValidating interface.
 func (vind *Binary) UnknownParams() []string {
 	return vind.unknownParams
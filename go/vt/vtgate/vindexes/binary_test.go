@@ -26,8 +26,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var binOnlyVindex SingleColumn
@@ -145,3 +148,61 @@ func TestBinaryReverseMap(t *testing.T) {
 		t.Errorf("ReverseMap(): %v, want %s", err, wantErr)
 	}
 }
+
+func TestBinaryRangeMap(t *testing.T) {
+	tcases := []struct {
+		name       string
+		start, end sqltypes.Value
+		collation  collations.ID
+		want       []key.Destination
+	}{{
+		name:      "closed range",
+		start:     sqltypes.NewVarBinary("\x10"),
+		end:       sqltypes.NewVarChar("\x20"),
+		collation: collations.CollationBinaryID,
+		want:      []key.Destination{key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("\x10"), End: []byte("\x20\x00")}}},
+	}, {
+		name:      "unbounded start",
+		start:     sqltypes.NULL,
+		end:       sqltypes.NewVarBinary("\x20"),
+		collation: collations.CollationBinaryID,
+		want:      []key.Destination{key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{End: []byte("\x20\x00")}}},
+	}, {
+		name:      "unbounded end",
+		start:     sqltypes.NewVarBinary("\x10"),
+		end:       sqltypes.NULL,
+		collation: collations.CollationBinaryID,
+		want:      []key.Destination{key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{Start: []byte("\x10")}}},
+	}, {
+		name:      "empty range",
+		start:     sqltypes.NewVarBinary("\x20"),
+		end:       sqltypes.NewVarBinary("\x10"),
+		collation: collations.CollationBinaryID,
+		want:      []key.Destination{key.DestinationNone{}},
+	}, {
+		name:      "numeric bound",
+		start:     sqltypes.NewInt64(10),
+		end:       sqltypes.NewVarBinary("\x20"),
+		collation: collations.CollationBinaryID,
+		want:      []key.Destination{key.DestinationAllShards{}},
+	}, {
+		name:      "column with a text collation",
+		start:     sqltypes.NewVarChar("a"),
+		end:       sqltypes.NewVarChar("b"),
+		collation: collations.CollationUtf8mb4ID,
+		want:      []key.Destination{key.DestinationAllShards{}},
+	}, {
+		name:      "column of an unknown type",
+		start:     sqltypes.NewVarChar("a"),
+		end:       sqltypes.NewVarChar("b"),
+		collation: collations.Unknown,
+		want:      []key.Destination{key.DestinationAllShards{}},
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			got, err := binOnlyVindex.(RangeMapper).RangeMap(context.Background(), nil, tcase.start, tcase.end, sqltypes.VarBinary, tcase.collation)
+			require.NoError(t, err)
+			require.Equal(t, tcase.want, got)
+		})
+	}
+}
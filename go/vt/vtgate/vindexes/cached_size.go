@@ -223,10 +223,12 @@ func (cached *LookupNonUnique) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(192)
+		size += int64(240)
 	}
 	// field name string
 	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field async vitess.io/vitess/go/vt/vtgate/vindexes.asyncConfig
+	size += cached.async.CachedSize(false)
 	// field lkp vitess.io/vitess/go/vt/vtgate/vindexes.lookupInternal
 	size += cached.lkp.CachedSize(false)
 	// field unknownParams []string
@@ -286,10 +288,12 @@ func (cached *LookupUnique) CachedSize(alloc bool) int64 {
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(192)
+		size += int64(240)
 	}
 	// field name string
 	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field async vitess.io/vitess/go/vt/vtgate/vindexes.asyncConfig
+	size += cached.async.CachedSize(false)
 	// field lkp vitess.io/vitess/go/vt/vtgate/vindexes.lookupInternal
 	size += cached.lkp.CachedSize(false)
 	// field unknownParams []string
@@ -342,6 +346,32 @@ func (cached *MultiCol) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *MultiColRangeMap) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(64)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field splits vitess.io/vitess/go/vt/vtgate/vindexes.rangeMapSplits
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.splits)) * int64(48))
+		for _, elem := range cached.splits {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field unknownParams []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.unknownParams)) * int64(16))
+		for _, elem := range cached.unknownParams {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	return size
+}
 func (cached *Null) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -416,6 +446,32 @@ func (cached *NumericStaticMap) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *RangeMap) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(64)
+	}
+	// field name string
+	size += hack.RuntimeAllocSize(int64(len(cached.name)))
+	// field splits vitess.io/vitess/go/vt/vtgate/vindexes.rangeMapSplits
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.splits)) * int64(48))
+		for _, elem := range cached.splits {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field unknownParams []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.unknownParams)) * int64(16))
+		for _, elem := range cached.unknownParams {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	return size
+}
 func (cached *RegionExperimental) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -546,6 +602,20 @@ func (cached *XXHash) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *asyncConfig) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field keyspace string
+	size += hack.RuntimeAllocSize(int64(len(cached.keyspace)))
+	// field workflow string
+	size += hack.RuntimeAllocSize(int64(len(cached.workflow)))
+	return size
+}
 func (cached *cfcCommon) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
@@ -645,3 +715,24 @@ func (cached *prefixCFC) CachedSize(alloc bool) int64 {
 	size += cached.cfcCommon.CachedSize(true)
 	return size
 }
+func (cached *rangeMapSplit) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field from []vitess.io/vitess/go/sqltypes.Value
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.from)) * int64(56))
+		for _, elem := range cached.from {
+			size += elem.CachedSize(false)
+		}
+	}
+	// field ksid []byte
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.ksid)))
+	}
+	return size
+}
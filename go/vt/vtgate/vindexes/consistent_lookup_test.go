@@ -25,6 +25,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -531,6 +532,10 @@ func (vc *loggingVCursor) ConnCollation() collations.ID {
 	return collations.Default()
 }
 
+func (vc *loggingVCursor) VReplicationLag(context.Context, string, string) (time.Duration, error) {
+	panic("unexpected")
+}
+
 type bv struct {
 	Name string
 	Bv   string
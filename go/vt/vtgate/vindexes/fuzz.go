@@ -86,6 +86,7 @@ var availableVindexes = []string{"binary",
 	"cfc",
 	"numeric",
 	"numeric_static_map",
+	"range_map",
 	"xxhash",
 	"unicode_loose_xxhash",
 	"reverse_bits",
@@ -20,16 +20,26 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 const (
-	lookupParamNoVerify  = "no_verify"
-	lookupParamWriteOnly = "write_only"
+	lookupParamNoVerify      = "no_verify"
+	lookupParamWriteOnly     = "write_only"
+	lookupParamAsync         = "async"
+	lookupParamAsyncMaxLag   = "async_max_lag"
+	lookupParamAsyncWorkflow = "async_workflow"
+
+	defaultAsyncMaxLag = 10 * time.Second
 )
 
 var (
@@ -37,18 +47,68 @@ var (
 	_ Lookup          = (*LookupUnique)(nil)
 	_ LookupPlanable  = (*LookupUnique)(nil)
 	_ ParamValidating = (*LookupUnique)(nil)
+	_ LookupAsync     = (*LookupUnique)(nil)
 	_ SingleColumn    = (*LookupNonUnique)(nil)
 	_ Lookup          = (*LookupNonUnique)(nil)
 	_ LookupPlanable  = (*LookupNonUnique)(nil)
 	_ ParamValidating = (*LookupNonUnique)(nil)
+	_ LookupAsync     = (*LookupNonUnique)(nil)
 
 	lookupParams = append(
 		append(make([]string, 0), lookupCommonParams...),
 		lookupParamNoVerify,
 		lookupParamWriteOnly,
+		lookupParamAsync,
+		lookupParamAsyncMaxLag,
+		lookupParamAsyncWorkflow,
 	)
 )
 
+// asyncConfig is the configuration of a lookup vindex whose table is maintained
+// by a vreplication stream from the owner table instead of by vtgate.
+// As the stream applies the changes of the owner table after they are committed,
+// the lookup table may not have the latest rows yet. The vindex then falls back
+// to all the shards for the ids missing from the lookup table, and for all the
+// ids when the stream lags behind by more than maxLag.
+type asyncConfig struct {
+	enabled  bool
+	keyspace string
+	workflow string
+	maxLag   time.Duration
+}
+
+func parseAsyncConfig(name string, m map[string]string) (asyncConfig, error) {
+	var ac asyncConfig
+	var err error
+	if ac.enabled, err = boolFromMap(m, lookupParamAsync); err != nil || !ac.enabled {
+		return ac, err
+	}
+	// the lag is the one of the stream writing to the lookup table, so its keyspace must be known.
+	ac.keyspace, _, err = sqlparser.ParseTable(m[lookupInternalParamTable])
+	if err != nil || ac.keyspace == "" {
+		return ac, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s vindex table name (%s) must be in the form <keyspace>.<table>", lookupParamAsync, m[lookupInternalParamTable])
+	}
+	// like LookupVindex create, the workflow is named after the vindex by default.
+	ac.workflow = name
+	if val, ok := m[lookupParamAsyncWorkflow]; ok && val != "" {
+		ac.workflow = val
+	}
+	ac.maxLag = defaultAsyncMaxLag
+	if val, ok := m[lookupParamAsyncMaxLag]; ok {
+		if ac.maxLag, err = time.ParseDuration(val); err != nil {
+			return ac, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s value: %s", lookupParamAsyncMaxLag, val)
+		}
+	}
+	return ac, nil
+}
+
+// isBehind returns true if the lookup table can't be used to route the queries,
+// because the stream maintaining it lags behind or its lag is unknown.
+func (ac asyncConfig) isBehind(ctx context.Context, vcursor VCursor) bool {
+	lag, err := vcursor.VReplicationLag(ctx, ac.keyspace, ac.workflow)
+	return err != nil || lag > ac.maxLag
+}
+
 func init() {
 	Register("lookup", newLookup)
 	Register("lookup_unique", newLookupUnique)
@@ -60,6 +120,7 @@ type LookupNonUnique struct {
 	name          string
 	writeOnly     bool
 	noVerify      bool
+	async         asyncConfig
 	lkp           lookupInternal
 	unknownParams []string
 }
@@ -99,7 +160,7 @@ func (ln *LookupNonUnique) NeedsVCursor() bool {
 // Map can map ids to key.Destination objects.
 func (ln *LookupNonUnique) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
 	out := make([]key.Destination, 0, len(ids))
-	if ln.writeOnly {
+	if ln.writeOnly || (ln.async.enabled && ln.async.isBehind(ctx, vcursor)) {
 		for range ids {
 			out = append(out, key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}})
 		}
@@ -133,6 +194,11 @@ func (ln *LookupNonUnique) MapResult(ids []sqltypes.Value, results []*sqltypes.R
 	}
 	for _, result := range results {
 		if len(result.Rows) == 0 {
+			if ln.async.enabled {
+				// the row may not have been replicated to the lookup table yet
+				out = append(out, key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}})
+				continue
+			}
 			out = append(out, key.DestinationNone{})
 			continue
 		}
@@ -151,7 +217,7 @@ func (ln *LookupNonUnique) MapResult(ids []sqltypes.Value, results []*sqltypes.R
 
 // Verify returns true if ids maps to ksids.
 func (ln *LookupNonUnique) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
-	if ln.writeOnly || ln.noVerify {
+	if ln.writeOnly || ln.noVerify || ln.async.enabled {
 		out := make([]bool, len(ids))
 		for i := range ids {
 			out[i] = true
@@ -163,16 +229,25 @@ func (ln *LookupNonUnique) Verify(ctx context.Context, vcursor VCursor, ids []sq
 
 // Create reserves the id by inserting it into the vindex table.
 func (ln *LookupNonUnique) Create(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, ksids [][]byte, ignoreMode bool) error {
+	if ln.async.enabled {
+		return nil
+	}
 	return ln.lkp.Create(ctx, vcursor, rowsColValues, ksidsToValues(ksids), ignoreMode)
 }
 
 // Delete deletes the entry from the vindex table.
 func (ln *LookupNonUnique) Delete(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, ksid []byte) error {
+	if ln.async.enabled {
+		return nil
+	}
 	return ln.lkp.Delete(ctx, vcursor, rowsColValues, sqltypes.MakeTrusted(sqltypes.VarBinary, ksid), vtgatepb.CommitOrder_NORMAL)
 }
 
 // Update updates the entry in the vindex table.
 func (ln *LookupNonUnique) Update(ctx context.Context, vcursor VCursor, oldValues []sqltypes.Value, ksid []byte, newValues []sqltypes.Value) error {
+	if ln.async.enabled {
+		return nil
+	}
 	return ln.lkp.Update(ctx, vcursor, oldValues, ksid, sqltypes.MakeTrusted(sqltypes.VarBinary, ksid), newValues)
 }
 
@@ -191,6 +266,11 @@ func (ln *LookupNonUnique) UnknownParams() []string {
 	return ln.unknownParams
 }
 
+// IsAsync implements the LookupAsync interface
+func (ln *LookupNonUnique) IsAsync() bool {
+	return ln.async.enabled
+}
+
 // newLookup creates a LookupNonUnique vindex.
 // The supplied map has the following required fields:
 //
@@ -203,6 +283,11 @@ func (ln *LookupNonUnique) UnknownParams() []string {
 //	autocommit: setting this to "true" will cause inserts to upsert and deletes to be ignored.
 //	write_only: in this mode, Map functions return the full keyrange causing a full scatter.
 //	no_verify: in this mode, Verify will always succeed.
+//	async: in this mode, the table is maintained by a vreplication stream instead of by vtgate.
+//	  The table name must be qualified by the keyspace. Map functions return the full keyrange
+//	  for the ids missing from the table, and for all the ids when the stream lags behind.
+//	async_max_lag: the highest lag of the stream allowing the table to be used in async mode. Defaults to 10s.
+//	async_workflow: the name of the vreplication workflow maintaining the table. Defaults to the vindex name.
 func newLookup(name string, m map[string]string) (Vindex, error) {
 	lookup := &LookupNonUnique{
 		name:          name,
@@ -223,6 +308,11 @@ func newLookup(name string, m map[string]string) (Vindex, error) {
 		return nil, err
 	}
 
+	lookup.async, err = parseAsyncConfig(name, m)
+	if err != nil {
+		return nil, err
+	}
+
 	// if autocommit is on for non-unique lookup, upsert should also be on.
 	upsert := cc.autocommit || cc.multiShardAutocommit
 	if err := lookup.lkp.Init(m, cc.autocommit, upsert, cc.multiShardAutocommit); err != nil {
@@ -248,6 +338,7 @@ type LookupUnique struct {
 	name          string
 	writeOnly     bool
 	noVerify      bool
+	async         asyncConfig
 	lkp           lookupInternal
 	unknownParams []string
 }
@@ -275,6 +366,11 @@ func (lu *LookupUnique) AutoCommitEnabled() bool {
 //
 //	autocommit: setting this to "true" will cause deletes to be ignored.
 //	write_only: in this mode, Map functions return the full keyrange causing a full scatter.
+//	async: in this mode, the table is maintained by a vreplication stream instead of by vtgate.
+//	  The table name must be qualified by the keyspace. Map functions return the full keyrange
+//	  for the ids missing from the table, and for all the ids when the stream lags behind.
+//	async_max_lag: the highest lag of the stream allowing the table to be used in async mode. Defaults to 10s.
+//	async_workflow: the name of the vreplication workflow maintaining the table. Defaults to the vindex name.
 func newLookupUnique(name string, m map[string]string) (Vindex, error) {
 	lu := &LookupUnique{
 		name:          name,
@@ -295,6 +391,11 @@ func newLookupUnique(name string, m map[string]string) (Vindex, error) {
 		return nil, err
 	}
 
+	lu.async, err = parseAsyncConfig(name, m)
+	if err != nil {
+		return nil, err
+	}
+
 	// Don't allow upserts for unique vindexes.
 	if err := lu.lkp.Init(m, cc.autocommit, false /* upsert */, cc.multiShardAutocommit); err != nil {
 		return nil, err
@@ -324,7 +425,7 @@ func (lu *LookupUnique) NeedsVCursor() bool {
 
 // Map can map ids to key.Destination objects.
 func (lu *LookupUnique) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
-	if lu.writeOnly {
+	if lu.writeOnly || (lu.async.enabled && lu.async.isBehind(ctx, vcursor)) {
 		out := make([]key.Destination, 0, len(ids))
 		for range ids {
 			out = append(out, key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}})
@@ -343,6 +444,11 @@ func (lu *LookupUnique) MapResult(ids []sqltypes.Value, results []*sqltypes.Resu
 	for i, result := range results {
 		switch len(result.Rows) {
 		case 0:
+			if lu.async.enabled {
+				// the row may not have been replicated to the lookup table yet
+				out = append(out, key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}})
+				continue
+			}
 			out = append(out, key.DestinationNone{})
 		case 1:
 			rowBytes, err := result.Rows[0][0].ToBytes()
@@ -359,7 +465,7 @@ func (lu *LookupUnique) MapResult(ids []sqltypes.Value, results []*sqltypes.Resu
 
 // Verify returns true if ids maps to ksids.
 func (lu *LookupUnique) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
-	if lu.writeOnly || lu.noVerify {
+	if lu.writeOnly || lu.noVerify || lu.async.enabled {
 		out := make([]bool, len(ids))
 		for i := range ids {
 			out[i] = true
@@ -371,16 +477,25 @@ func (lu *LookupUnique) Verify(ctx context.Context, vcursor VCursor, ids []sqlty
 
 // Create reserves the id by inserting it into the vindex table.
 func (lu *LookupUnique) Create(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, ksids [][]byte, ignoreMode bool) error {
+	if lu.async.enabled {
+		return nil
+	}
 	return lu.lkp.Create(ctx, vcursor, rowsColValues, ksidsToValues(ksids), ignoreMode)
 }
 
 // Update updates the entry in the vindex table.
 func (lu *LookupUnique) Update(ctx context.Context, vcursor VCursor, oldValues []sqltypes.Value, ksid []byte, newValues []sqltypes.Value) error {
+	if lu.async.enabled {
+		return nil
+	}
 	return lu.lkp.Update(ctx, vcursor, oldValues, ksid, sqltypes.MakeTrusted(sqltypes.VarBinary, ksid), newValues)
 }
 
 // Delete deletes the entry from the vindex table.
 func (lu *LookupUnique) Delete(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, ksid []byte) error {
+	if lu.async.enabled {
+		return nil
+	}
 	return lu.lkp.Delete(ctx, vcursor, rowsColValues, sqltypes.MakeTrusted(sqltypes.VarBinary, ksid), vtgatepb.CommitOrder_NORMAL)
 }
 
@@ -394,6 +509,11 @@ func (lu *LookupUnique) IsBackfilling() bool {
 	return lu.writeOnly
 }
 
+// IsAsync implements the LookupAsync interface
+func (lu *LookupUnique) IsAsync() bool {
+	return lu.async.enabled
+}
+
 func (lu *LookupUnique) LookupQuery() (string, error) {
 	return lu.lkp.sel, nil
 }
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/test/utils"
@@ -51,6 +52,9 @@ type vcursor struct {
 	autocommits int
 	pre, post   int
 	keys        []sqltypes.Value
+	lag         time.Duration
+	lagErr      error
+	lagWorkflow string
 }
 
 func (vc *vcursor) LookupRowLockShardSession() vtgatepb.CommitOrder {
@@ -118,6 +122,11 @@ func (vc *vcursor) ConnCollation() collations.ID {
 	return collations.Default()
 }
 
+func (vc *vcursor) VReplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error) {
+	vc.lagWorkflow = keyspace + "." + workflow
+	return vc.lag, vc.lagErr
+}
+
 func lookupCreateVindexTestCase(
 	testName string,
 	vindexParams map[string]string,
@@ -344,6 +353,30 @@ func TestLookupCreateVindex(t *testing.T) {
 				vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "no_verify value must be 'true' or 'false': 'hello'"),
 				nil,
 			),
+			testCaseF(
+				"async true",
+				map[string]string{"async": "true", "table": "ks.t", "async_max_lag": "1m"},
+				nil,
+				nil,
+			),
+			testCaseF(
+				"async requires a qualified table",
+				map[string]string{"async": "true", "table": "t"},
+				vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "async vindex table name (t) must be in the form <keyspace>.<table>"),
+				nil,
+			),
+			testCaseF(
+				"async_workflow",
+				map[string]string{"async": "true", "table": "ks.t", "async_workflow": "t_vdx"},
+				nil,
+				nil,
+			),
+			testCaseF(
+				"async_max_lag reject not duration",
+				map[string]string{"async": "true", "table": "ks.t", "async_max_lag": "hello"},
+				vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid async_max_lag value: hello"),
+				nil,
+			),
 		}
 
 		testCreateVindexes(t, cases)
@@ -474,6 +507,48 @@ func TestLookupNonUniqueMapAbsent(t *testing.T) {
 	utils.MustMatch(t, want, got)
 }
 
+func TestLookupNonUniqueMapAsync(t *testing.T) {
+	lnu := createAsyncLookup(t, "lookup")
+	vc := &vcursor{numRows: 1}
+
+	// the ids missing from the lookup table may not have been replicated yet
+	got, err := lnu.Map(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2)})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationKeyspaceIDs([][]byte{[]byte("1")}),
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}},
+	}
+	utils.MustMatch(t, want, got)
+	assert.Len(t, vc.queries, 1)
+	assert.Equal(t, "ks.lookup", vc.lagWorkflow)
+
+	// the lookup table is not used when the stream lags behind
+	for _, vc := range []*vcursor{{numRows: 1, lag: time.Minute}, {numRows: 1, lagErr: errors.New("no healthy tablet")}} {
+		got, err = lnu.Map(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1)})
+		require.NoError(t, err)
+		utils.MustMatch(t, []key.Destination{key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}}}, got)
+		assert.Empty(t, vc.queries)
+	}
+}
+
+func TestLookupNonUniqueAsyncWrites(t *testing.T) {
+	lnu := createAsyncLookup(t, "lookup")
+	vc := &vcursor{}
+
+	// the lookup table is maintained by vreplication, not by vtgate
+	err := lnu.(Lookup).Create(context.Background(), vc, [][]sqltypes.Value{{sqltypes.NewInt64(1)}}, [][]byte{[]byte("test1")}, false /* ignoreMode */)
+	require.NoError(t, err)
+	err = lnu.(Lookup).Update(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1)}, []byte("test1"), []sqltypes.Value{sqltypes.NewInt64(2)})
+	require.NoError(t, err)
+	err = lnu.(Lookup).Delete(context.Background(), vc, [][]sqltypes.Value{{sqltypes.NewInt64(2)}}, []byte("test1"))
+	require.NoError(t, err)
+	got, err := lnu.Verify(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1)}, [][]byte{[]byte("test1")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, got)
+	assert.Empty(t, vc.queries)
+	assert.True(t, lnu.(LookupAsync).IsAsync())
+}
+
 func TestLookupNonUniqueVerify(t *testing.T) {
 	lnu := createLookup(t, "lookup", false /* writeOnly */)
 	vc := &vcursor{numRows: 1}
@@ -836,6 +911,20 @@ func TestLookupNonUniqueCreateMultiShardAutocommit(t *testing.T) {
 	require.Equal(t, 1, vc.autocommits, "Create(autocommit) count")
 }
 
+func createAsyncLookup(t *testing.T, name string) SingleColumn {
+	t.Helper()
+	l, err := CreateVindex(name, name, map[string]string{
+		"table":         "ks.t",
+		"from":          "fromc",
+		"to":            "toc",
+		"async":         "true",
+		"async_max_lag": "30s",
+	})
+	require.NoError(t, err)
+	require.Empty(t, l.(ParamValidating).UnknownParams())
+	return l.(SingleColumn)
+}
+
 func createLookup(t *testing.T, name string, writeOnly bool) SingleColumn {
 	t.Helper()
 	write := "false"
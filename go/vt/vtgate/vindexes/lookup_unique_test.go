@@ -20,6 +20,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -122,6 +123,34 @@ func TestLookupUniqueMapWriteOnly(t *testing.T) {
 	}
 }
 
+func TestLookupUniqueMapAsync(t *testing.T) {
+	lookupUnique := createAsyncLookup(t, "lookup_unique")
+	vc := &vcursor{numRows: 1}
+
+	got, err := lookupUnique.Map(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(2)})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationKeyspaceID([]byte("1")),
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map(): %+v, want %+v", got, want)
+	}
+
+	vc = &vcursor{numRows: 1, lag: time.Minute}
+	got, err = lookupUnique.Map(context.Background(), vc, []sqltypes.Value{sqltypes.NewInt64(1)})
+	require.NoError(t, err)
+	want = []key.Destination{
+		key.DestinationKeyRange{KeyRange: &topodatapb.KeyRange{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map(): %+v, want %+v", got, want)
+	}
+	if got, want := len(vc.queries), 0; got != want {
+		t.Errorf("vc.queries length: %v, want %v", got, want)
+	}
+}
+
 func TestLookupUniqueVerify(t *testing.T) {
 	lookupUnique := createLookup(t, "lookup_unique", false)
 	vc := &vcursor{numRows: 1}
@@ -21,15 +21,21 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"math"
+	"strconv"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var (
 	_ SingleColumn    = (*Numeric)(nil)
 	_ Reversible      = (*Numeric)(nil)
 	_ Hashing         = (*Numeric)(nil)
+	_ RangeMapper     = (*Numeric)(nil)
 	_ ParamValidating = (*Numeric)(nil)
 )
 
@@ -108,6 +114,40 @@ func (*Numeric) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error)
 	return reverseIds, nil
 }
 
+// RangeMap returns the key range of the ids between start and end.
+// MySQL only compares the values of an integral column numerically, and bounds
+// that are not unsigned integers can't be mapped to keyspace ids in the same order,
+// so the whole keyspace is targeted otherwise.
+func (vind *Numeric) RangeMap(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, typ sqltypes.Type, _ collations.ID) ([]key.Destination, error) {
+	if typ == sqltypes.Unknown || !sqltypes.IsIntegral(typ) {
+		return []key.Destination{key.DestinationAllShards{}}, nil
+	}
+	kr := &topodatapb.KeyRange{}
+	var lower uint64
+	if !start.IsNull() {
+		num, ok := uint64RangeBound(start)
+		if !ok {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+		lower = num
+		kr.Start, _ = vind.Hash(sqltypes.NewUint64(num))
+	}
+	if !end.IsNull() {
+		num, ok := uint64RangeBound(end)
+		if !ok {
+			return []key.Destination{key.DestinationAllShards{}}, nil
+		}
+		if num < lower {
+			return []key.Destination{key.DestinationNone{}}, nil
+		}
+		// the end of a key range is exclusive
+		if num < math.MaxUint64 {
+			kr.End, _ = vind.Hash(sqltypes.NewUint64(num + 1))
+		}
+	}
+	return []key.Destination{key.DestinationKeyRange{KeyRange: kr}}, nil
+}
+
 // UnknownParams implements the ParamValidating interface.
 func (vind *Numeric) UnknownParams() []string {
 	return vind.unknownParams
@@ -126,3 +166,19 @@ func (*Numeric) Hash(id sqltypes.Value) ([]byte, error) {
 func init() {
 	Register("numeric", newNumeric)
 }
+
+// uint64RangeBound returns the value of a range bound if it is an unsigned integer.
+func uint64RangeBound(v sqltypes.Value) (uint64, bool) {
+	switch {
+	case v.IsUnsigned():
+		num, err := v.ToUint64()
+		return num, err == nil
+	case v.IsSigned():
+		num, err := v.ToInt64()
+		return uint64(num), err == nil && num >= 0
+	case v.IsText() || v.IsBinary():
+		num, err := strconv.ParseUint(v.ToString(), 10, 64)
+		return num, err == nil
+	}
+	return 0, false
+}
@@ -18,13 +18,17 @@ package vindexes
 
 import (
 	"context"
+	"math"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 )
 
 var numeric SingleColumn
@@ -146,3 +150,83 @@ func TestNumericReverseMapBadData(t *testing.T) {
 		t.Errorf("numeric.Map: %v, want %v", err, want)
 	}
 }
+
+func TestNumericRangeMap(t *testing.T) {
+	keyRange := func(start, end string) []key.Destination {
+		kr := &topodatapb.KeyRange{}
+		if start != "" {
+			kr.Start = []byte(start)
+		}
+		if end != "" {
+			kr.End = []byte(end)
+		}
+		return []key.Destination{key.DestinationKeyRange{KeyRange: kr}}
+	}
+	tcases := []struct {
+		name       string
+		start, end sqltypes.Value
+		typ        sqltypes.Type
+		want       []key.Destination
+	}{{
+		name:  "closed range",
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewUint64(2),
+		typ:   sqltypes.Uint64,
+		want:  keyRange("\x00\x00\x00\x00\x00\x00\x00\x01", "\x00\x00\x00\x00\x00\x00\x00\x03"),
+	}, {
+		name:  "unbounded start",
+		start: sqltypes.NULL,
+		end:   sqltypes.NewVarChar("255"),
+		typ:   sqltypes.Uint64,
+		want:  keyRange("", "\x00\x00\x00\x00\x00\x00\x01\x00"),
+	}, {
+		name:  "unbounded end",
+		start: sqltypes.NewInt64(256),
+		end:   sqltypes.NULL,
+		typ:   sqltypes.Uint64,
+		want:  keyRange("\x00\x00\x00\x00\x00\x00\x01\x00", ""),
+	}, {
+		name:  "maximum end",
+		start: sqltypes.NewInt64(256),
+		end:   sqltypes.NewUint64(math.MaxUint64),
+		typ:   sqltypes.Uint64,
+		want:  keyRange("\x00\x00\x00\x00\x00\x00\x01\x00", ""),
+	}, {
+		name:  "empty range",
+		start: sqltypes.NewInt64(2),
+		end:   sqltypes.NewInt64(1),
+		typ:   sqltypes.Uint64,
+		want:  []key.Destination{key.DestinationNone{}},
+	}, {
+		name:  "negative bound",
+		start: sqltypes.NewInt64(-1),
+		end:   sqltypes.NewInt64(1),
+		typ:   sqltypes.Uint64,
+		want:  []key.Destination{key.DestinationAllShards{}},
+	}, {
+		name:  "non integral bound",
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewFloat64(1.5),
+		typ:   sqltypes.Uint64,
+		want:  []key.Destination{key.DestinationAllShards{}},
+	}, {
+		name:  "column of a text type",
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewInt64(2),
+		typ:   sqltypes.VarChar,
+		want:  []key.Destination{key.DestinationAllShards{}},
+	}, {
+		name:  "column of an unknown type",
+		start: sqltypes.NewInt64(1),
+		end:   sqltypes.NewInt64(2),
+		typ:   sqltypes.Unknown,
+		want:  []key.Destination{key.DestinationAllShards{}},
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			got, err := numeric.(RangeMapper).RangeMap(context.Background(), nil, tcase.start, tcase.end, tcase.typ, collations.Unknown)
+			require.NoError(t, err)
+			require.Equal(t, tcase.want, got)
+		})
+	}
+}
@@ -0,0 +1,505 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+const (
+	rangeMapParamJSON      = "json"
+	rangeMapParamJSONPath  = "json_path"
+	rangeMapParamValueType = "value_type"
+)
+
+var (
+	_ SingleColumn    = (*RangeMap)(nil)
+	_ Reversible      = (*RangeMap)(nil)
+	_ Hashing         = (*RangeMap)(nil)
+	_ RangeMapper     = (*RangeMap)(nil)
+	_ ParamValidating = (*RangeMap)(nil)
+	_ MultiColumn     = (*MultiColRangeMap)(nil)
+	_ ParamValidating = (*MultiColRangeMap)(nil)
+
+	rangeMapParams = []string{
+		rangeMapParamJSON,
+		rangeMapParamJSONPath,
+		rangeMapParamValueType,
+	}
+)
+
+// rangeMapEntry is the JSON representation of a split point of a RangeMap.
+type rangeMapEntry struct {
+	From       rangeMapFrom `json:"from"`
+	KeyspaceID string       `json:"keyspace_id"`
+}
+
+// rangeMapFrom is the lowest id of a split point, given either as a string,
+// or as an array of strings with one value per column for the multi-column form.
+type rangeMapFrom []string
+
+func (f *rangeMapFrom) UnmarshalJSON(data []byte) error {
+	var from string
+	if err := json.Unmarshal(data, &from); err == nil {
+		*f = rangeMapFrom{from}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(f))
+}
+
+func (f rangeMapFrom) String() string {
+	if len(f) == 1 {
+		return f[0]
+	}
+	return "(" + strings.Join(f, ", ") + ")"
+}
+
+// rangeMapSplit is a parsed split point of a RangeMap: the ids starting
+// from the values from, up to the next split point, map to ksid.
+type rangeMapSplit struct {
+	from []sqltypes.Value
+	ksid []byte
+}
+
+// rangeMapSplits are the split points of a RangeMap, sorted by from.
+type rangeMapSplits []rangeMapSplit
+
+// RangeMap is a vindex that maps ranges of ids to keyspace ids using a list
+// of split points stored in the VSchema. Each split point gives the lowest
+// id of a range and the keyspace id of all the ids of that range, up to the
+// next split point. Ids lower than the first split point have no keyspace id.
+// The split points are given either inline with the `json` param or in a file
+// with the `json_path` param, as a JSON array of objects with a `from` value
+// and a hex encoded `keyspace_id`, sorted by `from`. The `value_type` param
+// gives the type of the ids, and defaults to INT64. If it lists several comma
+// separated types, the vindex is a MultiColRangeMap instead.
+// It's Unique, Reversible and can route range predicates.
+type RangeMap struct {
+	name          string
+	splits        rangeMapSplits
+	unknownParams []string
+}
+
+// MultiColRangeMap is the multi-column form of the RangeMap vindex: the ids
+// are tuples with one value per type listed in `value_type`, the `from` of
+// each split point is an array with one value per column, and the split
+// points are sorted by comparing their values column by column.
+// It's Unique, and a prefix of the columns maps to the keyspace ids of all
+// the ranges that can hold ids starting with that prefix.
+type MultiColRangeMap struct {
+	name          string
+	splits        rangeMapSplits
+	unknownParams []string
+}
+
+func init() {
+	Register("range_map", newRangeMap)
+}
+
+// newRangeMap creates a RangeMap vindex.
+func newRangeMap(name string, params map[string]string) (Vindex, error) {
+	jsonStr, jsok := params[rangeMapParamJSON]
+	jsonPath, jpok := params[rangeMapParamJSONPath]
+
+	if !jsok && !jpok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: could not find either `json_path` or `json` params in vschema")
+	}
+	if jsok && jpok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: found both `json` and `json_path` params in vschema")
+	}
+
+	types := []sqltypes.Type{sqltypes.Int64}
+	if s, ok := params[rangeMapParamValueType]; ok {
+		types = types[:0]
+		for _, name := range strings.Split(s, ",") {
+			name = strings.TrimSpace(name)
+			t, ok := querypb.Type_value[strings.ToUpper(name)]
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: unknown value type '%s'", name)
+			}
+			types = append(types, sqltypes.Type(t))
+		}
+	}
+
+	data := []byte(jsonStr)
+	if jpok {
+		var err error
+		data, err = os.ReadFile(jsonPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	splits, err := parseRangeMapSplits(data, types)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(types) > 1 {
+		return &MultiColRangeMap{
+			name:          name,
+			splits:        splits,
+			unknownParams: FindUnknownParams(params, rangeMapParams),
+		}, nil
+	}
+	return &RangeMap{
+		name:          name,
+		splits:        splits,
+		unknownParams: FindUnknownParams(params, rangeMapParams),
+	}, nil
+}
+
+func parseRangeMapSplits(data []byte, types []sqltypes.Type) (rangeMapSplits, error) {
+	var entries []rangeMapEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: no split point provided")
+	}
+
+	splits := make(rangeMapSplits, 0, len(entries))
+	for i, entry := range entries {
+		if len(entry.From) != len(types) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: split point '%s' must have %d values", entry.From, len(types))
+		}
+		ksid, err := hex.DecodeString(entry.KeyspaceID)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: invalid keyspace id '%s': %v", entry.KeyspaceID, err)
+		}
+		split := rangeMapSplit{
+			from: make([]sqltypes.Value, 0, len(types)),
+			ksid: ksid,
+		}
+		for j, typ := range types {
+			split.from = append(split.from, sqltypes.MakeTrusted(typ, []byte(entry.From[j])))
+		}
+		if len(splits) > 0 {
+			cmp, err := compareRangeMapValues(splits[len(splits)-1].from, split.from)
+			if err != nil {
+				return nil, err
+			}
+			if cmp >= 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: split points must be in strictly ascending order, got '%s' after '%s'", entry.From, entries[i-1].From)
+			}
+		}
+		splits = append(splits, split)
+	}
+	return splits, nil
+}
+
+// compareRangeMapValues compares the values of a split point with the ids
+// column by column, only up to the number of ids given.
+func compareRangeMapValues(from, ids []sqltypes.Value) (int, error) {
+	for i, id := range ids {
+		cmp, err := evalengine.NullsafeCompare(from[i], id, collations.CollationBinaryID)
+		if err != nil || cmp != 0 {
+			return cmp, err
+		}
+	}
+	return 0, nil
+}
+
+// String returns the name of the vindex.
+func (vind *RangeMap) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this vindex as 1.
+func (*RangeMap) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (*RangeMap) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (*RangeMap) NeedsVCursor() bool {
+	return false
+}
+
+// Map can map ids to key.Destination objects.
+func (vind *RangeMap) Map(ctx context.Context, vcursor VCursor, ids []sqltypes.Value) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(ids))
+	for _, id := range ids {
+		idx, err := vind.splits.find(id)
+		if err != nil {
+			return nil, err
+		}
+		if idx < 0 {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(vind.splits[idx].ksid))
+	}
+	return out, nil
+}
+
+// Verify returns true if ids maps to ksids.
+func (vind *RangeMap) Verify(ctx context.Context, vcursor VCursor, ids []sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(ids))
+	for i, id := range ids {
+		idx, err := vind.splits.find(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, idx >= 0 && bytes.Equal(vind.splits[idx].ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// ReverseMap returns the lowest id of the first range mapped to each keyspace id.
+func (vind *RangeMap) ReverseMap(_ VCursor, ksids [][]byte) ([]sqltypes.Value, error) {
+	out := make([]sqltypes.Value, 0, len(ksids))
+	for _, ksid := range ksids {
+		found := false
+		for _, split := range vind.splits {
+			if bytes.Equal(split.ksid, ksid) {
+				out = append(out, split.from[0])
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: no range found for keyspace id %x", ksid)
+		}
+	}
+	return out, nil
+}
+
+// Hash returns the keyspace id of the range the id belongs to.
+// It allows the vindex to be used as a column vindex of a multicol vindex.
+func (vind *RangeMap) Hash(id sqltypes.Value) ([]byte, error) {
+	idx, err := vind.splits.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if idx < 0 {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: no range found for value %s", id.ToString())
+	}
+	return vind.splits[idx].ksid, nil
+}
+
+// RangeMap returns the keyspace ids of all the ranges overlapping the ids between start and end.
+func (vind *RangeMap) RangeMap(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, _ sqltypes.Type, _ collations.ID) ([]key.Destination, error) {
+	first := 0
+	if !start.IsNull() {
+		idx, err := vind.splits.find(start)
+		if err != nil {
+			return nil, err
+		}
+		first = max(idx, 0)
+	}
+	last := len(vind.splits) - 1
+	if !end.IsNull() {
+		idx, err := vind.splits.find(end)
+		if err != nil {
+			return nil, err
+		}
+		last = idx
+	}
+
+	var out []key.Destination
+	for _, ksid := range vind.splits.keyspaceIDs(first, last) {
+		out = append(out, key.DestinationKeyspaceID(ksid))
+	}
+	if len(out) == 0 {
+		return []key.Destination{key.DestinationNone{}}, nil
+	}
+	return out, nil
+}
+
+// UnknownParams implements the ParamValidating interface.
+func (vind *RangeMap) UnknownParams() []string {
+	return vind.unknownParams
+}
+
+// String returns the name of the vindex.
+func (vind *MultiColRangeMap) String() string {
+	return vind.name
+}
+
+// Cost returns the cost of this vindex as 1.
+func (*MultiColRangeMap) Cost() int {
+	return 1
+}
+
+// IsUnique returns true since the Vindex is unique.
+func (*MultiColRangeMap) IsUnique() bool {
+	return true
+}
+
+// NeedsVCursor satisfies the Vindex interface.
+func (*MultiColRangeMap) NeedsVCursor() bool {
+	return false
+}
+
+// Map can map the ids to key.Destination objects. The ids giving only a prefix
+// of the columns map to the keyspace ids of all the ranges they can belong to.
+func (vind *MultiColRangeMap) Map(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value) ([]key.Destination, error) {
+	out := make([]key.Destination, 0, len(rowsColValues))
+	for _, ids := range rowsColValues {
+		if err := vind.checkColumnCount(ids); err != nil {
+			return nil, err
+		}
+		if len(ids) < vind.columnCount() {
+			first, last, err := vind.splits.findPrefix(ids)
+			if err != nil {
+				return nil, err
+			}
+			ksids := vind.splits.keyspaceIDs(first, last)
+			if len(ksids) == 0 {
+				out = append(out, key.DestinationNone{})
+				continue
+			}
+			out = append(out, key.DestinationKeyspaceIDs(ksids))
+			continue
+		}
+		idx, err := vind.splits.find(ids...)
+		if err != nil {
+			return nil, err
+		}
+		if idx < 0 {
+			out = append(out, key.DestinationNone{})
+			continue
+		}
+		out = append(out, key.DestinationKeyspaceID(vind.splits[idx].ksid))
+	}
+	return out, nil
+}
+
+// Verify returns true if the ids map to ksids.
+func (vind *MultiColRangeMap) Verify(ctx context.Context, vcursor VCursor, rowsColValues [][]sqltypes.Value, ksids [][]byte) ([]bool, error) {
+	out := make([]bool, 0, len(rowsColValues))
+	for i, ids := range rowsColValues {
+		if err := vind.checkColumnCount(ids); err != nil {
+			return nil, err
+		}
+		idx, err := vind.splits.find(ids...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, idx >= 0 && len(ids) == vind.columnCount() && bytes.Equal(vind.splits[idx].ksid, ksids[i]))
+	}
+	return out, nil
+}
+
+// PartialVindex returns true since a prefix of the columns can be mapped.
+func (*MultiColRangeMap) PartialVindex() bool {
+	return true
+}
+
+// UnknownParams implements the ParamValidating interface.
+func (vind *MultiColRangeMap) UnknownParams() []string {
+	return vind.unknownParams
+}
+
+func (vind *MultiColRangeMap) columnCount() int {
+	return len(vind.splits[0].from)
+}
+
+func (vind *MultiColRangeMap) checkColumnCount(ids []sqltypes.Value) error {
+	if len(ids) == 0 || len(ids) > vind.columnCount() {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] wrong number of column values were passed: maximum allowed %d, got %d", vind.columnCount(), len(ids))
+	}
+	return nil
+}
+
+// find returns the index of the split point of the range the ids belong to,
+// or -1 if the ids are lower than the first split point or contain a NULL.
+func (splits rangeMapSplits) find(ids ...sqltypes.Value) (int, error) {
+	for _, id := range ids {
+		if id.IsNull() {
+			return -1, nil
+		}
+	}
+	// the last split point lower than or equal to the ids
+	return splits.search(ids, func(cmp int) bool { return cmp <= 0 })
+}
+
+// findPrefix returns the indexes of the first and last split points of the
+// ranges that can hold ids starting with the prefix. last is lower than first
+// if there's no such range.
+func (splits rangeMapSplits) findPrefix(prefix []sqltypes.Value) (first, last int, err error) {
+	for _, id := range prefix {
+		if id.IsNull() {
+			return 0, -1, nil
+		}
+	}
+	// the range of the last split point lower than the prefix ends after the
+	// lowest id starting with the prefix, if there's such a split point
+	first, err = splits.search(prefix, func(cmp int) bool { return cmp < 0 })
+	if err != nil {
+		return 0, 0, err
+	}
+	// the highest id starting with the prefix is in the range of the last
+	// split point whose prefix is lower than or equal to it
+	last, err = splits.search(prefix, func(cmp int) bool { return cmp <= 0 })
+	if err != nil {
+		return 0, 0, err
+	}
+	return max(first, 0), last, nil
+}
+
+// search returns the index of the last split point whose comparison with the
+// ids is accepted by before, or -1 if there's none.
+func (splits rangeMapSplits) search(ids []sqltypes.Value, before func(cmp int) bool) (int, error) {
+	lo, hi := 0, len(splits)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		cmp, err := compareRangeMapValues(splits[mid].from, ids)
+		if err != nil {
+			return 0, err
+		}
+		if before(cmp) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo - 1, nil
+}
+
+// keyspaceIDs returns the keyspace ids of the split points between first and
+// last included, without repeating them.
+func (splits rangeMapSplits) keyspaceIDs(first, last int) [][]byte {
+	var out [][]byte
+	seen := make(map[string]bool)
+	for i := first; i <= last; i++ {
+		ksid := splits[i].ksid
+		if seen[string(ksid)] {
+			continue
+		}
+		seen[string(ksid)] = true
+		out = append(out, ksid)
+	}
+	return out
+}
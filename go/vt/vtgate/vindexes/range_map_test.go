@@ -0,0 +1,374 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vindexes
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/key"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const rangeMapTestJSON = `[
+	{"from": "0", "keyspace_id": "10"},
+	{"from": "100", "keyspace_id": "50"},
+	{"from": "200", "keyspace_id": "90"},
+	{"from": "300", "keyspace_id": "10"}
+]`
+
+func mustCreateRangeMap(params map[string]string) RangeMapper {
+	vindex, err := CreateVindex("range_map", "range_map", params)
+	if err != nil {
+		panic(err)
+	}
+	return vindex.(RangeMapper)
+}
+
+func rangeMapCreateVindexTestCase(
+	testName string,
+	vindexParams map[string]string,
+	expectErr error,
+	expectUnknownParams []string,
+) createVindexTestCase {
+	return createVindexTestCase{
+		testName: testName,
+
+		vindexType:   "range_map",
+		vindexName:   "range_map",
+		vindexParams: vindexParams,
+
+		expectCost:          1,
+		expectErr:           expectErr,
+		expectIsUnique:      true,
+		expectNeedsVCursor:  false,
+		expectString:        "range_map",
+		expectUnknownParams: expectUnknownParams,
+	}
+}
+
+func TestRangeMapCreateVindex(t *testing.T) {
+	cases := []createVindexTestCase{
+		rangeMapCreateVindexTestCase(
+			"no params invalid, require either json_path or json",
+			nil,
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: could not find either `json_path` or `json` params in vschema"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"json_path and json mutually exclusive",
+			map[string]string{
+				"json":      "[]",
+				"json_path": "/path/to/map.json",
+			},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: found both `json` and `json_path` params in vschema"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"json_path must exist",
+			map[string]string{
+				"json_path": "/path/to/map.json",
+			},
+			errors.New("open /path/to/map.json: no such file or directory"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"json ok",
+			map[string]string{
+				"json": rangeMapTestJSON,
+			},
+			nil,
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"json must not be empty",
+			map[string]string{
+				"json": "[]",
+			},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: no split point provided"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"keyspace id must be hex",
+			map[string]string{
+				"json": `[{"from": "1", "keyspace_id": "zz"}]`,
+			},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: invalid keyspace id 'zz': encoding/hex: invalid byte: U+007A 'z'"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"split points must be ascending",
+			map[string]string{
+				"json": `[{"from": "10", "keyspace_id": "10"}, {"from": "9", "keyspace_id": "20"}]`,
+			},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: split points must be in strictly ascending order, got '9' after '10'"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"value_type ok",
+			map[string]string{
+				"json":       `[{"from": "2023-01-01 00:00:00", "keyspace_id": "10"}]`,
+				"value_type": "datetime",
+			},
+			nil,
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"value_type must be valid",
+			map[string]string{
+				"json":       rangeMapTestJSON,
+				"value_type": "not_found",
+			},
+			vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "RangeMap: unknown value type 'not_found'"),
+			nil,
+		),
+		rangeMapCreateVindexTestCase(
+			"unknown params",
+			map[string]string{
+				"json":  rangeMapTestJSON,
+				"hello": "world",
+			},
+			nil,
+			[]string{"hello"},
+		),
+	}
+
+	testCreateVindexes(t, cases)
+}
+
+func TestRangeMapMap(t *testing.T) {
+	rangeMapVindex := mustCreateRangeMap(map[string]string{"json": rangeMapTestJSON})
+	got, err := rangeMapVindex.Map(context.Background(), nil, []sqltypes.Value{
+		sqltypes.NewInt64(-1),
+		sqltypes.NewInt64(0),
+		sqltypes.NewInt64(99),
+		sqltypes.NewInt64(100),
+		sqltypes.NewVarChar("250"),
+		sqltypes.NewInt64(1000),
+		sqltypes.NULL,
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationNone{},
+		key.DestinationKeyspaceID([]byte("\x10")),
+		key.DestinationKeyspaceID([]byte("\x10")),
+		key.DestinationKeyspaceID([]byte("\x50")),
+		key.DestinationKeyspaceID([]byte("\x90")),
+		key.DestinationKeyspaceID([]byte("\x10")),
+		key.DestinationNone{},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestRangeMapVerify(t *testing.T) {
+	rangeMapVindex := mustCreateRangeMap(map[string]string{"json": rangeMapTestJSON})
+	got, err := rangeMapVindex.Verify(context.Background(), nil,
+		[]sqltypes.Value{sqltypes.NewInt64(1), sqltypes.NewInt64(150), sqltypes.NewInt64(-1)},
+		[][]byte{[]byte("\x10"), []byte("\x10"), []byte("\x10")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, got)
+}
+
+func TestRangeMapReverseMap(t *testing.T) {
+	rangeMapVindex := mustCreateRangeMap(map[string]string{"json": rangeMapTestJSON})
+	got, err := rangeMapVindex.(Reversible).ReverseMap(nil, [][]byte{[]byte("\x50"), []byte("\x10")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"100", "0"}, []string{got[0].ToString(), got[1].ToString()})
+
+	_, err = rangeMapVindex.(Reversible).ReverseMap(nil, [][]byte{[]byte("\x20")})
+	require.EqualError(t, err, "RangeMap: no range found for keyspace id 20")
+}
+
+func TestRangeMapHash(t *testing.T) {
+	rangeMapVindex := mustCreateRangeMap(map[string]string{"json": rangeMapTestJSON})
+	got, err := rangeMapVindex.(Hashing).Hash(sqltypes.NewInt64(200))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\x90"), got)
+
+	_, err = rangeMapVindex.(Hashing).Hash(sqltypes.NewInt64(-5))
+	require.EqualError(t, err, "RangeMap: no range found for value -5")
+}
+
+func TestRangeMapRangeMap(t *testing.T) {
+	rangeMapVindex := mustCreateRangeMap(map[string]string{"json": rangeMapTestJSON})
+	tcases := []struct {
+		name       string
+		start, end sqltypes.Value
+		want       []key.Destination
+	}{{
+		name:  "single range",
+		start: sqltypes.NewInt64(10),
+		end:   sqltypes.NewInt64(20),
+		want:  []key.Destination{key.DestinationKeyspaceID("\x10")},
+	}, {
+		name:  "overlapping ranges",
+		start: sqltypes.NewInt64(50),
+		end:   sqltypes.NewInt64(200),
+		want:  []key.Destination{key.DestinationKeyspaceID("\x10"), key.DestinationKeyspaceID("\x50"), key.DestinationKeyspaceID("\x90")},
+	}, {
+		name:  "keyspace ids are not repeated",
+		start: sqltypes.NewInt64(-50),
+		end:   sqltypes.NewInt64(500),
+		want:  []key.Destination{key.DestinationKeyspaceID("\x10"), key.DestinationKeyspaceID("\x50"), key.DestinationKeyspaceID("\x90")},
+	}, {
+		name:  "unbounded start",
+		start: sqltypes.NULL,
+		end:   sqltypes.NewInt64(100),
+		want:  []key.Destination{key.DestinationKeyspaceID("\x10"), key.DestinationKeyspaceID("\x50")},
+	}, {
+		name:  "unbounded end",
+		start: sqltypes.NewInt64(250),
+		end:   sqltypes.NULL,
+		want:  []key.Destination{key.DestinationKeyspaceID("\x90"), key.DestinationKeyspaceID("\x10")},
+	}, {
+		name:  "range below the first split point",
+		start: sqltypes.NewInt64(-50),
+		end:   sqltypes.NewInt64(-10),
+		want:  []key.Destination{key.DestinationNone{}},
+	}, {
+		name:  "empty range",
+		start: sqltypes.NewInt64(250),
+		end:   sqltypes.NewInt64(150),
+		want:  []key.Destination{key.DestinationNone{}},
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			got, err := rangeMapVindex.RangeMap(context.Background(), nil, tcase.start, tcase.end, sqltypes.Unknown, collations.Unknown)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, got)
+		})
+	}
+}
+
+func TestRangeMapDatetime(t *testing.T) {
+	vindex := mustCreateRangeMap(map[string]string{
+		"json": `[
+			{"from": "2023-01-01 00:00:00", "keyspace_id": "40"},
+			{"from": "2023-02-01 00:00:00", "keyspace_id": "80"},
+			{"from": "2023-03-01 00:00:00", "keyspace_id": "c0"}
+		]`,
+		"value_type": "datetime",
+	})
+	got, err := vindex.RangeMap(context.Background(), nil, sqltypes.NewVarChar("2023-01-15 00:00:00"), sqltypes.NewVarChar("2023-02-15 00:00:00"), sqltypes.Unknown, collations.Unknown)
+	require.NoError(t, err)
+	assert.Equal(t, []key.Destination{key.DestinationKeyspaceID("\x40"), key.DestinationKeyspaceID("\x80")}, got)
+}
+
+const multiColRangeMapTestJSON = `[
+	{"from": ["1", "2023-01-01 00:00:00"], "keyspace_id": "10"},
+	{"from": ["1", "2023-06-01 00:00:00"], "keyspace_id": "50"},
+	{"from": ["2", "2023-01-01 00:00:00"], "keyspace_id": "90"},
+	{"from": ["4", "2023-01-01 00:00:00"], "keyspace_id": "10"}
+]`
+
+func mustCreateMultiColRangeMap() MultiColumn {
+	vindex, err := CreateVindex("range_map", "range_map", map[string]string{
+		"json":       multiColRangeMapTestJSON,
+		"value_type": "int64, datetime",
+	})
+	if err != nil {
+		panic(err)
+	}
+	return vindex.(MultiColumn)
+}
+
+func TestMultiColRangeMapCreateVindex(t *testing.T) {
+	_, err := CreateVindex("range_map", "range_map", map[string]string{
+		"json":       `[{"from": ["1"], "keyspace_id": "10"}]`,
+		"value_type": "int64,datetime",
+	})
+	require.EqualError(t, err, "RangeMap: split point '1' must have 2 values")
+
+	_, err = CreateVindex("range_map", "range_map", map[string]string{
+		"json":       `[{"from": ["1", "2023-01-01 00:00:00"], "keyspace_id": "10"}, {"from": ["1", "2022-01-01 00:00:00"], "keyspace_id": "20"}]`,
+		"value_type": "int64,datetime",
+	})
+	require.EqualError(t, err, "RangeMap: split points must be in strictly ascending order, got '(1, 2022-01-01 00:00:00)' after '(1, 2023-01-01 00:00:00)'")
+
+	vindex := mustCreateMultiColRangeMap()
+	assert.True(t, vindex.IsUnique())
+	assert.True(t, vindex.PartialVindex())
+}
+
+func TestMultiColRangeMapMap(t *testing.T) {
+	vindex := mustCreateMultiColRangeMap()
+	got, err := vindex.Map(context.Background(), nil, [][]sqltypes.Value{
+		{sqltypes.NewInt64(0), sqltypes.NewVarChar("2023-03-01 00:00:00")},
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("2023-03-01 00:00:00")},
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("2023-06-01 00:00:00")},
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("2024-01-01 00:00:00")},
+		{sqltypes.NewInt64(2), sqltypes.NewVarChar("2022-01-01 00:00:00")},
+		{sqltypes.NewInt64(3), sqltypes.NewVarChar("2022-01-01 00:00:00")},
+		{sqltypes.NewInt64(5), sqltypes.NewVarChar("2022-01-01 00:00:00")},
+		{sqltypes.NewInt64(2), sqltypes.NULL},
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationNone{},
+		key.DestinationKeyspaceID("\x10"),
+		key.DestinationKeyspaceID("\x50"),
+		key.DestinationKeyspaceID("\x50"),
+		key.DestinationKeyspaceID("\x50"),
+		key.DestinationKeyspaceID("\x90"),
+		key.DestinationKeyspaceID("\x10"),
+		key.DestinationNone{},
+	}
+	assert.Equal(t, want, got)
+
+	_, err = vindex.Map(context.Background(), nil, [][]sqltypes.Value{{sqltypes.NewInt64(1), sqltypes.NewInt64(2), sqltypes.NewInt64(3)}})
+	require.EqualError(t, err, "[BUG] wrong number of column values were passed: maximum allowed 2, got 3")
+}
+
+func TestMultiColRangeMapMapPartial(t *testing.T) {
+	vindex := mustCreateMultiColRangeMap()
+	got, err := vindex.Map(context.Background(), nil, [][]sqltypes.Value{
+		{sqltypes.NewInt64(0)},
+		{sqltypes.NewInt64(1)},
+		{sqltypes.NewInt64(2)},
+		{sqltypes.NewInt64(3)},
+		{sqltypes.NewInt64(4)},
+		{sqltypes.NULL},
+	})
+	require.NoError(t, err)
+	want := []key.Destination{
+		key.DestinationNone{},
+		key.DestinationKeyspaceIDs{[]byte("\x10"), []byte("\x50")},
+		key.DestinationKeyspaceIDs{[]byte("\x50"), []byte("\x90")},
+		key.DestinationKeyspaceIDs{[]byte("\x90")},
+		key.DestinationKeyspaceIDs{[]byte("\x90"), []byte("\x10")},
+		key.DestinationNone{},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestMultiColRangeMapVerify(t *testing.T) {
+	vindex := mustCreateMultiColRangeMap()
+	got, err := vindex.Verify(context.Background(), nil, [][]sqltypes.Value{
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("2023-07-01 00:00:00")},
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("2023-02-01 00:00:00")},
+		{sqltypes.NewInt64(0), sqltypes.NewVarChar("2023-02-01 00:00:00")},
+	}, [][]byte{[]byte("\x50"), []byte("\x50"), []byte("\x10")})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, false}, got)
+}
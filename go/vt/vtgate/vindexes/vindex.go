@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
+	"time"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
@@ -44,6 +45,11 @@ type (
 		InTransactionAndIsDML() bool
 		LookupRowLockShardSession() vtgatepb.CommitOrder
 		ConnCollation() collations.ID
+
+		// VReplicationLag returns the highest lag of the streams of the vreplication
+		// workflow running on the primary tablets of the keyspace. It fails if a shard
+		// of the keyspace has no healthy primary or no running stream of the workflow.
+		VReplicationLag(ctx context.Context, keyspace, workflow string) (time.Duration, error)
 	}
 
 	// Vindex defines the interface required to register a vindex.
@@ -136,6 +142,18 @@ type (
 		PrefixVindex() SingleColumn
 	}
 
+	// A RangeMapper vindex is one that can map a range of ids to the
+	// destinations that hold them. It's being used to reduce the fan out
+	// for range predicates like 'BETWEEN'.
+	// Both bounds are inclusive, and a NULL bound means the range is unbounded
+	// on that side. The type and collation are the ones of the vindex column,
+	// which are sqltypes.Unknown and collations.Unknown if the type of the
+	// column is not known.
+	RangeMapper interface {
+		SingleColumn
+		RangeMap(ctx context.Context, vcursor VCursor, start, end sqltypes.Value, typ sqltypes.Type, collation collations.ID) ([]key.Destination, error)
+	}
+
 	// A Lookup vindex is one that needs to lookup
 	// a previously stored map to compute the keyspace
 	// id from an id. This means that the creation of
@@ -170,6 +188,12 @@ type (
 		IsBackfilling() bool
 	}
 
+	// LookupAsync interfaces all lookup vindexes that can be maintained asynchronously
+	// by a vreplication stream instead of by vtgate, such as LookupUnique.
+	LookupAsync interface {
+		IsAsync() bool
+	}
+
 	// WantOwnerInfo defines the interface that a vindex must
 	// satisfy to request info about the owner table. This information can
 	// be used to query the owner's table for the owning row's presence.
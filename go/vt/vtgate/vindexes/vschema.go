@@ -106,6 +106,18 @@ type Table struct {
 	Columns                 []Column               `json:"columns,omitempty"`
 	Pinned                  []byte                 `json:"pinned,omitempty"`
 	ColumnListAuthoritative bool                   `json:"column_list_authoritative,omitempty"`
+	// AllowPrimaryVindexUpdate allows the updates of the primary vindex columns,
+	// which move the updated rows to their new shard.
+	AllowPrimaryVindexUpdate bool `json:"allow_primary_vindex_update,omitempty"`
+	// ResultCacheTTLMs is the duration in milliseconds for which vtgate caches
+	// the results of the SELECT queries reading the table. 0 disables the cache.
+	ResultCacheTTLMs int64 `json:"result_cache_ttl_ms,omitempty"`
+	// PrimaryKey is the list of columns of the primary key of the table,
+	// as reported by the schema tracker.
+	PrimaryKey sqlparser.Columns `json:"primary_key,omitempty"`
+	// UniqueKeys are the lists of columns of the unique keys of the table,
+	// other than its primary key, as reported by the schema tracker.
+	UniqueKeys []sqlparser.Columns `json:"unique_keys,omitempty"`
 	// ReferencedBy is an inverse mapping of tables in other keyspaces that
 	// reference this table via Source.
 	//
@@ -144,9 +156,11 @@ type ColumnVindex struct {
 	backfill bool
 }
 
-// TableInfo contains column and foreign key info for a table.
+// TableInfo contains column, primary key, unique key and foreign key info for a table.
 type TableInfo struct {
 	Columns     []Column
+	PrimaryKey  sqlparser.Columns
+	UniqueKeys  []sqlparser.Columns
 	ForeignKeys []*sqlparser.ForeignKeyDefinition
 }
 
@@ -178,6 +192,7 @@ type Column struct {
 	Name          sqlparser.IdentifierCI `json:"name"`
 	Type          querypb.Type           `json:"type"`
 	CollationName string                 `json:"collation_name"`
+	Generated     bool                   `json:"generated,omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of Column.
@@ -538,9 +553,18 @@ func buildTables(ks *vschemapb.Keyspace, vschema *VSchema, ksvschema *KeyspaceSc
 	}
 	for tname, table := range ks.Tables {
 		t := &Table{
-			Name:                    sqlparser.NewIdentifierCS(tname),
-			Keyspace:                keyspace,
-			ColumnListAuthoritative: table.ColumnListAuthoritative,
+			Name:                     sqlparser.NewIdentifierCS(tname),
+			Keyspace:                 keyspace,
+			ColumnListAuthoritative:  table.ColumnListAuthoritative,
+			AllowPrimaryVindexUpdate: table.AllowPrimaryVindexUpdate,
+			ResultCacheTTLMs:         table.ResultCacheTtlMs,
+		}
+		if table.ResultCacheTtlMs < 0 {
+			return vterrors.Errorf(
+				vtrpcpb.Code_INVALID_ARGUMENT,
+				"negative result cache ttl for table: %s",
+				tname,
+			)
 		}
 		switch table.Type {
 		case "":
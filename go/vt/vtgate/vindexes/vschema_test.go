@@ -2269,6 +2269,29 @@ func TestBadShardedSequence(t *testing.T) {
 	}
 }
 
+func TestResultCacheTTL(t *testing.T) {
+	input := vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"unsharded": {
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ResultCacheTtlMs: 1500,
+					},
+					"t2": {},
+				},
+			},
+		},
+	}
+	got := BuildVSchema(&input)
+	require.NoError(t, got.Keyspaces["unsharded"].Error)
+	assert.EqualValues(t, 1500, got.Keyspaces["unsharded"].Tables["t1"].ResultCacheTTLMs)
+	assert.Zero(t, got.Keyspaces["unsharded"].Tables["t2"].ResultCacheTTLMs)
+
+	input.Keyspaces["unsharded"].Tables["t2"].ResultCacheTtlMs = -1
+	got = BuildVSchema(&input)
+	assert.EqualError(t, got.Keyspaces["unsharded"].Error, "negative result cache ttl for table: t2")
+}
+
 func TestFindTable(t *testing.T) {
 	input := vschemapb.SrvVSchema{
 		Keyspaces: map[string]*vschemapb.Keyspace{
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// vreplicationLagTTL is the duration for which the lag of a vreplication workflow is reused.
+// It is short compared to the lag allowed for the async lookup vindexes, and saves reading
+// the vreplication streams of every shard on each query using these vindexes.
+const vreplicationLagTTL = time.Second
+
+// vreplicationLagCache caches the lag of the vreplication workflows maintaining the tables
+// of async lookup vindexes.
+type vreplicationLagCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*vreplicationLagEntry
+}
+
+// vreplicationLagEntry is the cached lag of a workflow. Its lock is held while the lag is
+// read from the shards, so that concurrent queries wait for the same read.
+type vreplicationLagEntry struct {
+	mu      sync.Mutex
+	lag     time.Duration
+	err     error
+	expires time.Time
+}
+
+func newVReplicationLagCache(ttl time.Duration) *vreplicationLagCache {
+	return &vreplicationLagCache{
+		ttl:     ttl,
+		entries: make(map[string]*vreplicationLagEntry),
+	}
+}
+
+// get returns the cached lag of the workflow of the keyspace, and uses fetch to read it again
+// once it expired. The errors are cached as well, to not read the lag of an unavailable workflow
+// on every query.
+func (c *vreplicationLagCache) get(ctx context.Context, keyspace, workflow string, fetch func(context.Context) (time.Duration, error)) (time.Duration, error) {
+	key := keyspace + "/" + workflow
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &vreplicationLagEntry{}
+		c.entries[key] = entry
+	}
+	c.mu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if now := time.Now(); now.After(entry.expires) {
+		entry.lag, entry.err = fetch(ctx)
+		entry.expires = now.Add(c.ttl)
+	}
+	return entry.lag, entry.err
+}
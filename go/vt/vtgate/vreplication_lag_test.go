@@ -0,0 +1,62 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVReplicationLagCache tests that the lag of a workflow is only read again once it expired.
+func TestVReplicationLagCache(t *testing.T) {
+	ctx := context.Background()
+	cache := newVReplicationLagCache(time.Hour)
+
+	fetches := 0
+	fetch := func(context.Context) (time.Duration, error) {
+		fetches++
+		return time.Duration(fetches) * time.Second, nil
+	}
+
+	lag, err := cache.get(ctx, "ks", "lkp_vdx", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lag)
+	lag, err = cache.get(ctx, "ks", "lkp_vdx", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, lag)
+	assert.Equal(t, 1, fetches)
+
+	// the workflows are cached separately
+	lag, err = cache.get(ctx, "ks", "other_vdx", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, lag)
+
+	// the errors are cached too, and the lag is read again once it expired
+	cache = newVReplicationLagCache(0)
+	_, err = cache.get(ctx, "ks", "lkp_vdx", func(context.Context) (time.Duration, error) {
+		return 0, errors.New("stream is not running")
+	})
+	require.EqualError(t, err, "stream is not running")
+	lag, err = cache.get(ctx, "ks", "lkp_vdx", fetch)
+	require.NoError(t, err)
+	assert.Equal(t, 3*time.Second, lag)
+}
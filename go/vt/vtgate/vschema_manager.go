@@ -199,7 +199,9 @@ func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 		// are created in the Vschema, so that later when we try to find the routed tables, we don't end up
 		// getting dummy tables.
 		for tblName, tblInfo := range m {
-			setColumns(ks, tblName, tblInfo.Columns)
+			vTbl := setColumns(ks, tblName, tblInfo.Columns)
+			vTbl.PrimaryKey = tblInfo.PrimaryKey
+			vTbl.UniqueKeys = tblInfo.UniqueKeys
 		}
 
 		// Now that we have ensured that all the tables are created, we can start populating the foreign keys
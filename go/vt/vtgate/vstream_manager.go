@@ -21,10 +21,14 @@ import (
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/mysql/replication"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/discovery"
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -141,6 +145,17 @@ func newVStreamManager(resolver *srvtopo.Resolver, serv srvtopo.Server, cell str
 
 func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
 	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags, send func(events []*binlogdatapb.VEvent) error) error {
+	if name := flags.GetCursor(); name != "" {
+		// The stream resumes from the position of the cursor when it exists,
+		// and otherwise starts from vgtid.
+		cursor, err := vsm.getCursor(ctx, name)
+		if err != nil {
+			return err
+		}
+		if cursor != nil {
+			vgtid = cursor
+		}
+	}
 	vgtid, filter, flags, err := vsm.resolveParams(ctx, tabletType, vgtid, filter, flags)
 	if err != nil {
 		return err
@@ -178,6 +193,125 @@ func (vsm *vstreamManager) VStream(ctx context.Context, tabletType topodatapb.Ta
 	return vs.stream(ctx)
 }
 
+// CurrentVGtid returns the current positions of all the shards of a keyspace, read from their tablets
+// of the given type. A stream started from them gets all the changes made after they were read.
+func (vsm *vstreamManager) CurrentVGtid(ctx context.Context, tabletType topodatapb.TabletType, keyspace string) (*binlogdatapb.VGtid, error) {
+	rss, _, err := vsm.resolver.GetAllShards(ctx, keyspace, tabletType)
+	if err != nil {
+		return nil, err
+	}
+	vgtid := &binlogdatapb.VGtid{}
+	for _, rs := range rss {
+		qr, err := rs.Gateway.Execute(ctx, rs.Target, "select @@global.gtid_executed", nil, 0, 0, nil)
+		if err != nil {
+			return nil, err
+		}
+		if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for the position of shard %s/%s: %v", rs.Target.Keyspace, rs.Target.Shard, qr.Rows)
+		}
+		gtidSet, err := replication.ParseMysql56GTIDSet(qr.Rows[0][0].ToString())
+		if err != nil {
+			return nil, err
+		}
+		vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{
+			Keyspace: rs.Target.Keyspace,
+			Shard:    rs.Target.Shard,
+			Gtid:     replication.EncodePosition(replication.Position{GTIDSet: gtidSet}),
+		})
+	}
+	return vgtid, nil
+}
+
+// VStreamAck merges vgtid into the position of the named cursor. The position
+// of every shard of vgtid is only saved if the cursor isn't already past it, so
+// that the consumers sharing a cursor never move it backwards, and the shards
+// of the cursor missing from vgtid keep their position.
+func (vsm *vstreamManager) VStreamAck(ctx context.Context, name string, vgtid *binlogdatapb.VGtid) error {
+	if len(vgtid.GetShardGtids()) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vgtid must have at least one value with a position")
+	}
+	ts, err := vsm.toposerv.GetTopoServer()
+	if err != nil {
+		return err
+	}
+	for {
+		current, version, err := ts.GetVStreamCursor(ctx, name)
+		switch {
+		case topo.IsErrType(err, topo.NoNode):
+			current = nil
+		case err != nil:
+			return err
+		}
+		merged, changed := mergeAck(current, vgtid)
+		if !changed {
+			return nil
+		}
+		_, err = ts.UpdateVStreamCursor(ctx, name, merged, version)
+		if !topo.IsErrType(err, topo.BadVersion) && !topo.IsErrType(err, topo.NodeExists) {
+			return err
+		}
+		// Another consumer of the cursor has saved its position concurrently.
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// getCursor returns the position of the named cursor, or nil if it doesn't exist.
+func (vsm *vstreamManager) getCursor(ctx context.Context, name string) (*binlogdatapb.VGtid, error) {
+	ts, err := vsm.toposerv.GetTopoServer()
+	if err != nil {
+		return nil, err
+	}
+	vgtid, _, err := ts.GetVStreamCursor(ctx, name)
+	if topo.IsErrType(err, topo.NoNode) {
+		return nil, nil
+	}
+	return vgtid, err
+}
+
+// mergeAck returns the position of cursor updated with the positions of the
+// shards of ack, and whether it has changed. The position of a shard is replaced
+// unless the cursor is strictly past it. Positions that can't be compared, like
+// the ones of shards that are being copied, are always replaced. The shards of
+// cursor missing from ack are kept as they are.
+func mergeAck(cursor, ack *binlogdatapb.VGtid) (*binlogdatapb.VGtid, bool) {
+	merged := cursor.CloneVT()
+	if merged == nil {
+		merged = &binlogdatapb.VGtid{}
+	}
+	changed := false
+	for _, sgtid := range ack.ShardGtids {
+		i := slices.IndexFunc(merged.ShardGtids, func(csgtid *binlogdatapb.ShardGtid) bool {
+			return csgtid.Keyspace == sgtid.Keyspace && csgtid.Shard == sgtid.Shard
+		})
+		switch {
+		case i < 0:
+			merged.ShardGtids = append(merged.ShardGtids, sgtid.CloneVT())
+			changed = true
+		case isAckBehind(sgtid, merged.ShardGtids[i]):
+		case !proto.Equal(sgtid, merged.ShardGtids[i]):
+			merged.ShardGtids[i] = sgtid.CloneVT()
+			changed = true
+		}
+	}
+	return merged, changed
+}
+
+// isAckBehind returns true if the position of a shard in an ack is strictly
+// behind its position in the cursor.
+func isAckBehind(ack, cursor *binlogdatapb.ShardGtid) bool {
+	ackPos, err := replication.DecodePosition(ack.Gtid)
+	if err != nil {
+		return false
+	}
+	cursorPos, err := replication.DecodePosition(cursor.Gtid)
+	if err != nil {
+		return false
+	}
+	return cursorPos.AtLeast(ackPos) && !cursorPos.Equal(ackPos)
+}
+
 // resolveParams provides defaults for the inputs if they're not specified.
 func (vsm *vstreamManager) resolveParams(ctx context.Context, tabletType topodatapb.TabletType, vgtid *binlogdatapb.VGtid,
 	filter *binlogdatapb.Filter, flags *vtgatepb.VStreamFlags) (*binlogdatapb.VGtid, *binlogdatapb.Filter, *vtgatepb.VStreamFlags, error) {
@@ -30,6 +30,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/srvtopo"
@@ -1192,6 +1193,161 @@ func TestVStreamIdleHeartbeat(t *testing.T) {
 	}
 }
 
+func TestVStreamCursor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "aa"
+	ks := "TestVStream"
+	_ = createSandbox(ks)
+	hc := discovery.NewFakeHealthCheck(nil)
+	st := getSandboxTopo(ctx, cell, ks, []string{"-20"})
+
+	vsm := newTestVStreamManager(ctx, hc, st, cell)
+	sbc0 := hc.AddTestTablet(cell, "1.1.1.1", 1001, ks, "-20", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "-20", sbc0.Tablet())
+
+	vgtid := func(gtid string) *binlogdatapb.VGtid {
+		return &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{Keyspace: ks, Shard: "-20", Gtid: gtid}}}
+	}
+	cursor, err := vsm.getCursor(ctx, "orders")
+	require.NoError(t, err)
+	assert.Nil(t, cursor)
+
+	// the stream starts from the position of the cursor, and not from the requested one
+	require.NoError(t, st.topoServer.SaveVStreamCursor(ctx, "orders", vgtid("cursorpos")))
+	sbc0.StartPos = "cursorpos"
+	sbc0.AddVStreamEvents([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_GTID, Gtid: "gtid01"},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}, nil)
+	ch := startVStream(ctx, t, vsm, vgtid("pos"), &vtgatepb.VStreamFlags{Cursor: "orders"})
+	verifyEvents(t, ch, &binlogdatapb.VStreamResponse{Events: []*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_VGTID, Vgtid: vgtid("gtid01")},
+		{Type: binlogdatapb.VEventType_COMMIT},
+	}})
+
+	// the position of the cursor is only updated by an ack
+	cursor, err = vsm.getCursor(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "cursorpos", cursor.ShardGtids[0].Gtid)
+}
+
+func TestVStreamCurrentVGtid(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cell := "aa"
+	ks := "TestVStream"
+	sandbox := createSandbox(ks)
+	sandbox.ShardSpec = "-80-"
+	hc := discovery.NewFakeHealthCheck(nil)
+	st := getSandboxTopo(ctx, cell, ks, []string{"-80", "80-"})
+
+	vsm := newTestVStreamManager(ctx, hc, st, cell)
+	sbc0 := hc.AddTestTablet(cell, "1.1.1.1", 1001, ks, "-80", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "-80", sbc0.Tablet())
+	sbc1 := hc.AddTestTablet(cell, "1.1.1.1", 1002, ks, "80-", topodatapb.TabletType_PRIMARY, true, 1, nil)
+	addTabletToSandboxTopo(t, ctx, st, ks, "80-", sbc1.Tablet())
+
+	fields := sqltypes.MakeTestFields("@@global.gtid_executed", "varchar")
+	sbc0.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5")})
+	sbc1.SetResults([]*sqltypes.Result{sqltypes.MakeTestResult(fields, "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-3,\n4e11fa47-71ca-11e1-9e33-c80aa9429562:1-2")})
+
+	vgtid, err := vsm.CurrentVGtid(ctx, topodatapb.TabletType_PRIMARY, ks)
+	require.NoError(t, err)
+	assert.Equal(t, []*binlogdatapb.ShardGtid{{
+		Keyspace: ks,
+		Shard:    "-80",
+		Gtid:     "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+	}, {
+		Keyspace: ks,
+		Shard:    "80-",
+		Gtid:     "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562:1-3,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-2",
+	}}, vgtid.ShardGtids)
+}
+
+func TestVStreamAck(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+	st := getSandboxTopo(ctx, "aa", "TestVStream", []string{"-80", "80-"})
+	vsm := newVStreamManager(nil, st, "aa")
+
+	const uuid = "MySQL56/3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	vgtid := func(gtids ...string) *binlogdatapb.VGtid {
+		vgtid := &binlogdatapb.VGtid{}
+		for i, shard := range []string{"-80", "80-"}[:len(gtids)] {
+			vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{Keyspace: "TestVStream", Shard: shard, Gtid: gtids[i]})
+		}
+		return vgtid
+	}
+	testcases := []struct {
+		name string
+		ack  *binlogdatapb.VGtid
+		want *binlogdatapb.VGtid
+	}{{
+		name: "create",
+		ack:  vgtid(uuid+":1-10", uuid+":1-20"),
+		want: vgtid(uuid+":1-10", uuid+":1-20"),
+	}, {
+		name: "forward",
+		ack:  vgtid(uuid+":1-12", uuid+":1-20"),
+		want: vgtid(uuid+":1-12", uuid+":1-20"),
+	}, {
+		name: "backward",
+		ack:  vgtid(uuid+":1-11", uuid+":1-20"),
+		want: vgtid(uuid+":1-12", uuid+":1-20"),
+	}, {
+		name: "mixed",
+		ack:  vgtid(uuid+":1-11", uuid+":1-21"),
+		want: vgtid(uuid+":1-12", uuid+":1-21"),
+	}, {
+		name: "partial",
+		ack:  vgtid(uuid + ":1-13"),
+		want: vgtid(uuid+":1-13", uuid+":1-21"),
+	}, {
+		name: "partial backward",
+		ack: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "TestVStream",
+			Shard:    "80-",
+			Gtid:     uuid + ":1-15",
+		}}},
+		want: vgtid(uuid+":1-13", uuid+":1-21"),
+	}, {
+		name: "copy progress",
+		ack: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "TestVStream",
+			Shard:    "-80",
+			Gtid:     uuid + ":1-13",
+			TablePKs: []*binlogdatapb.TableLastPK{{TableName: "t1"}},
+		}}},
+		want: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+			Keyspace: "TestVStream",
+			Shard:    "-80",
+			Gtid:     uuid + ":1-13",
+			TablePKs: []*binlogdatapb.TableLastPK{{TableName: "t1"}},
+		}, {
+			Keyspace: "TestVStream",
+			Shard:    "80-",
+			Gtid:     uuid + ":1-21",
+		}}},
+	}, {
+		name: "not a position",
+		ack:  vgtid("current"),
+		want: vgtid("current", uuid+":1-21"),
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			require.NoError(t, vsm.VStreamAck(ctx, "orders", tcase.ack))
+			got, err := vsm.getCursor(ctx, "orders")
+			require.NoError(t, err)
+			utils.MustMatch(t, tcase.want, got)
+		})
+	}
+
+	err := vsm.VStreamAck(ctx, "orders", &binlogdatapb.VGtid{})
+	assert.EqualError(t, err, "vgtid must have at least one value with a position")
+	err = vsm.VStreamAck(ctx, "", vgtid(uuid+":1-10"))
+	assert.EqualError(t, err, `invalid VStream cursor name: ""`)
+}
+
 func newTestVStreamManager(ctx context.Context, hc discovery.HealthCheck, serv srvtopo.Server, cell string) *vstreamManager {
 	gw := NewTabletGateway(ctx, hc, serv, cell)
 	srvResolver := srvtopo.NewResolver(serv, gw, cell)
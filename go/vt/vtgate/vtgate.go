@@ -115,6 +115,12 @@ var (
 	warmingReadsPercent      = 0
 	warmingReadsQueryTimeout = 5 * time.Second
 	warmingReadsConcurrency  = 500
+
+	// sequenceCacheSize is the number of values of each sequence reserved at once by vtgate
+	sequenceCacheSize int64
+
+	// resultCacheMemory is the memory of the cache of the results of the SELECT queries
+	resultCacheMemory int64
 )
 
 func registerFlags(fs *pflag.FlagSet) {
@@ -151,6 +157,8 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&warmingReadsPercent, "warming-reads-percent", 0, "Percentage of reads on the primary to forward to replicas. Useful for keeping buffer pools warm")
 	fs.IntVar(&warmingReadsConcurrency, "warming-reads-concurrency", 500, "Number of concurrent warming reads allowed")
 	fs.DurationVar(&warmingReadsQueryTimeout, "warming-reads-query-timeout", 5*time.Second, "Timeout of warming read queries")
+	fs.Int64Var(&sequenceCacheSize, "sequence-cache-size", sequenceCacheSize, "Number of values of each sequence that vtgate reserves at once and serves from memory. 0 disables the cache, and every insert fetches its values from the sequence tablet")
+	fs.Int64Var(&resultCacheMemory, "result-cache-memory", resultCacheMemory, "Maximum memory in bytes used by the cache of the results of the SELECT queries enabling it with the RESULT_CACHE_TTL_MS directive or the result_cache_ttl_ms of their tables in the VSchema. 0 disables the cache")
 
 	_ = fs.String("schema_change_signal_user", "", "User to be used to send down query to vttablet to retrieve schema changes")
 	_ = fs.MarkDeprecated("schema_change_signal_user", "schema tracking uses an internal api and does not require a user to be specified")
@@ -333,6 +341,20 @@ func Init(
 		log.Fatalf("error initializing query logger: %v", err)
 	}
 
+	if resultCacheMemory > 0 {
+		// when being endtoend tested, disable the doorkeeper to ensure reproducible results
+		executor.resultCache = newResultCache(resultCacheMemory, !servenv.TestingEndtoend, vsm)
+		stats.NewGaugeFunc("ResultCacheLength", "Result cache length", func() int64 {
+			return int64(executor.resultCache.store.Len())
+		})
+		stats.NewGaugeFunc("ResultCacheSize", "Result cache size", func() int64 {
+			return int64(executor.resultCache.store.UsedCapacity())
+		})
+		stats.NewGaugeFunc("ResultCacheCapacity", "Result cache capacity", func() int64 {
+			return int64(executor.resultCache.store.MaxCapacity())
+		})
+	}
+
 	// connect the schema tracker with the vschema manager
 	if enableSchemaChangeSignal {
 		st.RegisterSignalReceiver(executor.vm.Rebuild)
@@ -583,6 +605,12 @@ func (vtg *VTGate) VStream(ctx context.Context, tabletType topodatapb.TabletType
 	return vtg.vsm.VStream(ctx, tabletType, vgtid, filter, flags, send)
 }
 
+// VStreamAck merges vgtid into the position of the named VStream cursor,
+// shard by shard.
+func (vtg *VTGate) VStreamAck(ctx context.Context, cursor string, vgtid *binlogdatapb.VGtid) error {
+	return vtg.vsm.VStreamAck(ctx, cursor, vgtid)
+}
+
 // GetGatewayCacheStatus returns a displayable version of the Gateway cache.
 func (vtg *VTGate) GetGatewayCacheStatus() TabletCacheStatusList {
 	return vtg.gw.CacheStatus()
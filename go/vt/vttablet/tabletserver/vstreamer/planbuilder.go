@@ -81,6 +81,9 @@ const (
 	NotEqual
 	// IsNotNull is used to filter a column if it is NULL
 	IsNotNull
+	// Expression is used to filter a row with an arbitrary expression,
+	// which must evaluate to true for the row to match
+	Expression
 )
 
 // Filter contains opcodes for filtering.
@@ -97,6 +100,10 @@ type Filter struct {
 	Vindex        vindexes.Vindex
 	VindexColumns []int
 	KeyRange      *topodatapb.KeyRange
+
+	// Expr is the expression evaluated against the row for Expression.
+	// Its columns are the column numbers of the table.
+	Expr evalengine.Expr
 }
 
 // ColExpr represents a column expression.
@@ -114,6 +121,12 @@ type ColExpr struct {
 	Field *querypb.Field
 
 	FixedValue sqltypes.Value
+
+	// Expr, if set, is evaluated against the row to compute the value
+	// of the column. If so, ColNum is ignored.
+	// The columns of Expr are the column numbers of the table,
+	// and not the column numbers of the stream to be sent.
+	Expr evalengine.Expr
 }
 
 // Table contains the metadata for a table.
@@ -216,8 +229,21 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 	if len(result) != len(plan.ColExprs) {
 		return false, fmt.Errorf("expected %d values in result slice", len(plan.ColExprs))
 	}
+	// env is only created if the plan has expressions to evaluate.
+	var env *evalengine.ExpressionEnv
 	for _, filter := range plan.Filters {
 		switch filter.Opcode {
+		case Expression:
+			if env == nil {
+				env = newExpressionEnv(values)
+			}
+			res, err := env.Evaluate(filter.Expr)
+			if err != nil {
+				return false, err
+			}
+			if !res.ToBoolean() {
+				return false, nil
+			}
 		case VindexMatch:
 			ksid, err := getKeyspaceID(values, filter.Vindex, filter.VindexColumns, plan.Table.Fields)
 			if err != nil {
@@ -241,6 +267,17 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 		}
 	}
 	for i, colExpr := range plan.ColExprs {
+		if colExpr.Expr != nil {
+			if env == nil {
+				env = newExpressionEnv(values)
+			}
+			res, err := env.Evaluate(colExpr.Expr)
+			if err != nil {
+				return false, err
+			}
+			result[i] = res.Value(collations.Default())
+			continue
+		}
 		if colExpr.ColNum == -1 {
 			result[i] = colExpr.FixedValue
 			continue
@@ -261,6 +298,13 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 	return true, nil
 }
 
+// newExpressionEnv returns the environment to evaluate the expressions of the plan against a row.
+func newExpressionEnv(values []sqltypes.Value) *evalengine.ExpressionEnv {
+	env := evalengine.EmptyExpressionEnv()
+	env.Row = values
+	return env
+}
+
 func getKeyspaceID(values []sqltypes.Value, vindex vindexes.Vindex, vindexColumns []int, fields []*querypb.Field) (key.DestinationKeyspaceID, error) {
 	vindexValues := make([]sqltypes.Value, 0, len(vindexColumns))
 	for _, col := range vindexColumns {
@@ -510,76 +554,155 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	}
 	exprs := splitAndExpression(nil, where.Expr)
 	for _, expr := range exprs {
-		switch expr := expr.(type) {
-		case *sqlparser.ComparisonExpr:
-			opcode, err := getOpcode(expr)
-			if err != nil {
-				return err
-			}
-			qualifiedName, ok := expr.Left.(*sqlparser.ColName)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			if !qualifiedName.Qualifier.IsEmpty() {
-				return fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
-			}
-			colnum, err := findColumn(plan.Table, qualifiedName.Name)
-			if err != nil {
-				return err
-			}
-			val, ok := expr.Right.(*sqlparser.Literal)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
-			if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			pv, err := evalengine.Translate(val, nil)
-			if err != nil {
-				return err
-			}
-			env := evalengine.EmptyExpressionEnv()
-			resolved, err := env.Evaluate(pv)
-			if err != nil {
-				return err
-			}
-			plan.Filters = append(plan.Filters, Filter{
-				Opcode: opcode,
-				ColNum: colnum,
-				Value:  resolved.Value(collations.Default()),
-			})
-		case *sqlparser.FuncExpr:
-			if !expr.Name.EqualString("in_keyrange") {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-			}
-			if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
-				return err
-			}
-		case *sqlparser.IsExpr: // Needed for CreateLookupVindex with ignore_nulls
-			if expr.Right != sqlparser.IsNotNullOp {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-			}
-			qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+		ok, err := plan.analyzeConstraint(vschema, expr)
+		if err != nil {
+			return err
+		}
+		if ok {
+			continue
+		}
+		// Any other constraint is evaluated against the whole row.
+		if sqlparser.ContainsAggregation(expr) {
+			return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+		}
+		eexpr, err := plan.translateExpr(expr)
+		if err != nil {
+			return err
+		}
+		plan.Filters = append(plan.Filters, Filter{
+			Opcode: Expression,
+			Expr:   eexpr,
+		})
+	}
+	return nil
+}
+
+// analyzeConstraint adds the filter of the constraints that don't need
+// to be evaluated by the evalengine: comparisons of a column with a literal,
+// in_keyrange and IS NOT NULL. It returns false for any other constraint.
+func (plan *Plan) analyzeConstraint(vschema *localVSchema, expr sqlparser.Expr) (bool, error) {
+	switch expr := expr.(type) {
+	case *sqlparser.ComparisonExpr:
+		opcode, err := getOpcode(expr)
+		if err != nil {
+			return false, nil
+		}
+		qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+		if !ok || !qualifiedName.Qualifier.IsEmpty() {
+			return false, nil
+		}
+		val, ok := expr.Right.(*sqlparser.Literal)
+		if !ok {
+			return false, nil
+		}
+		//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
+		if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
+			return false, nil
+		}
+		colnum, err := findColumn(plan.Table, qualifiedName.Name)
+		if err != nil {
+			return false, err
+		}
+		pv, err := evalengine.Translate(val, nil)
+		if err != nil {
+			return false, err
+		}
+		env := evalengine.EmptyExpressionEnv()
+		resolved, err := env.Evaluate(pv)
+		if err != nil {
+			return false, err
+		}
+		plan.Filters = append(plan.Filters, Filter{
+			Opcode: opcode,
+			ColNum: colnum,
+			Value:  resolved.Value(collations.Default()),
+		})
+		return true, nil
+	case *sqlparser.FuncExpr:
+		if !expr.Name.EqualString("in_keyrange") {
+			return false, nil
+		}
+		if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
+			return false, err
+		}
+		return true, nil
+	case *sqlparser.IsExpr: // Needed for CreateLookupVindex with ignore_nulls
+		if expr.Right != sqlparser.IsNotNullOp {
+			return false, nil
+		}
+		qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+		if !ok || !qualifiedName.Qualifier.IsEmpty() {
+			return false, nil
+		}
+		colnum, err := findColumn(plan.Table, qualifiedName.Name)
+		if err != nil {
+			return false, err
+		}
+		plan.Filters = append(plan.Filters, Filter{
+			Opcode: IsNotNull,
+			ColNum: colnum,
+		})
+		return true, nil
+	}
+	return false, nil
+}
+
+// translateExpr translates an expression of the filter, whose columns are
+// the columns of the table, to be evaluated against the rows of the table.
+func (plan *Plan) translateExpr(expr sqlparser.Expr) (evalengine.Expr, error) {
+	return evalengine.Translate(expr, &evalengine.Config{
+		ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+			if !col.Qualifier.IsEmpty() {
+				return 0, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(col))
+			}
+			return findColumn(plan.Table, col.Name)
+		},
+		ResolveType: func(expr sqlparser.Expr) (sqltypes.Type, collations.ID, bool) {
+			col, ok := expr.(*sqlparser.ColName)
 			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			if !qualifiedName.Qualifier.IsEmpty() {
-				return fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
+				return sqltypes.Unknown, collations.Unknown, false
 			}
-			colnum, err := findColumn(plan.Table, qualifiedName.Name)
+			colnum, err := findColumn(plan.Table, col.Name)
 			if err != nil {
-				return err
+				return sqltypes.Unknown, collations.Unknown, false
 			}
-			plan.Filters = append(plan.Filters, Filter{
-				Opcode: IsNotNull,
-				ColNum: colnum,
-			})
-		default:
-			return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-		}
+			field := plan.Table.Fields[colnum]
+			return field.Type, collations.ID(field.Charset), true
+		},
+		Collation: collations.Default(),
+	})
+}
+
+// analyzeComputedExpr builds the column expression of a select expression
+// which is computed from the columns of the row, like "id + 1 as next_id"
+// or "doc->>'$.name' as name".
+func (plan *Plan) analyzeComputedExpr(aliased *sqlparser.AliasedExpr) (ColExpr, error) {
+	eexpr, err := plan.translateExpr(aliased.Expr)
+	if err != nil {
+		return ColExpr{}, err
 	}
-	return nil
+	typ, flags, err := evalengine.EmptyExpressionEnv().TypeOf(eexpr, plan.Table.Fields)
+	if err != nil {
+		return ColExpr{}, fmt.Errorf("cannot compute the type of %v, use an explicit cast: %v", sqlparser.String(aliased.Expr), err)
+	}
+	var charset collations.ID = collations.CollationBinaryID
+	if sqltypes.IsText(typ) {
+		charset = collations.Default()
+	}
+	fieldFlags := mysql.FlagsForColumn(typ, charset)
+	if !sqltypes.IsNull(typ) && !flags.Nullable() {
+		fieldFlags |= uint32(querypb.MySqlFlag_NOT_NULL_FLAG)
+	}
+	return ColExpr{
+		ColNum: -1,
+		Field: &querypb.Field{
+			Name:    aliased.ColumnName(),
+			Type:    typ,
+			Charset: uint32(charset),
+			Flags:   fieldFlags,
+		},
+		Expr: eexpr,
+	}, nil
 }
 
 // splitAndExpression breaks up the Expr into AND-separated conditions
@@ -702,7 +825,7 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 				Field:  field,
 			}, nil
 		default:
-			return ColExpr{}, fmt.Errorf("unsupported function: %v", sqlparser.String(inner))
+			return plan.analyzeComputedExpr(aliased)
 		}
 	case *sqlparser.Literal:
 		//allow only intval 1
@@ -757,8 +880,10 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 			Field:  field,
 		}, nil
 	default:
-		log.Infof("Unsupported expression: %v", inner)
-		return ColExpr{}, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr))
+		if sqlparser.ContainsAggregation(inner) {
+			return ColExpr{}, fmt.Errorf("unsupported function: %v", sqlparser.String(inner))
+		}
+		return plan.analyzeComputedExpr(aliased)
 	}
 }
 
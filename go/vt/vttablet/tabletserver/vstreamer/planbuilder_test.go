@@ -616,8 +616,16 @@ func TestPlanBuilder(t *testing.T) {
 		outErr:  `unsupported function: max(val)`,
 	}, {
 		inTable: t1,
-		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id+1, val from t1"},
-		outErr:  `unsupported: id + 1`,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, unknown(val) from t1"},
+		outErr:  `expr cannot be translated, not supported: unknown(val)`,
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where unknown(val)"},
+		outErr:  `expr cannot be translated, not supported: unknown(val)`,
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where t1.id + 1 > 2"},
+		outErr:  `unsupported qualifier for column: t1.id`,
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select t1.id, val from t1"},
@@ -746,6 +754,75 @@ func TestPlanBuilderFilterComparison(t *testing.T) {
 	}
 }
 
+func TestPlanBuilderExpressions(t *testing.T) {
+	t1 := &Table{
+		Name: "t1",
+		Fields: []*querypb.Field{{
+			Name:    "id",
+			Type:    sqltypes.Int64,
+			Charset: collations.CollationBinaryID,
+			Flags:   uint32(querypb.MySqlFlag_NOT_NULL_FLAG | querypb.MySqlFlag_NUM_FLAG),
+		}, {
+			Name:    "doc",
+			Type:    sqltypes.TypeJSON,
+			Charset: collations.CollationBinaryID,
+			Flags:   uint32(querypb.MySqlFlag_BINARY_FLAG),
+		}},
+	}
+	plan, err := buildPlan(t1, testLocalVSchema, &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match:  "t1",
+			Filter: "select id, id * 10 as score, doc->>'$.name' as name from t1 where json_extract(doc, '$.active') = true and id % 2 = 0",
+		}},
+	})
+	require.NoError(t, err)
+	require.Len(t, plan.Filters, 2)
+	assert.Equal(t, Expression, plan.Filters[0].Opcode)
+	assert.Equal(t, Expression, plan.Filters[1].Opcode)
+
+	fields := plan.fields()
+	require.Len(t, fields, 3)
+	assert.Equal(t, "score", fields[1].Name)
+	assert.Equal(t, sqltypes.Int64, fields[1].Type)
+	assert.Equal(t, "name", fields[2].Name)
+	assert.Equal(t, sqltypes.Blob, fields[2].Type)
+
+	testcases := []struct {
+		id     int64
+		doc    string
+		match  bool
+		result []sqltypes.Value
+	}{{
+		id:     2,
+		doc:    `{"name": "two", "active": true}`,
+		match:  true,
+		result: []sqltypes.Value{sqltypes.NewInt64(2), sqltypes.NewInt64(20), sqltypes.MakeTrusted(sqltypes.Blob, []byte("two"))},
+	}, {
+		id:  3,
+		doc: `{"name": "three", "active": true}`,
+	}, {
+		id:  4,
+		doc: `{"name": "four", "active": false}`,
+	}, {
+		id:  6,
+		doc: `{"name": "six"}`,
+	}}
+	for _, tcase := range testcases {
+		values := []sqltypes.Value{sqltypes.NewInt64(tcase.id), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(tcase.doc))}
+		result := make([]sqltypes.Value, len(plan.ColExprs))
+		match, err := plan.filter(values, result, []collations.ID{collations.CollationBinaryID, collations.CollationBinaryID})
+		require.NoError(t, err)
+		assert.Equal(t, tcase.match, match, "id %d", tcase.id)
+		if tcase.match {
+			assert.Equal(t, tcase.result, result)
+			// the values of the computed columns have the type of their field
+			for i, value := range result {
+				assert.Equal(t, fields[i].Type, value.Type())
+			}
+		}
+	}
+}
+
 func TestCompare(t *testing.T) {
 	type testcase struct {
 		opcode                   Opcode
@@ -0,0 +1,439 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+// autoIncrement matches the AUTO_INCREMENT table option, which changes on every insert.
+var autoIncrement = regexp.MustCompile(` AUTO_INCREMENT=\d+`)
+
+// schemaChangeHints are the hints used to diff the definitions of a table before and after a DDL.
+var schemaChangeHints = &schemadiff.DiffHints{ColumnRenameStrategy: schemadiff.ColumnRenameHeuristicStatement}
+
+// tableChange is a table changed by a DDL. oldName is empty if the DDL
+// creates the table, and newName is empty if the DDL drops it.
+type tableChange struct {
+	oldName string
+	newName string
+}
+
+// ddlTableChanges returns the tables of the database changed by a DDL that match the filter.
+// DDLs that don't change the definition of tables, like TRUNCATE or view DDLs, change no tables.
+func ddlTableChanges(stmt sqlparser.DDLStatement, dbname string, filter *binlogdatapb.Filter) []tableChange {
+	var changes []tableChange
+	add := func(oldTable, newTable sqlparser.TableName) {
+		if !oldTable.IsEmpty() && !oldTable.Qualifier.IsEmpty() && oldTable.Qualifier.String() != dbname {
+			return
+		}
+		if !newTable.IsEmpty() && !newTable.Qualifier.IsEmpty() && newTable.Qualifier.String() != dbname {
+			return
+		}
+		if (oldTable.IsEmpty() || !tableMatches(oldTable, dbname, filter)) && (newTable.IsEmpty() || !tableMatches(newTable, dbname, filter)) {
+			return
+		}
+		changes = append(changes, tableChange{oldName: oldTable.Name.String(), newName: newTable.Name.String()})
+	}
+
+	switch stmt := stmt.(type) {
+	case *sqlparser.CreateTable:
+		add(sqlparser.TableName{}, stmt.Table)
+	case *sqlparser.AlterTable:
+		newTable := stmt.Table
+		for _, option := range stmt.AlterOptions {
+			if rename, ok := option.(*sqlparser.RenameTableName); ok {
+				newTable = rename.Table
+			}
+		}
+		add(stmt.Table, newTable)
+	case *sqlparser.DropTable:
+		for _, table := range stmt.FromTables {
+			add(table, sqlparser.TableName{})
+		}
+	case *sqlparser.RenameTable:
+		// A RENAME TABLE statement can chain renames, like when swapping two tables
+		// through a temporary one, so only the original and final names are kept.
+		var names []sqlparser.TableName
+		origins := make(map[string]sqlparser.TableName)
+		for _, pair := range stmt.TablePairs {
+			from, to := pair.FromTable.Name.String(), pair.ToTable.Name.String()
+			origin, ok := origins[from]
+			if ok {
+				delete(origins, from)
+			} else {
+				origin = pair.FromTable
+			}
+			origins[to] = origin
+			names = append(names, pair.ToTable)
+		}
+		for _, name := range names {
+			origin, ok := origins[name.Name.String()]
+			if !ok {
+				continue
+			}
+			delete(origins, name.Name.String())
+			if origin.Name.String() != name.Name.String() {
+				add(origin, name)
+			}
+		}
+	}
+	return changes
+}
+
+// columnChanges returns the changes of the columns of a table between two of its definitions.
+// All the columns are added if the table is created, and dropped if the table is dropped.
+func columnChanges(oldCreateTable, newCreateTable string) ([]*binlogdatapb.ColumnChange, error) {
+	diff, err := schemadiff.DiffCreateTablesQueries(oldCreateTable, newCreateTable, schemaChangeHints)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*binlogdatapb.ColumnChange
+	for _, diff := range schemadiff.AllSubsequent(diff) {
+		switch diff := diff.(type) {
+		case *schemadiff.CreateTableEntityDiff:
+			_, to := diff.Entities()
+			for _, col := range to.(*schemadiff.CreateTableEntity).TableSpec.Columns {
+				changes = append(changes, &binlogdatapb.ColumnChange{
+					Type:       binlogdatapb.ColumnChange_ADD,
+					Name:       col.Name.String(),
+					Definition: sqlparser.String(col),
+				})
+			}
+		case *schemadiff.DropTableEntityDiff:
+			from, _ := diff.Entities()
+			for _, col := range from.(*schemadiff.CreateTableEntity).TableSpec.Columns {
+				changes = append(changes, &binlogdatapb.ColumnChange{
+					Type: binlogdatapb.ColumnChange_DROP,
+					Name: col.Name.String(),
+				})
+			}
+		case *schemadiff.AlterTableEntityDiff:
+			_, to := diff.Entities()
+			newColumns := make(map[string]*sqlparser.ColumnDefinition)
+			for _, col := range to.(*schemadiff.CreateTableEntity).TableSpec.Columns {
+				newColumns[col.Name.Lowered()] = col
+			}
+			for _, option := range diff.AlterTable().AlterOptions {
+				switch option := option.(type) {
+				case *sqlparser.AddColumns:
+					for _, col := range option.Columns {
+						changes = append(changes, &binlogdatapb.ColumnChange{
+							Type:       binlogdatapb.ColumnChange_ADD,
+							Name:       col.Name.String(),
+							Definition: sqlparser.String(col),
+						})
+					}
+				case *sqlparser.DropColumn:
+					changes = append(changes, &binlogdatapb.ColumnChange{
+						Type: binlogdatapb.ColumnChange_DROP,
+						Name: option.Name.Name.String(),
+					})
+				case *sqlparser.ModifyColumn:
+					changes = append(changes, &binlogdatapb.ColumnChange{
+						Type:       binlogdatapb.ColumnChange_MODIFY,
+						Name:       option.NewColDefinition.Name.String(),
+						Definition: sqlparser.String(option.NewColDefinition),
+					})
+				case *sqlparser.RenameColumn:
+					change := &binlogdatapb.ColumnChange{
+						Type:    binlogdatapb.ColumnChange_RENAME,
+						Name:    option.NewName.Name.String(),
+						OldName: option.OldName.Name.String(),
+					}
+					if col, ok := newColumns[option.NewName.Name.Lowered()]; ok {
+						change.Definition = sqlparser.String(col)
+					}
+					changes = append(changes, change)
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// showCreateTable returns the definition of a table, or an empty string if it doesn't exist.
+func showCreateTable(conn *mysql.Conn, database, table string) (string, error) {
+	query := fmt.Sprintf("show create table %s.%s", sqlescape.EscapeID(database), sqlescape.EscapeID(table))
+	qr, err := conn.ExecuteFetch(query, 1, true)
+	if err != nil {
+		if sqlErr, ok := err.(*sqlerror.SQLError); ok && sqlErr.Number() == sqlerror.ERNoSuchTable {
+			return "", nil
+		}
+		return "", err
+	}
+	// views have no table definition
+	if len(qr.Rows) == 0 || len(qr.Fields) < 2 || qr.Fields[1].Name != "Create Table" {
+		return "", nil
+	}
+	return autoIncrement.ReplaceAllLiteralString(qr.Rows[0][1].ToString(), ""), nil
+}
+
+// newTableEntity parses the definition of a table.
+func newTableEntity(definition string) (*schemadiff.CreateTableEntity, error) {
+	stmt, err := sqlparser.ParseStrictDDL(definition)
+	if err != nil {
+		return nil, err
+	}
+	createTable, ok := stmt.(*sqlparser.CreateTable)
+	if !ok {
+		return nil, fmt.Errorf("not a table definition: %s", definition)
+	}
+	return schemadiff.NewCreateTableEntity(createTable)
+}
+
+// renameTableEntity returns a copy of the definition of a table with another name.
+func renameTableEntity(entity *schemadiff.CreateTableEntity, name string) *schemadiff.CreateTableEntity {
+	dup := entity.Clone().(*schemadiff.CreateTableEntity)
+	dup.Table = sqlparser.NewTableName(name)
+	return dup
+}
+
+// tableDefinition returns the definition of a table as a CREATE TABLE statement.
+func tableDefinition(entity *schemadiff.CreateTableEntity) string {
+	if entity == nil {
+		return ""
+	}
+	return entity.Create().CanonicalStatementString()
+}
+
+// applyDDL returns the definitions of the tables changed by a DDL after it, given their definitions
+// before it. A definition is nil if the table was dropped, and is unknown if the DDL can't be applied.
+func applyDDL(ddl sqlparser.DDLStatement, changes []tableChange, oldEntities []*schemadiff.CreateTableEntity, oldKnown []bool, definitions map[string]*schemadiff.CreateTableEntity) ([]*schemadiff.CreateTableEntity, []bool) {
+	newEntities := make([]*schemadiff.CreateTableEntity, len(changes))
+	newKnown := make([]bool, len(changes))
+	for i, change := range changes {
+		if change.newName == "" {
+			newKnown[i] = true
+			continue
+		}
+		switch ddl := ddl.(type) {
+		case *sqlparser.CreateTable:
+			switch {
+			case !oldKnown[i]:
+				// CREATE TABLE IF NOT EXISTS on a table that may already exist
+			case ddl.OptLike != nil:
+				like, ok := definitions[ddl.OptLike.LikeTable.Name.String()]
+				if ok {
+					newEntities[i], newKnown[i] = renameTableEntity(like, change.newName), true
+				}
+			default:
+				createTable := sqlparser.CloneRefOfCreateTable(ddl)
+				createTable.Table = sqlparser.NewTableName(change.newName)
+				entity, err := schemadiff.NewCreateTableEntity(createTable)
+				if err != nil {
+					log.Warningf("Cannot apply DDL %q to the definition of table %s: %v", sqlparser.String(ddl), change.newName, err)
+					break
+				}
+				newEntities[i], newKnown[i] = entity, true
+			}
+		case *sqlparser.AlterTable:
+			if !oldKnown[i] || oldEntities[i] == nil {
+				break
+			}
+			entity, err := oldEntities[i].ApplyAlterTable(ddl)
+			if err != nil {
+				log.Warningf("Cannot apply DDL %q to the definition of table %s: %v", sqlparser.String(ddl), change.oldName, err)
+				break
+			}
+			newEntities[i], newKnown[i] = renameTableEntity(entity, change.newName), true
+		case *sqlparser.RenameTable:
+			if oldKnown[i] && oldEntities[i] != nil {
+				newEntities[i], newKnown[i] = renameTableEntity(oldEntities[i], change.newName), true
+			}
+		}
+	}
+	return newEntities, newKnown
+}
+
+// trackSchemaChanges returns the changes of the tables of a DDL. The definitions of the tables before
+// the DDL are taken from definitions, which are then updated with the DDL if track is set.
+func trackSchemaChanges(ddl sqlparser.DDLStatement, changes []tableChange, definitions map[string]*schemadiff.CreateTableEntity, track bool) []*binlogdatapb.SchemaChange {
+	// CREATE TABLE IF NOT EXISTS leaves a table that already exists unchanged. Whether the table
+	// existed is only known if the definitions are tracked, as they otherwise already reflect the DDL.
+	ifNotExists := false
+	if createTable, ok := ddl.(*sqlparser.CreateTable); ok && createTable.IfNotExists {
+		if _, exists := definitions[changes[0].newName]; exists && track {
+			return nil
+		}
+		ifNotExists = true
+	}
+
+	// the old definitions are all read before being replaced, as tables can be swapped
+	oldEntities := make([]*schemadiff.CreateTableEntity, len(changes))
+	oldKnown := make([]bool, len(changes))
+	for i, change := range changes {
+		switch {
+		case change.oldName == "":
+			oldKnown[i] = track || !ifNotExists
+		case track:
+			oldEntities[i], oldKnown[i] = definitions[change.oldName]
+		}
+	}
+	newEntities, newKnown := applyDDL(ddl, changes, oldEntities, oldKnown, definitions)
+
+	if track {
+		for _, change := range changes {
+			delete(definitions, change.oldName)
+		}
+		for i, change := range changes {
+			if change.newName != "" && newKnown[i] {
+				definitions[change.newName] = newEntities[i]
+			}
+		}
+	}
+
+	schemaChanges := make([]*binlogdatapb.SchemaChange, len(changes))
+	for i, change := range changes {
+		schemaChange := &binlogdatapb.SchemaChange{
+			TableName:      change.newName,
+			OldCreateTable: tableDefinition(oldEntities[i]),
+			NewCreateTable: tableDefinition(newEntities[i]),
+		}
+		if change.newName == "" {
+			schemaChange.TableName = change.oldName
+		}
+		if change.oldName != "" && change.newName != "" && change.oldName != change.newName {
+			schemaChange.OldTableName = change.oldName
+		}
+		// the changes of the columns of a table are unknown if one of its definitions is unknown
+		if oldKnown[i] && newKnown[i] {
+			var err error
+			schemaChange.ColumnChanges, err = columnChanges(schemaChange.OldCreateTable, schemaChange.NewCreateTable)
+			if err != nil {
+				log.Warningf("Cannot compute the column changes of table %s for DDL %q: %v", schemaChange.TableName, sqlparser.String(ddl), err)
+			}
+		}
+		schemaChanges[i] = schemaChange
+	}
+	return schemaChanges
+}
+
+// maxTableDefinitionsLoads is the number of times the definitions of the tables are loaded
+// before giving up on getting them at a consistent position.
+const maxTableDefinitionsLoads = 10
+
+// loadTableDefinitions loads the definitions of the tables matching the filter from the
+// schema of the database, along with the position of the database they were loaded at.
+func (vs *vstreamer) loadTableDefinitions(ctx context.Context) error {
+	conn, err := vs.cp.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	definitions, pos, err := loadConsistentDefinitions(conn.PrimaryPosition, func() (map[string]string, error) {
+		definitions := make(map[string]string)
+		for name := range vs.se.GetSchema() {
+			if name == "dual" || !ruleMatches(name, vs.filter) {
+				continue
+			}
+			definition, err := showCreateTable(conn, vs.cp.DBName(), name)
+			if err != nil {
+				return nil, err
+			}
+			if definition != "" {
+				definitions[name] = definition
+			}
+		}
+		return definitions, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	vs.tableDefinitions = make(map[string]*schemadiff.CreateTableEntity, len(definitions))
+	for name, definition := range definitions {
+		entity, err := newTableEntity(definition)
+		if err != nil {
+			log.Warningf("Cannot parse the definition of table %s: %v", name, err)
+			continue
+		}
+		vs.tableDefinitions[name] = entity
+	}
+	vs.definitionsPos = pos
+	return nil
+}
+
+// loadConsistentDefinitions loads the definitions of the tables along with the position they
+// reflect all the DDLs up to, and none after. The tables are not loaded in a single snapshot,
+// so a DDL committed while they are loaded could be missed. The definitions are consistent:
+//   - when the position is the same before and after loading them, as nothing was committed,
+//   - or when they are the same as the definitions loaded just before, as none of the DDLs
+//     committed while the two were loaded changed them, so they reflect all the DDLs up to the
+//     position read between the two loads.
+func loadConsistentDefinitions(readPos func() (replication.Position, error), load func() (map[string]string, error)) (map[string]string, replication.Position, error) {
+	var prevDefinitions map[string]string
+	var prevPos replication.Position
+	for i := 0; i < maxTableDefinitionsLoads; i++ {
+		before, err := readPos()
+		if err != nil {
+			return nil, replication.Position{}, err
+		}
+		definitions, err := load()
+		if err != nil {
+			return nil, replication.Position{}, err
+		}
+		after, err := readPos()
+		if err != nil {
+			return nil, replication.Position{}, err
+		}
+		if before.Equal(after) {
+			return definitions, after, nil
+		}
+		if prevDefinitions != nil && maps.Equal(prevDefinitions, definitions) {
+			return definitions, prevPos, nil
+		}
+		prevDefinitions, prevPos = definitions, after
+	}
+	return nil, replication.Position{}, fmt.Errorf("cannot load the table definitions at a consistent position after %d attempts", maxTableDefinitionsLoads)
+}
+
+// buildSchemaChanges returns the changes of the tables of a DDL. The definitions of the tables
+// are those loaded when the stream started, with the DDLs streamed since then applied to them.
+// The DDLs streamed before the position the definitions were loaded at are already reflected by
+// them, so the definitions of the tables they change are unknown at their position, unless
+// they create the tables.
+func (vs *vstreamer) buildSchemaChanges(sql string) []*binlogdatapb.SchemaChange {
+	stmt, err := sqlparser.Parse(sql)
+	if err != nil {
+		return nil
+	}
+	ddl, ok := stmt.(sqlparser.DDLStatement)
+	if !ok {
+		return nil
+	}
+	changes := ddlTableChanges(ddl, vs.cp.DBName(), vs.filter)
+	if len(changes) == 0 {
+		return nil
+	}
+	return trackSchemaChanges(ddl, changes, vs.tableDefinitions, !vs.definitionsPos.AtLeast(vs.pos))
+}
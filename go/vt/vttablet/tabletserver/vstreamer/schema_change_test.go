@@ -0,0 +1,272 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vstreamer
+
+import (
+	"testing"
+
+	"golang.org/x/exp/maps"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/vt/schemadiff"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+)
+
+func TestDDLTableChanges(t *testing.T) {
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/t.*/",
+		}},
+	}
+	testcases := []struct {
+		sql    string
+		output []tableChange
+	}{{
+		sql:    "create table t1(id int)",
+		output: []tableChange{{newName: "t1"}},
+	}, {
+		sql: "create table foo(id int)",
+	}, {
+		sql: "create table db.t1(id int)",
+	}, {
+		sql:    "create table mydb.t1(id int)",
+		output: []tableChange{{newName: "t1"}},
+	}, {
+		sql:    "alter table t1 add column val int",
+		output: []tableChange{{oldName: "t1", newName: "t1"}},
+	}, {
+		sql:    "alter table t1 rename to foo",
+		output: []tableChange{{oldName: "t1", newName: "foo"}},
+	}, {
+		sql:    "drop table t1, foo, t2",
+		output: []tableChange{{oldName: "t1"}, {oldName: "t2"}},
+	}, {
+		sql:    "rename table foo to t1, t2 to t3",
+		output: []tableChange{{oldName: "foo", newName: "t1"}, {oldName: "t2", newName: "t3"}},
+	}, {
+		sql:    "rename table t1 to tmp, t2 to t1, tmp to t2",
+		output: []tableChange{{oldName: "t2", newName: "t1"}, {oldName: "t1", newName: "t2"}},
+	}, {
+		sql: "rename table t1 to tmp, tmp to t1",
+	}, {
+		sql: "truncate table t1",
+	}, {
+		sql: "create view t1 as select 1 from dual",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tcase.sql)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.output, ddlTableChanges(stmt.(sqlparser.DDLStatement), "mydb", filter))
+		})
+	}
+}
+
+func TestColumnChanges(t *testing.T) {
+	testcases := []struct {
+		name   string
+		from   string
+		to     string
+		output []*binlogdatapb.ColumnChange
+	}{{
+		name: "create",
+		to:   "create table t1 (id int, val varchar(10), primary key (id))",
+		output: []*binlogdatapb.ColumnChange{
+			{Type: binlogdatapb.ColumnChange_ADD, Name: "id", Definition: "id int"},
+			{Type: binlogdatapb.ColumnChange_ADD, Name: "val", Definition: "val varchar(10)"},
+		},
+	}, {
+		name: "drop",
+		from: "create table t1 (id int, val varchar(10), primary key (id))",
+		output: []*binlogdatapb.ColumnChange{
+			{Type: binlogdatapb.ColumnChange_DROP, Name: "id"},
+			{Type: binlogdatapb.ColumnChange_DROP, Name: "val"},
+		},
+	}, {
+		name: "alter",
+		from: "create table t1 (id int, val varchar(10), old int, primary key (id))",
+		to:   "create table t1 (id bigint, val varchar(10), created datetime, primary key (id))",
+		output: []*binlogdatapb.ColumnChange{
+			{Type: binlogdatapb.ColumnChange_DROP, Name: "old"},
+			{Type: binlogdatapb.ColumnChange_MODIFY, Name: "id", Definition: "id bigint"},
+			{Type: binlogdatapb.ColumnChange_ADD, Name: "created", Definition: "created datetime"},
+		},
+	}, {
+		name: "rename",
+		from: "create table t1 (id int, val varchar(10), primary key (id))",
+		to:   "create table t1 (id int, name varchar(10), primary key (id))",
+		output: []*binlogdatapb.ColumnChange{
+			{Type: binlogdatapb.ColumnChange_RENAME, Name: "name", OldName: "val", Definition: "`name` varchar(10)"},
+		},
+	}, {
+		name: "index only",
+		from: "create table t1 (id int, val varchar(10), primary key (id))",
+		to:   "create table t1 (id int, val varchar(10), primary key (id), key (val))",
+	}}
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			changes, err := columnChanges(tcase.from, tcase.to)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.output, changes)
+		})
+	}
+
+	_, err := columnChanges("create view v1 as select 1 from dual", "")
+	assert.Error(t, err)
+}
+
+func TestTrackSchemaChanges(t *testing.T) {
+	filter := &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{{
+			Match: "/t.*/",
+		}},
+	}
+	entity, err := newTableEntity("create table t1 (id int, primary key (id))")
+	require.NoError(t, err)
+	definitions := map[string]*schemadiff.CreateTableEntity{"t1": entity}
+
+	track := func(sql string, track bool) []*binlogdatapb.SchemaChange {
+		stmt, err := sqlparser.Parse(sql)
+		require.NoError(t, err)
+		ddl := stmt.(sqlparser.DDLStatement)
+		return trackSchemaChanges(ddl, ddlTableChanges(ddl, "mydb", filter), definitions, track)
+	}
+
+	// the DDLs already reflected by the definitions change tables with unknown definitions
+	changes := track("alter table t1 add column c1 int", false)
+	assert.Equal(t, []*binlogdatapb.SchemaChange{{TableName: "t1"}}, changes)
+	changes = track("create table t2 (id int, primary key (id))", false)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "t2", changes[0].TableName)
+	assert.Empty(t, changes[0].OldCreateTable)
+	assert.Equal(t, "CREATE TABLE `t2` (\n\t`id` int,\n\tPRIMARY KEY (`id`)\n)", changes[0].NewCreateTable)
+	assert.Equal(t, []*binlogdatapb.ColumnChange{{Type: binlogdatapb.ColumnChange_ADD, Name: "id", Definition: "id int"}}, changes[0].ColumnChanges)
+	assert.NotContains(t, definitions, "t2")
+	changes = track("create table if not exists t1 (id int, primary key (id))", false)
+	assert.Equal(t, []*binlogdatapb.SchemaChange{{TableName: "t1"}}, changes)
+
+	// the DDLs streamed after the definitions were loaded are applied to them
+	changes = track("alter table t1 add column c1 int", true)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "CREATE TABLE `t1` (\n\t`id` int,\n\tPRIMARY KEY (`id`)\n)", changes[0].OldCreateTable)
+	assert.Equal(t, "CREATE TABLE `t1` (\n\t`id` int,\n\t`c1` int,\n\tPRIMARY KEY (`id`)\n)", changes[0].NewCreateTable)
+	assert.Equal(t, []*binlogdatapb.ColumnChange{{Type: binlogdatapb.ColumnChange_ADD, Name: "c1", Definition: "c1 int"}}, changes[0].ColumnChanges)
+
+	changes = track("alter table t1 add column c2 int", true)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []*binlogdatapb.ColumnChange{{Type: binlogdatapb.ColumnChange_ADD, Name: "c2", Definition: "c2 int"}}, changes[0].ColumnChanges)
+
+	changes = track("rename table t1 to t3", true)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "t3", changes[0].TableName)
+	assert.Equal(t, "t1", changes[0].OldTableName)
+	assert.Empty(t, changes[0].ColumnChanges)
+	assert.Equal(t, "CREATE TABLE `t3` (\n\t`id` int,\n\t`c1` int,\n\t`c2` int,\n\tPRIMARY KEY (`id`)\n)", changes[0].NewCreateTable)
+
+	changes = track("alter table t3 change column c1 c3 int", true)
+	require.Len(t, changes, 1)
+	assert.Equal(t, []*binlogdatapb.ColumnChange{{Type: binlogdatapb.ColumnChange_RENAME, Name: "c3", OldName: "c1", Definition: "c3 int"}}, changes[0].ColumnChanges)
+
+	changes = track("create table t4 like t3", true)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "CREATE TABLE `t4` (\n\t`id` int,\n\t`c3` int,\n\t`c2` int,\n\tPRIMARY KEY (`id`)\n)", changes[0].NewCreateTable)
+
+	// CREATE TABLE IF NOT EXISTS leaves the tables that already exist unchanged
+	changes = track("create table if not exists t4 (id int, primary key (id))", true)
+	assert.Empty(t, changes)
+	assert.Equal(t, "CREATE TABLE `t4` (\n\t`id` int,\n\t`c3` int,\n\t`c2` int,\n\tPRIMARY KEY (`id`)\n)", tableDefinition(definitions["t4"]))
+
+	changes = track("drop table t3", true)
+	require.Len(t, changes, 1)
+	assert.Len(t, changes[0].ColumnChanges, 3)
+	assert.Equal(t, []string{"t4"}, maps.Keys(definitions))
+}
+
+func TestLoadConsistentDefinitions(t *testing.T) {
+	mustParse := func(pos string) replication.Position {
+		p, err := replication.DecodePosition(pos)
+		require.NoError(t, err)
+		return p
+	}
+	pos1 := mustParse("MySQL56/a5b8f9e0-1f2d-11ee-9c4e-0242ac120002:1-10")
+	pos2 := mustParse("MySQL56/a5b8f9e0-1f2d-11ee-9c4e-0242ac120002:1-11")
+	pos3 := mustParse("MySQL56/a5b8f9e0-1f2d-11ee-9c4e-0242ac120002:1-12")
+	v1 := map[string]string{"t1": "create table t1 (id int)"}
+	v2 := map[string]string{"t1": "create table t1 (id int, c int)"}
+
+	testcases := []struct {
+		name        string
+		positions   []replication.Position
+		definitions []map[string]string
+		want        map[string]string
+		wantPos     replication.Position
+		wantErr     string
+	}{{
+		name:        "nothing committed while loading",
+		positions:   []replication.Position{pos1, pos1},
+		definitions: []map[string]string{v1},
+		want:        v1,
+		wantPos:     pos1,
+	}, {
+		// a DDL committed while loading may be missed by the first load, but
+		// it's reflected by the second one, which loads other definitions
+		name:        "ddl committed while loading",
+		positions:   []replication.Position{pos1, pos2, pos2, pos3, pos3, pos3},
+		definitions: []map[string]string{v1, v2, v2},
+		want:        v2,
+		wantPos:     pos3,
+	}, {
+		name:        "same definitions loaded twice",
+		positions:   []replication.Position{pos1, pos2, pos2, pos3},
+		definitions: []map[string]string{v1, v1},
+		want:        v1,
+		wantPos:     pos2,
+	}, {
+		name:        "definitions changing on every load",
+		positions:   []replication.Position{pos1, pos2},
+		definitions: []map[string]string{v1, v2},
+		wantErr:     "cannot load the table definitions at a consistent position after 10 attempts",
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			reads, loads := 0, 0
+			readPos := func() (replication.Position, error) {
+				pos := tc.positions[reads%len(tc.positions)]
+				reads++
+				return pos, nil
+			}
+			load := func() (map[string]string, error) {
+				definitions := tc.definitions[loads%len(tc.definitions)]
+				loads++
+				return definitions, nil
+			}
+			definitions, pos, err := loadConsistentDefinitions(readPos, load)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, definitions)
+			assert.True(t, tc.wantPos.Equal(pos), "got position %v, want %v", pos, tc.wantPos)
+		})
+	}
+}
@@ -39,6 +39,7 @@ import (
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	vtschema "vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/schemadiff"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet"
@@ -75,6 +76,12 @@ type vstreamer struct {
 	journalTableID uint64
 	versionTableID uint64
 
+	// tableDefinitions are the definitions of the tables matching the filter, if the
+	// filter requests schema change events. They are loaded from the database at
+	// definitionsPos, and kept up to date with the DDLs streamed after it.
+	tableDefinitions map[string]*schemadiff.CreateTableEntity
+	definitionsPos   replication.Position
+
 	// format and pos are updated by parseEvent.
 	format  mysql.BinlogFormat
 	pos     replication.Position
@@ -184,6 +191,12 @@ func (vs *vstreamer) replicate(ctx context.Context) error {
 		return wrapError(err, vs.pos, vs.vse)
 	}
 
+	if vs.filter.SchemaChangeEvents {
+		if err := vs.loadTableDefinitions(vs.ctx); err != nil {
+			return wrapError(err, vs.pos, vs.vse)
+		}
+	}
+
 	conn, err := binlog.NewBinlogConnection(vs.cp)
 	if err != nil {
 		return wrapError(err, vs.pos, vs.vse)
@@ -505,12 +518,17 @@ func (vs *vstreamer) parseEvent(ev mysql.BinlogEvent) ([]*binlogdatapb.VEvent, e
 			})
 		case sqlparser.StmtDDL:
 			if mustSendDDL(q, vs.cp.DBName(), vs.filter) {
+				var schemaChanges []*binlogdatapb.SchemaChange
+				if vs.filter.SchemaChangeEvents {
+					schemaChanges = vs.buildSchemaChanges(q.SQL)
+				}
 				vevents = append(vevents, &binlogdatapb.VEvent{
 					Type: binlogdatapb.VEventType_GTID,
 					Gtid: replication.EncodePosition(vs.pos),
 				}, &binlogdatapb.VEvent{
-					Type:      binlogdatapb.VEventType_DDL,
-					Statement: q.SQL,
+					Type:          binlogdatapb.VEventType_DDL,
+					Statement:     q.SQL,
+					SchemaChanges: schemaChanges,
 				})
 			} else {
 				// If the DDL need not be sent, send a dummy OTHER event.
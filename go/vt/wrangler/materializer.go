@@ -792,7 +792,7 @@ func (wr *Wrangler) prepareCreateLookup(ctx context.Context, keyspace string, sp
 		MaterializationIntent: vtctldatapb.MaterializationIntent_CREATELOOKUPINDEX,
 		SourceKeyspace:        keyspace,
 		TargetKeyspace:        targetKeyspace,
-		StopAfterCopy:         vindex.Owner != "" && !continueAfterCopyWithOwner,
+		StopAfterCopy:         vindex.Owner != "" && !continueAfterCopyWithOwner && !workflow.IsAsyncLookupVindex(vindex),
 		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
 			TargetTable:      targetTableName,
 			SourceExpression: materializeQuery,
@@ -837,6 +837,7 @@ func (wr *Wrangler) ExternalizeVindex(ctx context.Context, qualifiedVindexName s
 	if sourceVindex == nil {
 		return fmt.Errorf("vindex %s not found in vschema", qualifiedVindexName)
 	}
+	asyncVindex := workflow.IsAsyncLookupVindex(sourceVindex)
 
 	targetKeyspace, targetTableName, err := sqlparser.ParseTable(sourceVindex.Params["table"])
 	if err != nil || targetKeyspace == "" {
@@ -910,8 +911,9 @@ func (wr *Wrangler) ExternalizeVindex(ctx context.Context, qualifiedVindexName s
 		return err
 	}
 
-	if sourceVindex.Owner != "" {
-		// If there is an owner, we have to delete the streams.
+	if sourceVindex.Owner != "" && !asyncVindex {
+		// If there is an owner, we have to delete the streams, unless the vindex
+		// is async and keeps being maintained by them.
 		err := forAllTargets(func(targetShard *topo.ShardInfo) error {
 			targetPrimary, err := wr.ts.GetTablet(ctx, targetShard.PrimaryAlias)
 			if err != nil {